@@ -1,3 +1,5 @@
 module github.com/agentplexus/omniretrieve
 
 go 1.24.11
+
+require golang.org/x/sync v0.17.0
@@ -0,0 +1,247 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// EdgeLister is implemented by graphs that can list all their edges, for
+// whole-graph analytics such as centrality.
+type EdgeLister interface {
+	KnowledgeGraph
+	// Edges returns every edge in the graph.
+	Edges(ctx context.Context) ([]Edge, error)
+}
+
+// Centrality scores nodes by their importance in the graph.
+type Centrality interface {
+	// Scores returns the centrality of each of ids in [0, 1], with 1 the
+	// most central node in the graph. Unknown nodes score 0.
+	Scores(ctx context.Context, ids []string) (map[string]float64, error)
+}
+
+// CentralityAlgorithm selects how centrality is computed.
+type CentralityAlgorithm string
+
+const (
+	// CentralityPageRank ranks nodes by PageRank over the edge directions.
+	CentralityPageRank CentralityAlgorithm = "pagerank"
+	// CentralityDegree ranks nodes by their number of edges, in or out.
+	CentralityDegree CentralityAlgorithm = "degree"
+)
+
+// PageRankOptions configures PageRank.
+type PageRankOptions struct {
+	// Damping is the probability of following an edge rather than jumping
+	// to a random node (default 0.85).
+	Damping float64
+	// Iterations is the maximum number of power iterations (default 100).
+	Iterations int
+	// Tolerance stops the iteration once the scores change by less than it
+	// in total (default 1e-6).
+	Tolerance float64
+	// Weighted splits a node's rank across its edges by Edge.Weight instead
+	// of evenly. Nodes whose edges all have non-positive weights split it
+	// evenly.
+	Weighted bool
+}
+
+// PageRank computes the PageRank of the nodes connected by edges. Scores sum
+// to 1; rank of nodes without outgoing edges is spread over all nodes.
+func PageRank(edges []Edge, opts PageRankOptions) map[string]float64 {
+	if opts.Damping <= 0 || opts.Damping >= 1 {
+		opts.Damping = 0.85
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 100
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-6
+	}
+
+	index := make(map[string]int)
+	var ids []string
+	add := func(id string) {
+		if _, ok := index[id]; !ok {
+			index[id] = len(ids)
+			ids = append(ids, id)
+		}
+	}
+	for _, e := range edges {
+		add(e.From)
+		add(e.To)
+	}
+	n := len(ids)
+	if n == 0 {
+		return map[string]float64{}
+	}
+
+	type link struct {
+		to     int
+		weight float64
+	}
+	out := make([][]link, n)
+	for _, e := range edges {
+		from := index[e.From]
+		out[from] = append(out[from], link{to: index[e.To], weight: e.Weight})
+	}
+
+	// Normalize each node's outgoing weights to sum to 1
+	for _, links := range out {
+		total := 0.0
+		if opts.Weighted {
+			for _, l := range links {
+				total += max(l.weight, 0)
+			}
+		}
+		for i := range links {
+			if total > 0 {
+				links[i].weight = max(links[i].weight, 0) / total
+			} else {
+				links[i].weight = 1 / float64(len(links))
+			}
+		}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < opts.Iterations; iter++ {
+		dangling := 0.0
+		for i, links := range out {
+			if len(links) == 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1-opts.Damping)/float64(n) + opts.Damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i, links := range out {
+			for _, l := range links {
+				next[l.to] += opts.Damping * rank[i] * l.weight
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < opts.Tolerance {
+			break
+		}
+	}
+
+	scores := make(map[string]float64, n)
+	for i, id := range ids {
+		scores[id] = rank[i]
+	}
+	return scores
+}
+
+// DegreeCentrality returns the number of edges of each node connected by
+// edges, counting incoming and outgoing edges.
+func DegreeCentrality(edges []Edge) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, e := range edges {
+		scores[e.From]++
+		scores[e.To]++
+	}
+	return scores
+}
+
+// CentralityScorerConfig configures a CentralityScorer.
+type CentralityScorerConfig struct {
+	// Graph is the graph to analyze.
+	Graph EdgeLister
+	// Algorithm is the centrality measure (default CentralityPageRank).
+	Algorithm CentralityAlgorithm
+	// PageRank configures CentralityPageRank.
+	PageRank PageRankOptions
+	// MaxAge is how long computed scores are used before they are
+	// recomputed on the next call to Scores (default 0: until Refresh).
+	MaxAge time.Duration
+}
+
+// CentralityScorer implements Centrality by computing the centrality of the
+// whole graph on demand and caching it, normalized so the most central node
+// scores 1.
+type CentralityScorer struct {
+	config CentralityScorerConfig
+
+	mu         sync.Mutex
+	scores     map[string]float64
+	computedAt time.Time
+}
+
+// NewCentralityScorer creates a new centrality scorer.
+func NewCentralityScorer(cfg CentralityScorerConfig) *CentralityScorer {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = CentralityPageRank
+	}
+	return &CentralityScorer{config: cfg}
+}
+
+// Refresh recomputes the scores from the graph's current edges.
+func (c *CentralityScorer) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refresh(ctx)
+}
+
+// refresh recomputes the scores. The caller must hold the lock.
+func (c *CentralityScorer) refresh(ctx context.Context) error {
+	edges, err := c.config.Graph.Edges(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list edges: %w", err)
+	}
+
+	var scores map[string]float64
+	switch c.config.Algorithm {
+	case CentralityPageRank:
+		scores = PageRank(edges, c.config.PageRank)
+	case CentralityDegree:
+		scores = DegreeCentrality(edges)
+	default:
+		return fmt.Errorf("unknown centrality algorithm %q", c.config.Algorithm)
+	}
+
+	top := 0.0
+	for _, s := range scores {
+		top = max(top, s)
+	}
+	if top > 0 {
+		for id, s := range scores {
+			scores[id] = s / top
+		}
+	}
+	c.scores, c.computedAt = scores, time.Now()
+	return nil
+}
+
+// Scores implements Centrality, computing the scores on first use and once
+// they are older than MaxAge.
+func (c *CentralityScorer) Scores(ctx context.Context, ids []string) (map[string]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.scores == nil || (c.config.MaxAge > 0 && time.Since(c.computedAt) > c.config.MaxAge) {
+		if err := c.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	scores := make(map[string]float64, len(ids))
+	for _, id := range ids {
+		scores[id] = c.scores[id]
+	}
+	return scores, nil
+}
+
+// Verify interface compliance
+var _ Centrality = (*CentralityScorer)(nil)
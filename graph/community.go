@@ -0,0 +1,448 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// Community is a densely connected group of nodes.
+type Community struct {
+	// ID identifies the community within one detection run.
+	ID string
+	// Members are the IDs of the community's nodes, sorted.
+	Members []string
+}
+
+// LouvainOptions configures Louvain.
+type LouvainOptions struct {
+	// Resolution trades community size for number: values above 1 yield
+	// more, smaller communities (default 1).
+	Resolution float64
+	// Unweighted counts every edge as weight 1. By default edges count by
+	// Weight and edges with non-positive weight are ignored.
+	Unweighted bool
+	// MaxPasses bounds the node moving passes per level (default 100).
+	MaxPasses int
+}
+
+// Louvain detects communities with the Louvain modularity optimization,
+// treating edges as undirected. Nodes are visited in ID order, so results
+// are deterministic. Communities are ordered by size, largest first, then by
+// their smallest member, and numbered "c0", "c1", and so on. Only nodes with
+// edges are assigned to communities.
+func Louvain(edges []Edge, opts LouvainOptions) []Community {
+	if opts.Resolution <= 0 {
+		opts.Resolution = 1
+	}
+	if opts.MaxPasses <= 0 {
+		opts.MaxPasses = 100
+	}
+
+	var ids []string
+	index := make(map[string]int)
+	for _, e := range edges {
+		for _, id := range []string{e.From, e.To} {
+			if _, ok := index[id]; !ok {
+				index[id] = -1
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	g := &louvainGraph{adj: make([]map[int]float64, len(ids)), self: make([]float64, len(ids))}
+	for i := range g.adj {
+		g.adj[i] = make(map[int]float64)
+	}
+	for _, e := range edges {
+		w := e.Weight
+		if opts.Unweighted {
+			w = 1
+		}
+		if w <= 0 {
+			continue
+		}
+		from, to := index[e.From], index[e.To]
+		if from == to {
+			g.self[from] += w
+			continue
+		}
+		g.adj[from][to] += w
+		g.adj[to][from] += w
+	}
+
+	// membership maps each original node to its community in the current
+	// aggregated graph
+	membership := make([]int, len(ids))
+	for i := range membership {
+		membership[i] = i
+	}
+	for {
+		community, moved := g.moveNodes(opts)
+		if !moved {
+			break
+		}
+		for i, c := range membership {
+			membership[i] = community[c]
+		}
+		g = g.aggregate(community)
+	}
+
+	groups := make(map[int][]string)
+	for i, c := range membership {
+		if g.degree(c) > 0 {
+			groups[c] = append(groups[c], ids[i])
+		}
+	}
+	communities := make([]Community, 0, len(groups))
+	for _, members := range groups {
+		communities = append(communities, Community{Members: members})
+	}
+	sort.Slice(communities, func(i, j int) bool {
+		a, b := communities[i].Members, communities[j].Members
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a[0] < b[0]
+	})
+	for i := range communities {
+		communities[i].ID = "c" + strconv.Itoa(i)
+	}
+	return communities
+}
+
+// louvainGraph is an undirected weighted graph: adj holds the symmetric
+// weights between distinct nodes and self the weight of each node's
+// internal edges.
+type louvainGraph struct {
+	adj  []map[int]float64
+	self []float64
+}
+
+// degree returns the weighted degree of node i, counting internal edges
+// twice.
+func (g *louvainGraph) degree(i int) float64 {
+	k := 2 * g.self[i]
+	for _, w := range g.adj[i] {
+		k += w
+	}
+	return k
+}
+
+// moveNodes greedily moves nodes to the neighboring community with the best
+// modularity gain until no move improves it. It returns each node's
+// community, numbered densely, and whether any node moved.
+func (g *louvainGraph) moveNodes(opts LouvainOptions) ([]int, bool) {
+	n := len(g.adj)
+	community := make([]int, n)
+	degrees := make([]float64, n)
+	totals := make([]float64, n)
+	var m2 float64
+	for i := range community {
+		community[i] = i
+		degrees[i] = g.degree(i)
+		totals[i] = degrees[i]
+		m2 += degrees[i]
+	}
+	if m2 == 0 {
+		return community, false
+	}
+
+	moved := false
+	for pass := 0; pass < opts.MaxPasses; pass++ {
+		improved := false
+		for i := 0; i < n; i++ {
+			links := make(map[int]float64)
+			for j, w := range g.adj[i] {
+				links[community[j]] += w
+			}
+			current := community[i]
+			totals[current] -= degrees[i]
+
+			gain := func(c int) float64 {
+				return links[c] - opts.Resolution*totals[c]*degrees[i]/m2
+			}
+			best, bestGain := current, gain(current)
+			candidates := make([]int, 0, len(links))
+			for c := range links {
+				candidates = append(candidates, c)
+			}
+			sort.Ints(candidates)
+			for _, c := range candidates {
+				if g := gain(c); g > bestGain+1e-12 {
+					best, bestGain = c, g
+				}
+			}
+
+			totals[best] += degrees[i]
+			if best != current {
+				community[i] = best
+				improved, moved = true, true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	// Number the communities densely in order of first appearance
+	dense := make(map[int]int)
+	for i, c := range community {
+		if _, ok := dense[c]; !ok {
+			dense[c] = len(dense)
+		}
+		community[i] = dense[c]
+	}
+	return community, moved
+}
+
+// aggregate returns the graph whose nodes are the communities of g.
+func (g *louvainGraph) aggregate(community []int) *louvainGraph {
+	n := 0
+	for _, c := range community {
+		n = max(n, c+1)
+	}
+	agg := &louvainGraph{adj: make([]map[int]float64, n), self: make([]float64, n)}
+	for i := range agg.adj {
+		agg.adj[i] = make(map[int]float64)
+	}
+	for i, neighbors := range g.adj {
+		ci := community[i]
+		agg.self[ci] += g.self[i]
+		for j, w := range neighbors {
+			if cj := community[j]; cj != ci {
+				agg.adj[ci][cj] += w
+			} else {
+				// Each internal edge is visited from both ends
+				agg.self[ci] += w / 2
+			}
+		}
+	}
+	return agg
+}
+
+// CommunitySummarizer writes the summary of a community, typically with an
+// LLM, for global search.
+type CommunitySummarizer interface {
+	// Summarize returns a summary of the community's nodes.
+	Summarize(ctx context.Context, c Community, nodes []Node) (string, error)
+}
+
+// CommunityIndexConfig configures a CommunityIndex.
+type CommunityIndexConfig struct {
+	// Graph is the graph to detect communities in.
+	Graph EdgeLister
+	// Summarizer writes each community's summary.
+	Summarizer CommunitySummarizer
+	// Index stores the community summary nodes.
+	Index vector.Index
+	// Embedder embeds summaries and query text.
+	Embedder vector.Embedder
+	// Louvain configures community detection.
+	Louvain LouvainOptions
+	// MinSize is the smallest community that gets a summary (default 2).
+	MinSize int
+	// DefaultTopK is the number of summaries retrieved when the query has
+	// no TopK (default 5).
+	DefaultTopK int
+}
+
+// CommunityIndex detects the communities of a graph and indexes a summary
+// node per community, enabling GraphRAG-style global search: a query is
+// answered from the summaries of the communities it relates to rather than
+// from individual nodes. It implements retrieve.Retriever over the
+// summaries; each item's metadata holds the community ID, size, and members.
+type CommunityIndex struct {
+	config CommunityIndexConfig
+
+	mu    sync.Mutex
+	built []string
+}
+
+// Metadata keys of community summary nodes.
+const (
+	// CommunityKey holds the community ID.
+	CommunityKey = "community"
+	// CommunitySizeKey holds the number of members.
+	CommunitySizeKey = "community_size"
+	// CommunityMembersKey holds the comma-separated member IDs.
+	CommunityMembersKey = "community_members"
+)
+
+// NewCommunityIndex creates a new community index.
+func NewCommunityIndex(cfg CommunityIndexConfig) *CommunityIndex {
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = 2
+	}
+	if cfg.DefaultTopK <= 0 {
+		cfg.DefaultTopK = 5
+	}
+	return &CommunityIndex{config: cfg}
+}
+
+// summaryID returns the ID of a community's summary node.
+func summaryID(c Community) string {
+	return "community:" + c.ID
+}
+
+// Build detects communities, summarizes those of at least MinSize members,
+// and upserts their summary nodes. Summaries written by the previous Build
+// whose community no longer exists are deleted. It returns the summarized
+// communities.
+func (ci *CommunityIndex) Build(ctx context.Context) ([]Community, error) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	edges, err := ci.config.Graph.Edges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edges: %w", err)
+	}
+
+	nodes, err := ci.config.Graph.FindNodes(ctx, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	byID := make(map[string]Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+
+	var communities []Community
+	var summaries []vector.Node
+	for _, c := range Louvain(edges, ci.config.Louvain) {
+		if len(c.Members) < ci.config.MinSize {
+			continue
+		}
+		members := make([]Node, 0, len(c.Members))
+		for _, id := range c.Members {
+			if n, ok := byID[id]; ok {
+				members = append(members, n)
+			}
+		}
+		summary, err := ci.config.Summarizer.Summarize(ctx, c, members)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize community %s: %w", c.ID, err)
+		}
+		embedding, err := ci.config.Embedder.Embed(ctx, summary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed summary of community %s: %w", c.ID, err)
+		}
+
+		communities = append(communities, c)
+		summaries = append(summaries, vector.Node{
+			ID:        summaryID(c),
+			Content:   summary,
+			Embedding: embedding,
+			Source:    ci.config.Graph.Name(),
+			Metadata: map[string]string{
+				CommunityKey:        c.ID,
+				CommunitySizeKey:    strconv.Itoa(len(c.Members)),
+				CommunityMembersKey: strings.Join(c.Members, ","),
+			},
+		})
+	}
+
+	if err := ci.replace(ctx, summaries); err != nil {
+		return nil, err
+	}
+	return communities, nil
+}
+
+// replace upserts summaries and deletes the previously built ones that are
+// not among them.
+func (ci *CommunityIndex) replace(ctx context.Context, summaries []vector.Node) error {
+	if batch, ok := ci.config.Index.(vector.BatchIndex); ok {
+		if err := batch.UpsertBatch(ctx, summaries); err != nil {
+			return fmt.Errorf("failed to store community summaries: %w", err)
+		}
+	} else {
+		for _, s := range summaries {
+			if err := ci.config.Index.Upsert(ctx, s); err != nil {
+				return fmt.Errorf("failed to store community summary %s: %w", s.ID, err)
+			}
+		}
+	}
+
+	keep := make(map[string]bool, len(summaries))
+	built := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		keep[s.ID] = true
+		built = append(built, s.ID)
+	}
+	for _, id := range ci.built {
+		if keep[id] {
+			continue
+		}
+		if err := ci.config.Index.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete community summary %s: %w", id, err)
+		}
+	}
+	ci.built = built
+	return nil
+}
+
+// Retrieve implements retrieve.Retriever, returning the community summaries
+// most similar to the query.
+func (ci *CommunityIndex) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
+	start := time.Now()
+
+	embedding := q.Embedding
+	if len(embedding) == 0 {
+		var err error
+		if embedding, err = ci.config.Embedder.Embed(ctx, q.Text); err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+	k := q.TopK
+	if k <= 0 {
+		k = ci.config.DefaultTopK
+	}
+
+	results, err := ci.config.Index.Search(ctx, embedding, k, q.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search community summaries: %w", err)
+	}
+
+	items := make([]retrieve.ContextItem, 0, len(results))
+	for _, r := range results {
+		if q.MinScore > 0 && r.Score < q.MinScore {
+			continue
+		}
+		items = append(items, retrieve.ContextItem{
+			ID:       r.Node.ID,
+			Content:  r.Node.Content,
+			Source:   r.Node.Source,
+			Score:    r.Score,
+			Metadata: r.Node.Metadata,
+			Provenance: retrieve.Provenance{
+				Mode:            retrieve.ModeGraph,
+				Backend:         ci.config.Index.Name(),
+				SimilarityScore: r.Score,
+			},
+		})
+	}
+
+	return &retrieve.Result{
+		Items: items,
+		Query: q,
+		Metadata: retrieve.ResultMetadata{
+			TotalCandidates: len(results),
+			LatencyMS:       time.Since(start).Milliseconds(),
+			ModesUsed:       []retrieve.Mode{retrieve.ModeGraph},
+		},
+	}, nil
+}
+
+// Verify interface compliance
+var _ retrieve.Retriever = (*CommunityIndex)(nil)
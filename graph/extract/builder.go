@@ -0,0 +1,319 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// Metadata keys of entity nodes and edges written by a Builder.
+const (
+	// NameKey holds the entity's canonical name, its first mention.
+	NameKey = "name"
+	// AliasesKey holds the entity's other names, comma-separated.
+	AliasesKey = "aliases"
+	// MentionsKey holds the number of documents mentioning the entity or
+	// stating the relation.
+	MentionsKey = "mentions"
+	// SourcesKey holds the IDs of those documents, comma-separated.
+	SourcesKey = "sources"
+)
+
+// BuilderConfig configures a Builder.
+type BuilderConfig struct {
+	// Graph receives the extracted nodes and edges.
+	Graph graph.BatchKnowledgeGraph
+	// Entities extracts the entities of each document.
+	Entities EntityExtractor
+	// Relations extracts the relations between entities (optional).
+	Relations RelationExtractor
+	// IDPrefix prefixes entity node IDs (default "entity:").
+	IDPrefix string
+	// Documents adds a node per document, of type "document", with a
+	// "mentions" edge to each entity it mentions.
+	Documents bool
+}
+
+// Report summarizes a Builder.Ingest call.
+type Report struct {
+	// Entities are the IDs of the entity nodes written.
+	Entities []string
+	// Created is the number of entities not seen before.
+	Created int
+	// Merged is the number of mentions merged into an existing entity.
+	Merged int
+	// Edges is the number of edges written.
+	Edges int
+}
+
+// Builder ingests documents into a knowledge graph, extracting entities and
+// relations. Entities are deduplicated by normalized name and alias, so
+// "ACME Corp." and "Acme Corp" become one node; mentions of an entity across
+// documents are merged into its node, keeping the first mention's name and
+// type, the longest description, and the union of aliases and metadata. The
+// merge state covers the documents ingested by the Builder; entity nodes
+// already in the graph are overwritten. It is safe for concurrent use.
+type Builder struct {
+	config BuilderConfig
+
+	mu       sync.Mutex
+	entities map[string]*entityState // node ID -> state
+	keys     map[string]string       // normalized name or alias -> node ID
+	edges    map[edgeKey]*edgeState
+}
+
+// entityState is the merged state of an entity.
+type entityState struct {
+	node    graph.Node
+	aliases []string
+	sources []string
+}
+
+// edgeKey identifies an edge.
+type edgeKey struct {
+	from, to, edgeType string
+}
+
+// edgeState is the merged state of an edge.
+type edgeState struct {
+	edge    graph.Edge
+	sources []string
+}
+
+// NewBuilder creates a new graph builder.
+func NewBuilder(cfg BuilderConfig) *Builder {
+	if cfg.IDPrefix == "" {
+		cfg.IDPrefix = "entity:"
+	}
+	return &Builder{
+		config:   cfg,
+		entities: make(map[string]*entityState),
+		keys:     make(map[string]string),
+		edges:    make(map[edgeKey]*edgeState),
+	}
+}
+
+// Ingest extracts entities and relations from docs and upserts the affected
+// nodes and edges. Extraction runs before anything is written, so a failed
+// extraction writes nothing.
+func (b *Builder) Ingest(ctx context.Context, docs ...Document) (*Report, error) {
+	type extraction struct {
+		entities  []Entity
+		relations []Relation
+	}
+	extracted := make([]extraction, len(docs))
+	for i, doc := range docs {
+		entities, err := b.config.Entities.ExtractEntities(ctx, doc.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract entities from %s: %w", doc.ID, err)
+		}
+		extracted[i].entities = entities
+		if b.config.Relations != nil {
+			relations, err := b.config.Relations.ExtractRelations(ctx, doc.Content, entities)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract relations from %s: %w", doc.ID, err)
+			}
+			extracted[i].relations = relations
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	report := &Report{}
+	touched := make(map[string]bool)
+	var nodes []graph.Node
+	touchedEdges := make(map[edgeKey]bool)
+	for i, doc := range docs {
+		if b.config.Documents {
+			nodes = append(nodes, graph.Node{
+				ID:       doc.ID,
+				Type:     "document",
+				Content:  doc.Content,
+				Source:   doc.Source,
+				Metadata: doc.Metadata,
+			})
+		}
+
+		mentioned := make(map[string]bool)
+		for _, ent := range extracted[i].entities {
+			id, ok := b.merge(ent, doc, report)
+			if !ok {
+				continue
+			}
+			touched[id] = true
+			if b.config.Documents && !mentioned[id] {
+				mentioned[id] = true
+				touchedEdges[b.mergeEdge(graph.Edge{From: doc.ID, To: id, Type: "mentions", Weight: 1}, doc)] = true
+			}
+		}
+		for _, rel := range extracted[i].relations {
+			from, ok := b.resolve(rel.From, doc, report)
+			if !ok {
+				continue
+			}
+			to, ok := b.resolve(rel.To, doc, report)
+			if !ok || from == to {
+				continue
+			}
+			touched[from], touched[to] = true, true
+			touchedEdges[b.mergeEdge(graph.Edge{From: from, To: to, Type: rel.Type, Weight: rel.Weight}, doc)] = true
+		}
+	}
+
+	for id := range touched {
+		nodes = append(nodes, b.entityNode(id))
+		report.Entities = append(report.Entities, id)
+	}
+	sort.Strings(report.Entities)
+	edges := make([]graph.Edge, 0, len(touchedEdges))
+	for key := range touchedEdges {
+		edges = append(edges, b.edgeOf(key))
+	}
+	report.Edges = len(edges)
+
+	if err := b.config.Graph.UpsertNodeBatch(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to write nodes: %w", err)
+	}
+	if err := b.config.Graph.UpsertEdgeBatch(ctx, edges); err != nil {
+		return nil, fmt.Errorf("failed to write edges: %w", err)
+	}
+	return report, nil
+}
+
+// merge merges a mention of ent in doc into its entity, creating the entity
+// if it is new, and returns the entity's node ID. It returns false if the
+// entity has no name.
+func (b *Builder) merge(ent Entity, doc Document, report *Report) (string, bool) {
+	names := append([]string{ent.Name}, ent.Aliases...)
+	id := ""
+	for _, name := range names {
+		if existing, ok := b.keys[Normalize(name)]; ok {
+			id = existing
+			break
+		}
+	}
+
+	state, ok := b.entities[id]
+	if !ok {
+		key := Normalize(ent.Name)
+		if key == "" {
+			return "", false
+		}
+		id = b.config.IDPrefix + strings.ReplaceAll(key, " ", "-")
+		if state, ok = b.entities[id]; !ok {
+			state = &entityState{node: graph.Node{
+				ID:       id,
+				Type:     ent.Type,
+				Source:   doc.Source,
+				Metadata: map[string]string{NameKey: ent.Name},
+			}}
+			b.entities[id] = state
+			report.Created++
+		}
+	} else {
+		report.Merged++
+	}
+
+	for _, name := range names {
+		key := Normalize(name)
+		if key == "" {
+			continue
+		}
+		b.keys[key] = id
+		if Normalize(state.node.Metadata[NameKey]) != key && !containsKey(state.aliases, key) {
+			state.aliases = append(state.aliases, name)
+		}
+	}
+	if state.node.Type == "" {
+		state.node.Type = ent.Type
+	}
+	if len(ent.Description) > len(state.node.Content) {
+		state.node.Content = ent.Description
+	}
+	for k, v := range ent.Metadata {
+		if _, ok := state.node.Metadata[k]; !ok {
+			state.node.Metadata[k] = v
+		}
+	}
+	state.sources = addSource(state.sources, doc.ID)
+	return id, true
+}
+
+// resolve returns the node ID of the entity named by a relation, creating
+// the entity if the name is unknown.
+func (b *Builder) resolve(name string, doc Document, report *Report) (string, bool) {
+	if id, ok := b.keys[Normalize(name)]; ok {
+		return id, true
+	}
+	return b.merge(Entity{Name: name}, doc, report)
+}
+
+// mergeEdge merges an occurrence of edge in doc, keeping the highest weight,
+// and returns its key.
+func (b *Builder) mergeEdge(edge graph.Edge, doc Document) edgeKey {
+	key := edgeKey{edge.From, edge.To, edge.Type}
+	state, ok := b.edges[key]
+	if !ok {
+		state = &edgeState{edge: edge}
+		b.edges[key] = state
+	}
+	state.edge.Weight = max(state.edge.Weight, edge.Weight)
+	state.sources = addSource(state.sources, doc.ID)
+	return key
+}
+
+// entityNode returns the node of the entity with the given ID.
+func (b *Builder) entityNode(id string) graph.Node {
+	state := b.entities[id]
+	node := state.node
+	if node.Content == "" {
+		node.Content = node.Metadata[NameKey]
+	}
+	node.Metadata = make(map[string]string, len(state.node.Metadata)+3)
+	for k, v := range state.node.Metadata {
+		node.Metadata[k] = v
+	}
+	if len(state.aliases) > 0 {
+		node.Metadata[AliasesKey] = strings.Join(state.aliases, ",")
+	}
+	node.Metadata[MentionsKey] = strconv.Itoa(len(state.sources))
+	node.Metadata[SourcesKey] = strings.Join(state.sources, ",")
+	return node
+}
+
+// edgeOf returns the edge with the given key.
+func (b *Builder) edgeOf(key edgeKey) graph.Edge {
+	state := b.edges[key]
+	edge := state.edge
+	edge.Metadata = map[string]string{
+		MentionsKey: strconv.Itoa(len(state.sources)),
+		SourcesKey:  strings.Join(state.sources, ","),
+	}
+	return edge
+}
+
+// containsKey reports whether any of names normalizes to key.
+func containsKey(names []string, key string) bool {
+	for _, name := range names {
+		if Normalize(name) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// addSource adds a document ID to sources unless it is already there.
+func addSource(sources []string, id string) []string {
+	for _, s := range sources {
+		if s == id {
+			return sources
+		}
+	}
+	return append(sources, id)
+}
@@ -0,0 +1,197 @@
+// Package extract builds knowledge graphs from raw documents by extracting
+// entities and the relations between them.
+package extract
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Document is a raw document to extract a graph from.
+type Document struct {
+	// ID is the unique identifier for this document.
+	ID string
+	// Content is the document text.
+	Content string
+	// Source identifies where this document came from.
+	Source string
+	// Metadata contains additional document metadata.
+	Metadata map[string]string
+}
+
+// Entity is an entity mentioned in a document.
+type Entity struct {
+	// Name is the entity's name as mentioned.
+	Name string
+	// Type is the entity type (e.g., "person", "organization").
+	Type string
+	// Description describes the entity, if the extractor provides one.
+	Description string
+	// Aliases are other names of the same entity.
+	Aliases []string
+	// Metadata contains additional entity metadata.
+	Metadata map[string]string
+}
+
+// Relation is a relation between two entities mentioned in a document.
+type Relation struct {
+	// From is the name of the source entity.
+	From string
+	// To is the name of the target entity.
+	To string
+	// Type is the relation type (e.g., "works_for").
+	Type string
+	// Weight is the relation strength (0.0-1.0).
+	Weight float64
+}
+
+// EntityExtractor extracts the entities mentioned in text. Implementations
+// may be rule-based, such as RuleExtractor, or backed by an LLM or NER model.
+type EntityExtractor interface {
+	// ExtractEntities returns the entities mentioned in text.
+	ExtractEntities(ctx context.Context, text string) ([]Entity, error)
+}
+
+// RelationExtractor extracts the relations between entities mentioned in
+// text.
+type RelationExtractor interface {
+	// ExtractRelations returns the relations between entities in text,
+	// referring to entities by name.
+	ExtractRelations(ctx context.Context, text string, entities []Entity) ([]Relation, error)
+}
+
+// Rule extracts entities of one type by pattern.
+type Rule struct {
+	// Type is the type of the extracted entities.
+	Type string
+	// Pattern matches entity mentions.
+	Pattern *regexp.Regexp
+	// Group is the submatch holding the entity name (default 0: the whole
+	// match).
+	Group int
+}
+
+// DictionaryRule returns a rule matching any of terms as whole words,
+// ignoring case.
+func DictionaryRule(entityType string, terms ...string) Rule {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return Rule{
+		Type:    entityType,
+		Pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`),
+	}
+}
+
+// RuleExtractor is an EntityExtractor that matches rules against the text.
+// An entity mentioned several times is returned once, under its first
+// mention and the type of the first rule matching it.
+type RuleExtractor struct {
+	rules []Rule
+}
+
+// NewRuleExtractor creates a new rule-based entity extractor.
+func NewRuleExtractor(rules ...Rule) *RuleExtractor {
+	return &RuleExtractor{rules: rules}
+}
+
+// ExtractEntities implements EntityExtractor.
+func (e *RuleExtractor) ExtractEntities(_ context.Context, text string) ([]Entity, error) {
+	seen := make(map[string]bool)
+	var entities []Entity
+	for _, rule := range e.rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatch(text, -1) {
+			if rule.Group >= len(m) {
+				continue
+			}
+			name := strings.TrimSpace(m[rule.Group])
+			key := Normalize(name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			entities = append(entities, Entity{Name: name, Type: rule.Type})
+		}
+	}
+	return entities, nil
+}
+
+// CooccurrenceExtractor is a RelationExtractor that relates entities
+// mentioned in the same sentence.
+type CooccurrenceExtractor struct {
+	// Type is the relation type (default "related_to").
+	Type string
+	// Weight is the relation weight (default 0.5).
+	Weight float64
+}
+
+// ExtractRelations implements RelationExtractor. Each pair of entities is
+// related once, from the entity mentioned first.
+func (e *CooccurrenceExtractor) ExtractRelations(_ context.Context, text string, entities []Entity) ([]Relation, error) {
+	relType, weight := e.Type, e.Weight
+	if relType == "" {
+		relType = "related_to"
+	}
+	if weight <= 0 {
+		weight = 0.5
+	}
+
+	seen := make(map[[2]string]bool)
+	var relations []Relation
+	for _, sentence := range sentences(text) {
+		lower := strings.ToLower(sentence)
+		var mentioned []Entity
+		for _, ent := range entities {
+			if mentions(lower, ent) {
+				mentioned = append(mentioned, ent)
+			}
+		}
+		for i, from := range mentioned {
+			for _, to := range mentioned[i+1:] {
+				pair := [2]string{Normalize(from.Name), Normalize(to.Name)}
+				if pair[0] == pair[1] || seen[pair] || seen[[2]string{pair[1], pair[0]}] {
+					continue
+				}
+				seen[pair] = true
+				relations = append(relations, Relation{From: from.Name, To: to.Name, Type: relType, Weight: weight})
+			}
+		}
+	}
+	return relations, nil
+}
+
+// sentences splits text at sentence-ending punctuation and line breaks.
+func sentences(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == '.' || r == '!' || r == '?' || r == '\n'
+	})
+}
+
+// mentions reports whether lowercased text mentions the entity by name or
+// alias.
+func mentions(lower string, ent Entity) bool {
+	for _, name := range append([]string{ent.Name}, ent.Aliases...) {
+		if name != "" && strings.Contains(lower, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize returns the key under which entity names are deduplicated: the
+// name lowercased, with punctuation dropped and whitespace collapsed.
+func Normalize(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Verify interface compliance
+var (
+	_ EntityExtractor   = (*RuleExtractor)(nil)
+	_ RelationExtractor = (*CooccurrenceExtractor)(nil)
+)
@@ -0,0 +1,133 @@
+package extract_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/agentplexus/omniretrieve/graph/extract"
+	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+func TestRuleExtractor(t *testing.T) {
+	extractor := extract.NewRuleExtractor(
+		extract.DictionaryRule("organization", "Acme Corp", "Globex"),
+		extract.Rule{Type: "person", Pattern: regexp.MustCompile(`(?:Dr|Ms)\. ([A-Z][a-z]+)`), Group: 1},
+	)
+	text := "Dr. Smith joined ACME CORP. Later Dr. Smith left acme corp for Globex."
+	entities, err := extractor.ExtractEntities(context.Background(), text)
+	if err != nil {
+		t.Fatalf("failed to extract entities: %v", err)
+	}
+	want := []extract.Entity{
+		{Name: "ACME CORP", Type: "organization"},
+		{Name: "Globex", Type: "organization"},
+		{Name: "Smith", Type: "person"},
+	}
+	if len(entities) != len(want) {
+		t.Fatalf("expected %d entities, got %+v", len(want), entities)
+	}
+	for i := range want {
+		if entities[i].Name != want[i].Name || entities[i].Type != want[i].Type {
+			t.Errorf("entity %d: expected %+v, got %+v", i, want[i], entities[i])
+		}
+	}
+
+	relations, err := (&extract.CooccurrenceExtractor{}).ExtractRelations(context.Background(), text, entities)
+	if err != nil {
+		t.Fatalf("failed to extract relations: %v", err)
+	}
+	// Each pair is related once, however many sentences mention both
+	if len(relations) != 3 || relations[0].Type != "related_to" || relations[0].Weight != 0.5 {
+		t.Errorf("unexpected relations: %+v", relations)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	ctx := context.Background()
+	kg := memory.NewKnowledgeGraph("extracted")
+	builder := extract.NewBuilder(extract.BuilderConfig{
+		Graph:     kg,
+		Entities:  staticExtractor{},
+		Relations: &extract.CooccurrenceExtractor{Type: "co_occurs"},
+		Documents: true,
+	})
+
+	report, err := builder.Ingest(ctx,
+		extract.Document{ID: "d1", Content: "International Business Machines hired Ada Lovelace"},
+		extract.Document{ID: "d2", Content: "IBM praised ada lovelace"},
+	)
+	if err != nil {
+		t.Fatalf("failed to ingest: %v", err)
+	}
+	if report.Created != 2 || report.Merged != 2 || len(report.Entities) != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	nodes, err := kg.FindNodes(ctx, "organization", nil)
+	if err != nil {
+		t.Fatalf("failed to find nodes: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("expected IBM to be merged into one node, got %+v", nodes)
+	}
+	ibm := nodes[0]
+	if ibm.ID != "entity:international-business-machines" || ibm.Metadata[extract.AliasesKey] != "IBM" ||
+		ibm.Metadata[extract.MentionsKey] != "2" || ibm.Metadata[extract.SourcesKey] != "d1,d2" {
+		t.Errorf("unexpected merged node: %+v", ibm)
+	}
+	if ibm.Content != "A technology company" {
+		t.Errorf("expected the longest description, got %q", ibm.Content)
+	}
+
+	// Two mentions edges per document, and one co-occurrence edge merged
+	// across both
+	if kg.NodeCount() != 4 || kg.EdgeCount() != 5 {
+		t.Errorf("expected 4 nodes and 5 edges, got %d and %d", kg.NodeCount(), kg.EdgeCount())
+	}
+	result, err := kg.Traverse(ctx, []string{ibm.ID}, graph.TraversalOptions{Depth: 1, MaxNodes: 10, EdgeTypes: []string{"co_occurs"}})
+	if err != nil {
+		t.Fatalf("failed to traverse: %v", err)
+	}
+	if len(result.Edges) != 1 || result.Edges[0].Metadata[extract.MentionsKey] != "2" {
+		t.Errorf("expected one merged co-occurrence edge, got %+v", result.Edges)
+	}
+}
+
+// staticExtractor extracts two entities, under varying names.
+type staticExtractor struct{}
+
+func (staticExtractor) ExtractEntities(_ context.Context, text string) ([]extract.Entity, error) {
+	if text == "IBM praised ada lovelace" {
+		return []extract.Entity{
+			{Name: "IBM", Type: "organization", Description: "A technology company"},
+			{Name: "ada lovelace", Type: "person"},
+		}, nil
+	}
+	return []extract.Entity{
+		{Name: "International Business Machines", Type: "organization", Aliases: []string{"IBM"}, Description: "A company"},
+		{Name: "Ada Lovelace", Type: "person"},
+	}, nil
+}
+
+func TestLinker(t *testing.T) {
+	linker := extract.Linker{Extractor: extract.NewRuleExtractor(extract.DictionaryRule("organization", "Acme Corp", "Globex"))}
+	items := []retrieve.ContextItem{
+		{ID: "chunk-1", Score: 0.9, Content: "Globex acquired a stake in Acme Corp."},
+		{ID: "chunk-2", Score: 0.5, Content: "acme corp reported earnings."},
+	}
+	hints, err := linker.Link(context.Background(), items)
+	if err != nil {
+		t.Fatalf("failed to link: %v", err)
+	}
+	if len(hints) != 2 {
+		t.Fatalf("expected 2 distinct entities, got %+v", hints)
+	}
+	for _, h := range hints {
+		if h.ID != "" || h.Type != "organization" || h.Confidence != 0.9 {
+			t.Errorf("unexpected hint: %+v", h)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Linker links retrieved items to graph entities by extracting the entities
+// their content mentions. It implements hybrid.EntityLinker, so that vector
+// results seed graph expansion even when vector and graph IDs differ.
+// Entities are hinted by name, for the graph retriever's EntityResolver.
+type Linker struct {
+	// Extractor extracts the entities mentioned in item contents.
+	Extractor EntityExtractor
+}
+
+// Link returns one hint per distinct entity, by normalized name, with the
+// score of the highest-ranked item mentioning it as confidence.
+func (l Linker) Link(ctx context.Context, items []retrieve.ContextItem) ([]retrieve.EntityHint, error) {
+	seen := make(map[string]bool)
+	var hints []retrieve.EntityHint
+	for _, item := range items {
+		entities, err := l.Extractor.ExtractEntities(ctx, item.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract entities from %s: %w", item.ID, err)
+		}
+		for _, e := range entities {
+			key := Normalize(e.Name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			hints = append(hints, retrieve.EntityHint{Name: e.Name, Type: e.Type, Confidence: item.Score})
+		}
+	}
+	return hints, nil
+}
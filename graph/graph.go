@@ -3,9 +3,14 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
 )
 
 // Node represents a node in the knowledge graph.
@@ -20,6 +25,10 @@ type Node struct {
 	Source string
 	// Metadata contains additional node metadata.
 	Metadata map[string]string
+	// Embedding is an optional embedding of the node's content, used by
+	// EmbeddingAwareGraph backends and for semantic scoring. Backends that
+	// don't store embeddings ignore it.
+	Embedding []float32
 }
 
 // Edge represents an edge in the knowledge graph.
@@ -34,6 +43,16 @@ type Edge struct {
 	Weight float64
 	// Metadata contains additional edge metadata.
 	Metadata map[string]string
+	// ValidFrom is when the relationship starts to hold (zero: always has).
+	ValidFrom time.Time
+	// ValidTo is when the relationship stops holding, exclusive (zero:
+	// never stops).
+	ValidTo time.Time
+}
+
+// ValidAt reports whether the edge holds at t.
+func (e Edge) ValidAt(t time.Time) bool {
+	return (e.ValidFrom.IsZero() || !t.Before(e.ValidFrom)) && (e.ValidTo.IsZero() || t.Before(e.ValidTo))
 }
 
 // TraversalResult represents the result of a graph traversal.
@@ -46,26 +65,66 @@ type TraversalResult struct {
 	Paths map[string][]string
 }
 
+// Direction selects which edges traversal follows.
+type Direction string
+
+const (
+	// DirectionOut follows edges from their source to their target. It is
+	// the default.
+	DirectionOut Direction = "out"
+	// DirectionIn follows edges from their target to their source.
+	DirectionIn Direction = "in"
+	// DirectionBoth follows edges either way, treating the graph as
+	// undirected.
+	DirectionBoth Direction = "both"
+)
+
 // TraversalOptions configures graph traversal.
 type TraversalOptions struct {
 	// Depth is the maximum traversal depth.
 	Depth int
 	// EdgeTypes filters which edge types to traverse.
 	EdgeTypes []string
+	// EdgeMetadata restricts traversal to edges whose metadata has every
+	// given key/value pair (e.g. {"verified": "true"}).
+	EdgeMetadata map[string]string
 	// NodeTypes filters which node types to include.
 	NodeTypes []string
 	// MaxNodes limits the total number of nodes to return.
 	MaxNodes int
 	// MinWeight is the minimum edge weight to traverse.
 	MinWeight float64
+	// Direction selects which edges to follow (default DirectionOut).
+	Direction Direction
+	// AsOf restricts traversal to edges valid at that time, to query the
+	// graph as it was then (default zero: all edges).
+	AsOf time.Time
 }
 
+// ErrNodeNotFound is returned when a node does not exist.
+var ErrNodeNotFound = errors.New("node not found")
+
+// ErrGraphNotFound is returned by GraphManager implementations when a graph
+// does not exist.
+var ErrGraphNotFound = errors.New("graph not found")
+
 // KnowledgeGraph defines the interface for knowledge graph operations.
 type KnowledgeGraph interface {
-	// Traverse performs a graph traversal starting from the given nodes.
+	// Traverse performs a graph traversal starting from the given nodes,
+	// following edges in opts.Direction. Paths list nodes in traversal
+	// order; result edges keep their stored orientation.
 	Traverse(ctx context.Context, startNodes []string, opts TraversalOptions) (*TraversalResult, error)
 	// FindNodes finds nodes matching the given criteria.
 	FindNodes(ctx context.Context, nodeType string, filters map[string]string) ([]Node, error)
+	// GetNode returns the node with the given ID, or ErrNodeNotFound.
+	GetNode(ctx context.Context, id string) (Node, error)
+	// GetEdges returns the edges of a node in direction (default
+	// DirectionOut), in their stored orientation, ordered by source, target,
+	// and type.
+	GetEdges(ctx context.Context, nodeID string, direction Direction) ([]Edge, error)
+	// NeighborCount returns the number of distinct nodes linked to a node by
+	// its edges in direction (default DirectionOut).
+	NeighborCount(ctx context.Context, nodeID string, direction Direction) (int, error)
 	// AddNode adds a node to the graph.
 	AddNode(ctx context.Context, node Node) error
 	// UpsertNode inserts or updates a node in the graph.
@@ -82,6 +141,17 @@ type KnowledgeGraph interface {
 	Name() string
 }
 
+// EmbeddingAwareGraph is implemented by graphs that store node embeddings
+// and can constrain traversal by them.
+type EmbeddingAwareGraph interface {
+	KnowledgeGraph
+	// TraverseSimilar traverses like Traverse, but only expands to nodes
+	// whose embedding has a cosine similarity of at least minSimilarity to
+	// embedding. Nodes without an embedding are not expanded to; start
+	// nodes are always included.
+	TraverseSimilar(ctx context.Context, startNodes []string, embedding []float32, minSimilarity float64, opts TraversalOptions) (*TraversalResult, error)
+}
+
 // BatchKnowledgeGraph extends KnowledgeGraph with batch operations.
 type BatchKnowledgeGraph interface {
 	KnowledgeGraph
@@ -147,6 +217,45 @@ type RetrieverConfig struct {
 	DefaultMaxNodes int
 	// EdgeTypes filters which edge types to traverse by default.
 	EdgeTypes []string
+	// EdgeMetadata restricts traversal to edges with these metadata values.
+	EdgeMetadata map[string]string
+	// Direction selects which edges to follow (default DirectionOut).
+	Direction Direction
+	// Resolver links entity hints without an ID to nodes by name, e.g. a
+	// NodeResolver (optional).
+	Resolver EntityResolver
+	// StartNodeIndex is an optional vector index over node embeddings, keyed
+	// by graph node ID. When a query has no entity hints and no filters, or
+	// its filters match no nodes, start nodes are discovered by similarity to
+	// the query embedding instead.
+	StartNodeIndex vector.Index
+	// Embedder embeds query text for start-node discovery when the query has
+	// no embedding, and node contents for SemanticWeight.
+	Embedder vector.Embedder
+	// StartNodeK is the number of start nodes to discover (default 5).
+	StartNodeK int
+	// StartNodeMinScore is the minimum similarity for a discovered start node.
+	StartNodeMinScore float64
+	// Centrality scores nodes by their importance in the graph, e.g. a
+	// CentralityScorer (optional). It is blended into item scores with
+	// CentralityWeight, so hubs rank above incidental neighbors of the start
+	// nodes.
+	Centrality Centrality
+	// CentralityWeight is the share of an item's score taken from its
+	// centrality, in [0, 1] (default 0.3 when Centrality is set); the rest
+	// is the path score.
+	CentralityWeight float64
+	// SemanticWeight is the share of an item's score taken from the cosine
+	// similarity of the node's content to the query, in [0, 1], so that
+	// deep but relevant nodes can outrank close but unrelated ones (default
+	// 0: path score only). Node embeddings are used when set; other nodes
+	// are embedded with Embedder on each retrieval, so wrap it in an
+	// embedding cache to avoid re-embedding them.
+	SemanticWeight float64
+	// MinNeighborSimilarity, when positive and Graph implements
+	// EmbeddingAwareGraph, restricts traversal to nodes whose embedding is
+	// at least this similar to the query (default 0: no constraint).
+	MinNeighborSimilarity float64
 	// Observer for tracing and metrics.
 	Observer retrieve.Observer
 }
@@ -164,9 +273,24 @@ func NewRetriever(cfg RetrieverConfig) *Retriever {
 	if cfg.DefaultMaxNodes == 0 {
 		cfg.DefaultMaxNodes = 20
 	}
+	if cfg.StartNodeK == 0 {
+		cfg.StartNodeK = 5
+	}
+	if cfg.Centrality != nil && cfg.CentralityWeight == 0 {
+		cfg.CentralityWeight = 0.3
+	}
 	return &Retriever{config: cfg}
 }
 
+// Warmup implements retrieve.Warmer by validating the configuration and
+// warming the graph when it supports it.
+func (r *Retriever) Warmup(ctx context.Context) error {
+	if r.config.Graph == nil {
+		return errors.New("graph retriever: graph is required")
+	}
+	return retrieve.Warmup(ctx, r.config.Graph, r.config.StartNodeIndex, r.config.Embedder)
+}
+
 // Retrieve performs graph traversal to find relevant context.
 func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
 	start := time.Now()
@@ -176,11 +300,25 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 	for _, e := range q.Entities {
 		if e.ID != "" {
 			startNodes = append(startNodes, e.ID)
+			continue
+		}
+		if r.config.Resolver == nil || e.Name == "" {
+			continue
+		}
+		matches, err := r.config.Resolver.Resolve(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve entity %q: %w", e.Name, err)
+		}
+		for _, m := range matches {
+			if !slices.Contains(startNodes, m.ID) {
+				startNodes = append(startNodes, m.ID)
+			}
 		}
 	}
 
-	// If no start nodes, try to find matching nodes
-	if len(startNodes) == 0 {
+	// If no start nodes, try to find matching nodes. Without filters FindNodes
+	// matches every node, so it is skipped when embedding discovery is available.
+	if len(startNodes) == 0 && (len(q.Filters) > 0 || r.config.StartNodeIndex == nil) {
 		// Try to find nodes matching query text or metadata
 		nodes, err := r.config.Graph.FindNodes(ctx, "", q.Filters)
 		if err != nil {
@@ -191,15 +329,41 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 		}
 	}
 
+	// Fall back to discovering start nodes by embedding similarity. The
+	// query embedding is kept for traversal and scoring.
+	var queryEmbedding []float32
+	if len(startNodes) == 0 && r.config.StartNodeIndex != nil {
+		var err error
+		if queryEmbedding, err = r.queryEmbedding(ctx, q); err != nil {
+			return nil, err
+		}
+		discovered, err := r.discoverStartNodes(ctx, q, queryEmbedding)
+		if err != nil {
+			return nil, err
+		}
+		startNodes = discovered
+	}
+
 	// If still no start nodes, return empty result
 	if len(startNodes) == 0 {
-		return &retrieve.Result{
+		result := &retrieve.Result{
 			Items: []retrieve.ContextItem{},
 			Query: q,
 			Metadata: retrieve.ResultMetadata{
 				ModesUsed: []retrieve.Mode{retrieve.ModeGraph},
 			},
-		}, nil
+		}
+		if q.Explain {
+			result.Debug = &retrieve.Debug{
+				Filters:  q.Filters,
+				MinScore: q.MinScore,
+				Stages: []retrieve.StageDebug{{
+					Name:    "graph.start_nodes",
+					Backend: r.config.Graph.Name(),
+				}},
+			}
+		}
+		return result, nil
 	}
 
 	// Configure traversal
@@ -214,25 +378,71 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 	}
 
 	opts := TraversalOptions{
-		Depth:     depth,
-		EdgeTypes: r.config.EdgeTypes,
-		MaxNodes:  maxNodes,
-		MinWeight: q.MinScore,
+		Depth:        depth,
+		EdgeTypes:    r.config.EdgeTypes,
+		EdgeMetadata: r.config.EdgeMetadata,
+		MaxNodes:     maxNodes,
+		MinWeight:    q.MinScore,
+		Direction:    r.config.Direction,
+		AsOf:         q.AsOf,
+	}
+
+	similarGraph, constrained := r.config.Graph.(EmbeddingAwareGraph)
+	constrained = constrained && r.config.MinNeighborSimilarity > 0
+	if (constrained || r.config.SemanticWeight > 0) && len(queryEmbedding) == 0 {
+		var err error
+		if queryEmbedding, err = r.queryEmbedding(ctx, q); err != nil {
+			return nil, err
+		}
 	}
 
 	// Perform traversal
-	result, err := r.config.Graph.Traverse(ctx, startNodes, opts)
+	traverseStart := time.Now()
+	var result *TraversalResult
+	var err error
+	if constrained && len(queryEmbedding) > 0 {
+		result, err = similarGraph.TraverseSimilar(ctx, startNodes, queryEmbedding, r.config.MinNeighborSimilarity, opts)
+	} else {
+		result, err = r.config.Graph.Traverse(ctx, startNodes, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	traverseLatency := time.Since(traverseStart).Milliseconds()
+
+	var centrality map[string]float64
+	if r.config.Centrality != nil && len(result.Nodes) > 0 {
+		ids := make([]string, len(result.Nodes))
+		for i, node := range result.Nodes {
+			ids[i] = node.ID
+		}
+		if centrality, err = r.config.Centrality.Scores(ctx, ids); err != nil {
+			return nil, fmt.Errorf("failed to score centrality: %w", err)
+		}
+	}
+
+	similarity, err := r.semanticScores(ctx, queryEmbedding, result.Nodes)
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert to context items with path information
 	items := make([]retrieve.ContextItem, 0, len(result.Nodes))
+	dropped := 0
 	for _, node := range result.Nodes {
 		path := result.Paths[node.ID]
 		score := computePathScore(path, result.Edges)
+		if similarity != nil {
+			w := r.config.SemanticWeight
+			score = (1-w)*score + w*similarity[node.ID]
+		}
+		if centrality != nil {
+			w := r.config.CentralityWeight
+			score = (1-w)*score + w*centrality[node.ID]
+		}
 
 		if score < q.MinScore && q.MinScore > 0 {
+			dropped++
 			continue
 		}
 
@@ -243,13 +453,18 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 			Score:    score,
 			Metadata: node.Metadata,
 			Provenance: retrieve.Provenance{
-				Mode:      retrieve.ModeGraph,
-				Backend:   r.config.Graph.Name(),
-				GraphPath: path,
+				Mode:            retrieve.ModeGraph,
+				Backend:         r.config.Graph.Name(),
+				GraphPath:       path,
+				SimilarityScore: similarity[node.ID],
 			},
 		})
 	}
 
+	if centrality != nil || similarity != nil {
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+	}
+
 	latency := time.Since(start).Milliseconds()
 
 	// Report to observer
@@ -257,7 +472,7 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 		r.config.Observer.OnGraphTraverse(ctx, r.config.Graph.Name(), depth, len(items), latency)
 	}
 
-	return &retrieve.Result{
+	res := &retrieve.Result{
 		Items: items,
 		Query: q,
 		Metadata: retrieve.ResultMetadata{
@@ -265,7 +480,110 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 			LatencyMS:       latency,
 			ModesUsed:       []retrieve.Mode{retrieve.ModeGraph},
 		},
-	}, nil
+	}
+
+	if q.Explain {
+		res.Debug = &retrieve.Debug{
+			Filters:  q.Filters,
+			MinScore: q.MinScore,
+			Stages: []retrieve.StageDebug{
+				{
+					Name:       "graph.start_nodes",
+					Backend:    r.config.Graph.Name(),
+					Candidates: len(startNodes),
+					Returned:   len(startNodes),
+				},
+				{
+					Name:       "graph.traverse",
+					Backend:    r.config.Graph.Name(),
+					Candidates: len(result.Nodes),
+					Returned:   len(items),
+					LatencyMS:  traverseLatency,
+				},
+			},
+			DroppedByMinScore: dropped,
+		}
+	}
+
+	return res, nil
+}
+
+// discoverStartNodes selects start nodes by similarity between the query
+// embedding and node embeddings in the start-node index. It finds none
+// without an embedding.
+func (r *Retriever) discoverStartNodes(ctx context.Context, q retrieve.Query, embedding []float32) ([]string, error) {
+	if len(embedding) == 0 {
+		return nil, nil
+	}
+
+	results, err := r.config.StartNodeIndex.Search(ctx, embedding, r.config.StartNodeK, q.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	startNodes := make([]string, 0, len(results))
+	for _, res := range results {
+		if res.Score < r.config.StartNodeMinScore {
+			continue
+		}
+		startNodes = append(startNodes, res.Node.ID)
+	}
+	return startNodes, nil
+}
+
+// queryEmbedding returns the query's embedding, embedding its text with
+// Embedder if needed, or nil if neither is available.
+func (r *Retriever) queryEmbedding(ctx context.Context, q retrieve.Query) ([]float32, error) {
+	if len(q.Embedding) > 0 || r.config.Embedder == nil || q.Text == "" {
+		return q.Embedding, nil
+	}
+	embedding, err := r.config.Embedder.Embed(ctx, q.Text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return embedding, nil
+}
+
+// semanticScores returns the similarity of each node's content to the query
+// embedding in [0, 1], or nil if SemanticWeight is unset or there is no
+// query embedding. Nodes without an embedding are embedded with Embedder,
+// or score 0 without one.
+func (r *Retriever) semanticScores(ctx context.Context, embedding []float32, nodes []Node) (map[string]float64, error) {
+	if r.config.SemanticWeight <= 0 || len(embedding) == 0 || len(nodes) == 0 {
+		return nil, nil
+	}
+
+	embeddings := make([][]float32, len(nodes))
+	var missing []int
+	var contents []string
+	for i, node := range nodes {
+		if len(node.Embedding) > 0 {
+			embeddings[i] = node.Embedding
+			continue
+		}
+		missing = append(missing, i)
+		contents = append(contents, node.Content)
+	}
+	if len(missing) > 0 && r.config.Embedder != nil {
+		embedded, err := r.config.Embedder.EmbedBatch(ctx, contents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed node contents: %w", err)
+		}
+		if len(embedded) != len(contents) {
+			return nil, fmt.Errorf("failed to embed node contents: got %d embeddings for %d nodes", len(embedded), len(contents))
+		}
+		for j, i := range missing {
+			embeddings[i] = embedded[j]
+		}
+	}
+
+	scores := make(map[string]float64, len(nodes))
+	for i, node := range nodes {
+		if len(embeddings[i]) > 0 {
+			scores[node.ID] = max(vector.Similarity(vector.DistanceCosine, embedding, embeddings[i]), 0)
+		}
+	}
+	return scores, nil
 }
 
 // computePathScore calculates a relevance score based on path length and edge weights.
@@ -274,12 +592,20 @@ func computePathScore(path []string, edges []Edge) float64 {
 		return 1.0 // Start nodes have max score
 	}
 
-	// Build edge lookup
+	// Build edge lookup. Edges followed against their direction appear
+	// reversed in the path, so they are also keyed by their reverse unless
+	// an edge exists that way.
 	edgeWeights := make(map[string]float64)
 	for _, e := range edges {
 		key := e.From + "->" + e.To
 		edgeWeights[key] = e.Weight
 	}
+	for _, e := range edges {
+		key := e.To + "->" + e.From
+		if _, ok := edgeWeights[key]; !ok {
+			edgeWeights[key] = e.Weight
+		}
+	}
 
 	// Calculate cumulative score with decay
 	score := 1.0
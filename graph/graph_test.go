@@ -2,11 +2,16 @@ package graph_test
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/agentplexus/omniretrieve/graph"
 	"github.com/agentplexus/omniretrieve/memory"
 	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
+	"github.com/agentplexus/omniretrieve/vector"
 )
 
 func setupTestGraph(t *testing.T) *memory.KnowledgeGraph {
@@ -174,3 +179,573 @@ func TestGraphRetrieverEmptyStart(t *testing.T) {
 		t.Errorf("expected 0 results, got %d", len(result.Items))
 	}
 }
+
+func TestGraphRetrieverEmbeddingStartNodes(t *testing.T) {
+	ctx := context.Background()
+	kg := setupTestGraph(t)
+
+	// Index node embeddings under the graph node IDs
+	embedder := memory.NewHashEmbedder(128)
+	nodeIndex := memory.NewVectorIndex("graph-nodes")
+	nodes, err := kg.FindNodes(ctx, "", nil)
+	if err != nil {
+		t.Fatalf("failed to list nodes: %v", err)
+	}
+	for _, n := range nodes {
+		embedding, err := embedder.Embed(ctx, n.Content)
+		if err != nil {
+			t.Fatalf("failed to embed node: %v", err)
+		}
+		if err := nodeIndex.Upsert(ctx, vector.Node{ID: n.ID, Content: n.Content, Embedding: embedding}); err != nil {
+			t.Fatalf("failed to index node: %v", err)
+		}
+	}
+
+	retriever := graph.NewRetriever(graph.RetrieverConfig{
+		Graph:             kg,
+		DefaultDepth:      1,
+		StartNodeIndex:    nodeIndex,
+		Embedder:          embedder,
+		StartNodeK:        1,
+		StartNodeMinScore: 0.5,
+	})
+
+	result, err := retriever.Retrieve(ctx, retrieve.Query{Text: "Neural Networks", Explain: true})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	ids := make(map[string]bool)
+	for _, item := range result.Items {
+		ids[item.ID] = true
+	}
+	if !ids["B"] || !ids["C"] || !ids["D"] || ids["A"] {
+		t.Errorf("expected traversal from discovered start node B, got %v", ids)
+	}
+
+	if stages := result.Debug.Stages; stages[0].Name != "graph.start_nodes" || stages[0].Returned != 1 {
+		t.Errorf("unexpected start node stage: %+v", stages[0])
+	}
+
+	// Discovered nodes below the threshold are ignored
+	result, err = retriever.Retrieve(ctx, retrieve.Query{Embedding: make([]float32, 128)})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 0 {
+		t.Errorf("expected 0 results below threshold, got %d", len(result.Items))
+	}
+}
+
+// cypherGraph is a graph that answers every Cypher query with a fixed result.
+type cypherGraph struct {
+	*memory.KnowledgeGraph
+	query  string
+	params map[string]any
+}
+
+func (g *cypherGraph) QueryCypher(_ context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	g.query, g.params = query, params
+	return &graph.QueryResult{Nodes: []graph.Node{{ID: "A", Type: "concept"}}}, nil
+}
+
+func TestRawQueries(t *testing.T) {
+	ctx := context.Background()
+	kg := &cypherGraph{KnowledgeGraph: setupTestGraph(t)}
+
+	result, err := graph.Cypher(ctx, kg, "MATCH (n:concept {id: $id}) RETURN n", map[string]any{"id": "A"})
+	if err != nil {
+		t.Fatalf("failed to run cypher query: %v", err)
+	}
+	if len(result.Nodes) != 1 || result.Nodes[0].ID != "A" || kg.params["id"] != "A" {
+		t.Errorf("unexpected cypher result: %+v", result)
+	}
+
+	// Graphs without a Gremlin implementation report it as unsupported
+	if _, err := graph.Gremlin(ctx, kg, "g.V()", nil); !errors.Is(err, graph.ErrQueryLanguageNotSupported) {
+		t.Errorf("expected ErrQueryLanguageNotSupported, got %v", err)
+	}
+}
+
+func TestShortestPaths(t *testing.T) {
+	ctx := context.Background()
+
+	// The example graph from Yen's algorithm literature, with weights used
+	// directly as costs.
+	adjacency := map[string][]graph.Edge{}
+	for _, e := range []graph.Edge{
+		{From: "C", To: "D", Weight: 3}, {From: "C", To: "E", Weight: 2},
+		{From: "D", To: "F", Weight: 4},
+		{From: "E", To: "D", Weight: 1}, {From: "E", To: "F", Weight: 2}, {From: "E", To: "G", Weight: 3},
+		{From: "F", To: "G", Weight: 2}, {From: "F", To: "H", Weight: 1},
+		{From: "G", To: "H", Weight: 2},
+	} {
+		adjacency[e.From] = append(adjacency[e.From], e)
+	}
+	calls := 0
+	neighbors := func(_ context.Context, id string) ([]graph.Edge, error) {
+		calls++
+		return adjacency[id], nil
+	}
+	cost := func(e graph.Edge) float64 { return e.Weight }
+
+	paths, err := graph.ShortestPaths(ctx, "C", "H", graph.PathOptions{K: 4, Cost: cost}, neighbors)
+	if err != nil {
+		t.Fatalf("ShortestPaths failed: %v", err)
+	}
+	want := []struct {
+		path string
+		cost float64
+	}{
+		{"C -> E -> F -> H", 5},
+		{"C -> E -> G -> H", 7},
+		{"C -> D -> F -> H", 8},
+		{"C -> E -> D -> F -> H", 8},
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %d paths, got %v", len(want), paths)
+	}
+	for i, w := range want {
+		if paths[i].String() != w.path || paths[i].Cost != w.cost {
+			t.Errorf("path %d: expected %s (%v), got %s (%v)", i, w.path, w.cost, paths[i], paths[i].Cost)
+		}
+	}
+	if calls > len(adjacency)+1 {
+		t.Errorf("expected neighbors to be fetched once per node, got %d calls", calls)
+	}
+
+	if paths, err := graph.ShortestPaths(ctx, "H", "C", graph.PathOptions{Cost: cost}, neighbors); err != nil || len(paths) != 0 {
+		t.Errorf("expected no path against edge direction, got %v, %v", paths, err)
+	}
+
+	negative := func(graph.Edge) float64 { return -1 }
+	if _, err := graph.ShortestPaths(ctx, "C", "H", graph.PathOptions{Cost: negative}, neighbors); err == nil {
+		t.Error("expected negative costs to fail")
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	// Spokes link to the hub, which links back to one spoke
+	edges := []graph.Edge{
+		{From: "x", To: "hub"}, {From: "y", To: "hub"}, {From: "z", To: "hub"},
+		{From: "hub", To: "x"},
+	}
+	scores := graph.PageRank(edges, graph.PageRankOptions{})
+
+	total := 0.0
+	for _, s := range scores {
+		total += s
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Errorf("expected scores to sum to 1, got %v", total)
+	}
+	if scores["hub"] <= scores["x"] || scores["x"] <= scores["y"] || math.Abs(scores["y"]-scores["z"]) > 1e-9 {
+		t.Errorf("unexpected ranking: %v", scores)
+	}
+
+	// Weighted, the hub's rank follows its stronger edge
+	edges = append(edges, graph.Edge{From: "hub", To: "y", Weight: 0.1})
+	edges[3].Weight = 0.9
+	weighted := graph.PageRank(edges, graph.PageRankOptions{Weighted: true})
+	if weighted["x"] <= weighted["y"] {
+		t.Errorf("expected the strongly linked spoke to rank higher: %v", weighted)
+	}
+}
+
+func TestGraphRetrieverCentrality(t *testing.T) {
+	ctx := context.Background()
+	kg := setupTestGraph(t)
+
+	scorer := graph.NewCentralityScorer(graph.CentralityScorerConfig{Graph: kg, Algorithm: graph.CentralityDegree})
+	scores, err := scorer.Scores(ctx, []string{"B", "A", "missing"})
+	if err != nil {
+		t.Fatalf("failed to score centrality: %v", err)
+	}
+	if scores["B"] != 1 || math.Abs(scores["A"]-1.0/3) > 1e-9 || scores["missing"] != 0 {
+		t.Errorf("unexpected degree centrality: %v", scores)
+	}
+
+	retriever := graph.NewRetriever(graph.RetrieverConfig{
+		Graph:            kg,
+		DefaultDepth:     2,
+		DefaultMaxNodes:  10,
+		Centrality:       scorer,
+		CentralityWeight: 1,
+	})
+	result, err := retriever.Retrieve(ctx, retrieve.Query{Entities: []retrieve.EntityHint{{ID: "A"}}})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 4 || result.Items[0].ID != "B" || result.Items[0].Score != 1 {
+		t.Errorf("expected the hub to rank first, got %+v", result.Items)
+	}
+}
+
+func TestGraphRetrieverSemanticWeight(t *testing.T) {
+	ctx := context.Background()
+	kg := setupTestGraph(t)
+
+	retriever := graph.NewRetriever(graph.RetrieverConfig{
+		Graph:           kg,
+		DefaultDepth:    2,
+		DefaultMaxNodes: 10,
+		Embedder:        memory.NewHashEmbedder(128),
+		SemanticWeight:  0.9,
+	})
+	query := retrieve.Query{Text: "Geoffrey Hinton", Entities: []retrieve.EntityHint{{ID: "A"}}}
+	result, err := retriever.Retrieve(ctx, query)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 4 || result.Items[0].ID != "D" {
+		t.Fatalf("expected the matching node to rank first, got %+v", result.Items)
+	}
+	if sim := result.Items[0].Provenance.SimilarityScore; math.Abs(sim-1) > 1e-6 {
+		t.Errorf("expected similarity 1, got %v", sim)
+	}
+
+	// Without query text, path scores are used as is
+	query.Text = ""
+	if result, err = retriever.Retrieve(ctx, query); err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if result.Items[0].ID != "A" {
+		t.Errorf("expected the start node to rank first, got %+v", result.Items)
+	}
+}
+
+// shortEmbedder drops the last embedding of every batch.
+type shortEmbedder struct {
+	*retrievetest.Embedder
+}
+
+func (e shortEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings, err := e.Embedder.EmbedBatch(ctx, texts)
+	if err != nil || len(embeddings) == 0 {
+		return embeddings, err
+	}
+	return embeddings[:len(embeddings)-1], nil
+}
+
+func TestGraphRetrieverQueryEmbedding(t *testing.T) {
+	ctx := context.Background()
+	kg := setupTestGraph(t)
+	nodeIndex := memory.NewVectorIndex("graph-nodes")
+	if err := nodeIndex.Upsert(ctx, vector.Node{ID: "A", Embedding: []float32{1, 0}}); err != nil {
+		t.Fatalf("failed to index node: %v", err)
+	}
+	embedder := &retrievetest.Embedder{Embeddings: map[string][]float32{"q": {1, 0}}, Dimensions: 2}
+
+	// Start-node discovery and semantic scoring share one query embedding
+	retriever := graph.NewRetriever(graph.RetrieverConfig{
+		Graph:           kg,
+		DefaultDepth:    1,
+		DefaultMaxNodes: 10,
+		StartNodeIndex:  nodeIndex,
+		Embedder:        embedder,
+		SemanticWeight:  0.5,
+	})
+	result, err := retriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) == 0 {
+		t.Error("expected results from the discovered start node")
+	}
+	if n := embedder.CallCount(retrievetest.MethodEmbed); n != 1 {
+		t.Errorf("expected the query to be embedded once, got %d calls", n)
+	}
+
+	// Missing node embeddings fail instead of panicking
+	retriever = graph.NewRetriever(graph.RetrieverConfig{
+		Graph:           kg,
+		DefaultDepth:    1,
+		DefaultMaxNodes: 10,
+		StartNodeIndex:  nodeIndex,
+		Embedder:        shortEmbedder{embedder},
+		SemanticWeight:  0.5,
+	})
+	if _, err := retriever.Retrieve(ctx, retrieve.Query{Text: "q"}); err == nil || !strings.Contains(err.Error(), "embeddings for") {
+		t.Errorf("expected an embedding count error, got %v", err)
+	}
+}
+
+func TestTraverseStreamFallback(t *testing.T) {
+	ctx := context.Background()
+	// Embedding hides the memory graph's TraverseStream
+	kg := struct{ graph.KnowledgeGraph }{setupTestGraph(t)}
+
+	var ids []string
+	var edges []string
+	err := graph.TraverseStream(ctx, kg, []string{"A"}, graph.TraversalOptions{Depth: 2, MaxNodes: 10}, func(step graph.TraversalStep) bool {
+		ids = append(ids, step.Node.ID)
+		if step.Edge != nil {
+			edges = append(edges, step.Edge.Type)
+		}
+		return len(ids) < 3
+	})
+	if err != nil {
+		t.Fatalf("failed to stream traversal: %v", err)
+	}
+	if got := strings.Join(ids, ","); got != "A,B,C" {
+		t.Errorf("expected A,B,C, got %s", got)
+	}
+	if got := strings.Join(edges, ","); got != "relates_to,part_of" {
+		t.Errorf("expected relates_to,part_of, got %s", got)
+	}
+}
+
+func TestMemoryGraphManager(t *testing.T) {
+	ctx := context.Background()
+	m := memory.NewGraphManager()
+
+	if err := m.CreateGraph(ctx, graph.GraphConfig{Name: "kb"}); err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	if err := m.CreateGraph(ctx, graph.GraphConfig{Name: "archive"}); err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	kg := m.Graph("kb")
+	if err := kg.AddNode(ctx, graph.Node{ID: "A", Type: "concept"}); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	if err := kg.AddNode(ctx, graph.Node{ID: "B", Type: "concept"}); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	if err := kg.AddEdge(ctx, graph.Edge{From: "A", To: "B", Type: "relates_to"}); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	// Recreating a graph keeps its contents
+	if err := m.CreateGraph(ctx, graph.GraphConfig{Name: "kb"}); err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	stats, err := m.GraphStats(ctx, "kb")
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if stats.NodeCount != 2 || stats.EdgeCount != 1 || stats.NodeTypeStats["concept"] != 2 || stats.EdgeTypeStats["relates_to"] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	names, err := m.ListGraphs(ctx)
+	if err != nil {
+		t.Fatalf("failed to list graphs: %v", err)
+	}
+	if got := strings.Join(names, ","); got != "archive,kb" {
+		t.Errorf("expected archive,kb, got %s", got)
+	}
+
+	if err := m.DropGraph(ctx, "kb"); err != nil {
+		t.Fatalf("failed to drop graph: %v", err)
+	}
+	if exists, err := m.GraphExists(ctx, "kb"); err != nil || exists {
+		t.Errorf("expected kb to be dropped, got %v, %v", exists, err)
+	}
+	if _, err := m.GraphStats(ctx, "kb"); !errors.Is(err, graph.ErrGraphNotFound) {
+		t.Errorf("expected ErrGraphNotFound, got %v", err)
+	}
+}
+
+func TestGraphRetrieverNeighborSimilarity(t *testing.T) {
+	ctx := context.Background()
+	kg := memory.NewKnowledgeGraph("embedded")
+	nodes := []graph.Node{
+		{ID: "A", Content: "start"},
+		{ID: "B", Content: "close", Embedding: []float32{1, 0.1}},
+		{ID: "C", Content: "far", Embedding: []float32{0, 1}},
+		{ID: "D", Content: "close behind far", Embedding: []float32{1, 0}},
+		{ID: "E", Content: "no embedding"},
+	}
+	for _, n := range nodes {
+		if err := kg.AddNode(ctx, n); err != nil {
+			t.Fatalf("failed to add node: %v", err)
+		}
+	}
+	for _, e := range []graph.Edge{{From: "A", To: "B"}, {From: "A", To: "C"}, {From: "C", To: "D"}, {From: "A", To: "E"}} {
+		if err := kg.AddEdge(ctx, e); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	retriever := graph.NewRetriever(graph.RetrieverConfig{
+		Graph:                 kg,
+		DefaultDepth:          2,
+		DefaultMaxNodes:       10,
+		MinNeighborSimilarity: 0.9,
+		SemanticWeight:        0.5,
+	})
+	query := retrieve.Query{Embedding: []float32{1, 0}, Entities: []retrieve.EntityHint{{ID: "A"}}}
+	result, err := retriever.Retrieve(ctx, query)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	ids := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		ids[i] = item.ID
+	}
+	if got := strings.Join(ids, ","); got != "B,A" {
+		t.Errorf("expected only the similar neighbor to be reached, got %s", got)
+	}
+}
+
+func TestLouvain(t *testing.T) {
+	// Two triangles joined by a weak edge
+	edges := []graph.Edge{
+		{From: "a1", To: "a2", Weight: 1}, {From: "a2", To: "a3", Weight: 1}, {From: "a3", To: "a1", Weight: 1},
+		{From: "b1", To: "b2", Weight: 1}, {From: "b2", To: "b3", Weight: 1}, {From: "b3", To: "b1", Weight: 1},
+		{From: "b4", To: "b1", Weight: 1}, {From: "b4", To: "b2", Weight: 1},
+		{From: "a3", To: "b1", Weight: 0.1},
+		{From: "x", To: "y", Weight: 0},
+	}
+	communities := graph.Louvain(edges, graph.LouvainOptions{})
+	if len(communities) != 2 {
+		t.Fatalf("expected 2 communities, got %+v", communities)
+	}
+	if got := strings.Join(communities[0].Members, ","); communities[0].ID != "c0" || got != "b1,b2,b3,b4" {
+		t.Errorf("unexpected first community: %+v", communities[0])
+	}
+	if got := strings.Join(communities[1].Members, ","); communities[1].ID != "c1" || got != "a1,a2,a3" {
+		t.Errorf("unexpected second community: %+v", communities[1])
+	}
+}
+
+// joinSummarizer summarizes a community by joining its nodes' content.
+type joinSummarizer struct{}
+
+func (joinSummarizer) Summarize(_ context.Context, _ graph.Community, nodes []graph.Node) (string, error) {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = n.Content
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+func TestCommunityIndex(t *testing.T) {
+	ctx := context.Background()
+	kg := setupTestGraph(t)
+	summaries := memory.NewVectorIndex("communities")
+
+	index := graph.NewCommunityIndex(graph.CommunityIndexConfig{
+		Graph:      kg,
+		Summarizer: joinSummarizer{},
+		Index:      summaries,
+		Embedder:   memory.NewHashEmbedder(64),
+	})
+	communities, err := index.Build(ctx)
+	if err != nil {
+		t.Fatalf("failed to build community index: %v", err)
+	}
+	if len(communities) != 1 || len(communities[0].Members) != 4 {
+		t.Fatalf("expected the whole graph as one community, got %+v", communities)
+	}
+
+	result, err := index.Retrieve(ctx, retrieve.Query{Text: "neural networks", TopK: 3})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("expected 1 summary, got %d", len(result.Items))
+	}
+	item := result.Items[0]
+	if item.ID != "community:c0" || item.Metadata[graph.CommunityMembersKey] != "A,B,C,D" || item.Provenance.Mode != retrieve.ModeGraph {
+		t.Errorf("unexpected summary item: %+v", item)
+	}
+	if !strings.Contains(item.Content, "Neural Networks") {
+		t.Errorf("expected the summary to cover the members, got %q", item.Content)
+	}
+
+}
+
+func TestNodeResolver(t *testing.T) {
+	ctx := context.Background()
+	kg := memory.NewKnowledgeGraph("entities")
+	for _, n := range []graph.Node{
+		{ID: "ibm", Type: "organization", Metadata: map[string]string{"name": "International Business Machines", "aliases": "IBM,Big Blue"}},
+		{ID: "lovelace", Type: "person", Metadata: map[string]string{"name": "Ada Lovelace"}},
+		{ID: "babbage", Type: "person", Metadata: map[string]string{"name": "Charles Babbage"}},
+	} {
+		if err := kg.AddNode(ctx, n); err != nil {
+			t.Fatalf("failed to add node: %v", err)
+		}
+	}
+	if err := kg.AddEdge(ctx, graph.Edge{From: "lovelace", To: "babbage", Type: "worked_with", Weight: 0.9}); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	resolver := graph.NewNodeResolver(graph.NodeResolverConfig{Graph: kg})
+
+	tests := []struct {
+		hint retrieve.EntityHint
+		want graph.NodeMatch
+	}{
+		{retrieve.EntityHint{Name: "Big Blue"}, graph.NodeMatch{ID: "ibm", Kind: graph.MatchExact, Confidence: 1}},
+		{retrieve.EntityHint{Name: "ada  LOVELACE!"}, graph.NodeMatch{ID: "lovelace", Kind: graph.MatchNormalized, Confidence: 0.95}},
+		{retrieve.EntityHint{Name: "Charles Babbadge"}, graph.NodeMatch{ID: "babbage", Kind: graph.MatchFuzzy}},
+	}
+	for _, tt := range tests {
+		matches, err := resolver.Resolve(ctx, tt.hint)
+		if err != nil {
+			t.Fatalf("failed to resolve %q: %v", tt.hint.Name, err)
+		}
+		if len(matches) != 1 || matches[0].ID != tt.want.ID || matches[0].Kind != tt.want.Kind {
+			t.Errorf("%q: expected %+v, got %+v", tt.hint.Name, tt.want, matches)
+			continue
+		}
+		if tt.want.Kind == graph.MatchFuzzy {
+			if c := matches[0].Confidence; c < 0.5 || c >= 1 {
+				t.Errorf("%q: unexpected fuzzy confidence %v", tt.hint.Name, c)
+			}
+		} else if matches[0].Confidence != tt.want.Confidence {
+			t.Errorf("%q: expected confidence %v, got %v", tt.hint.Name, tt.want.Confidence, matches[0].Confidence)
+		}
+	}
+
+	// Type mismatches and dissimilar names don't match
+	for _, hint := range []retrieve.EntityHint{{Name: "IBM", Type: "person"}, {Name: "Grace Hopper"}} {
+		matches, err := resolver.Resolve(ctx, hint)
+		if err != nil || len(matches) != 0 {
+			t.Errorf("%+v: expected no match, got %+v, %v", hint, matches, err)
+		}
+	}
+
+	retriever := graph.NewRetriever(graph.RetrieverConfig{Graph: kg, Resolver: resolver})
+	result, err := retriever.Retrieve(ctx, retrieve.Query{Entities: []retrieve.EntityHint{{Name: "ada lovelace"}}})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].ID != "lovelace" || result.Items[1].ID != "babbage" {
+		t.Errorf("expected traversal from the resolved node, got %+v", result.Items)
+	}
+}
+
+func TestSubgraph(t *testing.T) {
+	ctx := context.Background()
+	kg := setupTestGraph(t)
+	if err := kg.AddEdge(ctx, graph.Edge{From: "C", To: "A", Type: "cites", Weight: 0.4}); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	sub, err := memory.Subgraph(ctx, kg, []string{"A"}, graph.TraversalOptions{Depth: 2, MaxNodes: 10, EdgeTypes: []string{"relates_to", "part_of", "cites"}})
+	if err != nil {
+		t.Fatalf("failed to extract subgraph: %v", err)
+	}
+	// D is only reachable over caused_by; the back edge C -> A is kept
+	// although traversal didn't follow it
+	if sub.NodeCount() != 3 || sub.EdgeCount() != 3 {
+		t.Errorf("expected 3 nodes and 3 edges, got %d and %d", sub.NodeCount(), sub.EdgeCount())
+	}
+	if _, err := sub.GetNode(ctx, "D"); !errors.Is(err, graph.ErrNodeNotFound) {
+		t.Errorf("expected D outside the subgraph, got %v", err)
+	}
+
+	// The subgraph is detached from its source
+	if err := kg.DeleteNode(ctx, "B"); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+	if _, err := sub.GetNode(ctx, "B"); err != nil {
+		t.Errorf("expected B to remain in the subgraph: %v", err)
+	}
+	if n, _ := sub.NeighborCount(ctx, "A", graph.DirectionBoth); n != 2 {
+		t.Errorf("expected A to have 2 neighbors in the subgraph, got %d", n)
+	}
+}
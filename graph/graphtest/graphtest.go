@@ -0,0 +1,585 @@
+// Package graphtest provides a conformance suite for graph.KnowledgeGraph
+// implementations. Provider authors can run it from their own tests to
+// verify that a graph honors the contracts the retrievers rely on.
+//
+//	func TestConformance(t *testing.T) {
+//		graphtest.RunGraphTests(t, func(t *testing.T) graph.KnowledgeGraph {
+//			return myprovider.New(t.Name())
+//		})
+//	}
+package graphtest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// GraphFactory creates a new, empty knowledge graph.
+// It is called once per subtest; use t.Cleanup to release resources.
+type GraphFactory func(t *testing.T) graph.KnowledgeGraph
+
+// RunGraphTests runs the graph.KnowledgeGraph conformance suite. If the graph
+// also implements graph.BatchKnowledgeGraph or graph.PathFinder, those
+// contracts are verified as well.
+func RunGraphTests(t *testing.T, factory GraphFactory) {
+	t.Helper()
+
+	t.Run("EmptyTraverse", func(t *testing.T) { testEmptyTraverse(t, factory) })
+	t.Run("TraverseDepth", func(t *testing.T) { testTraverseDepth(t, factory) })
+	t.Run("TraversePaths", func(t *testing.T) { testTraversePaths(t, factory) })
+	t.Run("Direction", func(t *testing.T) { testDirection(t, factory) })
+	t.Run("AsOf", func(t *testing.T) { testAsOf(t, factory) })
+	t.Run("EdgeTypeFilter", func(t *testing.T) { testEdgeTypeFilter(t, factory) })
+	t.Run("EdgeMetadataFilter", func(t *testing.T) { testEdgeMetadataFilter(t, factory) })
+	t.Run("MinWeight", func(t *testing.T) { testMinWeight(t, factory) })
+	t.Run("MaxNodes", func(t *testing.T) { testMaxNodes(t, factory) })
+	t.Run("Stream", func(t *testing.T) { testStream(t, factory) })
+	t.Run("FindNodes", func(t *testing.T) { testFindNodes(t, factory) })
+	t.Run("GetNode", func(t *testing.T) { testGetNode(t, factory) })
+	t.Run("GetEdges", func(t *testing.T) { testGetEdges(t, factory) })
+	t.Run("UpsertNodeReplaces", func(t *testing.T) { testUpsertNodeReplaces(t, factory) })
+	t.Run("UpsertEdgeReplaces", func(t *testing.T) { testUpsertEdgeReplaces(t, factory) })
+	t.Run("DeleteNode", func(t *testing.T) { testDeleteNode(t, factory) })
+	t.Run("DeleteEdge", func(t *testing.T) { testDeleteEdge(t, factory) })
+	t.Run("Batch", func(t *testing.T) { testBatch(t, factory) })
+	t.Run("Paths", func(t *testing.T) { testPaths(t, factory) })
+}
+
+// fixture graph:
+//
+//	A --relates_to(0.9)--> B --part_of(0.8)--> C
+//	                       B --cites(0.3)----> D
+var (
+	fixtureNodes = []graph.Node{
+		{ID: "A", Type: "concept", Content: "alpha", Source: "test", Metadata: map[string]string{"lang": "en"}},
+		{ID: "B", Type: "concept", Content: "beta", Source: "test", Metadata: map[string]string{"lang": "en"}},
+		{ID: "C", Type: "document", Content: "gamma", Source: "test", Metadata: map[string]string{"lang": "de"}},
+		{ID: "D", Type: "document", Content: "delta", Source: "test", Metadata: map[string]string{"lang": "en"}},
+	}
+	fixtureEdges = []graph.Edge{
+		{From: "A", To: "B", Type: "relates_to", Weight: 0.9},
+		{From: "B", To: "C", Type: "part_of", Weight: 0.8},
+		{From: "B", To: "D", Type: "cites", Weight: 0.3},
+	}
+)
+
+func newGraph(t *testing.T, factory GraphFactory) graph.KnowledgeGraph {
+	t.Helper()
+	ctx := context.Background()
+	kg := factory(t)
+	for _, n := range fixtureNodes {
+		if err := kg.AddNode(ctx, n); err != nil {
+			t.Fatalf("AddNode(%s) failed: %v", n.ID, err)
+		}
+	}
+	for _, e := range fixtureEdges {
+		if err := kg.AddEdge(ctx, e); err != nil {
+			t.Fatalf("AddEdge(%s->%s) failed: %v", e.From, e.To, err)
+		}
+	}
+	return kg
+}
+
+func traverse(t *testing.T, kg graph.KnowledgeGraph, start []string, opts graph.TraversalOptions) *graph.TraversalResult {
+	t.Helper()
+	if opts.MaxNodes == 0 {
+		opts.MaxNodes = 100
+	}
+	res, err := kg.Traverse(context.Background(), start, opts)
+	if err != nil {
+		t.Fatalf("Traverse failed: %v", err)
+	}
+	return res
+}
+
+func nodeIDs(nodes []graph.Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.ID
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func expectNodes(t *testing.T, nodes []graph.Node, want ...string) {
+	t.Helper()
+	got := nodeIDs(nodes)
+	sort.Strings(want)
+	if !equal(got, want) {
+		t.Errorf("expected nodes %v, got %v", want, got)
+	}
+}
+
+func testEmptyTraverse(t *testing.T, factory GraphFactory) {
+	kg := factory(t)
+	res := traverse(t, kg, []string{"missing"}, graph.TraversalOptions{Depth: 2})
+	if len(res.Nodes) != 0 {
+		t.Errorf("expected no nodes from empty graph, got %v", nodeIDs(res.Nodes))
+	}
+
+	kg = newGraph(t, factory)
+	res = traverse(t, kg, nil, graph.TraversalOptions{Depth: 2})
+	if len(res.Nodes) != 0 {
+		t.Errorf("expected no nodes without start nodes, got %v", nodeIDs(res.Nodes))
+	}
+}
+
+func testTraverseDepth(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+
+	expectNodes(t, traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 0}).Nodes, "A")
+	expectNodes(t, traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1}).Nodes, "A", "B")
+	expectNodes(t, traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2}).Nodes, "A", "B", "C", "D")
+}
+
+func testTraversePaths(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2})
+
+	want := map[string][]string{
+		"A": {"A"},
+		"B": {"A", "B"},
+		"C": {"A", "B", "C"},
+	}
+	for id, path := range want {
+		if !equal(res.Paths[id], path) {
+			t.Errorf("expected path %v for %s, got %v", path, id, res.Paths[id])
+		}
+	}
+}
+
+func testDirection(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+
+	res := traverse(t, kg, []string{"C"}, graph.TraversalOptions{Depth: 2})
+	expectNodes(t, res.Nodes, "C")
+
+	res = traverse(t, kg, []string{"C"}, graph.TraversalOptions{Depth: 2, Direction: graph.DirectionIn})
+	expectNodes(t, res.Nodes, "A", "B", "C")
+	if !equal(res.Paths["A"], []string{"C", "B", "A"}) {
+		t.Errorf("expected path [C B A] for A, got %v", res.Paths["A"])
+	}
+	for _, e := range res.Edges {
+		if e.From == "B" && e.To == "C" || e.From == "A" && e.To == "B" {
+			continue
+		}
+		t.Errorf("expected edges in stored orientation, got %s->%s", e.From, e.To)
+	}
+
+	res = traverse(t, kg, []string{"C"}, graph.TraversalOptions{Depth: 2, Direction: graph.DirectionBoth})
+	expectNodes(t, res.Nodes, "A", "B", "C", "D")
+	if !equal(res.Paths["D"], []string{"C", "B", "D"}) {
+		t.Errorf("expected path [C B D] for D, got %v", res.Paths["D"])
+	}
+
+	// Incoming traversal reflects deleted edges
+	if err := kg.DeleteEdge(context.Background(), "B", "C", "part_of"); err != nil {
+		t.Fatalf("DeleteEdge failed: %v", err)
+	}
+	res = traverse(t, kg, []string{"C"}, graph.TraversalOptions{Depth: 2, Direction: graph.DirectionIn})
+	expectNodes(t, res.Nodes, "C")
+}
+
+func testAsOf(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := factory(t)
+	for _, n := range fixtureNodes {
+		if err := kg.AddNode(ctx, n); err != nil {
+			t.Fatalf("AddNode(%s) failed: %v", n.ID, err)
+		}
+	}
+	y2020 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	y2022 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	edges := []graph.Edge{
+		{From: "A", To: "B", Type: "member_of", Weight: 1, ValidFrom: y2020, ValidTo: y2022},
+		{From: "A", To: "C", Type: "member_of", Weight: 1, ValidFrom: y2022},
+		{From: "A", To: "D", Type: "relates_to", Weight: 1},
+	}
+	for _, e := range edges {
+		if err := kg.AddEdge(ctx, e); err != nil {
+			t.Fatalf("AddEdge(%s->%s) failed: %v", e.From, e.To, err)
+		}
+	}
+
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1})
+	expectNodes(t, res.Nodes, "A", "B", "C", "D")
+	for _, e := range res.Edges {
+		if e.To == "B" && (!e.ValidFrom.Equal(y2020) || !e.ValidTo.Equal(y2022)) {
+			t.Errorf("expected validity [%v, %v), got [%v, %v)", y2020, y2022, e.ValidFrom, e.ValidTo)
+		}
+		if e.To == "D" && (!e.ValidFrom.IsZero() || !e.ValidTo.IsZero()) {
+			t.Errorf("expected unbounded validity, got [%v, %v)", e.ValidFrom, e.ValidTo)
+		}
+	}
+
+	res = traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1, AsOf: y2020.AddDate(1, 0, 0)})
+	expectNodes(t, res.Nodes, "A", "B", "D")
+
+	// ValidTo is exclusive
+	res = traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1, AsOf: y2022})
+	expectNodes(t, res.Nodes, "A", "C", "D")
+}
+
+func testEdgeTypeFilter(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{
+		Depth:     2,
+		EdgeTypes: []string{"relates_to", "part_of"},
+	})
+	expectNodes(t, res.Nodes, "A", "B", "C")
+
+	for _, e := range res.Edges {
+		if e.Type == "cites" {
+			t.Errorf("expected cites edges to be filtered, got %+v", e)
+		}
+	}
+}
+
+func testEdgeMetadataFilter(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := factory(t)
+	for _, n := range fixtureNodes {
+		if err := kg.AddNode(ctx, n); err != nil {
+			t.Fatalf("AddNode(%s) failed: %v", n.ID, err)
+		}
+	}
+	edges := []graph.Edge{
+		{From: "A", To: "B", Type: "relates_to", Weight: 1, Metadata: map[string]string{"verified": "true", "source": "wiki"}},
+		{From: "A", To: "C", Type: "relates_to", Weight: 1, Metadata: map[string]string{"verified": "false"}},
+		{From: "A", To: "D", Type: "relates_to", Weight: 1},
+	}
+	for _, e := range edges {
+		if err := kg.AddEdge(ctx, e); err != nil {
+			t.Fatalf("AddEdge(%s->%s) failed: %v", e.From, e.To, err)
+		}
+	}
+
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1, EdgeMetadata: map[string]string{"verified": "true"}})
+	expectNodes(t, res.Nodes, "A", "B")
+
+	res = traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1, EdgeMetadata: map[string]string{"verified": "true", "source": "news"}})
+	expectNodes(t, res.Nodes, "A")
+}
+
+func testMinWeight(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2, MinWeight: 0.5})
+	expectNodes(t, res.Nodes, "A", "B", "C")
+}
+
+func testMaxNodes(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2, MaxNodes: 2})
+	if len(res.Nodes) > 2 {
+		t.Errorf("expected at most 2 nodes, got %v", nodeIDs(res.Nodes))
+	}
+}
+
+func testStream(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := newGraph(t, factory)
+	opts := graph.TraversalOptions{Depth: 2, MaxNodes: 10}
+
+	var steps []graph.TraversalStep
+	err := graph.TraverseStream(ctx, kg, []string{"A"}, opts, func(step graph.TraversalStep) bool {
+		steps = append(steps, step)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("TraverseStream failed: %v", err)
+	}
+	if len(steps) != 4 || steps[0].Node.ID != "A" || steps[0].Edge != nil {
+		t.Fatalf("expected 4 steps starting at A, got %+v", steps)
+	}
+	for _, step := range steps[1:] {
+		n := len(step.Path)
+		if step.Edge == nil || step.Edge.From != step.Path[n-2] || step.Edge.To != step.Node.ID {
+			t.Errorf("step %s: unexpected edge %+v for path %v", step.Node.ID, step.Edge, step.Path)
+		}
+	}
+
+	// Returning false stops the traversal
+	visited := 0
+	err = graph.TraverseStream(ctx, kg, []string{"A"}, opts, func(graph.TraversalStep) bool {
+		visited++
+		return visited < 2
+	})
+	if err != nil {
+		t.Fatalf("TraverseStream failed: %v", err)
+	}
+	if visited != 2 {
+		t.Errorf("expected the traversal to stop after 2 nodes, visited %d", visited)
+	}
+}
+
+func testFindNodes(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := newGraph(t, factory)
+
+	nodes, err := kg.FindNodes(ctx, "document", nil)
+	if err != nil {
+		t.Fatalf("FindNodes failed: %v", err)
+	}
+	expectNodes(t, nodes, "C", "D")
+
+	nodes, err = kg.FindNodes(ctx, "", map[string]string{"lang": "en"})
+	if err != nil {
+		t.Fatalf("FindNodes failed: %v", err)
+	}
+	expectNodes(t, nodes, "A", "B", "D")
+
+	nodes, err = kg.FindNodes(ctx, "document", map[string]string{"lang": "en"})
+	if err != nil {
+		t.Fatalf("FindNodes failed: %v", err)
+	}
+	expectNodes(t, nodes, "D")
+
+	nodes, err = kg.FindNodes(ctx, "missing", nil)
+	if err != nil {
+		t.Fatalf("FindNodes failed: %v", err)
+	}
+	expectNodes(t, nodes)
+}
+
+func testGetNode(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+	ctx := context.Background()
+
+	node, err := kg.GetNode(ctx, "C")
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	want := fixtureNodes[2]
+	if node.ID != want.ID || node.Type != want.Type || node.Content != want.Content ||
+		node.Source != want.Source || node.Metadata["lang"] != "de" {
+		t.Errorf("expected %+v, got %+v", want, node)
+	}
+
+	if _, err := kg.GetNode(ctx, "missing"); !errors.Is(err, graph.ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+}
+
+func testGetEdges(t *testing.T, factory GraphFactory) {
+	kg := newGraph(t, factory)
+	ctx := context.Background()
+
+	edgeKeys := func(edges []graph.Edge) []string {
+		keys := make([]string, len(edges))
+		for i, e := range edges {
+			keys[i] = e.From + "->" + e.To
+		}
+		return keys
+	}
+	tests := []struct {
+		direction graph.Direction
+		want      []string
+	}{
+		{"", []string{"B->C", "B->D"}},
+		{graph.DirectionIn, []string{"A->B"}},
+		{graph.DirectionBoth, []string{"A->B", "B->C", "B->D"}},
+	}
+	for _, tt := range tests {
+		edges, err := kg.GetEdges(ctx, "B", tt.direction)
+		if err != nil {
+			t.Fatalf("GetEdges(%q) failed: %v", tt.direction, err)
+		}
+		if got := edgeKeys(edges); !equal(got, tt.want) {
+			t.Errorf("GetEdges(%q): expected %v, got %v", tt.direction, tt.want, got)
+		}
+		count, err := kg.NeighborCount(ctx, "B", tt.direction)
+		if err != nil {
+			t.Fatalf("NeighborCount(%q) failed: %v", tt.direction, err)
+		}
+		if count != len(tt.want) {
+			t.Errorf("NeighborCount(%q): expected %d, got %d", tt.direction, len(tt.want), count)
+		}
+	}
+
+	// Parallel edges of different types link the same neighbor
+	if err := kg.AddEdge(ctx, graph.Edge{From: "B", To: "C", Type: "cites", Weight: 0.5}); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+	edges, err := kg.GetEdges(ctx, "B", graph.DirectionOut)
+	if err != nil {
+		t.Fatalf("GetEdges failed: %v", err)
+	}
+	if len(edges) != 3 || edges[0].Type != "cites" || edges[1].Type != "part_of" {
+		t.Errorf("expected edges ordered by target and type, got %+v", edges)
+	}
+	if count, err := kg.NeighborCount(ctx, "B", graph.DirectionOut); err != nil || count != 2 {
+		t.Errorf("expected 2 distinct neighbors, got %d, %v", count, err)
+	}
+
+	if edges, err := kg.GetEdges(ctx, "missing", graph.DirectionBoth); err != nil || len(edges) != 0 {
+		t.Errorf("expected no edges for a missing node, got %v, %v", edges, err)
+	}
+}
+
+func testUpsertNodeReplaces(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := newGraph(t, factory)
+
+	if err := kg.UpsertNode(ctx, graph.Node{ID: "A", Type: "concept", Content: "updated"}); err != nil {
+		t.Fatalf("UpsertNode failed: %v", err)
+	}
+
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 0})
+	if len(res.Nodes) != 1 || res.Nodes[0].Content != "updated" {
+		t.Errorf("expected updated node A, got %+v", res.Nodes)
+	}
+}
+
+func testUpsertEdgeReplaces(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := newGraph(t, factory)
+
+	// Lower the weight of A->B below the traversal threshold
+	if err := kg.UpsertEdge(ctx, graph.Edge{From: "A", To: "B", Type: "relates_to", Weight: 0.1}); err != nil {
+		t.Fatalf("UpsertEdge failed: %v", err)
+	}
+
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1, MinWeight: 0.5})
+	expectNodes(t, res.Nodes, "A")
+
+	res = traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 1})
+	count := 0
+	for _, e := range res.Edges {
+		if e.From == "A" && e.To == "B" && e.Type == "relates_to" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected a single A->B edge after upsert, got %d", count)
+	}
+}
+
+func testDeleteNode(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := newGraph(t, factory)
+
+	if err := kg.DeleteNode(ctx, "B"); err != nil {
+		t.Fatalf("DeleteNode failed: %v", err)
+	}
+
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2})
+	expectNodes(t, res.Nodes, "A")
+	if len(res.Edges) != 0 {
+		t.Errorf("expected edges to B to be removed, got %+v", res.Edges)
+	}
+}
+
+func testDeleteEdge(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg := newGraph(t, factory)
+
+	if err := kg.DeleteEdge(ctx, "B", "D", "cites"); err != nil {
+		t.Fatalf("DeleteEdge failed: %v", err)
+	}
+
+	res := traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2})
+	expectNodes(t, res.Nodes, "A", "B", "C")
+
+	// The node itself is untouched
+	nodes, err := kg.FindNodes(ctx, "document", nil)
+	if err != nil {
+		t.Fatalf("FindNodes failed: %v", err)
+	}
+	expectNodes(t, nodes, "C", "D")
+}
+
+func testBatch(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg, ok := factory(t).(graph.BatchKnowledgeGraph)
+	if !ok {
+		t.Skip("graph does not implement graph.BatchKnowledgeGraph")
+	}
+
+	if err := kg.AddNodeBatch(ctx, fixtureNodes); err != nil {
+		t.Fatalf("AddNodeBatch failed: %v", err)
+	}
+	if err := kg.AddEdgeBatch(ctx, fixtureEdges); err != nil {
+		t.Fatalf("AddEdgeBatch failed: %v", err)
+	}
+	expectNodes(t, traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2}).Nodes, "A", "B", "C", "D")
+
+	if err := kg.UpsertNodeBatch(ctx, []graph.Node{{ID: "C", Type: "concept", Content: "updated"}}); err != nil {
+		t.Fatalf("UpsertNodeBatch failed: %v", err)
+	}
+	nodes, err := kg.FindNodes(ctx, "document", nil)
+	if err != nil {
+		t.Fatalf("FindNodes failed: %v", err)
+	}
+	expectNodes(t, nodes, "D")
+
+	if err := kg.UpsertEdgeBatch(ctx, []graph.Edge{{From: "B", To: "D", Type: "cites", Weight: 0.9}}); err != nil {
+		t.Fatalf("UpsertEdgeBatch failed: %v", err)
+	}
+	expectNodes(t, traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2, MinWeight: 0.5}).Nodes, "A", "B", "C", "D")
+
+	if err := kg.DeleteNodeBatch(ctx, []string{"C", "D"}); err != nil {
+		t.Fatalf("DeleteNodeBatch failed: %v", err)
+	}
+	expectNodes(t, traverse(t, kg, []string{"A"}, graph.TraversalOptions{Depth: 2}).Nodes, "A", "B")
+}
+
+func testPaths(t *testing.T, factory GraphFactory) {
+	ctx := context.Background()
+	kg, ok := newGraph(t, factory).(graph.PathFinder)
+	if !ok {
+		t.Skip("graph does not implement graph.PathFinder")
+	}
+	// A shortcut that is weaker than the path through B
+	if err := kg.AddEdge(ctx, graph.Edge{From: "A", To: "C", Type: "cites", Weight: 0.5}); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+
+	findPaths := func(from, to string, opts graph.PathOptions) []string {
+		t.Helper()
+		paths, err := kg.FindPaths(ctx, from, to, opts)
+		if err != nil {
+			t.Fatalf("FindPaths failed: %v", err)
+		}
+		out := make([]string, len(paths))
+		for i, p := range paths {
+			out[i] = p.String()
+			if len(p.Edges) != len(p.Nodes)-1 {
+				t.Errorf("path %s has %d edges", p, len(p.Edges))
+			}
+		}
+		return out
+	}
+
+	if got := findPaths("A", "C", graph.PathOptions{}); !equal(got, []string{"A -> B -> C"}) {
+		t.Errorf("expected the strongest path, got %v", got)
+	}
+	if got := findPaths("A", "C", graph.PathOptions{K: 3}); !equal(got, []string{"A -> B -> C", "A -> C"}) {
+		t.Errorf("expected paths ranked by cost, got %v", got)
+	}
+	if got := findPaths("A", "C", graph.PathOptions{K: 3, MaxDepth: 1}); !equal(got, []string{"A -> C"}) {
+		t.Errorf("expected MaxDepth to bound paths, got %v", got)
+	}
+	if got := findPaths("A", "C", graph.PathOptions{K: 3, EdgeTypes: []string{"relates_to", "part_of"}}); !equal(got, []string{"A -> B -> C"}) {
+		t.Errorf("expected edge type filter, got %v", got)
+	}
+	if got := findPaths("C", "missing", graph.PathOptions{}); len(got) != 0 {
+		t.Errorf("expected no path to a missing node, got %v", got)
+	}
+}
@@ -0,0 +1,15 @@
+package graphtest_test
+
+import (
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/agentplexus/omniretrieve/graph/graphtest"
+	"github.com/agentplexus/omniretrieve/memory"
+)
+
+func TestMemoryKnowledgeGraph(t *testing.T) {
+	graphtest.RunGraphTests(t, func(t *testing.T) graph.KnowledgeGraph {
+		return memory.NewKnowledgeGraph(t.Name())
+	})
+}
@@ -0,0 +1,266 @@
+package graph
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Path is a path between two nodes.
+type Path struct {
+	// Nodes are the IDs of the nodes along the path, from start to end.
+	Nodes []string
+	// Edges are the edges along the path, oriented from start to end.
+	Edges []Edge
+	// Cost is the sum of the edge costs.
+	Cost float64
+}
+
+// String returns the path as "A -> B -> C".
+func (p Path) String() string {
+	return strings.Join(p.Nodes, " -> ")
+}
+
+// PathOptions configures path finding.
+type PathOptions struct {
+	// K is the number of paths to find (default 1).
+	K int
+	// MaxDepth is the maximum number of edges per path (0: unlimited).
+	MaxDepth int
+	// EdgeTypes filters which edge types to traverse.
+	EdgeTypes []string
+	// MinWeight is the minimum edge weight to traverse.
+	MinWeight float64
+	// Cost returns the non-negative cost of traversing an edge; +Inf skips
+	// the edge (default EdgeCost).
+	Cost func(Edge) float64
+}
+
+// EdgeCost is the default edge cost, -ln(Weight): a path's cost is the
+// negative log of the product of its edge weights, so strongly related nodes
+// are close. Edges with non-positive weight are not traversed.
+func EdgeCost(e Edge) float64 {
+	if e.Weight <= 0 {
+		return math.Inf(1)
+	}
+	return max(-math.Log(e.Weight), 0)
+}
+
+// PathFinder is implemented by graphs that can find the cheapest paths
+// between two nodes, e.g. to answer "how are X and Y connected" queries.
+type PathFinder interface {
+	KnowledgeGraph
+	// FindPaths returns up to opts.K loopless paths from from to to, ordered
+	// by cost. It returns no paths if to is unreachable.
+	FindPaths(ctx context.Context, from, to string, opts PathOptions) ([]Path, error)
+}
+
+// Neighbors returns the edges leaving a node, oriented away from it.
+type Neighbors func(ctx context.Context, id string) ([]Edge, error)
+
+// ShortestPaths finds up to opts.K loopless paths from from to to with Yen's
+// algorithm, running Dijkstra's algorithm over the edges returned by
+// neighbors, which is called at most once per node. Paths are ordered by
+// cost, then by their node IDs. It lets providers implement PathFinder
+// without a native shortest-path query.
+func ShortestPaths(ctx context.Context, from, to string, opts PathOptions, neighbors Neighbors) ([]Path, error) {
+	if opts.K <= 0 {
+		opts.K = 1
+	}
+	if opts.Cost == nil {
+		opts.Cost = EdgeCost
+	}
+
+	s := &pathSearch{opts: opts, neighbors: neighbors, edges: make(map[string][]Edge)}
+	first, err := s.dijkstra(ctx, from, to, nil, nil, opts.MaxDepth)
+	if err != nil || first == nil {
+		return nil, err
+	}
+
+	paths := []Path{*first}
+	var candidates []Path
+	for len(paths) < opts.K {
+		prev := paths[len(paths)-1]
+		for i := 0; i < len(prev.Edges); i++ {
+			root := prev.Nodes[:i+1]
+
+			// Block the next edge of every found path sharing the root, and
+			// the root's nodes before the spur node, to force a deviation.
+			blockedEdges := make(map[edgeKey]bool)
+			for _, p := range paths {
+				if len(p.Edges) > i && slices.Equal(p.Nodes[:i+1], root) {
+					blockedEdges[keyOf(p.Edges[i])] = true
+				}
+			}
+			blockedNodes := make(map[string]bool, i)
+			for _, id := range root[:i] {
+				blockedNodes[id] = true
+			}
+
+			depth := 0
+			if opts.MaxDepth > 0 {
+				if depth = opts.MaxDepth - i; depth <= 0 {
+					continue
+				}
+			}
+			spur, err := s.dijkstra(ctx, root[i], to, blockedNodes, blockedEdges, depth)
+			if err != nil {
+				return nil, err
+			}
+			if spur == nil {
+				continue
+			}
+
+			path := Path{
+				Nodes: append(slices.Clone(root), spur.Nodes[1:]...),
+				Edges: append(slices.Clone(prev.Edges[:i]), spur.Edges...),
+				Cost:  spur.Cost,
+			}
+			for _, e := range path.Edges[:i] {
+				path.Cost += opts.Cost(e)
+			}
+			if !slices.ContainsFunc(candidates, func(p Path) bool { return samePath(p, path) }) {
+				candidates = append(candidates, path)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		best := 0
+		for i := range candidates {
+			if lessPath(candidates[i], candidates[best]) {
+				best = i
+			}
+		}
+		paths = append(paths, candidates[best])
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+	return paths, nil
+}
+
+// edgeKey identifies an edge.
+type edgeKey struct {
+	from, to, edgeType string
+}
+
+func keyOf(e Edge) edgeKey {
+	return edgeKey{e.From, e.To, e.Type}
+}
+
+// samePath reports whether two paths take the same edges.
+func samePath(a, b Path) bool {
+	return slices.EqualFunc(a.Edges, b.Edges, func(x, y Edge) bool { return keyOf(x) == keyOf(y) })
+}
+
+// lessPath orders paths by cost, then by node IDs.
+func lessPath(a, b Path) bool {
+	if a.Cost != b.Cost {
+		return a.Cost < b.Cost
+	}
+	return slices.Compare(a.Nodes, b.Nodes) < 0
+}
+
+// pathSearch holds the state shared by the Dijkstra runs of one search.
+type pathSearch struct {
+	opts      PathOptions
+	neighbors Neighbors
+	edges     map[string][]Edge
+}
+
+// out returns the traversable edges leaving id, fetching them once.
+func (s *pathSearch) out(ctx context.Context, id string) ([]Edge, error) {
+	if edges, ok := s.edges[id]; ok {
+		return edges, nil
+	}
+	all, err := s.neighbors(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get neighbors of %s: %w", id, err)
+	}
+	var edges []Edge
+	for _, e := range all {
+		if e.Weight < s.opts.MinWeight || (len(s.opts.EdgeTypes) > 0 && !slices.Contains(s.opts.EdgeTypes, e.Type)) {
+			continue
+		}
+		cost := s.opts.Cost(e)
+		if cost < 0 || math.IsNaN(cost) {
+			return nil, fmt.Errorf("edge %s -> %s has invalid cost %v", e.From, e.To, cost)
+		}
+		if !math.IsInf(cost, 1) {
+			edges = append(edges, e)
+		}
+	}
+	s.edges[id] = edges
+	return edges, nil
+}
+
+// dijkstra returns the cheapest path from from to to that avoids the blocked
+// nodes and edges and has at most maxDepth edges (0: unlimited), or nil if
+// there is none.
+func (s *pathSearch) dijkstra(ctx context.Context, from, to string, blockedNodes map[string]bool, blockedEdges map[edgeKey]bool, maxDepth int) (*Path, error) {
+	// With a depth limit, a node reached in fewer hops may still lead
+	// somewhere a cheaper but longer path can't, so states include the depth.
+	type state struct {
+		id    string
+		depth int
+	}
+	stateOf := func(p *Path) state {
+		if maxDepth > 0 {
+			return state{p.Nodes[len(p.Nodes)-1], len(p.Edges)}
+		}
+		return state{id: p.Nodes[len(p.Nodes)-1]}
+	}
+
+	settled := make(map[state]bool)
+	queue := &pathQueue{{Nodes: []string{from}}}
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := heap.Pop(queue).(*Path)
+		st := stateOf(p)
+		if settled[st] {
+			continue
+		}
+		settled[st] = true
+		if st.id == to {
+			return p, nil
+		}
+		if maxDepth > 0 && len(p.Edges) >= maxDepth {
+			continue
+		}
+
+		edges, err := s.out(ctx, st.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			if blockedNodes[e.To] || blockedEdges[keyOf(e)] || slices.Contains(p.Nodes, e.To) {
+				continue
+			}
+			heap.Push(queue, &Path{
+				Nodes: append(slices.Clip(p.Nodes), e.To),
+				Edges: append(slices.Clip(p.Edges), e),
+				Cost:  p.Cost + s.opts.Cost(e),
+			})
+		}
+	}
+	return nil, nil
+}
+
+// pathQueue is a min-heap of paths ordered by lessPath.
+type pathQueue []*Path
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return lessPath(*q[i], *q[j]) }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)        { *q = append(*q, x.(*Path)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	p := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return p
+}
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrQueryLanguageNotSupported is returned by Cypher and Gremlin when the
+// graph does not implement the corresponding querier interface.
+var ErrQueryLanguageNotSupported = errors.New("query language not supported")
+
+// QueryResult is the result of a backend-native query, mapped back to
+// graph nodes and edges.
+type QueryResult struct {
+	// Nodes are the nodes returned by the query.
+	Nodes []Node
+	// Edges are the edges returned by the query.
+	Edges []Edge
+}
+
+// CypherQuerier is implemented by graphs that can run raw Cypher queries
+// (e.g., Neo4j, Memgraph, Apache AGE). It is an escape hatch for traversals
+// TraversalOptions can't express; queries are not portable across providers.
+type CypherQuerier interface {
+	KnowledgeGraph
+	// QueryCypher runs a Cypher query with named parameters.
+	QueryCypher(ctx context.Context, query string, params map[string]any) (*QueryResult, error)
+}
+
+// GremlinQuerier is implemented by graphs that can run raw Gremlin queries
+// (e.g., JanusGraph, Neptune).
+type GremlinQuerier interface {
+	KnowledgeGraph
+	// QueryGremlin runs a Gremlin query with variable bindings.
+	QueryGremlin(ctx context.Context, query string, bindings map[string]any) (*QueryResult, error)
+}
+
+// Cypher runs a Cypher query against g if it implements CypherQuerier.
+func Cypher(ctx context.Context, g KnowledgeGraph, query string, params map[string]any) (*QueryResult, error) {
+	q, ok := g.(CypherQuerier)
+	if !ok {
+		return nil, fmt.Errorf("graph %s: cypher: %w", g.Name(), ErrQueryLanguageNotSupported)
+	}
+	return q.QueryCypher(ctx, query, params)
+}
+
+// Gremlin runs a Gremlin query against g if it implements GremlinQuerier.
+func Gremlin(ctx context.Context, g KnowledgeGraph, query string, bindings map[string]any) (*QueryResult, error) {
+	q, ok := g.(GremlinQuerier)
+	if !ok {
+		return nil, fmt.Errorf("graph %s: gremlin: %w", g.Name(), ErrQueryLanguageNotSupported)
+	}
+	return q.QueryGremlin(ctx, query, bindings)
+}
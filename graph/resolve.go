@@ -0,0 +1,302 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// MatchKind describes how an entity hint was linked to a node.
+type MatchKind string
+
+const (
+	// MatchExact links a name equal to the node's ID or one of its names.
+	MatchExact MatchKind = "exact"
+	// MatchNormalized links a name equal to one of the node's names after
+	// case folding and dropping punctuation.
+	MatchNormalized MatchKind = "normalized"
+	// MatchFuzzy links a name similar to one of the node's names by trigram
+	// similarity.
+	MatchFuzzy MatchKind = "fuzzy"
+	// MatchEmbedding links a name similar to the node by embedding.
+	MatchEmbedding MatchKind = "embedding"
+)
+
+// NodeMatch is a node an entity hint was linked to.
+type NodeMatch struct {
+	// ID is the node ID.
+	ID string
+	// Kind is how the hint was matched.
+	Kind MatchKind
+	// Confidence is the match confidence (0.0-1.0).
+	Confidence float64
+}
+
+// EntityResolver links query entity hints to graph nodes.
+type EntityResolver interface {
+	// Resolve returns the nodes hint refers to, most confident first.
+	Resolve(ctx context.Context, hint retrieve.EntityHint) ([]NodeMatch, error)
+}
+
+// NodeResolverConfig configures a NodeResolver.
+type NodeResolverConfig struct {
+	// Graph is the graph whose nodes are linked.
+	Graph KnowledgeGraph
+	// NameKeys are the metadata keys holding a node's name (default
+	// ["name"]). Node IDs are always names.
+	NameKeys []string
+	// AliasKeys are the metadata keys holding comma-separated alternative
+	// names (default ["aliases"]).
+	AliasKeys []string
+	// MinSimilarity is the minimum trigram similarity of a fuzzy match
+	// (default 0.5).
+	MinSimilarity float64
+	// Index is an optional vector index over node embeddings, keyed by node
+	// ID, for matching by embedding when no name matches.
+	Index vector.Index
+	// Embedder embeds hint names for Index.
+	Embedder vector.Embedder
+	// MinScore is the minimum similarity of an embedding match (default 0.8).
+	MinScore float64
+	// MaxMatches is the maximum number of nodes a hint is linked to
+	// (default 1).
+	MaxMatches int
+	// MaxAge is how long the node names are used before they are reloaded on
+	// the next call to Resolve (default 0: until Refresh).
+	MaxAge time.Duration
+}
+
+// NodeResolver implements EntityResolver by matching hint names against the
+// names of the graph's nodes, trying in turn exact, normalized, fuzzy, and
+// embedding matches, and stopping at the first kind that matches. Exact and
+// normalized matches have confidence 1 and 0.95; fuzzy and embedding matches
+// have their similarity as confidence. Hints with a Type only match nodes of
+// that type. Node names are loaded once with FindNodes and cached.
+type NodeResolver struct {
+	config NodeResolverConfig
+
+	mu       sync.Mutex
+	names    []nodeName
+	loadedAt time.Time
+}
+
+// nodeName is one name of a node.
+type nodeName struct {
+	id, nodeType, name, normalized string
+	trigrams                       map[string]bool
+}
+
+// NewNodeResolver creates a new node resolver.
+func NewNodeResolver(cfg NodeResolverConfig) *NodeResolver {
+	if cfg.NameKeys == nil {
+		cfg.NameKeys = []string{"name"}
+	}
+	if cfg.AliasKeys == nil {
+		cfg.AliasKeys = []string{"aliases"}
+	}
+	if cfg.MinSimilarity == 0 {
+		cfg.MinSimilarity = 0.5
+	}
+	if cfg.MinScore == 0 {
+		cfg.MinScore = 0.8
+	}
+	if cfg.MaxMatches <= 0 {
+		cfg.MaxMatches = 1
+	}
+	return &NodeResolver{config: cfg}
+}
+
+// Refresh reloads the node names from the graph.
+func (r *NodeResolver) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.refresh(ctx)
+}
+
+// refresh reloads the node names. The caller must hold the lock.
+func (r *NodeResolver) refresh(ctx context.Context) error {
+	nodes, err := r.config.Graph.FindNodes(ctx, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var names []nodeName
+	add := func(n Node, name string) {
+		if name = strings.TrimSpace(name); name == "" {
+			return
+		}
+		normalized := normalizeName(name)
+		names = append(names, nodeName{
+			id:         n.ID,
+			nodeType:   n.Type,
+			name:       name,
+			normalized: normalized,
+			trigrams:   trigrams(normalized),
+		})
+	}
+	for _, n := range nodes {
+		add(n, n.ID)
+		for _, key := range r.config.NameKeys {
+			add(n, n.Metadata[key])
+		}
+		for _, key := range r.config.AliasKeys {
+			for _, alias := range strings.Split(n.Metadata[key], ",") {
+				add(n, alias)
+			}
+		}
+	}
+	r.names, r.loadedAt = names, time.Now()
+	return nil
+}
+
+// Resolve implements EntityResolver. A hint with an ID is returned as an
+// exact match without lookup.
+func (r *NodeResolver) Resolve(ctx context.Context, hint retrieve.EntityHint) ([]NodeMatch, error) {
+	if hint.ID != "" {
+		return []NodeMatch{{ID: hint.ID, Kind: MatchExact, Confidence: 1}}, nil
+	}
+	if strings.TrimSpace(hint.Name) == "" {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	if r.names == nil || (r.config.MaxAge > 0 && time.Since(r.loadedAt) > r.config.MaxAge) {
+		if err := r.refresh(ctx); err != nil {
+			r.mu.Unlock()
+			return nil, err
+		}
+	}
+	names := r.names
+	r.mu.Unlock()
+
+	name := strings.TrimSpace(hint.Name)
+	normalized := normalizeName(name)
+	hintTrigrams := trigrams(normalized)
+	best := make(map[MatchKind]map[string]float64)
+	for _, n := range names {
+		if hint.Type != "" && n.nodeType != hint.Type {
+			continue
+		}
+		kind, confidence := MatchFuzzy, trigramSimilarity(hintTrigrams, n.trigrams)
+		switch {
+		case n.name == name:
+			kind, confidence = MatchExact, 1
+		case n.normalized == normalized:
+			kind, confidence = MatchNormalized, 0.95
+		case confidence < r.config.MinSimilarity:
+			continue
+		}
+		if best[kind] == nil {
+			best[kind] = make(map[string]float64)
+		}
+		best[kind][n.id] = max(best[kind][n.id], confidence)
+	}
+
+	for _, kind := range []MatchKind{MatchExact, MatchNormalized, MatchFuzzy} {
+		if len(best[kind]) > 0 {
+			return r.top(kind, best[kind]), nil
+		}
+	}
+	if r.config.Index == nil || r.config.Embedder == nil {
+		return nil, nil
+	}
+	return r.resolveEmbedding(ctx, hint)
+}
+
+// top returns the most confident matches, ordered by confidence, then ID.
+func (r *NodeResolver) top(kind MatchKind, confidences map[string]float64) []NodeMatch {
+	matches := make([]NodeMatch, 0, len(confidences))
+	for id, c := range confidences {
+		matches = append(matches, NodeMatch{ID: id, Kind: kind, Confidence: c})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if len(matches) > r.config.MaxMatches {
+		matches = matches[:r.config.MaxMatches]
+	}
+	return matches
+}
+
+// resolveEmbedding matches the hint name against the node embeddings.
+func (r *NodeResolver) resolveEmbedding(ctx context.Context, hint retrieve.EntityHint) ([]NodeMatch, error) {
+	embedding, err := r.config.Embedder.Embed(ctx, hint.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed entity name: %w", err)
+	}
+	results, err := r.config.Index.Search(ctx, embedding, r.config.MaxMatches, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search node embeddings: %w", err)
+	}
+
+	confidences := make(map[string]float64)
+	for _, res := range results {
+		if res.Score < r.config.MinScore {
+			continue
+		}
+		if hint.Type != "" && !r.hasType(res.Node.ID, hint.Type) {
+			continue
+		}
+		confidences[res.Node.ID] = min(res.Score, 1)
+	}
+	return r.top(MatchEmbedding, confidences), nil
+}
+
+// hasType reports whether the node with the given ID has the given type.
+func (r *NodeResolver) hasType(id, nodeType string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.names {
+		if n.id == id {
+			return n.nodeType == nodeType
+		}
+	}
+	return false
+}
+
+// normalizeName case-folds a name, drops punctuation, and collapses
+// whitespace.
+func normalizeName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// trigrams returns the character trigrams of a normalized name, padded so
+// that word boundaries count.
+func trigrams(normalized string) map[string]bool {
+	runes := []rune("  " + normalized + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// trigramSimilarity returns the Jaccard similarity of two trigram sets.
+func trigramSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Verify interface compliance
+var _ EntityResolver = (*NodeResolver)(nil)
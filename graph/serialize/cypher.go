@@ -0,0 +1,90 @@
+package serialize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cypherLabel is the label of every exported node, under which nodes are
+// matched by ID when creating relationships.
+const cypherLabel = "Node"
+
+// cypherDefaultRelType is the relationship type of edges without a type,
+// since Cypher relationships require one.
+const cypherDefaultRelType = "RELATED_TO"
+
+// encodeCypher writes one statement per line: a CREATE per node, labeled
+// Node and with its type as a second label, then a MATCH ... CREATE per
+// edge, typed by the edge type. Metadata entries become properties prefixed
+// with "meta.". Create an index on :Node(id) before running the edge
+// statements on large graphs.
+func encodeCypher(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	for _, n := range g.Nodes {
+		labels := ":" + cypherIdent(cypherLabel)
+		if n.Type != "" {
+			labels += ":" + cypherIdent(n.Type)
+		}
+		props := []string{"id: " + cypherString(n.ID)}
+		props = appendProp(props, "content", n.Content)
+		props = appendProp(props, "source", n.Source)
+		props = appendMetadata(props, n.Metadata)
+		fmt.Fprintf(bw, "CREATE (%s {%s});\n", labels, strings.Join(props, ", "))
+	}
+	for _, e := range g.Edges {
+		relType := e.Type
+		if relType == "" {
+			relType = cypherDefaultRelType
+		}
+		props := []string{"weight: " + strconv.FormatFloat(e.Weight, 'g', -1, 64)}
+		if !e.ValidFrom.IsZero() {
+			props = append(props, "valid_from: datetime("+cypherString(formatTime(e.ValidFrom))+")")
+		}
+		if !e.ValidTo.IsZero() {
+			props = append(props, "valid_to: datetime("+cypherString(formatTime(e.ValidTo))+")")
+		}
+		props = appendMetadata(props, e.Metadata)
+		label := cypherIdent(cypherLabel)
+		fmt.Fprintf(bw, "MATCH (a:%s {id: %s}), (b:%s {id: %s}) CREATE (a)-[:%s {%s}]->(b);\n",
+			label, cypherString(e.From), label, cypherString(e.To), cypherIdent(relType), strings.Join(props, ", "))
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	return nil
+}
+
+// appendProp appends a string property unless value is empty.
+func appendProp(props []string, name, value string) []string {
+	if value == "" {
+		return props
+	}
+	return append(props, name+": "+cypherString(value))
+}
+
+// appendMetadata appends metadata entries as properties in key order.
+func appendMetadata(props []string, m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		props = append(props, cypherIdent(metaPrefix+k)+": "+cypherString(m[k]))
+	}
+	return props
+}
+
+// cypherIdent quotes an identifier with backticks.
+func cypherIdent(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+// cypherString quotes a string literal.
+func cypherString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`).Replace(s) + "'"
+}
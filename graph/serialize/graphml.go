@@ -0,0 +1,205 @@
+package serialize
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// graphMLNamespace is the GraphML XML namespace.
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// metaPrefix prefixes the GraphML attribute names of metadata entries, so
+// they can't collide with the built-in attributes.
+const metaPrefix = "meta."
+
+type gmlDocument struct {
+	XMLName xml.Name `xml:"graphml"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Keys    []gmlKey `xml:"key"`
+	Graph   gmlGraph `xml:"graph"`
+}
+
+type gmlKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type gmlGraph struct {
+	ID          string    `xml:"id,attr,omitempty"`
+	EdgeDefault string    `xml:"edgedefault,attr,omitempty"`
+	Nodes       []gmlNode `xml:"node"`
+	Edges       []gmlEdge `xml:"edge"`
+}
+
+type gmlNode struct {
+	ID   string    `xml:"id,attr"`
+	Data []gmlData `xml:"data"`
+}
+
+type gmlEdge struct {
+	Source string    `xml:"source,attr"`
+	Target string    `xml:"target,attr"`
+	Data   []gmlData `xml:"data"`
+}
+
+type gmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// gmlKeys assigns GraphML key IDs to attribute names, per element kind.
+type gmlKeys struct {
+	keys []gmlKey
+	ids  map[[2]string]string // {for, name} -> key ID
+}
+
+// id returns the key ID of an attribute, declaring it on first use.
+func (k *gmlKeys) id(elem, name, attrType string) string {
+	if id, ok := k.ids[[2]string{elem, name}]; ok {
+		return id
+	}
+	id := "d" + strconv.Itoa(len(k.keys))
+	k.keys = append(k.keys, gmlKey{ID: id, For: elem, Name: name, Type: attrType})
+	k.ids[[2]string{elem, name}] = id
+	return id
+}
+
+// data appends an attribute value unless it is empty.
+func (k *gmlKeys) data(data []gmlData, elem, name, attrType, value string) []gmlData {
+	if value == "" {
+		return data
+	}
+	return append(data, gmlData{Key: k.id(elem, name, attrType), Value: value})
+}
+
+// metadata appends metadata entries in key order.
+func (k *gmlKeys) metadata(data []gmlData, elem string, m map[string]string) []gmlData {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data = append(data, gmlData{Key: k.id(elem, metaPrefix+name, "string"), Value: m[name]})
+	}
+	return data
+}
+
+func encodeGraphML(w io.Writer, g *Graph) error {
+	keys := &gmlKeys{ids: make(map[[2]string]string)}
+	doc := gmlDocument{Xmlns: graphMLNamespace, Graph: gmlGraph{ID: "G", EdgeDefault: "directed"}}
+	for _, n := range g.Nodes {
+		var data []gmlData
+		data = keys.data(data, "node", "type", "string", n.Type)
+		data = keys.data(data, "node", "content", "string", n.Content)
+		data = keys.data(data, "node", "source", "string", n.Source)
+		data = keys.metadata(data, "node", n.Metadata)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gmlNode{ID: n.ID, Data: data})
+	}
+	for _, e := range g.Edges {
+		var data []gmlData
+		data = keys.data(data, "edge", "type", "string", e.Type)
+		data = keys.data(data, "edge", "weight", "double", strconv.FormatFloat(e.Weight, 'g', -1, 64))
+		data = keys.data(data, "edge", "valid_from", "string", formatTime(e.ValidFrom))
+		data = keys.data(data, "edge", "valid_to", "string", formatTime(e.ValidTo))
+		data = keys.metadata(data, "edge", e.Metadata)
+		doc.Graph.Edges = append(doc.Graph.Edges, gmlEdge{Source: e.From, Target: e.To, Data: data})
+	}
+	doc.Keys = keys.keys
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	return nil
+}
+
+func decodeGraphML(r io.Reader) (*Graph, error) {
+	var doc gmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse GraphML: %w", err)
+	}
+	names := make(map[string]string, len(doc.Keys))
+	for _, k := range doc.Keys {
+		names[k.ID] = k.Name
+	}
+
+	g := &Graph{}
+	for _, gn := range doc.Graph.Nodes {
+		n := graph.Node{ID: gn.ID}
+		for _, d := range gn.Data {
+			switch name := names[d.Key]; name {
+			case "type":
+				n.Type = d.Value
+			case "content":
+				n.Content = d.Value
+			case "source":
+				n.Source = d.Value
+			default:
+				n.Metadata = setMetadata(n.Metadata, name, d.Value)
+			}
+		}
+		g.Nodes = append(g.Nodes, n)
+	}
+	for _, ge := range doc.Graph.Edges {
+		e := graph.Edge{From: ge.Source, To: ge.Target}
+		for _, d := range ge.Data {
+			var err error
+			switch name := names[d.Key]; name {
+			case "type":
+				e.Type = d.Value
+			case "weight":
+				e.Weight, err = strconv.ParseFloat(strings.TrimSpace(d.Value), 64)
+			case "valid_from":
+				e.ValidFrom, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(d.Value))
+			case "valid_to":
+				e.ValidTo, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(d.Value))
+			default:
+				e.Metadata = setMetadata(e.Metadata, name, d.Value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("edge %s->%s: invalid %s: %w", e.From, e.To, names[d.Key], err)
+			}
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	return g, nil
+}
+
+// setMetadata sets a metadata entry from a GraphML attribute, ignoring
+// attributes other than metadata entries.
+func setMetadata(m map[string]string, name, value string) map[string]string {
+	key, ok := strings.CutPrefix(name, metaPrefix)
+	if !ok {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]string)
+	}
+	m[key] = value
+	return m
+}
+
+// formatTime formats a time as RFC 3339, or "" for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,99 @@
+package serialize
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// record is one line of the JSONL format.
+type record struct {
+	Kind      string            `json:"kind"`
+	ID        string            `json:"id,omitempty"`
+	From      string            `json:"from,omitempty"`
+	To        string            `json:"to,omitempty"`
+	Type      string            `json:"type,omitempty"`
+	Content   string            `json:"content,omitempty"`
+	Source    string            `json:"source,omitempty"`
+	Weight    float64           `json:"weight,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	ValidFrom time.Time         `json:"valid_from,omitzero"`
+	ValidTo   time.Time         `json:"valid_to,omitzero"`
+}
+
+// Record kinds of the JSONL format.
+const (
+	kindNode = "node"
+	kindEdge = "edge"
+)
+
+func encodeJSONL(w io.Writer, g *Graph) error {
+	enc := json.NewEncoder(w)
+	for _, n := range g.Nodes {
+		rec := record{Kind: kindNode, ID: n.ID, Type: n.Type, Content: n.Content, Source: n.Source, Metadata: n.Metadata}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write node %s: %w", n.ID, err)
+		}
+	}
+	for _, e := range g.Edges {
+		rec := record{
+			Kind:      kindEdge,
+			From:      e.From,
+			To:        e.To,
+			Type:      e.Type,
+			Weight:    e.Weight,
+			Metadata:  e.Metadata,
+			ValidFrom: e.ValidFrom,
+			ValidTo:   e.ValidTo,
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write edge %s->%s: %w", e.From, e.To, err)
+		}
+	}
+	return nil
+}
+
+func decodeJSONL(r io.Reader) (*Graph, error) {
+	g := &Graph{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d: %w", line, err)
+		}
+		switch rec.Kind {
+		case kindNode:
+			g.Nodes = append(g.Nodes, graph.Node{
+				ID:       rec.ID,
+				Type:     rec.Type,
+				Content:  rec.Content,
+				Source:   rec.Source,
+				Metadata: rec.Metadata,
+			})
+		case kindEdge:
+			g.Edges = append(g.Edges, graph.Edge{
+				From:      rec.From,
+				To:        rec.To,
+				Type:      rec.Type,
+				Weight:    rec.Weight,
+				Metadata:  rec.Metadata,
+				ValidFrom: rec.ValidFrom,
+				ValidTo:   rec.ValidTo,
+			})
+		default:
+			return nil, fmt.Errorf("line %d: unknown record kind %q", line, rec.Kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read graph: %w", err)
+	}
+	return g, nil
+}
@@ -0,0 +1,149 @@
+// Package serialize exports knowledge graphs to portable formats and imports
+// them back, so that graphs built in memory can be versioned or moved to
+// another backend such as Neo4j.
+package serialize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// Format is a serialization format.
+type Format string
+
+const (
+	// FormatJSONL writes one JSON object per line: nodes first, then edges.
+	FormatJSONL Format = "jsonl"
+	// FormatGraphML writes GraphML, readable by graph tools such as Gephi,
+	// yEd, and Neo4j's APOC.
+	FormatGraphML Format = "graphml"
+	// FormatCypher writes Cypher CREATE statements for Neo4j. It can't be
+	// decoded.
+	FormatCypher Format = "cypher"
+)
+
+// ErrUnsupportedFormat is returned for unknown formats and for decoding
+// write-only formats.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Graph is a snapshot of a knowledge graph.
+type Graph struct {
+	// Nodes are the nodes, ordered by ID.
+	Nodes []graph.Node
+	// Edges are the edges, ordered by source, target, and type.
+	Edges []graph.Edge
+}
+
+// Snapshot reads every node and edge of kg. Edges are listed with
+// graph.EdgeLister when implemented and otherwise node by node.
+func Snapshot(ctx context.Context, kg graph.KnowledgeGraph) (*Graph, error) {
+	nodes, err := kg.FindNodes(ctx, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	var edges []graph.Edge
+	if lister, ok := kg.(graph.EdgeLister); ok {
+		if edges, err = lister.Edges(ctx); err != nil {
+			return nil, fmt.Errorf("failed to list edges: %w", err)
+		}
+	} else {
+		for _, n := range nodes {
+			out, err := kg.GetEdges(ctx, n.ID, graph.DirectionOut)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list edges of %s: %w", n.ID, err)
+			}
+			edges = append(edges, out...)
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+	return &Graph{Nodes: nodes, Edges: edges}, nil
+}
+
+// Load upserts the nodes and then the edges of g into kg, in batches when kg
+// implements graph.BatchKnowledgeGraph.
+func Load(ctx context.Context, kg graph.KnowledgeGraph, g *Graph) error {
+	if batch, ok := kg.(graph.BatchKnowledgeGraph); ok {
+		if err := batch.UpsertNodeBatch(ctx, g.Nodes); err != nil {
+			return fmt.Errorf("failed to load nodes: %w", err)
+		}
+		if err := batch.UpsertEdgeBatch(ctx, g.Edges); err != nil {
+			return fmt.Errorf("failed to load edges: %w", err)
+		}
+		return nil
+	}
+	for _, n := range g.Nodes {
+		if err := kg.UpsertNode(ctx, n); err != nil {
+			return fmt.Errorf("failed to load node %s: %w", n.ID, err)
+		}
+	}
+	for _, e := range g.Edges {
+		if err := kg.UpsertEdge(ctx, e); err != nil {
+			return fmt.Errorf("failed to load edge %s->%s: %w", e.From, e.To, err)
+		}
+	}
+	return nil
+}
+
+// Encode writes g in format.
+func Encode(w io.Writer, g *Graph, format Format) error {
+	switch format {
+	case FormatJSONL:
+		return encodeJSONL(w, g)
+	case FormatGraphML:
+		return encodeGraphML(w, g)
+	case FormatCypher:
+		return encodeCypher(w, g)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// Decode reads a graph in format.
+func Decode(r io.Reader, format Format) (*Graph, error) {
+	switch format {
+	case FormatJSONL:
+		return decodeJSONL(r)
+	case FormatGraphML:
+		return decodeGraphML(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// Export writes every node and edge of kg to w in format.
+func Export(ctx context.Context, kg graph.KnowledgeGraph, w io.Writer, format Format) error {
+	g, err := Snapshot(ctx, kg)
+	if err != nil {
+		return err
+	}
+	return Encode(w, g, format)
+}
+
+// Import reads a graph in format from r and loads it into kg. It returns the
+// graph read.
+func Import(ctx context.Context, kg graph.KnowledgeGraph, r io.Reader, format Format) (*Graph, error) {
+	g, err := Decode(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := Load(ctx, kg, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
@@ -0,0 +1,88 @@
+package serialize_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/agentplexus/omniretrieve/graph/serialize"
+	"github.com/agentplexus/omniretrieve/memory"
+)
+
+func newGraph(t *testing.T) *memory.KnowledgeGraph {
+	t.Helper()
+	ctx := context.Background()
+	kg := memory.NewKnowledgeGraph("source")
+	nodes := []graph.Node{
+		{ID: "A", Type: "concept", Content: "Machine <Learning> & \"AI\"", Source: "test", Metadata: map[string]string{"lang": "en", "type": "shadowed"}},
+		{ID: "B", Type: "person", Content: "O'Brien\nline two"},
+	}
+	edges := []graph.Edge{
+		{From: "A", To: "B", Type: "relates_to", Weight: 0.75, Metadata: map[string]string{"via": "x"},
+			ValidFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), ValidTo: time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)},
+		{From: "B", To: "A", Weight: 1},
+	}
+	if err := kg.AddNodeBatch(ctx, nodes); err != nil {
+		t.Fatalf("failed to add nodes: %v", err)
+	}
+	if err := kg.AddEdgeBatch(ctx, edges); err != nil {
+		t.Fatalf("failed to add edges: %v", err)
+	}
+	return kg
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newGraph(t)
+	want, err := serialize.Snapshot(ctx, source)
+	if err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+
+	for _, format := range []serialize.Format{serialize.FormatJSONL, serialize.FormatGraphML} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := serialize.Export(ctx, source, &buf, format); err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+
+			target := memory.NewKnowledgeGraph("target")
+			if _, err := serialize.Import(ctx, target, &buf, format); err != nil {
+				t.Fatalf("failed to import: %v", err)
+			}
+			got, err := serialize.Snapshot(ctx, target)
+			if err != nil {
+				t.Fatalf("failed to snapshot: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCypher(t *testing.T) {
+	var buf bytes.Buffer
+	if err := serialize.Export(context.Background(), newGraph(t), &buf, serialize.FormatCypher); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"CREATE (:`Node`:`concept` {id: 'A', content: 'Machine <Learning> & \"AI\"', source: 'test', `meta.lang`: 'en', `meta.type`: 'shadowed'});",
+		"CREATE (:`Node`:`person` {id: 'B', content: 'O\\'Brien\\nline two'});",
+		"MATCH (a:`Node` {id: 'A'}), (b:`Node` {id: 'B'}) CREATE (a)-[:`relates_to` {weight: 0.75, valid_from: datetime('2020-01-01T00:00:00Z'), valid_to: datetime('2022-06-01T12:30:00Z'), `meta.via`: 'x'}]->(b);",
+		"MATCH (a:`Node` {id: 'B'}), (b:`Node` {id: 'A'}) CREATE (a)-[:`RELATED_TO` {weight: 1}]->(b);",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("unexpected Cypher:\n%s", buf.String())
+	}
+
+	if _, err := serialize.Decode(&buf, serialize.FormatCypher); !errors.Is(err, serialize.ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat decoding Cypher, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package graph
+
+import "context"
+
+// TraversalStep is a node reached during a streaming traversal.
+type TraversalStep struct {
+	// Node is the node reached.
+	Node Node
+	// Path lists the node IDs from a start node to Node.
+	Path []string
+	// Edge is the edge Node was reached by, in its stored orientation, or
+	// nil for start nodes.
+	Edge *Edge
+}
+
+// TraversalVisitor is called for each node of a streaming traversal.
+// Returning false stops the traversal.
+type TraversalVisitor func(step TraversalStep) bool
+
+// StreamingTraverser is implemented by graphs that can stream a traversal
+// instead of materializing its result, to cap memory on very large graphs.
+type StreamingTraverser interface {
+	KnowledgeGraph
+	// TraverseStream traverses like Traverse, passing each reached node to
+	// visit in traversal order until it returns false. visit must not
+	// modify the graph.
+	TraverseStream(ctx context.Context, startNodes []string, opts TraversalOptions, visit TraversalVisitor) error
+}
+
+// TraverseStream streams a traversal of g to visit. Graphs that don't
+// implement StreamingTraverser are traversed with Traverse and the result
+// is replayed, so memory is only capped for streaming graphs.
+func TraverseStream(ctx context.Context, g KnowledgeGraph, startNodes []string, opts TraversalOptions, visit TraversalVisitor) error {
+	if s, ok := g.(StreamingTraverser); ok {
+		return s.TraverseStream(ctx, startNodes, opts, visit)
+	}
+	result, err := g.Traverse(ctx, startNodes, opts)
+	if err != nil {
+		return err
+	}
+	for _, node := range result.Nodes {
+		step := TraversalStep{Node: node, Path: result.Paths[node.ID]}
+		if n := len(step.Path); n > 1 {
+			step.Edge = findEdge(result.Edges, step.Path[n-2], step.Path[n-1])
+		}
+		if !visit(step) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// findEdge returns the edge linking two nodes in either orientation, or nil.
+func findEdge(edges []Edge, from, to string) *Edge {
+	for i, e := range edges {
+		if (e.From == from && e.To == to) || (e.From == to && e.To == from) {
+			return &edges[i]
+		}
+	}
+	return nil
+}
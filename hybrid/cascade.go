@@ -0,0 +1,91 @@
+package hybrid
+
+import (
+	"context"
+	"slices"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Confidence measures how well a cascade stage answered a query from the
+// scores of its top results.
+type Confidence string
+
+const (
+	// ConfidenceMax uses the highest score.
+	ConfidenceMax Confidence = "max"
+	// ConfidenceMean uses the mean score of the top results.
+	ConfidenceMean Confidence = "mean"
+)
+
+// defaultCascadeThreshold is the default confidence that ends a cascade.
+const defaultCascadeThreshold = 0.5
+
+// of returns the confidence of the top k items (all items if k <= 0), or 0
+// if there are none.
+func (c Confidence) of(items []retrieve.ContextItem, k int) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+	scores := make([]float64, len(items))
+	for i, item := range items {
+		scores[i] = item.Score
+	}
+	slices.Sort(scores)
+	slices.Reverse(scores)
+	if k > 0 && k < len(scores) {
+		scores = scores[:k]
+	}
+
+	if c == ConfidenceMean {
+		var sum float64
+		for _, s := range scores {
+			sum += s
+		}
+		return sum / float64(len(scores))
+	}
+	return scores[0]
+}
+
+// retrieveCascade runs the cheap stage first and the other stage only if
+// the first one's confidence is below the threshold.
+func (r *Retriever) retrieveCascade(ctx context.Context, q retrieve.Query) (*policyResult, error) {
+	pr := &policyResult{modesUsed: []retrieve.Mode{retrieve.ModeHybrid}}
+
+	stages := []retrieve.Mode{retrieve.ModeVector, retrieve.ModeGraph}
+	if r.config.CascadeFirst == retrieve.ModeGraph {
+		slices.Reverse(stages)
+	}
+	for i, mode := range stages {
+		primary, fallback, items := r.config.Vector, r.config.VectorFallback, &pr.vectorItems
+		if mode == retrieve.ModeGraph {
+			primary, fallback, items = r.config.Graph, r.config.GraphFallback, &pr.graphItems
+		}
+		if primary == nil {
+			continue
+		}
+
+		res, err := r.runBranch(ctx, primary, fallback, q)
+		switch {
+		case r.expired(ctx, err):
+			pr.partial = true
+			return pr, nil
+		case err != nil:
+			if !r.tolerate(ctx, pr, string(mode), err) {
+				return nil, err
+			}
+		default:
+			*items = res.Items
+			pr.addBranch(res)
+			pr.modesUsed = append(pr.modesUsed, mode)
+		}
+		pr.answeredBy = mode
+
+		// Stop early when the cheap stage is confident enough
+		if i == 0 && err == nil && r.config.CascadeConfidence.of(res.Items, q.TopK) >= r.config.CascadeThreshold {
+			return pr, nil
+		}
+	}
+
+	return pr, nil
+}
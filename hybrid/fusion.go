@@ -0,0 +1,81 @@
+package hybrid
+
+import (
+	"math"
+	"sort"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// FusionStrategy defines how branch scores are put on a common scale before
+// they are weighted and summed.
+type FusionStrategy string
+
+const (
+	// FusionWeighted sums weighted raw scores. It assumes branches score on
+	// comparable scales.
+	FusionWeighted FusionStrategy = "weighted"
+	// FusionMinMax rescales each branch's scores to [0, 1] before weighting.
+	FusionMinMax FusionStrategy = "min_max"
+	// FusionZScore standardizes each branch's scores to zero mean and unit
+	// variance before weighting, so fused scores may be negative.
+	FusionZScore FusionStrategy = "z_score"
+	// FusionRRF ignores raw scores and sums weighted reciprocal ranks,
+	// weight / (k + rank), with k set by RetrieverConfig.RRFK.
+	FusionRRF FusionStrategy = "rrf"
+)
+
+// DefaultRRFK is the default RRF rank constant, from Cormack et al. (2009).
+const DefaultRRFK = 60
+
+// fuse returns the scores of a branch's items on the fusion scale, in item
+// order.
+func fuse(strategy FusionStrategy, k int, items []retrieve.ContextItem) []float64 {
+	scores := make([]float64, len(items))
+	switch strategy {
+	case FusionMinMax:
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, item := range items {
+			lo, hi = math.Min(lo, item.Score), math.Max(hi, item.Score)
+		}
+		for i, item := range items {
+			if hi > lo {
+				scores[i] = (item.Score - lo) / (hi - lo)
+			} else {
+				scores[i] = 1
+			}
+		}
+	case FusionZScore:
+		var mean, variance float64
+		for _, item := range items {
+			mean += item.Score
+		}
+		mean /= float64(len(items))
+		for _, item := range items {
+			variance += (item.Score - mean) * (item.Score - mean)
+		}
+		stddev := math.Sqrt(variance / float64(len(items)))
+		for i, item := range items {
+			if stddev > 0 {
+				scores[i] = (item.Score - mean) / stddev
+			}
+		}
+	case FusionRRF:
+		// Rank by score, keeping the branch order for ties
+		order := make([]int, len(items))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return items[order[a]].Score > items[order[b]].Score
+		})
+		for rank, i := range order {
+			scores[i] = 1 / float64(k+rank+1)
+		}
+	default:
+		for i, item := range items {
+			scores[i] = item.Score
+		}
+	}
+	return scores
+}
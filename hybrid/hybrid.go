@@ -1,12 +1,18 @@
-// Package hybrid provides hybrid retrieval combining vector and graph strategies.
+// Package hybrid provides hybrid retrieval combining vector, graph, and
+// keyword strategies.
 package hybrid
 
 import (
 	"context"
-	"sort"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+	"golang.org/x/sync/errgroup"
 )
 
 // Policy defines how to combine vector and graph retrieval.
@@ -19,14 +25,21 @@ const (
 	PolicyVectorThenGraph Policy = "vector_then_graph"
 	// PolicyGraphThenVector runs graph traversal first, then grounds via vector.
 	PolicyGraphThenVector Policy = "graph_then_vector"
+	// PolicyCascade runs the cheap retriever first and the other one only
+	// if the first one's confidence is below a threshold.
+	PolicyCascade Policy = "cascade"
 )
 
-// Weights configures the relative importance of vector vs graph results.
+// Weights configures the relative importance of vector, graph, and keyword
+// results.
 type Weights struct {
 	// Vector weight (0.0-1.0).
 	Vector float64
 	// Graph weight (0.0-1.0).
 	Graph float64
+	// Keyword weight (0.0-1.0). Defaults to 0.3 when a keyword retriever is
+	// configured.
+	Keyword float64
 }
 
 // DefaultWeights returns balanced weights.
@@ -34,20 +47,169 @@ func DefaultWeights() Weights {
 	return Weights{Vector: 0.6, Graph: 0.4}
 }
 
+// WeightsFromAlpha derives weights from a single alpha knob, where 0 is pure
+// graph retrieval and 1 is pure vector retrieval. Alpha is clamped to [0, 1].
+func WeightsFromAlpha(alpha float64) Weights {
+	alpha = math.Max(0, math.Min(1, alpha))
+	return Weights{Vector: alpha, Graph: 1 - alpha}
+}
+
+// Alpha returns a pointer to alpha for use in RetrieverConfig.Alpha.
+func Alpha(alpha float64) *float64 {
+	return &alpha
+}
+
+// Preset is a named hybrid configuration for common tuning goals.
+type Preset string
+
+const (
+	// PresetBalanced weighs vector and graph results equally.
+	PresetBalanced Preset = "balanced"
+	// PresetPrecision favors vector similarity and cuts the low-scoring tail.
+	PresetPrecision Preset = "precision"
+	// PresetRecall expands vector hits through the graph and keeps the full tail.
+	PresetRecall Preset = "recall"
+)
+
+// Config returns a RetrieverConfig for the preset using the given retrievers.
+// Unknown presets fall back to PresetBalanced.
+func (p Preset) Config(vector, graph retrieve.Retriever) RetrieverConfig {
+	cfg := RetrieverConfig{
+		Vector:    vector,
+		Graph:     graph,
+		Policy:    PolicyParallel,
+		Alpha:     Alpha(0.5),
+		DedupByID: true,
+	}
+	switch p {
+	case PresetPrecision:
+		cfg.Alpha = Alpha(0.75)
+		cfg.Autocut = retrieve.Autocut{Mode: retrieve.AutocutLargestGap}
+	case PresetRecall:
+		cfg.Policy = PolicyVectorThenGraph
+	}
+	return cfg
+}
+
+// ErrorPolicy defines how branch errors affect a hybrid retrieval.
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyFailFast fails the retrieval when any branch fails.
+	ErrorPolicyFailFast ErrorPolicy = "fail_fast"
+	// ErrorPolicyBestEffort skips failed branches, failing the retrieval
+	// only when every branch that ran failed.
+	ErrorPolicyBestEffort ErrorPolicy = "best_effort"
+	// ErrorPolicyQuorum skips failed branches as long as at least
+	// RetrieverConfig.Quorum branches succeed.
+	ErrorPolicyQuorum ErrorPolicy = "quorum"
+)
+
+// Branch is a named retriever fused with the vector and graph results, such
+// as a sparse, dense, or web-search retriever.
+type Branch struct {
+	// Name identifies the branch in score explanations.
+	Name string
+	// Retriever runs the branch.
+	Retriever retrieve.Retriever
+	// Mode labels the branch's contributions and is reported in ModesUsed
+	// when the branch returns items (default Mode(Name)).
+	Mode retrieve.Mode
+	// Weight of the branch's scores (default 1).
+	Weight float64
+	// Fallback serves hedged requests for the branch (default Retriever).
+	Fallback retrieve.Retriever
+}
+
 // RetrieverConfig configures the hybrid retriever.
 type RetrieverConfig struct {
 	// Vector is the vector retriever.
 	Vector retrieve.Retriever
 	// Graph is the graph retriever.
 	Graph retrieve.Retriever
+	// VectorFallback and GraphFallback serve hedged requests for the vector
+	// and graph branches, such as read replicas (default: the branch's own
+	// retriever).
+	VectorFallback retrieve.Retriever
+	GraphFallback  retrieve.Retriever
+	// Keyword is an optional keyword retriever (e.g., lexical.Retriever). It
+	// runs concurrently with the policy and its results are fused with the
+	// vector and graph results.
+	Keyword retrieve.Retriever
+	// Branches are additional retrievers that, like Keyword, run
+	// concurrently with the policy and are fused with its results. Branches
+	// without a Retriever are ignored.
+	Branches []Branch
 	// Policy defines how to combine results.
 	Policy Policy
+	// CascadeFirst is the cheap retriever run first under PolicyCascade,
+	// retrieve.ModeVector or retrieve.ModeGraph (default ModeVector).
+	CascadeFirst retrieve.Mode
+	// CascadeConfidence measures the first stage's confidence over the top
+	// TopK results under PolicyCascade (default ConfidenceMax).
+	CascadeConfidence Confidence
+	// CascadeThreshold is the confidence at which PolicyCascade answers
+	// with the first stage alone (default 0.5).
+	CascadeThreshold float64
+	// Linker links vector results to the graph entities that seed graph
+	// expansion under PolicyVectorThenGraph (default IDLinker).
+	Linker EntityLinker
+	// Router, if set, chooses the policy and weights for each query,
+	// overriding Policy and Weights.
+	Router QueryRouter
 	// Weights for combining scores.
 	Weights Weights
+	// Alpha, if set, derives Weights from a single knob where 0 is pure graph
+	// and 1 is pure vector retrieval. It takes precedence over Weights.
+	Alpha *float64
+	// Fusion puts branch scores on a common scale before weighting (default
+	// FusionWeighted).
+	Fusion FusionStrategy
+	// RRFK is the rank constant for FusionRRF (default DefaultRRFK). Lower
+	// values favor top-ranked items more.
+	RRFK int
 	// Reranker to apply after merging (optional).
 	Reranker retrieve.Reranker
 	// DedupByID removes duplicate items by ID.
 	DedupByID bool
+	// Merge orders the merged results (default MergeScore).
+	Merge MergeStrategy
+	// MMRLambda trades relevance (1) against diversity (near 0) for
+	// MergeMMR (default 0.5).
+	MMRLambda float64
+	// MMREmbedder embeds result contents to compare them for MergeMMR
+	// (optional; default: word overlap).
+	MMREmbedder vector.Embedder
+	// Autocut truncates merged results at a score gap instead of returning
+	// all TopK. It only applies with MergeScore, since other strategies
+	// don't order results by score.
+	Autocut retrieve.Autocut
+	// Budget limits the total tokens or characters of the final results,
+	// which are selected greedily by rank after reranking.
+	Budget retrieve.ContextBudget
+	// BestEffort returns the results gathered so far, instead of an error,
+	// when the context deadline approaches. Branches or reranking that miss
+	// the deadline are skipped and Metadata.PartialResult is set.
+	BestEffort bool
+	// DeadlineMargin is the time reserved before the context deadline for
+	// merging and returning results in best-effort mode (default 10ms).
+	DeadlineMargin time.Duration
+	// BranchTimeout bounds each branch request (default: no bound beyond
+	// the context). A timed-out branch fails like any other branch error,
+	// so combine it with ErrorPolicy to skip slow backends.
+	BranchTimeout time.Duration
+	// HedgeAfter, if positive, sends a hedged request to a branch's fallback
+	// when the branch hasn't answered after this long. The first successful
+	// answer wins and the other request is canceled.
+	HedgeAfter time.Duration
+	// ErrorPolicy defines how branch errors other than deadline misses are
+	// handled (default ErrorPolicyFailFast). Skipped branch errors are
+	// reported in Metadata.BranchErrors. Cancellation of the caller's
+	// context always fails the retrieval.
+	ErrorPolicy ErrorPolicy
+	// Quorum is the number of branches that must succeed under
+	// ErrorPolicyQuorum (default: a majority of the branches that ran).
+	Quorum int
 	// Observer for tracing and metrics.
 	Observer retrieve.Observer
 }
@@ -55,6 +217,9 @@ type RetrieverConfig struct {
 // Retriever implements hybrid vector+graph retrieval.
 type Retriever struct {
 	config RetrieverConfig
+	// branches are the keyword retriever and the configured branches, run
+	// alongside the policy.
+	branches []Branch
 }
 
 // NewRetriever creates a new hybrid retriever.
@@ -62,252 +227,705 @@ func NewRetriever(cfg RetrieverConfig) *Retriever {
 	if cfg.Policy == "" {
 		cfg.Policy = PolicyParallel
 	}
+	if cfg.Alpha != nil {
+		cfg.Weights = WeightsFromAlpha(*cfg.Alpha)
+	}
 	if cfg.Weights.Vector == 0 && cfg.Weights.Graph == 0 {
 		cfg.Weights = DefaultWeights()
 	}
-	return &Retriever{config: cfg}
+	if cfg.Keyword != nil && cfg.Weights.Keyword == 0 {
+		cfg.Weights.Keyword = 0.3
+	}
+	if cfg.DeadlineMargin == 0 {
+		cfg.DeadlineMargin = 10 * time.Millisecond
+	}
+	if cfg.CascadeFirst == "" {
+		cfg.CascadeFirst = retrieve.ModeVector
+	}
+	if cfg.CascadeConfidence == "" {
+		cfg.CascadeConfidence = ConfidenceMax
+	}
+	if cfg.CascadeThreshold == 0 {
+		cfg.CascadeThreshold = defaultCascadeThreshold
+	}
+	if cfg.Fusion == "" {
+		cfg.Fusion = FusionWeighted
+	}
+	if cfg.ErrorPolicy == "" {
+		cfg.ErrorPolicy = ErrorPolicyFailFast
+	}
+	if cfg.Linker == nil {
+		cfg.Linker = IDLinker{}
+	}
+	if cfg.Merge == "" {
+		cfg.Merge = MergeScore
+	}
+	if cfg.MMRLambda == 0 {
+		cfg.MMRLambda = defaultMMRLambda
+	}
+	if cfg.RRFK <= 0 {
+		cfg.RRFK = DefaultRRFK
+	}
+
+	var branches []Branch
+	if cfg.Keyword != nil {
+		branches = append(branches, Branch{
+			Name:      string(retrieve.ModeKeyword),
+			Retriever: cfg.Keyword,
+			Mode:      retrieve.ModeKeyword,
+			Weight:    cfg.Weights.Keyword,
+		})
+	}
+	for _, b := range cfg.Branches {
+		if b.Retriever == nil {
+			continue
+		}
+		if b.Mode == "" {
+			b.Mode = retrieve.Mode(b.Name)
+		}
+		if b.Weight == 0 {
+			b.Weight = 1
+		}
+		branches = append(branches, b)
+	}
+	return &Retriever{config: cfg, branches: branches}
+}
+
+// Warmup implements retrieve.Warmer by warming the branches and the reranker.
+func (r *Retriever) Warmup(ctx context.Context) error {
+	if r.config.Vector == nil && r.config.Graph == nil && len(r.branches) == 0 {
+		return errors.New("hybrid retriever: at least one of vector, graph, keyword, or a branch is required")
+	}
+	components := []any{r.config.Vector, r.config.Graph}
+	for _, b := range r.branches {
+		components = append(components, b.Retriever)
+	}
+	return retrieve.Warmup(ctx, append(components, r.config.Reranker)...)
 }
 
 // Retrieve performs hybrid retrieval based on the configured policy.
 func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
 	start := time.Now()
 
-	var items []retrieve.ContextItem
-	var modesUsed []retrieve.Mode
-	var totalCandidates int
-	var err error
+	ctx, cancel := r.budget(ctx)
+	defer cancel()
+
+	policy, weights, err := r.route(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := r.startBranches(ctx, q)
+
+	var pr *policyResult
 
-	switch r.config.Policy {
+	switch policy {
 	case PolicyParallel:
-		items, modesUsed, totalCandidates, err = r.retrieveParallel(ctx, q)
+		pr, err = r.retrieveParallel(ctx, q)
 	case PolicyVectorThenGraph:
-		items, modesUsed, totalCandidates, err = r.retrieveVectorThenGraph(ctx, q)
+		pr, err = r.retrieveVectorThenGraph(ctx, q)
 	case PolicyGraphThenVector:
-		items, modesUsed, totalCandidates, err = r.retrieveGraphThenVector(ctx, q)
+		pr, err = r.retrieveGraphThenVector(ctx, q)
+	case PolicyCascade:
+		pr, err = r.retrieveCascade(ctx, q)
 	default:
-		items, modesUsed, totalCandidates, err = r.retrieveParallel(ctx, q)
+		pr, err = r.retrieveParallel(ctx, q)
 	}
 
 	if err != nil {
 		return nil, err
 	}
+	if err := branches(pr); err != nil {
+		return nil, err
+	}
+	if err := r.checkQuorum(pr); err != nil {
+		return nil, err
+	}
+
+	mergeStart := time.Now()
+	items := r.mergeResults(pr, weights)
+	mergedCount := len(items)
 
 	// Deduplicate if configured
 	if r.config.DedupByID {
 		items = deduplicate(items)
 	}
+	dedupedCount := len(items)
 
-	// Sort by score
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Score > items[j].Score
-	})
+	// Order by the merge strategy
+	if items, err = r.order(ctx, q, pr, items); err != nil {
+		return nil, err
+	}
 
 	// Apply top-k limit
 	if q.TopK > 0 && len(items) > q.TopK {
 		items = items[:q.TopK]
 	}
 
-	// Apply reranker if configured
-	if r.config.Reranker != nil {
+	// Truncate the irrelevant tail if autocut is enabled
+	if r.config.Merge == MergeScore {
+		items = r.config.Autocut.Apply(items)
+	}
+
+	if obs, ok := r.config.Observer.(retrieve.MergeObserver); ok {
+		counts := pr.branchCounts(r.branches)
+		var hits int
+		for _, n := range counts {
+			hits += n
+		}
+		obs.OnHybridMerge(ctx, string(r.config.Fusion), counts, hits-dedupedCount, len(items), time.Since(mergeStart).Milliseconds())
+	}
+
+	var debug *retrieve.Debug
+	if q.Explain {
+		debug = pr.debug(q)
+		debug.Stages = append(debug.Stages, retrieve.StageDebug{
+			Name:       "hybrid.merge",
+			Candidates: mergedCount,
+			Returned:   len(items),
+		})
+	}
+
+	// Apply reranker if configured, unless the best-effort budget is spent
+	if r.config.Reranker != nil && r.expired(ctx, ctx.Err()) {
+		pr.partial = true
+	} else if r.config.Reranker != nil {
 		rerankStart := time.Now()
-		items, err = r.config.Reranker.Rerank(ctx, q, items)
-		if err != nil {
+		inputCount := len(items)
+		var before map[string]float64
+		if debug != nil {
+			before = make(map[string]float64, len(items))
+			for _, item := range items {
+				before[item.ID] = item.Score
+			}
+		}
+		reranked, err := r.config.Reranker.Rerank(ctx, q, items)
+		if err != nil && !r.expired(ctx, err) {
 			return nil, err
 		}
+		if err != nil {
+			// Keep the merged order when reranking misses the deadline
+			pr.partial = true
+			reranked = items
+		}
+		items = reranked
+		rerankLatency := time.Since(rerankStart).Milliseconds()
 		if r.config.Observer != nil {
-			r.config.Observer.OnRerank(ctx, "hybrid", len(items), len(items), time.Since(rerankStart).Milliseconds())
+			r.config.Observer.OnRerank(ctx, "hybrid", inputCount, len(items), rerankLatency)
+		}
+		if debug != nil {
+			debug.RerankDeltas = make(map[string]float64, len(items))
+			for _, item := range items {
+				debug.RerankDeltas[item.ID] = item.Score - before[item.ID]
+			}
+			debug.Stages = append(debug.Stages, retrieve.StageDebug{
+				Name:       "hybrid.rerank",
+				Candidates: inputCount,
+				Returned:   len(items),
+				LatencyMS:  rerankLatency,
+			})
 		}
 	}
 
+	// Fit the results into the context budget
+	budgeted := r.config.Budget.Apply(items)
+	if debug != nil && len(budgeted) < len(items) {
+		debug.Stages = append(debug.Stages, retrieve.StageDebug{
+			Name:       "hybrid.budget",
+			Candidates: len(items),
+			Returned:   len(budgeted),
+		})
+	}
+	items = budgeted
+
 	return &retrieve.Result{
 		Items: items,
 		Query: q,
 		Metadata: retrieve.ResultMetadata{
-			TotalCandidates: totalCandidates,
+			TotalCandidates: pr.totalCandidates,
 			LatencyMS:       time.Since(start).Milliseconds(),
-			ModesUsed:       modesUsed,
+			ModesUsed:       pr.modesUsed,
+			PartialResult:   pr.partial,
+			BranchErrors:    pr.errors,
+			AnsweredBy:      pr.answeredBy,
 		},
+		Debug: debug,
 	}, nil
 }
 
-// retrieveParallel runs vector and graph retrieval concurrently.
-func (r *Retriever) retrieveParallel(ctx context.Context, q retrieve.Query) ([]retrieve.ContextItem, []retrieve.Mode, int, error) {
+// route returns the policy and weights for q, as chosen by the router if
+// configured. Routes without a keyword weight keep the configured one.
+func (r *Retriever) route(ctx context.Context, q retrieve.Query) (Policy, Weights, error) {
+	policy, weights := r.config.Policy, r.config.Weights
+	if r.config.Router == nil {
+		return policy, weights, nil
+	}
+	route, err := r.config.Router.Route(ctx, q)
+	if err != nil {
+		return "", Weights{}, fmt.Errorf("failed to route query: %w", err)
+	}
+	if route.Policy != "" {
+		policy = route.Policy
+	}
+	if route.Weights != nil {
+		weights = *route.Weights
+		if weights.Keyword == 0 {
+			weights.Keyword = r.config.Weights.Keyword
+		}
+	}
+	return policy, weights, nil
+}
+
+// budget returns a context that expires DeadlineMargin before the parent
+// deadline in best-effort mode, leaving time to merge and return results.
+func (r *Retriever) budget(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !r.config.BestEffort || !ok {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline.Add(-r.config.DeadlineMargin))
+}
+
+// expired reports whether err should be tolerated because the best-effort
+// budget ran out. Cancellation of the caller's context is never tolerated.
+func (r *Retriever) expired(ctx context.Context, err error) bool {
+	return r.config.BestEffort && err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// runBranch runs a branch request, bounded by BranchTimeout and hedged with
+// fallback (or primary again, if nil) after HedgeAfter.
+func (r *Retriever) runBranch(ctx context.Context, primary, fallback retrieve.Retriever, q retrieve.Query) (*retrieve.Result, error) {
+	parent := ctx
+	var cancel context.CancelFunc
+	if r.config.BranchTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.config.BranchTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
 	type result struct {
-		items []retrieve.ContextItem
-		count int
-		err   error
+		res *retrieve.Result
+		err error
+	}
+	ch := make(chan result, 2)
+	run := func(retriever retrieve.Retriever) {
+		go func() {
+			res, err := retriever.Retrieve(ctx, q)
+			ch <- result{res: res, err: err}
+		}()
+	}
+	run(primary)
+	pending := 1
+
+	var hedge <-chan time.Time
+	if r.config.HedgeAfter > 0 {
+		timer := time.NewTimer(r.config.HedgeAfter)
+		defer timer.Stop()
+		hedge = timer.C
 	}
 
-	vectorCh := make(chan result, 1)
-	graphCh := make(chan result, 1)
+	var firstErr error
+	for {
+		select {
+		case res := <-ch:
+			pending--
+			if res.err == nil {
+				return res.res, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if pending == 0 {
+				return nil, firstErr
+			}
+		case <-hedge:
+			hedge = nil
+			if fallback == nil {
+				fallback = primary
+			}
+			run(fallback)
+			pending++
+		case <-ctx.Done():
+			// Don't wait for a branch that ignores cancellation
+			if parent.Err() == nil {
+				return nil, fmt.Errorf("hybrid branch timed out after %s: %w", r.config.BranchTimeout, ctx.Err())
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
 
-	// Run vector retrieval
-	go func() {
-		if r.config.Vector == nil {
-			vectorCh <- result{}
-			return
+// tolerate reports whether a branch error is skipped under the error policy,
+// recording it in pr if so.
+func (r *Retriever) tolerate(ctx context.Context, pr *policyResult, branch string, err error) bool {
+	if !r.tolerable(ctx) {
+		return false
+	}
+	pr.partial = true
+	pr.errors = append(pr.errors, retrieve.BranchError{Branch: branch, Err: err})
+	return true
+}
+
+// tolerable reports whether branch errors are skipped under the error policy.
+func (r *Retriever) tolerable(ctx context.Context) bool {
+	return r.config.ErrorPolicy != ErrorPolicyFailFast && ctx.Err() == nil
+}
+
+// checkQuorum returns an error if too few branches succeeded under the error
+// policy.
+func (r *Retriever) checkQuorum(pr *policyResult) error {
+	if len(pr.errors) == 0 {
+		return nil
+	}
+	errs := make([]error, len(pr.errors))
+	for i, e := range pr.errors {
+		errs[i] = e
+	}
+	switch r.config.ErrorPolicy {
+	case ErrorPolicyBestEffort:
+		if pr.succeeded == 0 {
+			return fmt.Errorf("hybrid retriever: all branches failed: %w", errors.Join(errs...))
 		}
-		res, err := r.config.Vector.Retrieve(ctx, q)
-		if err != nil {
-			vectorCh <- result{err: err}
-			return
+	case ErrorPolicyQuorum:
+		quorum := r.config.Quorum
+		if quorum <= 0 {
+			quorum = (pr.succeeded+len(pr.errors))/2 + 1
 		}
-		vectorCh <- result{items: res.Items, count: res.Metadata.TotalCandidates}
-	}()
-
-	// Run graph retrieval
-	go func() {
-		if r.config.Graph == nil {
-			graphCh <- result{}
-			return
+		if pr.succeeded < quorum {
+			return fmt.Errorf("hybrid retriever: %d branches succeeded, quorum is %d: %w",
+				pr.succeeded, quorum, errors.Join(errs...))
 		}
-		res, err := r.config.Graph.Retrieve(ctx, q)
-		if err != nil {
-			graphCh <- result{err: err}
-			return
+	}
+	return nil
+}
+
+// policyResult holds the branch outcomes of a retrieval policy.
+type policyResult struct {
+	vectorItems     []retrieve.ContextItem
+	graphItems      []retrieve.ContextItem
+	branchItems     [][]retrieve.ContextItem // per Retriever.branches entry
+	modesUsed       []retrieve.Mode
+	totalCandidates int
+	// partial is set when a branch was skipped or returned partial results.
+	partial bool
+	// succeeded counts the branches that returned results.
+	succeeded int
+	// errors holds the branch errors skipped under the error policy.
+	errors []retrieve.BranchError
+	// branchDebug holds the debug sections reported by each branch.
+	branchDebug []*retrieve.Debug
+	// answeredBy is the last cascade stage that ran.
+	answeredBy retrieve.Mode
+}
+
+// addBranch records a branch result's candidates and debug section.
+func (pr *policyResult) addBranch(res *retrieve.Result) {
+	pr.succeeded++
+	pr.totalCandidates += res.Metadata.TotalCandidates
+	pr.partial = pr.partial || res.Metadata.PartialResult
+	if res.Debug != nil {
+		pr.branchDebug = append(pr.branchDebug, res.Debug)
+	}
+}
+
+// branchCounts returns the number of items each branch returned.
+func (pr *policyResult) branchCounts(branches []Branch) map[string]int {
+	counts := map[string]int{
+		string(retrieve.ModeVector): len(pr.vectorItems),
+		string(retrieve.ModeGraph):  len(pr.graphItems),
+	}
+	for i, b := range branches {
+		counts[b.Name] += len(pr.branchItems[i])
+	}
+	return counts
+}
+
+// debug combines the branch debug sections into a single section.
+func (pr *policyResult) debug(q retrieve.Query) *retrieve.Debug {
+	debug := &retrieve.Debug{
+		Filters:  q.Filters,
+		MinScore: q.MinScore,
+	}
+	for _, d := range pr.branchDebug {
+		debug.Stages = append(debug.Stages, d.Stages...)
+		debug.DroppedByMinScore += d.DroppedByMinScore
+	}
+	return debug
+}
+
+// retrieveParallel runs vector and graph retrieval concurrently. A branch
+// error that fails the retrieval cancels the other branch.
+func (r *Retriever) retrieveParallel(ctx context.Context, q retrieve.Query) (*policyResult, error) {
+	type branch struct {
+		name     string
+		primary  retrieve.Retriever
+		fallback retrieve.Retriever
+		res      *retrieve.Result
+		err      error
+	}
+	branches := []*branch{
+		{name: "vector", primary: r.config.Vector, fallback: r.config.VectorFallback},
+		{name: "graph", primary: r.config.Graph, fallback: r.config.GraphFallback},
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, b := range branches {
+		if b.primary == nil {
+			continue
 		}
-		graphCh <- result{items: res.Items, count: res.Metadata.TotalCandidates}
-	}()
+		g.Go(func() error {
+			b.res, b.err = r.runBranch(gctx, b.primary, b.fallback, q)
+			if b.err != nil && !r.expired(ctx, b.err) && !r.tolerable(ctx) {
+				return b.err
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-	// Collect results
-	vectorRes := <-vectorCh
-	graphRes := <-graphCh
+	pr := &policyResult{modesUsed: []retrieve.Mode{retrieve.ModeHybrid}}
+	for _, b := range branches {
+		switch {
+		case b.err == nil:
+		case r.expired(ctx, b.err):
+			pr.partial = true
+		case !r.tolerate(ctx, pr, b.name, b.err):
+			return nil, b.err
+		}
+	}
 
-	if vectorRes.err != nil {
-		return nil, nil, 0, vectorRes.err
+	if vector := branches[0]; vector.res != nil && vector.err == nil {
+		pr.vectorItems = vector.res.Items
+		pr.addBranch(vector.res)
 	}
-	if graphRes.err != nil {
-		return nil, nil, 0, graphRes.err
+	if graph := branches[1]; graph.res != nil && graph.err == nil {
+		pr.graphItems = graph.res.Items
+		pr.addBranch(graph.res)
 	}
 
-	// Merge and weight results
-	items := r.mergeResults(vectorRes.items, graphRes.items)
-	modesUsed := []retrieve.Mode{retrieve.ModeHybrid}
-	if len(vectorRes.items) > 0 {
-		modesUsed = append(modesUsed, retrieve.ModeVector)
+	if len(pr.vectorItems) > 0 {
+		pr.modesUsed = append(pr.modesUsed, retrieve.ModeVector)
 	}
-	if len(graphRes.items) > 0 {
-		modesUsed = append(modesUsed, retrieve.ModeGraph)
+	if len(pr.graphItems) > 0 {
+		pr.modesUsed = append(pr.modesUsed, retrieve.ModeGraph)
 	}
 
-	return items, modesUsed, vectorRes.count + graphRes.count, nil
+	return pr, nil
 }
 
 // retrieveVectorThenGraph runs vector search, then expands results via graph.
-func (r *Retriever) retrieveVectorThenGraph(ctx context.Context, q retrieve.Query) ([]retrieve.ContextItem, []retrieve.Mode, int, error) {
-	modesUsed := []retrieve.Mode{retrieve.ModeHybrid}
-	var totalCandidates int
+func (r *Retriever) retrieveVectorThenGraph(ctx context.Context, q retrieve.Query) (*policyResult, error) {
+	pr := &policyResult{modesUsed: []retrieve.Mode{retrieve.ModeHybrid}}
 
 	// First: vector search
-	var vectorItems []retrieve.ContextItem
 	if r.config.Vector != nil {
-		res, err := r.config.Vector.Retrieve(ctx, q)
-		if err != nil {
-			return nil, nil, 0, err
+		res, err := r.runBranch(ctx, r.config.Vector, r.config.VectorFallback, q)
+		switch {
+		case r.expired(ctx, err):
+			pr.partial = true
+			return pr, nil
+		case err != nil:
+			if !r.tolerate(ctx, pr, "vector", err) {
+				return nil, err
+			}
+		default:
+			pr.vectorItems = res.Items
+			pr.addBranch(res)
+			pr.modesUsed = append(pr.modesUsed, retrieve.ModeVector)
 		}
-		vectorItems = res.Items
-		totalCandidates += res.Metadata.TotalCandidates
-		modesUsed = append(modesUsed, retrieve.ModeVector)
-	}
-
-	// Extract entity hints from vector results for graph expansion
-	var graphItems []retrieve.ContextItem
-	if r.config.Graph != nil && len(vectorItems) > 0 {
-		// Use vector results as starting points for graph expansion
-		entities := make([]retrieve.EntityHint, 0, len(vectorItems))
-		for _, item := range vectorItems {
-			entities = append(entities, retrieve.EntityHint{
-				ID:   item.ID,
-				Name: item.ID,
-			})
+	}
+
+	// Link vector results to the entities that seed graph expansion
+	var entities []retrieve.EntityHint
+	if r.config.Graph != nil && len(pr.vectorItems) > 0 {
+		var err error
+		entities, err = r.config.Linker.Link(ctx, pr.vectorItems)
+		if err != nil {
+			err = fmt.Errorf("failed to link entities: %w", err)
+			if !r.tolerate(ctx, pr, "graph", err) {
+				return nil, err
+			}
 		}
+	}
 
+	if len(entities) > 0 {
 		graphQuery := q
 		graphQuery.Entities = entities
 
-		res, err := r.config.Graph.Retrieve(ctx, graphQuery)
-		if err != nil {
-			return nil, nil, 0, err
+		res, err := r.runBranch(ctx, r.config.Graph, r.config.GraphFallback, graphQuery)
+		switch {
+		case r.expired(ctx, err):
+			pr.partial = true
+		case err != nil:
+			if !r.tolerate(ctx, pr, "graph", err) {
+				return nil, err
+			}
+		default:
+			pr.graphItems = res.Items
+			pr.addBranch(res)
+			pr.modesUsed = append(pr.modesUsed, retrieve.ModeGraph)
 		}
-		graphItems = res.Items
-		totalCandidates += res.Metadata.TotalCandidates
-		modesUsed = append(modesUsed, retrieve.ModeGraph)
 	}
 
-	items := r.mergeResults(vectorItems, graphItems)
-	return items, modesUsed, totalCandidates, nil
+	return pr, nil
 }
 
 // retrieveGraphThenVector runs graph traversal, then grounds via vector search.
-func (r *Retriever) retrieveGraphThenVector(ctx context.Context, q retrieve.Query) ([]retrieve.ContextItem, []retrieve.Mode, int, error) {
-	modesUsed := []retrieve.Mode{retrieve.ModeHybrid}
-	var totalCandidates int
+func (r *Retriever) retrieveGraphThenVector(ctx context.Context, q retrieve.Query) (*policyResult, error) {
+	pr := &policyResult{modesUsed: []retrieve.Mode{retrieve.ModeHybrid}}
 
 	// First: graph traversal
-	var graphItems []retrieve.ContextItem
 	if r.config.Graph != nil {
-		res, err := r.config.Graph.Retrieve(ctx, q)
-		if err != nil {
-			return nil, nil, 0, err
+		res, err := r.runBranch(ctx, r.config.Graph, r.config.GraphFallback, q)
+		switch {
+		case r.expired(ctx, err):
+			pr.partial = true
+			return pr, nil
+		case err != nil:
+			if !r.tolerate(ctx, pr, "graph", err) {
+				return nil, err
+			}
+		default:
+			pr.graphItems = res.Items
+			pr.addBranch(res)
+			pr.modesUsed = append(pr.modesUsed, retrieve.ModeGraph)
 		}
-		graphItems = res.Items
-		totalCandidates += res.Metadata.TotalCandidates
-		modesUsed = append(modesUsed, retrieve.ModeGraph)
 	}
 
 	// Use graph results to inform vector search
-	var vectorItems []retrieve.ContextItem
 	if r.config.Vector != nil {
-		res, err := r.config.Vector.Retrieve(ctx, q)
-		if err != nil {
-			return nil, nil, 0, err
+		res, err := r.runBranch(ctx, r.config.Vector, r.config.VectorFallback, q)
+		switch {
+		case r.expired(ctx, err):
+			pr.partial = true
+		case err != nil:
+			if !r.tolerate(ctx, pr, "vector", err) {
+				return nil, err
+			}
+		default:
+			pr.vectorItems = res.Items
+			pr.addBranch(res)
+			pr.modesUsed = append(pr.modesUsed, retrieve.ModeVector)
 		}
-		vectorItems = res.Items
-		totalCandidates += res.Metadata.TotalCandidates
-		modesUsed = append(modesUsed, retrieve.ModeVector)
 	}
 
-	items := r.mergeResults(vectorItems, graphItems)
-	return items, modesUsed, totalCandidates, nil
+	return pr, nil
 }
 
-// mergeResults combines vector and graph results with weighted scoring.
-func (r *Retriever) mergeResults(vectorItems, graphItems []retrieve.ContextItem) []retrieve.ContextItem {
-	// Create a map for merging by ID
-	merged := make(map[string]*retrieve.ContextItem)
+// startBranches starts the keyword retriever and the configured branches
+// concurrently with the policy. The returned function waits for them and
+// adds their results to pr.
+func (r *Retriever) startBranches(ctx context.Context, q retrieve.Query) func(pr *policyResult) error {
+	type result struct {
+		res *retrieve.Result
+		err error
+	}
+	chs := make([]chan result, len(r.branches))
+	for i, b := range r.branches {
+		chs[i] = make(chan result, 1)
+		go func() {
+			res, err := r.runBranch(ctx, b.Retriever, b.Fallback, q)
+			chs[i] <- result{res: res, err: err}
+		}()
+	}
 
-	// Add vector items with weighted score
-	for _, item := range vectorItems {
-		weightedScore := item.Score * r.config.Weights.Vector
-		if existing, ok := merged[item.ID]; ok {
-			existing.Score += weightedScore
-		} else {
-			itemCopy := item
-			itemCopy.Score = weightedScore
-			merged[item.ID] = &itemCopy
+	return func(pr *policyResult) error {
+		pr.branchItems = make([][]retrieve.ContextItem, len(r.branches))
+		for i, b := range r.branches {
+			var res result
+			select {
+			case res = <-chs[i]:
+			case <-ctx.Done():
+				res.err = ctx.Err()
+			}
+			switch {
+			case r.expired(ctx, res.err):
+				pr.partial = true
+			case res.err != nil:
+				if !r.tolerate(ctx, pr, b.Name, res.err) {
+					return res.err
+				}
+			default:
+				pr.branchItems[i] = res.res.Items
+				pr.addBranch(res.res)
+				if len(res.res.Items) > 0 && !slices.Contains(pr.modesUsed, b.Mode) {
+					pr.modesUsed = append(pr.modesUsed, b.Mode)
+				}
+			}
 		}
+		return nil
 	}
+}
 
-	// Add graph items with weighted score
-	for _, item := range graphItems {
-		weightedScore := item.Score * r.config.Weights.Graph
+// mergeResults combines the policy and branch results with weighted scoring
+// on the configured fusion scale.
+func (r *Retriever) mergeResults(pr *policyResult, weights Weights) []retrieve.ContextItem {
+	// Create a map for merging by ID
+	merged := make(map[string]*retrieve.ContextItem)
+	var order []string
+
+	add := func(item retrieve.ContextItem, fused float64, name string, mode retrieve.Mode, weight float64) {
+		weightedScore := fused * weight
+		contribution := retrieve.Contribution{
+			Mode:          mode,
+			Branch:        name,
+			RawScore:      item.Score,
+			FusedScore:    fused,
+			Weight:        weight,
+			WeightedScore: weightedScore,
+		}
 		if existing, ok := merged[item.ID]; ok {
 			existing.Score += weightedScore
+			existing.Provenance.SubScores[name] = item.Score
+			if existing.Provenance.SimilarityScore == 0 {
+				existing.Provenance.SimilarityScore = item.Provenance.SimilarityScore
+			}
+			existing.Provenance.Explanation.Contributions = append(existing.Provenance.Explanation.Contributions, contribution)
+			existing.Provenance.Explanation.Dedup = retrieve.DedupMerged
 			// Preserve graph path if this item came from graph
 			if len(item.Provenance.GraphPath) > 0 {
 				existing.Provenance.GraphPath = item.Provenance.GraphPath
 			}
-		} else {
-			itemCopy := item
-			itemCopy.Score = weightedScore
-			merged[item.ID] = &itemCopy
+			return
+		}
+		itemCopy := item
+		itemCopy.Score = weightedScore
+		itemCopy.Provenance.SubScores = map[string]float64{name: item.Score}
+		itemCopy.Provenance.Explanation = &retrieve.Explanation{
+			Contributions: []retrieve.Contribution{contribution},
+			Dedup:         retrieve.DedupUnique,
+		}
+		merged[item.ID] = &itemCopy
+		order = append(order, item.ID)
+	}
+
+	// Add each branch's items with weighted score
+	branches := []Branch{
+		{Name: string(retrieve.ModeVector), Mode: retrieve.ModeVector, Weight: weights.Vector},
+		{Name: string(retrieve.ModeGraph), Mode: retrieve.ModeGraph, Weight: weights.Graph},
+	}
+	branches = append(branches, r.branches...)
+	if r.config.Keyword != nil {
+		// The keyword retriever is the first branch
+		branches[2].Weight = weights.Keyword
+	}
+	items := append([][]retrieve.ContextItem{pr.vectorItems, pr.graphItems}, pr.branchItems...)
+	for i, b := range branches {
+		fused := fuse(r.config.Fusion, r.config.RRFK, items[i])
+		for j, item := range items[i] {
+			add(item, fused[j], b.Name, b.Mode, b.Weight)
 		}
 	}
 
 	// Convert to slice
 	result := make([]retrieve.ContextItem, 0, len(merged))
-	for _, item := range merged {
+	for _, id := range order {
+		item := merged[id]
 		item.Provenance.Mode = retrieve.ModeHybrid
+		item.Provenance.Explanation.MergedScore = item.Score
 		result = append(result, *item)
 	}
 
@@ -325,6 +943,11 @@ func deduplicate(items []retrieve.ContextItem) []retrieve.ContextItem {
 			if item.Score > result[idx].Score {
 				result[idx] = item
 			}
+			if result[idx].Provenance.Explanation != nil {
+				explanation := *result[idx].Provenance.Explanation
+				explanation.Dedup = retrieve.DedupKeptHighest
+				result[idx].Provenance.Explanation = &explanation
+			}
 		} else {
 			seen[item.ID] = len(result)
 			result = append(result, item)
@@ -2,12 +2,20 @@ package hybrid_test
 
 import (
 	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/agentplexus/omniretrieve/graph"
 	"github.com/agentplexus/omniretrieve/hybrid"
+	"github.com/agentplexus/omniretrieve/lexical"
 	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/observe"
 	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
 	"github.com/agentplexus/omniretrieve/vector"
 )
 
@@ -266,3 +274,951 @@ func TestHybridRetrieverGraphOnly(t *testing.T) {
 		t.Fatal("expected results with graph-only hybrid")
 	}
 }
+
+func TestHybridRetrieverExplanation(t *testing.T) {
+	ctx := context.Background()
+	vectorRetriever, graphRetriever := setupTestRetrievers(t)
+
+	weights := hybrid.Weights{Vector: 0.7, Graph: 0.3}
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:    vectorRetriever,
+		Graph:     graphRetriever,
+		Policy:    hybrid.PolicyParallel,
+		DedupByID: true,
+		Weights:   weights,
+	})
+
+	result, err := hybridRetriever.Retrieve(ctx, retrieve.Query{
+		Text:     "Machine learning algorithms",
+		Entities: []retrieve.EntityHint{{ID: "g1"}},
+		TopK:     10,
+	})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	for _, item := range result.Items {
+		explanation := item.Provenance.Explanation
+		if explanation == nil {
+			t.Fatalf("expected explanation for item %s", item.ID)
+		}
+
+		var total float64
+		for _, c := range explanation.Contributions {
+			total += c.WeightedScore
+			if raw, ok := item.Provenance.SubScores[c.Branch]; !ok || raw != c.RawScore {
+				t.Errorf("item %s: %s sub-score = %f, want %f", item.ID, c.Branch, raw, c.RawScore)
+			}
+			switch c.Mode {
+			case retrieve.ModeVector:
+				if c.Weight != weights.Vector {
+					t.Errorf("item %s: vector weight = %f, want %f", item.ID, c.Weight, weights.Vector)
+				}
+			case retrieve.ModeGraph:
+				if c.Weight != weights.Graph {
+					t.Errorf("item %s: graph weight = %f, want %f", item.ID, c.Weight, weights.Graph)
+				}
+			}
+		}
+		if total != item.Score {
+			t.Errorf("item %s: contributions sum to %f, score is %f", item.ID, total, item.Score)
+		}
+		if explanation.MergedScore != item.Score {
+			t.Errorf("item %s: merged score = %f, score is %f", item.ID, explanation.MergedScore, item.Score)
+		}
+		if len(item.Provenance.SubScores) != len(explanation.Contributions) {
+			t.Errorf("item %s: expected %d sub-scores, got %d", item.ID, len(explanation.Contributions), len(item.Provenance.SubScores))
+		}
+
+		// v1 is present in both the vector index and the graph
+		if item.ID == "v1" {
+			if len(explanation.Contributions) != 2 {
+				t.Errorf("expected 2 contributions for v1, got %d", len(explanation.Contributions))
+			}
+			if explanation.Dedup != retrieve.DedupMerged {
+				t.Errorf("expected v1 dedup decision merged, got %s", explanation.Dedup)
+			}
+		}
+	}
+}
+
+// boostReranker adds a fixed boost to every item score.
+type boostReranker struct {
+	boost float64
+}
+
+func (b boostReranker) Rerank(_ context.Context, _ retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	for i := range items {
+		items[i].Score += b.boost
+	}
+	return items, nil
+}
+
+func TestHybridRetrieverExplain(t *testing.T) {
+	ctx := context.Background()
+	vectorRetriever, graphRetriever := setupTestRetrievers(t)
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:    vectorRetriever,
+		Graph:     graphRetriever,
+		Policy:    hybrid.PolicyParallel,
+		DedupByID: true,
+		Reranker:  boostReranker{boost: 0.5},
+	})
+
+	result, err := hybridRetriever.Retrieve(ctx, retrieve.Query{
+		Text:     "machine learning",
+		Entities: []retrieve.EntityHint{{ID: "g1"}},
+		TopK:     10,
+		Explain:  true,
+	})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	if result.Debug == nil {
+		t.Fatal("expected debug section when Explain is set")
+	}
+
+	stages := make(map[string]retrieve.StageDebug)
+	for _, stage := range result.Debug.Stages {
+		stages[stage.Name] = stage
+	}
+	for _, name := range []string{"vector.search", "graph.traverse", "hybrid.merge", "hybrid.rerank"} {
+		if _, ok := stages[name]; !ok {
+			t.Errorf("expected stage %s, got %+v", name, result.Debug.Stages)
+		}
+	}
+
+	if len(result.Debug.RerankDeltas) != len(result.Items) {
+		t.Fatalf("expected %d rerank deltas, got %d", len(result.Items), len(result.Debug.RerankDeltas))
+	}
+	for id, delta := range result.Debug.RerankDeltas {
+		if delta < 0.49 || delta > 0.51 {
+			t.Errorf("item %s: expected rerank delta 0.5, got %f", id, delta)
+		}
+	}
+}
+
+func TestHybridRetrieverAutocut(t *testing.T) {
+	ctx := context.Background()
+
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "a", Score: 1.0},
+			{ID: "b", Score: 0.95},
+			{ID: "c", Score: 0.2},
+		},
+	}}}
+	graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "d", Score: 0.3},
+		},
+	}}}
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:  vectorRetriever,
+		Graph:   graphRetriever,
+		Weights: hybrid.Weights{Vector: 1, Graph: 1},
+		Autocut: retrieve.Autocut{Mode: retrieve.AutocutLargestGap},
+	})
+
+	result, err := hybridRetriever.Retrieve(ctx, retrieve.Query{Text: "q", TopK: 10})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	if len(result.Items) != 2 || result.Items[0].ID != "a" || result.Items[1].ID != "b" {
+		t.Errorf("expected autocut to keep [a b], got %+v", result.Items)
+	}
+}
+
+func TestHybridRetrieverAlpha(t *testing.T) {
+	ctx := context.Background()
+
+	newRetriever := func(alpha float64) *hybrid.Retriever {
+		return hybrid.NewRetriever(hybrid.RetrieverConfig{
+			Vector: &retrievetest.Retriever{Results: []*retrieve.Result{{
+				Items: []retrieve.ContextItem{{ID: "v", Score: 1}},
+			}}},
+			Graph: &retrievetest.Retriever{Results: []*retrieve.Result{{
+				Items: []retrieve.ContextItem{{ID: "g", Score: 1}},
+			}}},
+			Alpha: hybrid.Alpha(alpha),
+		})
+	}
+
+	scores := func(r *hybrid.Retriever) map[string]float64 {
+		result, err := r.Retrieve(ctx, retrieve.Query{Text: "q"})
+		if err != nil {
+			t.Fatalf("failed to retrieve: %v", err)
+		}
+		out := make(map[string]float64)
+		for _, item := range result.Items {
+			out[item.ID] = item.Score
+		}
+		return out
+	}
+
+	if s := scores(newRetriever(1)); s["v"] != 1 || s["g"] != 0 {
+		t.Errorf("alpha=1: expected pure vector scores, got %v", s)
+	}
+	if s := scores(newRetriever(0)); s["v"] != 0 || s["g"] != 1 {
+		t.Errorf("alpha=0: expected pure graph scores, got %v", s)
+	}
+	if s := scores(newRetriever(0.25)); s["v"] != 0.25 || s["g"] != 0.75 {
+		t.Errorf("alpha=0.25: unexpected scores %v", s)
+	}
+}
+
+func TestHybridPresets(t *testing.T) {
+	vectorRetriever, graphRetriever := setupTestRetrievers(t)
+
+	precision := hybrid.PresetPrecision.Config(vectorRetriever, graphRetriever)
+	if precision.Autocut.Mode == retrieve.AutocutOff {
+		t.Error("expected precision preset to enable autocut")
+	}
+	if w := hybrid.WeightsFromAlpha(*precision.Alpha); w.Vector <= w.Graph {
+		t.Errorf("expected precision preset to favor vector, got %+v", w)
+	}
+
+	recall := hybrid.PresetRecall.Config(vectorRetriever, graphRetriever)
+	if recall.Policy != hybrid.PolicyVectorThenGraph {
+		t.Errorf("expected recall preset to expand via graph, got %s", recall.Policy)
+	}
+
+	result, err := hybrid.NewRetriever(hybrid.PresetBalanced.Config(vectorRetriever, graphRetriever)).
+		Retrieve(context.Background(), retrieve.Query{
+			Text:     "machine learning",
+			Entities: []retrieve.EntityHint{{ID: "g1"}},
+		})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) == 0 {
+		t.Error("expected results from balanced preset")
+	}
+}
+
+// blockingRetriever blocks until the context is done.
+var blockingRetriever = retrieve.RetrieverFunc(func(ctx context.Context, _ retrieve.Query) (*retrieve.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+})
+
+// blockingReranker blocks until the context is done.
+type blockingReranker struct{}
+
+func (blockingReranker) Rerank(ctx context.Context, _ retrieve.Query, _ []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHybridRetrieverBestEffort(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}},
+	}}}
+
+	tests := []struct {
+		name     string
+		policy   hybrid.Policy
+		graph    retrieve.Retriever
+		reranker retrieve.Reranker
+	}{
+		{name: "parallel slow graph", policy: hybrid.PolicyParallel, graph: blockingRetriever},
+		{name: "vector then graph slow graph", policy: hybrid.PolicyVectorThenGraph, graph: blockingRetriever},
+		{name: "slow reranker", policy: hybrid.PolicyParallel, reranker: blockingReranker{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector:         vectorRetriever,
+				Graph:          tt.graph,
+				Policy:         tt.policy,
+				Reranker:       tt.reranker,
+				BestEffort:     true,
+				DeadlineMargin: 20 * time.Millisecond,
+			})
+
+			result, err := hybridRetriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+			if err != nil {
+				t.Fatalf("expected best-effort result, got error: %v", err)
+			}
+			if !result.Metadata.PartialResult {
+				t.Error("expected PartialResult to be set")
+			}
+			if len(result.Items) != 1 || result.Items[0].ID != "a" {
+				t.Errorf("expected gathered vector results, got %+v", result.Items)
+			}
+			if ctx.Err() != nil {
+				t.Error("expected result before the caller's deadline")
+			}
+		})
+	}
+
+	// Without best-effort mode the deadline is an error
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: vectorRetriever,
+		Graph:  blockingRetriever,
+	})
+	if _, err := hybridRetriever.Retrieve(ctx, retrieve.Query{Text: "q"}); err == nil {
+		t.Error("expected deadline error without best-effort mode")
+	}
+}
+
+func TestHybridRetrieverKeyword(t *testing.T) {
+	ctx := context.Background()
+	vectorRetriever, _ := setupTestRetrievers(t)
+
+	idx := memory.NewKeywordIndex("test-keywords")
+	for _, doc := range []lexical.Document{
+		{ID: "v2", Content: "Neural network architectures"},
+		{ID: "k1", Content: "Error code NN_4711 in network training"},
+	} {
+		if err := idx.Insert(ctx, doc); err != nil {
+			t.Fatalf("failed to insert document: %v", err)
+		}
+	}
+
+	for _, policy := range []hybrid.Policy{hybrid.PolicyParallel, hybrid.PolicyVectorThenGraph, hybrid.PolicyGraphThenVector} {
+		t.Run(string(policy), func(t *testing.T) {
+			r := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector:    vectorRetriever,
+				Keyword:   lexical.NewRetriever(lexical.RetrieverConfig{Index: idx}),
+				Policy:    policy,
+				DedupByID: true,
+			})
+
+			result, err := r.Retrieve(ctx, retrieve.Query{Text: "nn_4711 network", TopK: 10})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			if !slices.Contains(result.Metadata.ModesUsed, retrieve.ModeKeyword) {
+				t.Errorf("expected keyword mode, got %v", result.Metadata.ModesUsed)
+			}
+
+			modes := make(map[string][]retrieve.Mode)
+			for _, item := range result.Items {
+				for _, c := range item.Provenance.Explanation.Contributions {
+					modes[item.ID] = append(modes[item.ID], c.Mode)
+				}
+			}
+			// The exact identifier is only found by keyword search
+			if !slices.Equal(modes["k1"], []retrieve.Mode{retrieve.ModeKeyword}) {
+				t.Errorf("expected k1 from keyword search, got %v", modes["k1"])
+			}
+			// Items found by both branches are fused
+			if !slices.Equal(modes["v2"], []retrieve.Mode{retrieve.ModeVector, retrieve.ModeKeyword}) {
+				t.Errorf("expected v2 fused from vector and keyword, got %v", modes["v2"])
+			}
+		})
+	}
+}
+
+func TestHybridRetrieverFusion(t *testing.T) {
+	// Graph scores live on a much larger scale than vector scores
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.8}},
+	}}}
+	graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "c", Score: 100}, {ID: "a", Score: 50}},
+	}}}
+
+	tests := []struct {
+		fusion hybrid.FusionStrategy
+		order  []string
+		scores []float64
+	}{
+		{hybrid.FusionWeighted, []string{"c", "a", "b"}, []float64{40, 20.54, 0.48}},
+		{hybrid.FusionMinMax, []string{"a", "c", "b"}, []float64{0.6, 0.4, 0}},
+		{hybrid.FusionZScore, []string{"c", "a", "b"}, []float64{0.4, 0.2, -0.6}},
+		{hybrid.FusionRRF, []string{"a", "b", "c"}, []float64{0.6/61 + 0.4/62, 0.6 / 62, 0.4 / 61}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.fusion), func(t *testing.T) {
+			hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector:    vectorRetriever,
+				Graph:     graphRetriever,
+				Alpha:     hybrid.Alpha(0.6),
+				Fusion:    tt.fusion,
+				DedupByID: true,
+			})
+			result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			if len(result.Items) != len(tt.order) {
+				t.Fatalf("expected %d items, got %d", len(tt.order), len(result.Items))
+			}
+			for i, item := range result.Items {
+				if item.ID != tt.order[i] || math.Abs(item.Score-tt.scores[i]) > 1e-9 {
+					t.Errorf("item %d: expected %s (%v), got %s (%v)", i, tt.order[i], tt.scores[i], item.ID, item.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestHybridRetrieverBranches(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}},
+	}}}
+	sparseRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 12}, {ID: "b", Score: 8}},
+	}}}
+	webRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "c", Score: 0.5}},
+	}}}
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: vectorRetriever,
+		Branches: []hybrid.Branch{
+			{Name: "sparse", Retriever: sparseRetriever, Mode: retrieve.ModeKeyword, Weight: 0.5},
+			{Name: "web", Retriever: webRetriever},
+		},
+		Fusion:    hybrid.FusionRRF,
+		DedupByID: true,
+	})
+	result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	ids := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		ids[i] = item.ID
+	}
+	if !slices.Equal(ids, []string{"a", "c", "b"}) {
+		t.Errorf("expected a, c, b, got %v", ids)
+	}
+	if !slices.Equal(result.Metadata.ModesUsed, []retrieve.Mode{retrieve.ModeHybrid, retrieve.ModeVector, retrieve.ModeKeyword, "web"}) {
+		t.Errorf("unexpected modes: %v", result.Metadata.ModesUsed)
+	}
+
+	var branches []string
+	for _, c := range result.Items[0].Provenance.Explanation.Contributions {
+		branches = append(branches, c.Branch)
+	}
+	if !slices.Equal(branches, []string{"vector", "sparse"}) {
+		t.Errorf("expected vector and sparse contributions, got %v", branches)
+	}
+	if c := result.Items[1].Provenance.Explanation.Contributions[0]; c.Mode != "web" || c.Weight != 1 {
+		t.Errorf("expected a web contribution with default weight, got %+v", c)
+	}
+}
+
+func TestHybridRetrieverNilBranch(t *testing.T) {
+	ctx := context.Background()
+
+	// A branch without a retriever doesn't count towards Warmup's check
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Branches: []hybrid.Branch{{Name: "web"}},
+	})
+	if err := hybridRetriever.Warmup(ctx); err == nil {
+		t.Error("expected error without retrievers")
+	}
+
+	hybridRetriever = hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: &retrievetest.Retriever{Results: []*retrieve.Result{{
+			Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}},
+		}}},
+		Branches: []hybrid.Branch{{Name: "web"}},
+	})
+	if err := hybridRetriever.Warmup(ctx); err != nil {
+		t.Fatalf("failed to warm up: %v", err)
+	}
+	result, err := hybridRetriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || slices.Contains(result.Metadata.ModesUsed, "web") {
+		t.Errorf("expected the nil branch to be skipped, got %+v", result)
+	}
+}
+
+func TestHybridRetrieverErrorPolicy(t *testing.T) {
+	errDown := errors.New("backend down")
+	failing := &retrievetest.Retriever{}
+	failing.FailWith(retrievetest.MethodRetrieve, errDown)
+	ok := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}},
+	}}}
+
+	tests := []struct {
+		name     string
+		policy   hybrid.ErrorPolicy
+		quorum   int
+		graph    retrieve.Retriever
+		web      retrieve.Retriever
+		wantErr  bool
+		branches []string
+	}{
+		{name: "fail fast", policy: hybrid.ErrorPolicyFailFast, graph: ok, web: ok, wantErr: true},
+		{name: "best effort", policy: hybrid.ErrorPolicyBestEffort, graph: ok, web: ok, branches: []string{"vector"}},
+		{name: "best effort all failed", policy: hybrid.ErrorPolicyBestEffort, graph: failing, web: failing, wantErr: true},
+		{name: "default quorum", policy: hybrid.ErrorPolicyQuorum, graph: ok, web: ok, branches: []string{"vector"}},
+		{name: "quorum not met", policy: hybrid.ErrorPolicyQuorum, quorum: 3, graph: ok, web: ok, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector:      failing,
+				Graph:       tt.graph,
+				Branches:    []hybrid.Branch{{Name: "web", Retriever: tt.web}},
+				ErrorPolicy: tt.policy,
+				Quorum:      tt.quorum,
+			})
+			result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+			if tt.wantErr {
+				if !errors.Is(err, errDown) {
+					t.Fatalf("expected the branch error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			var branches []string
+			for _, e := range result.Metadata.BranchErrors {
+				branches = append(branches, e.Branch)
+				if !errors.Is(e, errDown) {
+					t.Errorf("unexpected branch error: %v", e)
+				}
+			}
+			if !slices.Equal(branches, tt.branches) || !result.Metadata.PartialResult {
+				t.Errorf("expected partial result with failed branches %v, got %+v", tt.branches, result.Metadata)
+			}
+			if len(result.Items) != 1 {
+				t.Errorf("expected results from the healthy branches, got %+v", result.Items)
+			}
+		})
+	}
+}
+
+func TestHybridRetrieverBranchTimeout(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}},
+	}}}
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:        vectorRetriever,
+		Graph:         blockingRetriever,
+		BranchTimeout: 20 * time.Millisecond,
+		ErrorPolicy:   hybrid.ErrorPolicyBestEffort,
+	})
+	start := time.Now()
+	result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the slow branch to time out, took %v", elapsed)
+	}
+	errs := result.Metadata.BranchErrors
+	if len(errs) != 1 || errs[0].Branch != "graph" || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("expected a graph timeout, got %v", errs)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("expected the vector results, got %+v", result.Items)
+	}
+}
+
+func TestHybridRetrieverHedging(t *testing.T) {
+	replica := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "g", Score: 0.8}},
+	}}}
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Graph:         blockingRetriever,
+		GraphFallback: replica,
+		HedgeAfter:    10 * time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := hybridRetriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "g" {
+		t.Errorf("expected the hedged answer, got %+v", result.Items)
+	}
+	if calls := replica.Calls(); len(calls) != 1 {
+		t.Errorf("expected one hedged request, got %d", len(calls))
+	}
+}
+
+// labelClassifier labels every query with a fixed label.
+type labelClassifier string
+
+func (c labelClassifier) Classify(context.Context, retrieve.Query) (string, error) {
+	if c == "" {
+		return "", errors.New("classifier unavailable")
+	}
+	return string(c), nil
+}
+
+func TestHybridRetrieverRouter(t *testing.T) {
+	newRetriever := func(router hybrid.QueryRouter) (*hybrid.Retriever, *retrievetest.Retriever) {
+		vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+			Items: []retrieve.ContextItem{{ID: "v", Score: 1}},
+		}}}
+		graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+			Items: []retrieve.ContextItem{{ID: "g", Score: 1}},
+		}}}
+		return hybrid.NewRetriever(hybrid.RetrieverConfig{
+			Vector: vectorRetriever,
+			Graph:  graphRetriever,
+			Router: router,
+		}), graphRetriever
+	}
+	scores := func(result *retrieve.Result) map[string]float64 {
+		m := make(map[string]float64)
+		for _, item := range result.Items {
+			m[item.ID] = item.Score
+		}
+		return m
+	}
+	ctx := context.Background()
+
+	// Entity-heavy queries go graph-first with graph-favoring weights
+	r, _ := newRetriever(hybrid.RuleRouter{Rules: hybrid.DefaultRules()})
+	result, err := r.Retrieve(ctx, retrieve.Query{Text: "acme", Entities: []retrieve.EntityHint{{ID: "acme"}}})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if s := scores(result); math.Abs(s["g"]-0.7) > 1e-9 || math.Abs(s["v"]-0.3) > 1e-9 {
+		t.Errorf("expected graph-favoring weights, got %v", s)
+	}
+
+	// Open-ended queries go vector-first: the graph expands vector hits
+	r, graphRetriever := newRetriever(hybrid.RuleRouter{Rules: hybrid.DefaultRules()})
+	result, err = r.Retrieve(ctx, retrieve.Query{Text: "how do transformers handle long context"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	calls := graphRetriever.Calls()
+	if len(calls) != 1 || len(calls[0].Args[0].(retrieve.Query).Entities) != 1 {
+		t.Errorf("expected the graph to expand vector hits, got %+v", calls)
+	}
+	if s := scores(result); math.Abs(s["v"]-0.7) > 1e-9 {
+		t.Errorf("expected vector-favoring weights, got %v", s)
+	}
+
+	// Classifier labels select routes
+	graphOnly := hybrid.Weights{Graph: 1}
+	router := hybrid.ClassifierRouter{
+		Classifier: labelClassifier("lookup"),
+		Routes:     map[string]hybrid.Route{"lookup": {Weights: &graphOnly}},
+	}
+	r, _ = newRetriever(router)
+	if result, err = r.Retrieve(ctx, retrieve.Query{Text: "q"}); err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if s := scores(result); s["g"] != 1 || s["v"] != 0 {
+		t.Errorf("expected graph-only weights, got %v", s)
+	}
+
+	router.Classifier = labelClassifier("")
+	r, _ = newRetriever(router)
+	if _, err := r.Retrieve(ctx, retrieve.Query{Text: "q"}); err == nil {
+		t.Error("expected the classifier error")
+	}
+}
+
+func TestHybridRetrieverBudget(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "a", Score: 0.9, Content: strings.Repeat("a", 40)},
+			{ID: "b", Score: 0.8, Content: strings.Repeat("b", 80)},
+			{ID: "c", Score: 0.7, Content: strings.Repeat("c", 20)},
+		},
+	}}}
+
+	tests := []struct {
+		name   string
+		budget retrieve.ContextBudget
+		want   []string
+	}{
+		{name: "unlimited", want: []string{"a", "b", "c"}},
+		// 10 + 20 estimated tokens exceed the budget, so b is skipped for c
+		{name: "tokens", budget: retrieve.ContextBudget{MaxTokens: 20}, want: []string{"a", "c"}},
+		{name: "chars", budget: retrieve.ContextBudget{MaxChars: 125}, want: []string{"a", "b"}},
+		{name: "custom counter", budget: retrieve.ContextBudget{
+			MaxTokens:   2,
+			CountTokens: func(string) int { return 1 },
+		}, want: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector: vectorRetriever,
+				Budget: tt.budget,
+			})
+			result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q", Explain: true})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			ids := make([]string, len(result.Items))
+			for i, item := range result.Items {
+				ids[i] = item.ID
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestHybridRetrieverLinker(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "chunk-1", Score: 0.9, Metadata: map[string]string{"entities": "org:acme, person:smith"}},
+			{ID: "chunk-2", Score: 0.5, Metadata: map[string]string{"entities": "org:acme"}},
+			{ID: "chunk-3", Score: 0.4},
+		},
+	}}}
+	graphRetriever := &retrievetest.Retriever{}
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: vectorRetriever,
+		Graph:  graphRetriever,
+		Policy: hybrid.PolicyVectorThenGraph,
+		Linker: hybrid.MetadataLinker{Key: "entities"},
+	})
+	if _, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"}); err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	calls := graphRetriever.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected one graph call, got %d", len(calls))
+	}
+	entities := calls[0].Args[0].(retrieve.Query).Entities
+	want := []retrieve.EntityHint{{ID: "org:acme", Confidence: 0.9}, {ID: "person:smith", Confidence: 0.9}}
+	if !slices.Equal(entities, want) {
+		t.Errorf("expected %+v, got %+v", want, entities)
+	}
+
+	// Nothing to expand when no entity is linked
+	graphRetriever = &retrievetest.Retriever{}
+	hybridRetriever = hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: vectorRetriever,
+		Graph:  graphRetriever,
+		Policy: hybrid.PolicyVectorThenGraph,
+		Linker: hybrid.LinkerFunc(func(context.Context, []retrieve.ContextItem) ([]retrieve.EntityHint, error) {
+			return nil, nil
+		}),
+	})
+	if _, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"}); err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if calls := graphRetriever.Calls(); len(calls) != 0 {
+		t.Errorf("expected no graph call, got %d", len(calls))
+	}
+}
+
+func TestHybridRetrieverMerge(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "v1", Score: 0.9, Content: "go channels and goroutines"},
+			{ID: "v2", Score: 0.85, Content: "go channels and goroutines explained"},
+			{ID: "v3", Score: 0.8, Content: "database indexing basics"},
+		},
+	}}}
+	graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "g1", Score: 0.3, Content: "team ownership"},
+			{ID: "g2", Score: 0.2, Content: "service dependencies"},
+		},
+	}}}
+
+	tests := []struct {
+		merge hybrid.MergeStrategy
+		order []string
+	}{
+		{hybrid.MergeScore, []string{"v1", "v2", "v3", "g1", "g2"}},
+		{hybrid.MergeInterleave, []string{"v1", "g1", "v2", "g2", "v3"}},
+		// v2 nearly repeats v1, so it drops below less relevant results
+		{hybrid.MergeMMR, []string{"v1", "v3", "g1", "v2", "g2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.merge), func(t *testing.T) {
+			hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector: vectorRetriever,
+				Graph:  graphRetriever,
+				Alpha:  hybrid.Alpha(0.5),
+				Merge:  tt.merge,
+			})
+			result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			var ids []string
+			for _, item := range result.Items {
+				ids = append(ids, item.ID)
+			}
+			if !slices.Equal(ids, tt.order) {
+				t.Errorf("expected %v, got %v", tt.order, ids)
+			}
+		})
+	}
+}
+
+func TestHybridRetrieverCascade(t *testing.T) {
+	tests := []struct {
+		name       string
+		vector     []retrieve.ContextItem
+		confidence hybrid.Confidence
+		answeredBy retrieve.Mode
+		graphCalls int
+	}{
+		{"confident", []retrieve.ContextItem{{ID: "v1", Score: 0.9}, {ID: "v2", Score: 0.1}}, hybrid.ConfidenceMax, retrieve.ModeVector, 0},
+		{"unsure", []retrieve.ContextItem{{ID: "v1", Score: 0.3}}, hybrid.ConfidenceMax, retrieve.ModeGraph, 1},
+		{"mean", []retrieve.ContextItem{{ID: "v1", Score: 0.9}, {ID: "v2", Score: 0.1}}, hybrid.ConfidenceMean, retrieve.ModeGraph, 1},
+		{"empty", nil, hybrid.ConfidenceMax, retrieve.ModeGraph, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{Items: tt.vector}}}
+			graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+				Items: []retrieve.ContextItem{{ID: "g1", Score: 0.8}},
+			}}}
+			hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+				Vector:            vectorRetriever,
+				Graph:             graphRetriever,
+				Policy:            hybrid.PolicyCascade,
+				CascadeConfidence: tt.confidence,
+				CascadeThreshold:  0.6,
+			})
+			result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q", TopK: 2})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			if result.Metadata.AnsweredBy != tt.answeredBy {
+				t.Errorf("expected answer by %s, got %s", tt.answeredBy, result.Metadata.AnsweredBy)
+			}
+			if calls := graphRetriever.Calls(); len(calls) != tt.graphCalls {
+				t.Errorf("expected %d graph calls, got %d", tt.graphCalls, len(calls))
+			}
+			if want := len(tt.vector) + tt.graphCalls; len(result.Items) != min(want, 2) {
+				t.Errorf("expected %d items, got %d", min(want, 2), len(result.Items))
+			}
+		})
+	}
+
+	// The graph can be the cheap stage
+	vectorRetriever := &retrievetest.Retriever{}
+	graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "g1", Score: 0.8}},
+	}}}
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:       vectorRetriever,
+		Graph:        graphRetriever,
+		Policy:       hybrid.PolicyCascade,
+		CascadeFirst: retrieve.ModeGraph,
+	})
+	result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if result.Metadata.AnsweredBy != retrieve.ModeGraph || len(vectorRetriever.Calls()) != 0 {
+		t.Errorf("expected the graph to answer alone, got %s with %d vector calls", result.Metadata.AnsweredBy, len(vectorRetriever.Calls()))
+	}
+}
+
+func TestHybridRetrieverParallelCancellation(t *testing.T) {
+	errVector := errors.New("vector down")
+	vectorRetriever := &retrievetest.Retriever{}
+	vectorRetriever.FailWith(retrievetest.MethodRetrieve, errVector)
+
+	canceled := make(chan struct{})
+	graphRetriever := retrieve.RetrieverFunc(func(ctx context.Context, _ retrieve.Query) (*retrieve.Result, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	})
+
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: vectorRetriever,
+		Graph:  graphRetriever,
+		Policy: hybrid.PolicyParallel,
+	})
+	_, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+	if !errors.Is(err, errVector) {
+		t.Fatalf("expected vector error, got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the graph branch to be canceled")
+	}
+
+	// A skipped branch error leaves the other branch running
+	bestEffortGraph := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "g1", Score: 0.8}},
+	}}}
+	hybridRetriever = hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:      vectorRetriever,
+		Graph:       bestEffortGraph,
+		Policy:      hybrid.PolicyParallel,
+		ErrorPolicy: hybrid.ErrorPolicyBestEffort,
+	})
+	result, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || len(result.Metadata.BranchErrors) != 1 {
+		t.Errorf("expected 1 item and 1 branch error, got %d and %d", len(result.Items), len(result.Metadata.BranchErrors))
+	}
+}
+
+// mergeObserver records hybrid merge events.
+type mergeObserver struct {
+	observe.NoOpObserver
+	fusion       string
+	branchCounts map[string]int
+	dedupCount   int
+	outputCount  int
+}
+
+func (o *mergeObserver) OnHybridMerge(_ context.Context, fusion string, branchCounts map[string]int, dedupCount int, outputCount int, _ int64) {
+	o.fusion, o.branchCounts, o.dedupCount, o.outputCount = fusion, branchCounts, dedupCount, outputCount
+}
+
+func TestHybridRetrieverMergeObserver(t *testing.T) {
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.8}},
+	}}}
+	graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.7}},
+	}}}
+
+	observer := &mergeObserver{}
+	hybridRetriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector:   vectorRetriever,
+		Graph:    graphRetriever,
+		Fusion:   hybrid.FusionRRF,
+		Observer: observer,
+	})
+	if _, err := hybridRetriever.Retrieve(context.Background(), retrieve.Query{Text: "q", TopK: 1}); err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	if observer.fusion != string(hybrid.FusionRRF) || observer.dedupCount != 1 || observer.outputCount != 1 {
+		t.Errorf("unexpected merge event: %+v", observer)
+	}
+	if observer.branchCounts["vector"] != 2 || observer.branchCounts["graph"] != 1 {
+		t.Errorf("unexpected branch counts: %v", observer.branchCounts)
+	}
+}
@@ -0,0 +1,74 @@
+package hybrid
+
+import (
+	"context"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// EntityLinker links retrieved items to graph entities, to seed graph
+// expansion under PolicyVectorThenGraph.
+type EntityLinker interface {
+	// Link returns the entity hints for items.
+	Link(ctx context.Context, items []retrieve.ContextItem) ([]retrieve.EntityHint, error)
+}
+
+// LinkerFunc is a function adapter for EntityLinker.
+type LinkerFunc func(ctx context.Context, items []retrieve.ContextItem) ([]retrieve.EntityHint, error)
+
+// Link implements EntityLinker for LinkerFunc.
+func (f LinkerFunc) Link(ctx context.Context, items []retrieve.ContextItem) ([]retrieve.EntityHint, error) {
+	return f(ctx, items)
+}
+
+// IDLinker links each item to the graph node with the same ID, for graphs
+// whose node IDs match the vector IDs. It is the default linker.
+type IDLinker struct{}
+
+// Link implements EntityLinker.
+func (IDLinker) Link(_ context.Context, items []retrieve.ContextItem) ([]retrieve.EntityHint, error) {
+	entities := make([]retrieve.EntityHint, 0, len(items))
+	for _, item := range items {
+		entities = append(entities, retrieve.EntityHint{ID: item.ID, Name: item.ID})
+	}
+	return entities, nil
+}
+
+// MetadataLinker links items to the graph node IDs listed in a metadata
+// entry, such as the entities recorded for a chunk at ingestion.
+type MetadataLinker struct {
+	// Key is the metadata key holding the node IDs.
+	Key string
+	// Separator separates the node IDs (default ",").
+	Separator string
+}
+
+// Link implements EntityLinker. Each node ID is linked once, with the
+// score of the highest-ranked item listing it as confidence.
+func (l MetadataLinker) Link(_ context.Context, items []retrieve.ContextItem) ([]retrieve.EntityHint, error) {
+	sep := l.Separator
+	if sep == "" {
+		sep = ","
+	}
+	seen := make(map[string]bool)
+	var entities []retrieve.EntityHint
+	for _, item := range items {
+		for _, id := range strings.Split(item.Metadata[l.Key], sep) {
+			id = strings.TrimSpace(id)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			entities = append(entities, retrieve.EntityHint{ID: id, Confidence: item.Score})
+		}
+	}
+	return entities, nil
+}
+
+// Verify interface compliance
+var (
+	_ EntityLinker = LinkerFunc(nil)
+	_ EntityLinker = IDLinker{}
+	_ EntityLinker = MetadataLinker{}
+)
@@ -0,0 +1,94 @@
+package hybrid
+
+import (
+	"context"
+	"sort"
+
+	"github.com/agentplexus/omniretrieve/rerank"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// MergeStrategy defines how merged results are ordered.
+type MergeStrategy string
+
+const (
+	// MergeScore orders results by fused score.
+	MergeScore MergeStrategy = "score"
+	// MergeInterleave takes results round-robin from each branch in the
+	// branch's own rank order, so every source is represented near the top.
+	MergeInterleave MergeStrategy = "interleave"
+	// MergeMMR orders results by maximal marginal relevance, trading fused
+	// score against similarity to the results already picked.
+	MergeMMR MergeStrategy = "mmr"
+)
+
+// defaultMMRLambda weighs relevance and diversity equally.
+const defaultMMRLambda = 0.5
+
+// order orders merged items by the configured merge strategy.
+func (r *Retriever) order(ctx context.Context, q retrieve.Query, pr *policyResult, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	// Sort by score
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Score > items[j].Score
+	})
+
+	switch r.config.Merge {
+	case MergeInterleave:
+		lists := append([][]retrieve.ContextItem{pr.vectorItems, pr.graphItems}, pr.branchItems...)
+		return interleave(lists, items), nil
+	case MergeMMR:
+		return r.diversify(ctx, q, items)
+	default:
+		return items, nil
+	}
+}
+
+// interleave orders items by taking, in turn from each list, the
+// highest-ranked item not taken yet. Lists are the branch results the items
+// were merged from.
+func interleave(lists [][]retrieve.ContextItem, items []retrieve.ContextItem) []retrieve.ContextItem {
+	byID := make(map[string]retrieve.ContextItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	ranked := make([][]retrieve.ContextItem, len(lists))
+	for i, list := range lists {
+		ranked[i] = append([]retrieve.ContextItem(nil), list...)
+		sort.SliceStable(ranked[i], func(a, b int) bool {
+			return ranked[i][a].Score > ranked[i][b].Score
+		})
+	}
+
+	result := make([]retrieve.ContextItem, 0, len(items))
+	taken := make(map[string]bool, len(items))
+	next := make([]int, len(ranked))
+	for len(result) < len(items) {
+		progressed := false
+		for i, list := range ranked {
+			for next[i] < len(list) {
+				id := list[next[i]].ID
+				next[i]++
+				if item, ok := byID[id]; ok && !taken[id] {
+					taken[id] = true
+					result = append(result, item)
+					progressed = true
+					break
+				}
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return result
+}
+
+// diversify orders items by maximal marginal relevance with rerank.MMR.
+func (r *Retriever) diversify(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	mmr := rerank.NewMMR(rerank.MMRConfig{
+		Embedder: r.config.MMREmbedder,
+		Lambda:   r.config.MMRLambda,
+	})
+	return mmr.Rerank(ctx, q, items)
+}
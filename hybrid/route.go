@@ -0,0 +1,123 @@
+package hybrid
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Route is a per-query choice of policy and weights.
+type Route struct {
+	// Policy overrides RetrieverConfig.Policy (empty: keep it).
+	Policy Policy
+	// Weights overrides RetrieverConfig.Weights (nil: keep them).
+	Weights *Weights
+}
+
+// QueryRouter chooses how a hybrid retriever handles each query, e.g.
+// sending entity-heavy questions graph-first and open-ended ones
+// vector-first.
+type QueryRouter interface {
+	// Route returns the route for q. The zero Route keeps the configured
+	// policy and weights.
+	Route(ctx context.Context, q retrieve.Query) (Route, error)
+}
+
+// RouterFunc is a function adapter for QueryRouter.
+type RouterFunc func(ctx context.Context, q retrieve.Query) (Route, error)
+
+// Route implements QueryRouter for RouterFunc.
+func (f RouterFunc) Route(ctx context.Context, q retrieve.Query) (Route, error) {
+	return f(ctx, q)
+}
+
+// Rule routes queries that match a predicate.
+type Rule struct {
+	// Name identifies the rule.
+	Name string
+	// Match reports whether the rule applies to a query.
+	Match func(q retrieve.Query) bool
+	// Route is used for matching queries.
+	Route Route
+}
+
+// RuleRouter routes each query by the first rule it matches, or the zero
+// Route if none match.
+type RuleRouter struct {
+	Rules []Rule
+}
+
+// Route implements QueryRouter.
+func (r RuleRouter) Route(_ context.Context, q retrieve.Query) (Route, error) {
+	for _, rule := range r.Rules {
+		if rule.Match(q) {
+			return rule.Route, nil
+		}
+	}
+	return Route{}, nil
+}
+
+// HasEntities matches queries with at least n entity hints.
+func HasEntities(n int) func(q retrieve.Query) bool {
+	return func(q retrieve.Query) bool {
+		return len(q.Entities) >= n
+	}
+}
+
+// IsOpenEnded matches queries of at least minWords words, or that start
+// with an open question word such as "why" or "how".
+func IsOpenEnded(minWords int) func(q retrieve.Query) bool {
+	return func(q retrieve.Query) bool {
+		words := strings.Fields(strings.ToLower(q.Text))
+		if len(words) == 0 {
+			return false
+		}
+		switch words[0] {
+		case "why", "how", "explain", "describe", "compare":
+			return true
+		}
+		return len(words) >= minWords
+	}
+}
+
+// DefaultRules returns rules that send queries with entity hints
+// graph-first, favoring graph results, and open-ended queries
+// vector-first, favoring vector results.
+func DefaultRules() []Rule {
+	graphWeights, vectorWeights := WeightsFromAlpha(0.3), WeightsFromAlpha(0.7)
+	return []Rule{
+		{Name: "entities", Match: HasEntities(1), Route: Route{Policy: PolicyGraphThenVector, Weights: &graphWeights}},
+		{Name: "open_ended", Match: IsOpenEnded(8), Route: Route{Policy: PolicyVectorThenGraph, Weights: &vectorWeights}},
+	}
+}
+
+// QueryClassifier labels queries, e.g. with an LLM or a trained classifier.
+type QueryClassifier interface {
+	// Classify returns the label of q.
+	Classify(ctx context.Context, q retrieve.Query) (string, error)
+}
+
+// ClassifierRouter routes queries by the label a classifier assigns them.
+// Labels without a route get the zero Route.
+type ClassifierRouter struct {
+	Classifier QueryClassifier
+	Routes     map[string]Route
+}
+
+// Route implements QueryRouter.
+func (r ClassifierRouter) Route(ctx context.Context, q retrieve.Query) (Route, error) {
+	label, err := r.Classifier.Classify(ctx, q)
+	if err != nil {
+		return Route{}, fmt.Errorf("failed to classify query: %w", err)
+	}
+	return r.Routes[label], nil
+}
+
+// Verify interface compliance
+var (
+	_ QueryRouter = RouterFunc(nil)
+	_ QueryRouter = RuleRouter{}
+	_ QueryRouter = ClassifierRouter{}
+)
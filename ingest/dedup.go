@@ -0,0 +1,190 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// DeduperConfig configures a Deduper.
+type DeduperConfig struct {
+	// NearDuplicates enables simhash-based near-duplicate detection in
+	// addition to exact content hashing.
+	NearDuplicates bool
+	// MaxDistance is the largest simhash Hamming distance (out of 64 bits)
+	// treated as a near duplicate (default 3).
+	MaxDistance int
+}
+
+// Match describes a previously seen chunk that a new chunk duplicates.
+type Match struct {
+	// CanonicalID is the ID of the first chunk seen with this content.
+	CanonicalID string
+	// Exact is true when the normalized content is identical.
+	Exact bool
+	// Distance is the simhash Hamming distance (0 for exact matches).
+	Distance int
+}
+
+// Deduper detects exact and near-duplicate chunks by content. Content is
+// normalized (case-folded, whitespace collapsed) before hashing. It is safe
+// for concurrent use.
+type Deduper struct {
+	mu       sync.RWMutex
+	config   DeduperConfig
+	exact    map[[sha256.Size]byte]string
+	simhash  map[string]uint64 // ID -> simhash, for near-duplicate checks
+	contents map[string][sha256.Size]byte
+}
+
+// NewDeduper creates a new deduper.
+func NewDeduper(cfg DeduperConfig) *Deduper {
+	if cfg.MaxDistance == 0 {
+		cfg.MaxDistance = 3
+	}
+	return &Deduper{
+		config:   cfg,
+		exact:    make(map[[sha256.Size]byte]string),
+		simhash:  make(map[string]uint64),
+		contents: make(map[string][sha256.Size]byte),
+	}
+}
+
+// Check reports whether content duplicates a chunk already added.
+func (d *Deduper) Check(content string) (Match, bool) {
+	normalized := normalize(content)
+	sum := sha256.Sum256([]byte(normalized))
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.match("", normalized, sum)
+}
+
+// Add records content under id so later duplicates resolve to it. Adding
+// content that is already known keeps the existing canonical ID. Re-adding
+// id with changed content replaces its previous content.
+func (d *Deduper) Add(id, content string) {
+	normalized := normalize(content)
+	sum := sha256.Sum256([]byte(normalized))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.add(id, normalized, sum)
+}
+
+// CheckAndAdd atomically checks content for duplicates of chunks other than
+// id and, if it is not a duplicate, adds it under id. Concurrent callers
+// therefore never both accept the same content.
+func (d *Deduper) CheckAndAdd(id, content string) (Match, bool) {
+	normalized := normalize(content)
+	sum := sha256.Sum256([]byte(normalized))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if match, ok := d.match(id, normalized, sum); ok {
+		return match, true
+	}
+	d.add(id, normalized, sum)
+	return Match{}, false
+}
+
+// match finds a chunk other than self that content duplicates. The caller
+// must hold d.mu.
+func (d *Deduper) match(self, normalized string, sum [sha256.Size]byte) (Match, bool) {
+	if id, ok := d.exact[sum]; ok && id != self {
+		return Match{CanonicalID: id, Exact: true}, true
+	}
+	if !d.config.NearDuplicates {
+		return Match{}, false
+	}
+
+	hash := simhash(normalized)
+	best := Match{Distance: d.config.MaxDistance + 1}
+	for id, other := range d.simhash {
+		if id == self {
+			continue
+		}
+		dist := bits.OnesCount64(hash ^ other)
+		// Break ties by ID so results are deterministic
+		if dist < best.Distance || (dist == best.Distance && id < best.CanonicalID) {
+			best = Match{CanonicalID: id, Distance: dist}
+		}
+	}
+	if best.Distance > d.config.MaxDistance {
+		return Match{}, false
+	}
+	return best, true
+}
+
+// add records content under id, replacing any content previously recorded
+// under it. The caller must hold d.mu for writing.
+func (d *Deduper) add(id, normalized string, sum [sha256.Size]byte) {
+	d.forget(id)
+	d.contents[id] = sum
+	if _, ok := d.exact[sum]; ok {
+		return
+	}
+	d.exact[sum] = id
+	if d.config.NearDuplicates {
+		d.simhash[id] = simhash(normalized)
+	}
+}
+
+// Remove forgets the content recorded under id.
+func (d *Deduper) Remove(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.forget(id)
+}
+
+// forget removes the content recorded under id. The caller must hold d.mu
+// for writing.
+func (d *Deduper) forget(id string) {
+	if sum, ok := d.contents[id]; ok && d.exact[sum] == id {
+		delete(d.exact, sum)
+	}
+	delete(d.contents, id)
+	delete(d.simhash, id)
+}
+
+// normalize case-folds content and collapses whitespace.
+func normalize(content string) string {
+	return strings.Join(strings.Fields(strings.ToLower(content)), " ")
+}
+
+// simhash computes a 64-bit simhash over word bigrams (or single words for
+// one-word content), so that similar texts have hashes with few differing bits.
+func simhash(normalized string) uint64 {
+	words := strings.Fields(normalized)
+	features := words
+	if len(words) > 1 {
+		features = make([]string, len(words)-1)
+		for i := range features {
+			features[i] = words[i] + " " + words[i+1]
+		}
+	}
+
+	var counts [64]int
+	for _, f := range features {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(f))
+		v := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if v&(1<<bit) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, c := range counts {
+		if c > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
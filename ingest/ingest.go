@@ -0,0 +1,163 @@
+// Package ingest provides ingestion pipelines that prepare content for
+// retrieval, embedding chunks and writing them to a vector index.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// DedupAction defines what happens to a duplicate chunk.
+type DedupAction string
+
+const (
+	// DedupSkip drops duplicate chunks.
+	DedupSkip DedupAction = "skip"
+	// DedupLink drops duplicate chunks but records an alias from the
+	// duplicate ID to the canonical ID, resolvable with Pipeline.Canonical.
+	DedupLink DedupAction = "link"
+)
+
+// PipelineConfig configures an ingestion pipeline.
+type PipelineConfig struct {
+	// Index receives the ingested nodes. BatchIndex is used when implemented.
+	Index vector.Index
+	// Embedder embeds node content for nodes without an embedding.
+	Embedder vector.Embedder
+	// Deduper detects duplicate chunks before embedding (optional). It keeps
+	// state across Ingest calls and may be shared between pipelines.
+	Deduper *Deduper
+	// Action is applied to duplicate chunks (default skip).
+	Action DedupAction
+}
+
+// Duplicate describes a chunk that was not embedded or stored.
+type Duplicate struct {
+	// ID is the ID of the duplicate chunk.
+	ID string
+	Match
+}
+
+// Report summarizes an Ingest call.
+type Report struct {
+	// Written are the IDs of nodes written to the index.
+	Written []string
+	// Duplicates are the chunks skipped or linked as duplicates.
+	Duplicates []Duplicate
+}
+
+// Pipeline embeds and writes nodes, dropping duplicate chunks first.
+type Pipeline struct {
+	config  PipelineConfig
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// NewPipeline creates a new ingestion pipeline.
+func NewPipeline(cfg PipelineConfig) *Pipeline {
+	if cfg.Action == "" {
+		cfg.Action = DedupSkip
+	}
+	return &Pipeline{config: cfg, aliases: make(map[string]string)}
+}
+
+// Canonical returns the canonical ID a linked duplicate resolves to.
+func (p *Pipeline) Canonical(id string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	canonical, ok := p.aliases[id]
+	return canonical, ok
+}
+
+// Ingest deduplicates, embeds, and writes nodes. Duplicates are detected
+// against previously ingested content and within the batch itself; a node
+// re-ingested under the same ID is an update, not a duplicate of itself. If
+// embedding or writing fails, the batch is not recorded by the deduper.
+func (p *Pipeline) Ingest(ctx context.Context, nodes []vector.Node) (*Report, error) {
+	if p.config.Index == nil {
+		return nil, errors.New("ingest pipeline: index is required")
+	}
+
+	report := &Report{}
+	fresh := make([]vector.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if d := p.config.Deduper; d != nil {
+			// Updates of a node don't duplicate its own earlier content
+			if match, ok := d.CheckAndAdd(node.ID, node.Content); ok {
+				report.Duplicates = append(report.Duplicates, Duplicate{ID: node.ID, Match: match})
+				continue
+			}
+		}
+		fresh = append(fresh, node)
+	}
+
+	if err := p.write(ctx, fresh); err != nil {
+		if d := p.config.Deduper; d != nil {
+			for _, node := range fresh {
+				d.Remove(node.ID)
+			}
+		}
+		return nil, err
+	}
+
+	if p.config.Action == DedupLink && len(report.Duplicates) > 0 {
+		p.mu.Lock()
+		for _, dup := range report.Duplicates {
+			p.aliases[dup.ID] = dup.CanonicalID
+		}
+		p.mu.Unlock()
+	}
+
+	for _, node := range fresh {
+		report.Written = append(report.Written, node.ID)
+	}
+	return report, nil
+}
+
+// write embeds nodes that lack embeddings and writes them to the index.
+func (p *Pipeline) write(ctx context.Context, nodes []vector.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var texts []string
+	var missing []int
+	for i, node := range nodes {
+		if len(node.Embedding) == 0 {
+			texts = append(texts, node.Content)
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		if p.config.Embedder == nil {
+			return errors.New("ingest pipeline: embedder is required for nodes without embeddings")
+		}
+		embeddings, err := p.config.Embedder.EmbedBatch(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed nodes: %w", err)
+		}
+		if len(embeddings) != len(texts) {
+			return fmt.Errorf("embedder returned %d embeddings for %d texts", len(embeddings), len(texts))
+		}
+		for j, i := range missing {
+			nodes[i].Embedding = embeddings[j]
+		}
+	}
+
+	if batch, ok := p.config.Index.(vector.BatchIndex); ok {
+		if err := batch.UpsertBatch(ctx, nodes); err != nil {
+			return fmt.Errorf("failed to write nodes: %w", err)
+		}
+		return nil
+	}
+	for _, node := range nodes {
+		if err := p.config.Index.Upsert(ctx, node); err != nil {
+			return fmt.Errorf("failed to write node %s: %w", node.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package ingest_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/ingest"
+	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+func TestDeduper(t *testing.T) {
+	deduper := ingest.NewDeduper(ingest.DeduperConfig{NearDuplicates: true, MaxDistance: 12})
+	deduper.Add("a", "The quick brown fox jumps over the lazy dog near the river bank today")
+
+	// Exact duplicates match after normalization
+	match, ok := deduper.Check("  the QUICK brown fox jumps over the lazy dog near the river bank today ")
+	if !ok || !match.Exact || match.CanonicalID != "a" {
+		t.Errorf("expected exact match, got %+v, %v", match, ok)
+	}
+
+	// Near duplicates match within the distance threshold
+	match, ok = deduper.Check("The quick brown fox jumps over the lazy dog near the river bank yesterday")
+	if !ok || match.Exact || match.CanonicalID != "a" {
+		t.Errorf("expected near-duplicate match, got %+v, %v", match, ok)
+	}
+
+	if _, ok := deduper.Check("Go is a statically typed, compiled programming language"); ok {
+		t.Error("did not expect unrelated content to match")
+	}
+
+	deduper.Remove("a")
+	if _, ok := deduper.Check("The quick brown fox jumps over the lazy dog near the river bank today"); ok {
+		t.Error("expected removed content not to match")
+	}
+}
+
+func TestDeduperExactOnly(t *testing.T) {
+	deduper := ingest.NewDeduper(ingest.DeduperConfig{})
+	deduper.Add("a", "The quick brown fox jumps over the lazy dog near the river bank today")
+
+	if _, ok := deduper.Check("The quick brown fox jumps over the lazy dog near the river bank yesterday"); ok {
+		t.Error("did not expect near duplicates without NearDuplicates")
+	}
+}
+
+func TestDeduperUpdate(t *testing.T) {
+	deduper := ingest.NewDeduper(ingest.DeduperConfig{NearDuplicates: true, MaxDistance: 12})
+	deduper.Add("a", "The quick brown fox jumps over the lazy dog near the river bank today")
+	deduper.Add("a", "Go is a statically typed, compiled programming language")
+
+	// The replaced content no longer matches
+	if _, ok := deduper.Check("The quick brown fox jumps over the lazy dog near the river bank today"); ok {
+		t.Error("expected replaced content not to match")
+	}
+	if match, ok := deduper.Check("Go is a statically typed, compiled programming language"); !ok || match.CanonicalID != "a" {
+		t.Errorf("expected updated content to match a, got %+v, %v", match, ok)
+	}
+
+	// A chunk never duplicates its own content, exactly or nearly
+	if match, ok := deduper.CheckAndAdd("a", "Go is a statically typed, compiled programming language"); ok {
+		t.Errorf("expected a not to duplicate itself, got %+v", match)
+	}
+	if match, ok := deduper.CheckAndAdd("a", "Go is a statically typed, compiled programming language today"); ok {
+		t.Errorf("expected a not to nearly duplicate itself, got %+v", match)
+	}
+}
+
+func TestDeduperCheckAndAddConcurrent(t *testing.T) {
+	deduper := ingest.NewDeduper(ingest.DeduperConfig{})
+
+	var wg sync.WaitGroup
+	var accepted atomic.Int32
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := deduper.CheckAndAdd(fmt.Sprint(i), "the same chunk"); !ok {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted.Load() != 1 {
+		t.Errorf("expected exactly one chunk to be accepted, got %d", accepted.Load())
+	}
+}
+
+func TestPipelineDedup(t *testing.T) {
+	ctx := context.Background()
+
+	for _, action := range []ingest.DedupAction{ingest.DedupSkip, ingest.DedupLink} {
+		t.Run(string(action), func(t *testing.T) {
+			idx := memory.NewVectorIndex("test")
+			embedder := memory.NewHashEmbedder(64)
+			pipeline := ingest.NewPipeline(ingest.PipelineConfig{
+				Index:    idx,
+				Embedder: embedder,
+				Deduper:  ingest.NewDeduper(ingest.DeduperConfig{}),
+				Action:   action,
+			})
+
+			report, err := pipeline.Ingest(ctx, []vector.Node{
+				{ID: "1", Content: "Go is a statically typed language"},
+				{ID: "2", Content: "go is a  statically typed language"},
+				{ID: "3", Content: "Rust has strong memory safety"},
+			})
+			if err != nil {
+				t.Fatalf("failed to ingest: %v", err)
+			}
+
+			if len(report.Written) != 2 || len(report.Duplicates) != 1 || report.Duplicates[0].ID != "2" {
+				t.Errorf("unexpected report: %+v", report)
+			}
+			if idx.Count() != 2 {
+				t.Errorf("expected 2 stored nodes, got %d", idx.Count())
+			}
+
+			// Duplicates of previously ingested content are detected too
+			report, err = pipeline.Ingest(ctx, []vector.Node{{ID: "4", Content: "Rust has strong memory safety"}})
+			if err != nil {
+				t.Fatalf("failed to ingest: %v", err)
+			}
+			if len(report.Written) != 0 || report.Duplicates[0].CanonicalID != "3" {
+				t.Errorf("unexpected report: %+v", report)
+			}
+
+			canonical, ok := pipeline.Canonical("4")
+			if linked := action == ingest.DedupLink; ok != linked || (linked && canonical != "3") {
+				t.Errorf("unexpected alias for %s: %q, %v", action, canonical, ok)
+			}
+		})
+	}
+}
+
+func TestPipelineUpdate(t *testing.T) {
+	ctx := context.Background()
+	idx := memory.NewVectorIndex("test")
+	pipeline := ingest.NewPipeline(ingest.PipelineConfig{
+		Index:    idx,
+		Embedder: memory.NewHashEmbedder(64),
+		Deduper:  ingest.NewDeduper(ingest.DeduperConfig{NearDuplicates: true}),
+	})
+
+	if _, err := pipeline.Ingest(ctx, []vector.Node{{ID: "1", Content: "Go is a statically typed language"}}); err != nil {
+		t.Fatalf("failed to ingest: %v", err)
+	}
+
+	// Re-ingesting a node with edited content writes the new content
+	report, err := pipeline.Ingest(ctx, []vector.Node{{ID: "1", Content: "Go is a statically typed, compiled language"}})
+	if err != nil {
+		t.Fatalf("failed to ingest: %v", err)
+	}
+	if len(report.Written) != 1 || len(report.Duplicates) != 0 {
+		t.Errorf("expected the update to be written, got %+v", report)
+	}
+
+	// The old content no longer swallows new nodes
+	report, err = pipeline.Ingest(ctx, []vector.Node{{ID: "2", Content: "Go is a statically typed language"}})
+	if err != nil {
+		t.Fatalf("failed to ingest: %v", err)
+	}
+	if len(report.Written) != 1 {
+		t.Errorf("expected the old content to be written under a new ID, got %+v", report)
+	}
+}
+
+func TestPipelineRequiresEmbedder(t *testing.T) {
+	deduper := ingest.NewDeduper(ingest.DeduperConfig{})
+	pipeline := ingest.NewPipeline(ingest.PipelineConfig{
+		Index:   memory.NewVectorIndex("test"),
+		Deduper: deduper,
+	})
+
+	if _, err := pipeline.Ingest(context.Background(), []vector.Node{{ID: "1", Content: "text"}}); err == nil {
+		t.Fatal("expected error without embedder")
+	}
+
+	// Failed batches are not remembered as seen
+	if _, ok := deduper.Check("text"); ok {
+		t.Error("expected failed batch to be forgotten by the deduper")
+	}
+}
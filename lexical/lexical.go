@@ -0,0 +1,174 @@
+// Package lexical provides keyword retrieval, which finds exact identifiers
+// and rare terms that embeddings tend to miss.
+package lexical
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Document is a unit of text in a keyword index.
+type Document struct {
+	// ID is the unique identifier for this document.
+	ID string
+	// Content is the indexed text.
+	Content string
+	// Source identifies where this document came from.
+	Source string
+	// Metadata contains additional document metadata.
+	Metadata map[string]string
+}
+
+// SearchResult represents a keyword search result.
+type SearchResult struct {
+	// Document is the matched document.
+	Document Document
+	// Score is the relevance score (e.g., BM25); higher is better. Scores
+	// are not bounded and are only comparable within one search.
+	Score float64
+}
+
+// KeywordIndex defines the interface for keyword (lexical) search.
+type KeywordIndex interface {
+	// Search finds the k documents that best match the query terms.
+	Search(ctx context.Context, query string, k int, filters map[string]string) ([]SearchResult, error)
+	// Insert adds a document to the index.
+	Insert(ctx context.Context, doc Document) error
+	// Upsert inserts or updates a document in the index.
+	Upsert(ctx context.Context, doc Document) error
+	// Delete removes a document from the index.
+	Delete(ctx context.Context, id string) error
+	// Name returns the name/identifier of this index.
+	Name() string
+}
+
+// Tokenize splits text into lowercase terms. Letters, digits, and
+// underscores form terms, so identifiers like ERR_CONN_RESET stay whole.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// RetrieverConfig configures the keyword retriever.
+type RetrieverConfig struct {
+	// Index is the keyword index to search.
+	Index KeywordIndex
+	// DefaultTopK is the default number of results to return.
+	DefaultTopK int
+	// MinScore is the minimum normalized score threshold.
+	MinScore float64
+	// Observer for tracing and metrics. Searches are reported if it
+	// implements retrieve.KeywordObserver.
+	Observer retrieve.Observer
+}
+
+// Retriever implements keyword retrieval. Scores are normalized to [0, 1]
+// by dividing by the top score, so they can be fused with other modes.
+type Retriever struct {
+	config RetrieverConfig
+}
+
+// NewRetriever creates a new keyword retriever.
+func NewRetriever(cfg RetrieverConfig) *Retriever {
+	if cfg.DefaultTopK == 0 {
+		cfg.DefaultTopK = 10
+	}
+	return &Retriever{config: cfg}
+}
+
+// Warmup implements retrieve.Warmer by validating the configuration and
+// warming the index when it supports it.
+func (r *Retriever) Warmup(ctx context.Context) error {
+	if r.config.Index == nil {
+		return errors.New("keyword retriever: index is required")
+	}
+	return retrieve.Warmup(ctx, r.config.Index)
+}
+
+// Retrieve performs keyword search on the query text.
+func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
+	start := time.Now()
+
+	topK := q.TopK
+	if topK == 0 {
+		topK = r.config.DefaultTopK
+	}
+
+	results, err := r.config.Index.Search(ctx, q.Text, topK, q.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	minScore := q.MinScore
+	if minScore == 0 {
+		minScore = r.config.MinScore
+	}
+
+	var top float64
+	for _, res := range results {
+		top = max(top, res.Score)
+	}
+
+	items := make([]retrieve.ContextItem, 0, len(results))
+	dropped := 0
+	for _, res := range results {
+		score := 0.0
+		if top > 0 {
+			score = res.Score / top
+		}
+		if score < minScore {
+			dropped++
+			continue
+		}
+		items = append(items, retrieve.ContextItem{
+			ID:       res.Document.ID,
+			Content:  res.Document.Content,
+			Source:   res.Document.Source,
+			Score:    score,
+			Metadata: res.Document.Metadata,
+			Provenance: retrieve.Provenance{
+				Mode:    retrieve.ModeKeyword,
+				Backend: r.config.Index.Name(),
+			},
+		})
+	}
+
+	latency := time.Since(start).Milliseconds()
+
+	if obs, ok := r.config.Observer.(retrieve.KeywordObserver); ok {
+		obs.OnKeywordSearch(ctx, r.config.Index.Name(), topK, len(items), latency)
+	}
+
+	result := &retrieve.Result{
+		Items: items,
+		Query: q,
+		Metadata: retrieve.ResultMetadata{
+			TotalCandidates: len(results),
+			LatencyMS:       latency,
+			ModesUsed:       []retrieve.Mode{retrieve.ModeKeyword},
+		},
+	}
+
+	if q.Explain {
+		result.Debug = &retrieve.Debug{
+			Filters:  q.Filters,
+			MinScore: minScore,
+			Stages: []retrieve.StageDebug{{
+				Name:       "keyword.search",
+				Backend:    r.config.Index.Name(),
+				Candidates: len(results),
+				Returned:   len(items),
+				LatencyMS:  latency,
+			}},
+			DroppedByMinScore: dropped,
+		}
+	}
+
+	return result, nil
+}
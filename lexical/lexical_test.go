@@ -0,0 +1,140 @@
+package lexical_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/lexical"
+	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+func setupKeywordIndex(t *testing.T) *memory.KeywordIndex {
+	t.Helper()
+	ctx := context.Background()
+	idx := memory.NewKeywordIndex("test-keywords")
+	for _, doc := range []lexical.Document{
+		{ID: "d1", Content: "Connection reset: ERR_CONN_RESET when the proxy closes idle sockets", Metadata: map[string]string{"lang": "en"}},
+		{ID: "d2", Content: "The proxy pools connections and closes idle connections", Metadata: map[string]string{"lang": "en"}},
+		{ID: "d3", Content: "Die Verbindung wurde vom Proxy getrennt", Metadata: map[string]string{"lang": "de"}},
+	} {
+		if err := idx.Insert(ctx, doc); err != nil {
+			t.Fatalf("failed to insert document: %v", err)
+		}
+	}
+	return idx
+}
+
+func ids(results []lexical.SearchResult) []string {
+	out := make([]string, len(results))
+	for i, res := range results {
+		out[i] = res.Document.ID
+	}
+	return out
+}
+
+func TestTokenize(t *testing.T) {
+	got := lexical.Tokenize("Fix ERR_CONN_RESET in v2.1 (über-proxy)")
+	want := []string{"fix", "err_conn_reset", "in", "v2", "1", "über", "proxy"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize = %v, want %v", got, want)
+	}
+}
+
+func TestKeywordIndexBM25(t *testing.T) {
+	ctx := context.Background()
+	idx := setupKeywordIndex(t)
+
+	// Exact identifiers match only the documents containing them
+	results, err := idx.Search(ctx, "err_conn_reset", 10, nil)
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if !slices.Equal(ids(results), []string{"d1"}) {
+		t.Errorf("expected only d1, got %v", ids(results))
+	}
+
+	// Repeated rare terms rank higher; common terms contribute little
+	results, err = idx.Search(ctx, "idle connections proxy", 10, nil)
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 3 || results[0].Document.ID != "d2" {
+		t.Errorf("expected d2 first of all documents, got %v", ids(results))
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("expected descending scores, got %+v", results)
+	}
+
+	results, err = idx.Search(ctx, "proxy", 10, map[string]string{"lang": "de"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if !slices.Equal(ids(results), []string{"d3"}) {
+		t.Errorf("expected filtered d3, got %v", ids(results))
+	}
+
+	results, err = idx.Search(ctx, "proxy", 1, nil)
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected top-1 result, got %v, %v", ids(results), err)
+	}
+}
+
+func TestKeywordIndexUpsertDelete(t *testing.T) {
+	ctx := context.Background()
+	idx := setupKeywordIndex(t)
+
+	if err := idx.Upsert(ctx, lexical.Document{ID: "d1", Content: "replaced"}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	if results, _ := idx.Search(ctx, "err_conn_reset", 10, nil); len(results) != 0 {
+		t.Errorf("expected old content to be unindexed, got %v", ids(results))
+	}
+	if results, _ := idx.Search(ctx, "replaced", 10, nil); !slices.Equal(ids(results), []string{"d1"}) {
+		t.Errorf("expected new content to be indexed, got %v", ids(results))
+	}
+
+	if err := idx.Delete(ctx, "d1"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if results, _ := idx.Search(ctx, "replaced", 10, nil); len(results) != 0 {
+		t.Errorf("expected deleted document to be gone, got %v", ids(results))
+	}
+}
+
+func TestKeywordRetriever(t *testing.T) {
+	ctx := context.Background()
+	r := lexical.NewRetriever(lexical.RetrieverConfig{Index: setupKeywordIndex(t)})
+	if err := r.Warmup(ctx); err != nil {
+		t.Fatalf("failed to warm up: %v", err)
+	}
+
+	result, err := r.Retrieve(ctx, retrieve.Query{Text: "idle connections proxy", Explain: true})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 3 || result.Items[0].ID != "d2" || result.Items[0].Score != 1 {
+		t.Fatalf("expected d2 first with normalized score 1, got %+v", result.Items)
+	}
+	for _, item := range result.Items {
+		if item.Score <= 0 || item.Score > 1 || item.Provenance.Mode != retrieve.ModeKeyword {
+			t.Errorf("unexpected item: %+v", item)
+		}
+	}
+	if result.Debug == nil || result.Debug.Stages[0].Name != "keyword.search" {
+		t.Errorf("expected keyword.search debug stage, got %+v", result.Debug)
+	}
+
+	result, err = r.Retrieve(ctx, retrieve.Query{Text: "idle connections proxy", MinScore: 0.99})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("expected only the top item above MinScore, got %+v", result.Items)
+	}
+
+	if err := lexical.NewRetriever(lexical.RetrieverConfig{}).Warmup(ctx); err == nil {
+		t.Error("expected warmup error without index")
+	}
+}
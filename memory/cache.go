@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// ResultCacheConfig configures a ResultCache.
+type ResultCacheConfig struct {
+	// MaxEntries limits the number of cached results (default 1000).
+	// The least recently used entry is evicted when the limit is reached.
+	MaxEntries int
+	// TTL is how long an entry stays valid (zero means no expiry).
+	TTL time.Duration
+}
+
+// ResultCache is an in-memory LRU cache of retrieval results. It implements
+// retrieve.Invalidator, so it can subscribe to a retrieve.InvalidationBus and
+// evict results that contain mutated IDs or belong to a flushed namespace.
+type ResultCache struct {
+	mu      sync.Mutex
+	config  ResultCacheConfig
+	lru     *list.List               // Front is most recently used
+	entries map[string]*list.Element // Key -> element holding *cacheEntry
+	byID    map[string]map[string]struct{}
+}
+
+// cacheEntry is a single cached result.
+type cacheEntry struct {
+	key        string
+	result     *retrieve.Result
+	ids        []string
+	namespaces map[string]struct{}
+	expiresAt  time.Time
+}
+
+// NewResultCache creates a new in-memory result cache.
+func NewResultCache(cfg ResultCacheConfig) *ResultCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1000
+	}
+	return &ResultCache{
+		config:  cfg,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		byID:    make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements retrieve.Cache.
+func (c *ResultCache) Get(ctx context.Context, q retrieve.Query) (*retrieve.Result, bool) {
+	key := cacheKey(q)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+
+	result := cloneResult(entry.result)
+	result.Metadata.CacheHit = true
+	return result, true
+}
+
+// Set implements retrieve.Cache.
+func (c *ResultCache) Set(ctx context.Context, q retrieve.Query, r *retrieve.Result) error {
+	if r == nil {
+		return nil
+	}
+	key := cacheKey(q)
+
+	entry := &cacheEntry{
+		key:        key,
+		result:     cloneResult(r),
+		ids:        make([]string, 0, len(r.Items)),
+		namespaces: make(map[string]struct{}),
+	}
+	for _, item := range r.Items {
+		entry.ids = append(entry.ids, item.ID)
+		if item.Provenance.Backend != "" {
+			entry.namespaces[item.Provenance.Backend] = struct{}{}
+		}
+	}
+	if c.config.TTL > 0 {
+		entry.expiresAt = time.Now().Add(c.config.TTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	for c.lru.Len() >= c.config.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for _, id := range entry.ids {
+		if c.byID[id] == nil {
+			c.byID[id] = make(map[string]struct{})
+		}
+		c.byID[id][key] = struct{}{}
+	}
+	return nil
+}
+
+// Invalidate implements retrieve.Invalidator.
+func (c *ResultCache) Invalidate(ctx context.Context, m retrieve.Mutation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if m.Flush {
+		for elem := c.lru.Front(); elem != nil; {
+			next := elem.Next()
+			entry := elem.Value.(*cacheEntry)
+			_, inNamespace := entry.namespaces[m.Namespace]
+			// Entries without namespaces (e.g., empty results) may be
+			// affected by any write, so they are flushed too.
+			if m.Namespace == "" || inNamespace || len(entry.namespaces) == 0 {
+				c.remove(elem)
+			}
+			elem = next
+		}
+		return
+	}
+
+	for _, id := range m.IDs {
+		for key := range c.byID[id] {
+			if elem, ok := c.entries[key]; ok {
+				c.remove(elem)
+			}
+		}
+	}
+}
+
+// Compact implements retrieve.Compactor by purging expired entries, which
+// are otherwise only evicted when looked up or pushed out by the LRU.
+func (c *ResultCache) Compact(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*cacheEntry); !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+	return nil
+}
+
+// Len returns the number of cached entries.
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove deletes an entry and its ID index references. Callers must hold mu.
+func (c *ResultCache) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	for _, id := range entry.ids {
+		delete(c.byID[id], entry.key)
+		if len(c.byID[id]) == 0 {
+			delete(c.byID, id)
+		}
+	}
+}
+
+// cacheKey derives a deterministic cache key from the fields of a query
+// that affect its results.
+func cacheKey(q retrieve.Query) string {
+	data, _ := json.Marshal(struct {
+		Text       string
+		Embedding  []float32
+		Entities   []retrieve.EntityHint
+		Filters    map[string]string
+		Namespace  string
+		MaxDepth   int
+		TopK       int
+		Modes      []retrieve.Mode
+		MinScore   float64
+		Metric     string
+		Oversample float64
+		AsOf       time.Time
+		Explain    bool
+	}{q.Text, q.Embedding, q.Entities, q.Filters, q.Namespace, q.MaxDepth, q.TopK, q.Modes, q.MinScore, q.Metric, q.Oversample, q.AsOf, q.Explain})
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// Verify interface compliance
+var (
+	_ retrieve.Cache       = (*ResultCache)(nil)
+	_ retrieve.Invalidator = (*ResultCache)(nil)
+	_ retrieve.Compactor   = (*ResultCache)(nil)
+)
+
+// cloneResult copies r and its items, so cached results are not affected by
+// callers editing results in place, e.g. when reranking.
+func cloneResult(r *retrieve.Result) *retrieve.Result {
+	result := *r
+	result.Items = slices.Clone(r.Items)
+	for i := range result.Items {
+		item := &result.Items[i]
+		item.Metadata = maps.Clone(item.Metadata)
+		item.Provenance.GraphPath = slices.Clone(item.Provenance.GraphPath)
+	}
+	return &result
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// EmbeddingCacheConfig configures an EmbeddingCache.
+type EmbeddingCacheConfig struct {
+	// MaxEntries limits the number of cached embeddings (default 10000).
+	// The least recently used entry is evicted when the limit is reached.
+	MaxEntries int
+	// TTL is how long an entry stays valid (zero means no expiry).
+	TTL time.Duration
+}
+
+// EmbeddingCache is an in-memory LRU cache of query embeddings for
+// vector.RetrieverConfig.EmbeddingCache.
+type EmbeddingCache struct {
+	mu      sync.Mutex
+	config  EmbeddingCacheConfig
+	lru     *list.List               // Front is most recently used
+	entries map[string]*list.Element // Key -> element holding *embeddingEntry
+}
+
+// embeddingEntry is a single cached embedding.
+type embeddingEntry struct {
+	key       string
+	embedding []float32
+	expiresAt time.Time
+}
+
+// NewEmbeddingCache creates a new in-memory embedding cache.
+func NewEmbeddingCache(cfg EmbeddingCacheConfig) *EmbeddingCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	return &EmbeddingCache{
+		config:  cfg,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get implements vector.EmbeddingCache.
+func (c *EmbeddingCache) Get(ctx context.Context, key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*embeddingEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.embedding, true
+}
+
+// Set implements vector.EmbeddingCache.
+func (c *EmbeddingCache) Set(ctx context.Context, key string, embedding []float32) {
+	entry := &embeddingEntry{key: key, embedding: embedding}
+	if c.config.TTL > 0 {
+		entry.expiresAt = time.Now().Add(c.config.TTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	for c.lru.Len() >= c.config.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+}
+
+// Compact implements retrieve.Compactor by purging expired entries, which
+// are otherwise only evicted when looked up or pushed out by the LRU.
+func (c *EmbeddingCache) Compact(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*embeddingEntry); !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+	return nil
+}
+
+// Len returns the number of cached embeddings, including expired ones not
+// yet evicted.
+func (c *EmbeddingCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove deletes an entry. The caller must hold the lock.
+func (c *EmbeddingCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*embeddingEntry)
+	delete(c.entries, entry.key)
+}
+
+// Verify interface compliance
+var (
+	_ vector.EmbeddingCache = (*EmbeddingCache)(nil)
+	_ retrieve.Compactor    = (*EmbeddingCache)(nil)
+)
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// FeedbackStore is an in-memory feedback store.
+type FeedbackStore struct {
+	mu       sync.RWMutex
+	feedback []retrieve.Feedback
+}
+
+// NewFeedbackStore creates a new in-memory feedback store.
+func NewFeedbackStore() *FeedbackStore {
+	return &FeedbackStore{}
+}
+
+// Record implements retrieve.FeedbackStore. A zero Timestamp is set to now.
+func (s *FeedbackStore) Record(ctx context.Context, fb retrieve.Feedback) error {
+	if fb.Timestamp.IsZero() {
+		fb.Timestamp = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feedback = append(s.feedback, fb)
+	return nil
+}
+
+// List implements retrieve.FeedbackStore.
+func (s *FeedbackStore) List(ctx context.Context) ([]retrieve.Feedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feedback := make([]retrieve.Feedback, len(s.feedback))
+	copy(feedback, s.feedback)
+	return feedback, nil
+}
+
+// Verify interface compliance
+var _ retrieve.FeedbackStore = (*FeedbackStore)(nil)
@@ -2,9 +2,12 @@ package memory
 
 import (
 	"context"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/agentplexus/omniretrieve/vector"
 )
 
 // KnowledgeGraph is an in-memory knowledge graph.
@@ -13,14 +16,17 @@ type KnowledgeGraph struct {
 	name  string
 	nodes map[string]graph.Node
 	edges map[string][]graph.Edge // From node ID -> edges
+	// reverse indexes edges by target, for incoming traversal
+	reverse map[string][]graph.Edge // To node ID -> edges
 }
 
 // NewKnowledgeGraph creates a new in-memory knowledge graph.
 func NewKnowledgeGraph(name string) *KnowledgeGraph {
 	return &KnowledgeGraph{
-		name:  name,
-		nodes: make(map[string]graph.Node),
-		edges: make(map[string][]graph.Edge),
+		name:    name,
+		nodes:   make(map[string]graph.Node),
+		edges:   make(map[string][]graph.Edge),
+		reverse: make(map[string][]graph.Edge),
 	}
 }
 
@@ -28,17 +34,69 @@ func NewKnowledgeGraph(name string) *KnowledgeGraph {
 func (kg *KnowledgeGraph) Traverse(ctx context.Context, startNodes []string, opts graph.TraversalOptions) (*graph.TraversalResult, error) {
 	kg.mu.RLock()
 	defer kg.mu.RUnlock()
+	return kg.collect(startNodes, opts, nil), nil
+}
 
-	visited := make(map[string]bool)
+// TraverseSimilar implements graph.EmbeddingAwareGraph.
+func (kg *KnowledgeGraph) TraverseSimilar(ctx context.Context, startNodes []string, embedding []float32, minSimilarity float64, opts graph.TraversalOptions) (*graph.TraversalResult, error) {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+	return kg.collect(startNodes, opts, func(node graph.Node) bool {
+		return len(node.Embedding) > 0 && vector.Similarity(vector.DistanceCosine, embedding, node.Embedding) >= minSimilarity
+	}), nil
+}
+
+// collect walks the graph and materializes the traversal result. The caller
+// must hold the read lock.
+func (kg *KnowledgeGraph) collect(startNodes []string, opts graph.TraversalOptions, accept func(graph.Node) bool) *graph.TraversalResult {
 	paths := make(map[string][]string)
 	var resultNodes []graph.Node
 	var resultEdges []graph.Edge
+	kg.walk(startNodes, opts, accept, func(step graph.TraversalStep) bool {
+		resultNodes = append(resultNodes, step.Node)
+		paths[step.Node.ID] = step.Path
+		return true
+	}, func(edge graph.Edge) {
+		resultEdges = append(resultEdges, edge)
+	})
+
+	return &graph.TraversalResult{
+		Nodes: resultNodes,
+		Edges: resultEdges,
+		Paths: paths,
+	}
+}
+
+// TraverseStream implements graph.StreamingTraverser. The graph is
+// read-locked until the traversal ends.
+func (kg *KnowledgeGraph) TraverseStream(ctx context.Context, startNodes []string, opts graph.TraversalOptions, visit graph.TraversalVisitor) error {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+
+	var err error
+	kg.walk(startNodes, opts, nil, func(step graph.TraversalStep) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		return visit(step)
+	}, nil)
+	return err
+}
+
+// walk traverses the graph breadth first, passing each reached node to visit
+// until it returns false, and each edge queued for traversal to follow if
+// set. If accept is set, only nodes it accepts are expanded to. The caller
+// must hold the read lock.
+func (kg *KnowledgeGraph) walk(startNodes []string, opts graph.TraversalOptions, accept func(graph.Node) bool, visit graph.TraversalVisitor, follow func(graph.Edge)) {
+	visited := make(map[string]bool)
+	reached := 0
 
 	// BFS traversal
 	type queueItem struct {
 		nodeID string
 		path   []string
 		depth  int
+		via    *graph.Edge
 	}
 
 	queue := make([]queueItem, 0, len(startNodes))
@@ -48,7 +106,7 @@ func (kg *KnowledgeGraph) Traverse(ctx context.Context, startNodes []string, opt
 		}
 	}
 
-	for len(queue) > 0 && len(resultNodes) < opts.MaxNodes {
+	for len(queue) > 0 && reached < opts.MaxNodes {
 		current := queue[0]
 		queue = queue[1:]
 
@@ -57,14 +115,16 @@ func (kg *KnowledgeGraph) Traverse(ctx context.Context, startNodes []string, opt
 		}
 		visited[current.nodeID] = true
 
-		// Add node to results
+		// Visit the node
 		if node, ok := kg.nodes[current.nodeID]; ok {
 			// Apply node type filter
 			if len(opts.NodeTypes) > 0 && !containsString(opts.NodeTypes, node.Type) {
 				continue
 			}
-			resultNodes = append(resultNodes, node)
-			paths[current.nodeID] = current.path
+			reached++
+			if !visit(graph.TraversalStep{Node: node, Path: current.path, Edge: current.via}) {
+				return
+			}
 		}
 
 		// Stop if max depth reached
@@ -72,8 +132,9 @@ func (kg *KnowledgeGraph) Traverse(ctx context.Context, startNodes []string, opt
 			continue
 		}
 
-		// Traverse edges
-		for _, edge := range kg.edges[current.nodeID] {
+		// Traverse edges in the requested directions
+		for _, h := range kg.hops(current.nodeID, opts.Direction) {
+			edge, next := h.edge, h.to
 			// Apply edge type filter
 			if len(opts.EdgeTypes) > 0 && !containsString(opts.EdgeTypes, edge.Type) {
 				continue
@@ -84,26 +145,151 @@ func (kg *KnowledgeGraph) Traverse(ctx context.Context, startNodes []string, opt
 				continue
 			}
 
-			if !visited[edge.To] {
+			// Apply edge metadata filter
+			if !matchesFilters(edge.Metadata, opts.EdgeMetadata) {
+				continue
+			}
+
+			// Apply point-in-time filter
+			if !opts.AsOf.IsZero() && !edge.ValidAt(opts.AsOf) {
+				continue
+			}
+
+			if accept != nil && !accept(kg.nodes[next]) {
+				continue
+			}
+
+			if !visited[next] {
 				newPath := make([]string, len(current.path)+1)
 				copy(newPath, current.path)
-				newPath[len(current.path)] = edge.To
+				newPath[len(current.path)] = next
 
 				queue = append(queue, queueItem{
-					nodeID: edge.To,
+					nodeID: next,
 					path:   newPath,
 					depth:  current.depth + 1,
+					via:    &edge,
 				})
-				resultEdges = append(resultEdges, edge)
+				if follow != nil {
+					follow(edge)
+				}
 			}
 		}
 	}
+}
 
-	return &graph.TraversalResult{
-		Nodes: resultNodes,
-		Edges: resultEdges,
-		Paths: paths,
-	}, nil
+// Subgraph traverses kg from startNodes and copies the reached nodes, and
+// the edges between them that pass the traversal's edge filters, into a new
+// in-memory graph. The copy is detached from kg, so callers such as prompt
+// builders and visualizers can query it without further round trips. Edges
+// are fetched with one GetEdges call per reached node.
+func Subgraph(ctx context.Context, kg graph.KnowledgeGraph, startNodes []string, opts graph.TraversalOptions) (*KnowledgeGraph, error) {
+	result, err := kg.Traverse(ctx, startNodes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := NewKnowledgeGraph(kg.Name())
+	for _, node := range result.Nodes {
+		sub.nodes[node.ID] = node
+	}
+	for _, node := range result.Nodes {
+		edges, err := kg.GetEdges(ctx, node.ID, graph.DirectionOut)
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range edges {
+			if _, ok := sub.nodes[edge.To]; !ok {
+				continue
+			}
+			if len(opts.EdgeTypes) > 0 && !containsString(opts.EdgeTypes, edge.Type) {
+				continue
+			}
+			if edge.Weight < opts.MinWeight || (!opts.AsOf.IsZero() && !edge.ValidAt(opts.AsOf)) {
+				continue
+			}
+			if !matchesFilters(edge.Metadata, opts.EdgeMetadata) {
+				continue
+			}
+			sub.addEdge(edge)
+		}
+	}
+	return sub, nil
+}
+
+// hop is an edge followed from one of its endpoints to the other.
+type hop struct {
+	edge graph.Edge
+	to   string
+}
+
+// hops returns the edges of a node that traversal follows in direction. A
+// self-loop is returned once.
+func (kg *KnowledgeGraph) hops(id string, direction graph.Direction) []hop {
+	var hops []hop
+	if direction != graph.DirectionIn {
+		for _, e := range kg.edges[id] {
+			hops = append(hops, hop{edge: e, to: e.To})
+		}
+	}
+	if direction == graph.DirectionIn || direction == graph.DirectionBoth {
+		for _, e := range kg.reverse[id] {
+			if direction == graph.DirectionBoth && e.From == id {
+				continue
+			}
+			hops = append(hops, hop{edge: e, to: e.From})
+		}
+	}
+	return hops
+}
+
+// FindPaths implements graph.PathFinder.
+func (kg *KnowledgeGraph) FindPaths(ctx context.Context, from, to string, opts graph.PathOptions) ([]graph.Path, error) {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+
+	if _, ok := kg.nodes[from]; !ok {
+		return nil, nil
+	}
+	if _, ok := kg.nodes[to]; !ok {
+		return nil, nil
+	}
+	return graph.ShortestPaths(ctx, from, to, opts, func(_ context.Context, id string) ([]graph.Edge, error) {
+		var edges []graph.Edge
+		for _, edge := range kg.edges[id] {
+			if _, ok := kg.nodes[edge.To]; ok {
+				edges = append(edges, edge)
+			}
+		}
+		return edges, nil
+	})
+}
+
+// Edges implements graph.EdgeLister. Edges are ordered by source, target,
+// and type.
+func (kg *KnowledgeGraph) Edges(ctx context.Context) ([]graph.Edge, error) {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+	var edges []graph.Edge
+	for _, out := range kg.edges {
+		edges = append(edges, out...)
+	}
+	sortEdges(edges)
+	return edges, nil
+}
+
+// sortEdges orders edges by source, target, and type.
+func sortEdges(edges []graph.Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
 }
 
 // FindNodes implements graph.KnowledgeGraph.
@@ -129,6 +315,42 @@ func (kg *KnowledgeGraph) FindNodes(ctx context.Context, nodeType string, filter
 	return result, nil
 }
 
+// GetNode implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) GetNode(ctx context.Context, id string) (graph.Node, error) {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+	node, ok := kg.nodes[id]
+	if !ok {
+		return graph.Node{}, graph.ErrNodeNotFound
+	}
+	return node, nil
+}
+
+// GetEdges implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) GetEdges(ctx context.Context, nodeID string, direction graph.Direction) ([]graph.Edge, error) {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+
+	var edges []graph.Edge
+	for _, h := range kg.hops(nodeID, direction) {
+		edges = append(edges, h.edge)
+	}
+	sortEdges(edges)
+	return edges, nil
+}
+
+// NeighborCount implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) NeighborCount(ctx context.Context, nodeID string, direction graph.Direction) (int, error) {
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+
+	neighbors := make(map[string]bool)
+	for _, h := range kg.hops(nodeID, direction) {
+		neighbors[h.to] = true
+	}
+	return len(neighbors), nil
+}
+
 // AddNode implements graph.KnowledgeGraph.
 func (kg *KnowledgeGraph) AddNode(ctx context.Context, node graph.Node) error {
 	kg.mu.Lock()
@@ -149,7 +371,7 @@ func (kg *KnowledgeGraph) UpsertNode(ctx context.Context, node graph.Node) error
 func (kg *KnowledgeGraph) AddEdge(ctx context.Context, edge graph.Edge) error {
 	kg.mu.Lock()
 	defer kg.mu.Unlock()
-	kg.edges[edge.From] = append(kg.edges[edge.From], edge)
+	kg.addEdge(edge)
 	return nil
 }
 
@@ -159,14 +381,8 @@ func (kg *KnowledgeGraph) UpsertEdge(ctx context.Context, edge graph.Edge) error
 	defer kg.mu.Unlock()
 
 	// Remove existing edge if present
-	edges := kg.edges[edge.From]
-	filtered := make([]graph.Edge, 0, len(edges))
-	for _, e := range edges {
-		if e.To != edge.To || e.Type != edge.Type {
-			filtered = append(filtered, e)
-		}
-	}
-	kg.edges[edge.From] = append(filtered, edge)
+	kg.removeEdge(edge.From, edge.To, edge.Type)
+	kg.addEdge(edge)
 	return nil
 }
 
@@ -176,19 +392,7 @@ func (kg *KnowledgeGraph) DeleteNode(ctx context.Context, id string) error {
 	defer kg.mu.Unlock()
 
 	delete(kg.nodes, id)
-	delete(kg.edges, id)
-
-	// Remove edges pointing to this node
-	for from, edges := range kg.edges {
-		filtered := make([]graph.Edge, 0, len(edges))
-		for _, e := range edges {
-			if e.To != id {
-				filtered = append(filtered, e)
-			}
-		}
-		kg.edges[from] = filtered
-	}
-
+	kg.removeNodeEdges(id)
 	return nil
 }
 
@@ -197,14 +401,7 @@ func (kg *KnowledgeGraph) DeleteEdge(ctx context.Context, from, to, edgeType str
 	kg.mu.Lock()
 	defer kg.mu.Unlock()
 
-	edges := kg.edges[from]
-	filtered := make([]graph.Edge, 0, len(edges))
-	for _, e := range edges {
-		if e.To != to || e.Type != edgeType {
-			filtered = append(filtered, e)
-		}
-	}
-	kg.edges[from] = filtered
+	kg.removeEdge(from, to, edgeType)
 	return nil
 }
 
@@ -238,7 +435,7 @@ func (kg *KnowledgeGraph) AddEdgeBatch(ctx context.Context, edges []graph.Edge)
 	kg.mu.Lock()
 	defer kg.mu.Unlock()
 	for _, edge := range edges {
-		kg.edges[edge.From] = append(kg.edges[edge.From], edge)
+		kg.addEdge(edge)
 	}
 	return nil
 }
@@ -249,14 +446,8 @@ func (kg *KnowledgeGraph) UpsertEdgeBatch(ctx context.Context, edges []graph.Edg
 	defer kg.mu.Unlock()
 	for _, edge := range edges {
 		// Remove existing edge if present
-		existingEdges := kg.edges[edge.From]
-		filtered := make([]graph.Edge, 0, len(existingEdges))
-		for _, e := range existingEdges {
-			if e.To != edge.To || e.Type != edge.Type {
-				filtered = append(filtered, e)
-			}
-		}
-		kg.edges[edge.From] = append(filtered, edge)
+		kg.removeEdge(edge.From, edge.To, edge.Type)
+		kg.addEdge(edge)
 	}
 	return nil
 }
@@ -268,26 +459,38 @@ func (kg *KnowledgeGraph) DeleteNodeBatch(ctx context.Context, ids []string) err
 
 	for _, id := range ids {
 		delete(kg.nodes, id)
-		delete(kg.edges, id)
+		kg.removeNodeEdges(id)
 	}
+	return nil
+}
 
-	// Remove edges pointing to deleted nodes
-	idSet := make(map[string]bool)
-	for _, id := range ids {
-		idSet[id] = true
-	}
+// addEdge indexes an edge. The caller must hold the write lock.
+func (kg *KnowledgeGraph) addEdge(edge graph.Edge) {
+	kg.edges[edge.From] = append(kg.edges[edge.From], edge)
+	kg.reverse[edge.To] = append(kg.reverse[edge.To], edge)
+}
 
-	for from, edges := range kg.edges {
-		filtered := make([]graph.Edge, 0, len(edges))
-		for _, e := range edges {
-			if !idSet[e.To] {
-				filtered = append(filtered, e)
-			}
-		}
-		kg.edges[from] = filtered
+// removeEdge removes an edge from both indexes. The caller must hold the
+// write lock.
+func (kg *KnowledgeGraph) removeEdge(from, to, edgeType string) {
+	match := func(e graph.Edge) bool {
+		return e.From == from && e.To == to && e.Type == edgeType
 	}
+	kg.edges[from] = slices.DeleteFunc(kg.edges[from], match)
+	kg.reverse[to] = slices.DeleteFunc(kg.reverse[to], match)
+}
 
-	return nil
+// removeNodeEdges removes the edges from and to a node. The caller must hold
+// the write lock.
+func (kg *KnowledgeGraph) removeNodeEdges(id string) {
+	for _, e := range kg.edges[id] {
+		kg.reverse[e.To] = slices.DeleteFunc(kg.reverse[e.To], func(r graph.Edge) bool { return r.From == id })
+	}
+	for _, e := range kg.reverse[id] {
+		kg.edges[e.From] = slices.DeleteFunc(kg.edges[e.From], func(o graph.Edge) bool { return o.To == id })
+	}
+	delete(kg.edges, id)
+	delete(kg.reverse, id)
 }
 
 // NodeCount returns the number of nodes in the graph.
@@ -321,5 +524,9 @@ func containsString(slice []string, s string) bool {
 // Verify interface compliance
 var (
 	_ graph.KnowledgeGraph      = (*KnowledgeGraph)(nil)
+	_ graph.StreamingTraverser  = (*KnowledgeGraph)(nil)
+	_ graph.EmbeddingAwareGraph = (*KnowledgeGraph)(nil)
 	_ graph.BatchKnowledgeGraph = (*KnowledgeGraph)(nil)
+	_ graph.PathFinder          = (*KnowledgeGraph)(nil)
+	_ graph.EdgeLister          = (*KnowledgeGraph)(nil)
 )
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// GraphManager implements graph.GraphManager over a registry of named
+// in-memory graphs, for testing lifecycle code without a database. Node and
+// edge types and directedness are recorded but not enforced.
+type GraphManager struct {
+	mu     sync.RWMutex
+	graphs map[string]*KnowledgeGraph
+}
+
+// NewGraphManager creates a new in-memory graph manager.
+func NewGraphManager() *GraphManager {
+	return &GraphManager{graphs: make(map[string]*KnowledgeGraph)}
+}
+
+// Graph returns the graph with the given name, or nil if it doesn't exist.
+func (m *GraphManager) Graph(name string) *KnowledgeGraph {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.graphs[name]
+}
+
+// CreateGraph implements graph.GraphManager. Creating an existing graph
+// keeps its contents.
+func (m *GraphManager) CreateGraph(ctx context.Context, cfg graph.GraphConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("graph name is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.graphs[cfg.Name]; !ok {
+		m.graphs[cfg.Name] = NewKnowledgeGraph(cfg.Name)
+	}
+	return nil
+}
+
+// DropGraph implements graph.GraphManager. Dropping a missing graph is a
+// no-op.
+func (m *GraphManager) DropGraph(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.graphs, name)
+	return nil
+}
+
+// GraphExists implements graph.GraphManager.
+func (m *GraphManager) GraphExists(ctx context.Context, name string) (bool, error) {
+	return m.Graph(name) != nil, nil
+}
+
+// GraphStats implements graph.GraphManager. It returns
+// graph.ErrGraphNotFound for missing graphs.
+func (m *GraphManager) GraphStats(ctx context.Context, name string) (*graph.GraphStats, error) {
+	kg := m.Graph(name)
+	if kg == nil {
+		return nil, fmt.Errorf("%w: %s", graph.ErrGraphNotFound, name)
+	}
+
+	kg.mu.RLock()
+	defer kg.mu.RUnlock()
+	stats := &graph.GraphStats{
+		Name:          name,
+		NodeTypeStats: make(map[string]int64),
+		EdgeTypeStats: make(map[string]int64),
+	}
+	for _, node := range kg.nodes {
+		stats.NodeCount++
+		stats.NodeTypeStats[node.Type]++
+	}
+	for _, edges := range kg.edges {
+		for _, edge := range edges {
+			stats.EdgeCount++
+			stats.EdgeTypeStats[edge.Type]++
+		}
+	}
+	return stats, nil
+}
+
+// ListGraphs implements graph.GraphManager. Names are sorted.
+func (m *GraphManager) ListGraphs(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.graphs))
+	for name := range m.graphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Verify interface compliance
+var _ graph.GraphManager = (*GraphManager)(nil)
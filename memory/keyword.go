@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/agentplexus/omniretrieve/lexical"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2  // Term frequency saturation
+	bm25B  = 0.75 // Document length normalization
+)
+
+// KeywordIndex is an in-memory keyword index using BM25 scoring.
+type KeywordIndex struct {
+	mu       sync.RWMutex
+	name     string
+	docs     map[string]keywordDoc
+	postings map[string]map[string]int // Term -> document ID -> frequency
+	totalLen int
+}
+
+// keywordDoc is an indexed document with its term count.
+type keywordDoc struct {
+	doc    lexical.Document
+	length int
+}
+
+// NewKeywordIndex creates a new in-memory keyword index.
+func NewKeywordIndex(name string) *KeywordIndex {
+	return &KeywordIndex{
+		name:     name,
+		docs:     make(map[string]keywordDoc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Search implements lexical.KeywordIndex. Documents matching no query term
+// are not returned.
+func (idx *KeywordIndex) Search(ctx context.Context, query string, k int, filters map[string]string) ([]lexical.SearchResult, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.docs) == 0 {
+		return nil, nil
+	}
+	n := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / n
+
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, term := range lexical.Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.postings[term]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			if !matchesFilters(idx.docs[id].doc.Metadata, filters) {
+				continue
+			}
+			f := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(idx.docs[id].length)/avgLen
+			scores[id] += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+	}
+
+	results := make([]lexical.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, lexical.SearchResult{Document: idx.docs[id].doc, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Document.ID < results[j].Document.ID
+	})
+
+	if k < len(results) {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Insert implements lexical.KeywordIndex.
+func (idx *KeywordIndex) Insert(ctx context.Context, doc lexical.Document) error {
+	return idx.Upsert(ctx, doc)
+}
+
+// Upsert implements lexical.KeywordIndex.
+func (idx *KeywordIndex) Upsert(ctx context.Context, doc lexical.Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(doc.ID)
+	terms := lexical.Tokenize(doc.Content)
+	for _, term := range terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]int)
+		}
+		idx.postings[term][doc.ID]++
+	}
+	idx.docs[doc.ID] = keywordDoc{doc: doc, length: len(terms)}
+	idx.totalLen += len(terms)
+	return nil
+}
+
+// Delete implements lexical.KeywordIndex.
+func (idx *KeywordIndex) Delete(ctx context.Context, id string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+	return nil
+}
+
+// Name implements lexical.KeywordIndex.
+func (idx *KeywordIndex) Name() string {
+	return idx.name
+}
+
+// remove deletes a document and its postings. The caller holds the lock.
+func (idx *KeywordIndex) remove(id string) {
+	existing, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range lexical.Tokenize(existing.doc.Content) {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= existing.length
+	delete(idx.docs, id)
+}
+
+// Verify interface compliance
+var _ lexical.KeywordIndex = (*KeywordIndex)(nil)
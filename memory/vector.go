@@ -3,30 +3,171 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"sort"
 	"sync"
+	"time"
 
+	"github.com/agentplexus/omniretrieve/retrieve"
 	"github.com/agentplexus/omniretrieve/vector"
 )
 
-// VectorIndex is an in-memory vector index using brute-force search.
+// VectorIndex is an in-memory vector index using brute-force search. Node
+// IDs are unique per namespace. Its dimensions are those of the first
+// embedding written; other lengths fail with vector.ErrDimensionMismatch.
+// Expired nodes are hidden from reads until PurgeExpired removes them.
 type VectorIndex struct {
 	mu    sync.RWMutex
 	name  string
-	nodes map[string]vector.Node
+	nodes map[nodeKey]vector.Node
+	dims  int
+}
+
+// nodeKey identifies a node within its namespace.
+type nodeKey struct {
+	namespace string
+	id        string
 }
 
 // NewVectorIndex creates a new in-memory vector index.
 func NewVectorIndex(name string) *VectorIndex {
 	return &VectorIndex{
 		name:  name,
-		nodes: make(map[string]vector.Node),
+		nodes: make(map[nodeKey]vector.Node),
 	}
 }
 
+// OpenVectorIndex is a vector.IndexOpener that creates an empty in-memory
+// index for a collection.
+func OpenVectorIndex(ctx context.Context, cfg vector.IndexConfig) (vector.Index, error) {
+	return NewVectorIndex(cfg.Name), nil
+}
+
 // Search implements vector.Index.
 func (idx *VectorIndex) Search(ctx context.Context, embedding []float32, k int, filters map[string]string) ([]vector.SearchResult, error) {
+	if err := idx.checkQuery(embedding); err != nil {
+		return nil, err
+	}
+	return idx.search(ctx, cosineScorer(embedding), k, func(metadata map[string]string) bool {
+		return matchesFilters(metadata, filters)
+	}), nil
+}
+
+// SearchMulti implements vector.MultiVectorIndex by scoring every node with
+// vector.MaxSim.
+func (idx *VectorIndex) SearchMulti(ctx context.Context, embeddings [][]float32, k int, filters map[string]string) ([]vector.SearchResult, error) {
+	score := func(node vector.Node) float64 {
+		return vector.MaxSim(embeddings, vector.NodeEmbeddings(node))
+	}
+	return idx.search(ctx, score, k, func(metadata map[string]string) bool {
+		return matchesFilters(metadata, filters)
+	}), nil
+}
+
+// SearchSparse implements vector.SparseIndex. Only nodes scoring above 0,
+// which share a term with sparse, are returned.
+func (idx *VectorIndex) SearchSparse(ctx context.Context, sparse vector.SparseVector, k int, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	score := func(node vector.Node) float64 {
+		return sparse.Dot(node.SparseEmbedding)
+	}
+	results := idx.search(ctx, score, k, opts.Filter.Match)
+	n := sort.Search(len(results), func(i int) bool { return results[i].Score <= 0 })
+	return results[:n], nil
+}
+
+// SearchFilter implements vector.FilterIndex.
+func (idx *VectorIndex) SearchFilter(ctx context.Context, embedding []float32, k int, filter vector.Filter) ([]vector.SearchResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if err := idx.checkQuery(embedding); err != nil {
+		return nil, err
+	}
+	return idx.search(ctx, cosineScorer(embedding), k, filter.Match), nil
+}
+
+// SearchWithOptions implements vector.TunableIndex. Search is exact and
+// deletes are immediate, so EfSearch, Probes, and IncludeDeleted are ignored.
+// Every metric is supported, scored as by vector.Similarity.
+func (idx *VectorIndex) SearchWithOptions(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	score, err := idx.scorer(embedding, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.search(ctx, score, k, opts.Filter.Match), nil
+}
+
+// SearchPage implements vector.PageIndex. Each page ranks every node, so
+// pages are consistent with each other unless the index changes between
+// them.
+func (idx *VectorIndex) SearchPage(ctx context.Context, embedding []float32, cursor string, limit int, opts vector.SearchOptions) ([]vector.SearchResult, string, error) {
+	after, err := vector.ParsePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("invalid page limit %d", limit)
+	}
+	score, err := idx.scorer(embedding, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ranked := idx.search(ctx, score, math.MaxInt, opts.Filter.Match)
+	start := sort.Search(len(ranked), func(i int) bool { return after.Precedes(ranked[i]) })
+	page := ranked[start:min(start+limit, len(ranked))]
+	return page, vector.NextPageCursor(page, limit), nil
+}
+
+// SearchGroups implements vector.GroupIndex by grouping every ranked node.
+func (idx *VectorIndex) SearchGroups(ctx context.Context, embedding []float32, k int, groupBy string, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	score, err := idx.scorer(embedding, opts)
+	if err != nil {
+		return nil, err
+	}
+	ranked := idx.search(ctx, score, math.MaxInt, opts.Filter.Match)
+	return vector.GroupResults(ranked, groupBy, k), nil
+}
+
+// scorer validates a search with opts and returns the scorer of its metric.
+func (idx *VectorIndex) scorer(embedding []float32, opts vector.SearchOptions) (func(node vector.Node) float64, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if err := idx.checkQuery(embedding); err != nil {
+		return nil, err
+	}
+	switch opts.Metric {
+	case "", vector.DistanceCosine:
+		return cosineScorer(embedding), nil
+	case vector.DistanceEuclidean, vector.DistanceDot:
+		return func(node vector.Node) float64 {
+			return vector.Similarity(opts.Metric, embedding, node.Embedding)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", vector.ErrMetricUnsupported, opts.Metric)
+	}
+}
+
+// cosineScorer scores nodes by cosine similarity to embedding.
+func cosineScorer(embedding []float32) func(node vector.Node) float64 {
+	return func(node vector.Node) float64 {
+		return vector.Similarity(vector.DistanceCosine, embedding, node.Embedding)
+	}
+}
+
+// search returns the k highest scoring nodes in the context's namespace
+// whose metadata matches.
+func (idx *VectorIndex) search(ctx context.Context, score func(node vector.Node) float64, k int, match func(metadata map[string]string) bool) []vector.SearchResult {
+	namespace := vector.ContextNamespace(ctx)
+	now := time.Now()
+
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
@@ -39,17 +180,19 @@ func (idx *VectorIndex) Search(ctx context.Context, embedding []float32, k int,
 
 	for _, node := range idx.nodes {
 		// Apply filters
-		if !matchesFilters(node.Metadata, filters) {
+		if node.Namespace != namespace || node.Expired(now) || !match(node.Metadata) {
 			continue
 		}
 
-		score := cosineSimilarity(embedding, node.Embedding)
-		candidates = append(candidates, scored{node: node, score: score})
+		candidates = append(candidates, scored{node: node, score: score(node)})
 	}
 
-	// Sort by score descending
+	// Sort by score descending, breaking ties by ID
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].score > candidates[j].score
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].node.ID < candidates[j].node.ID
 	})
 
 	// Return top-k
@@ -65,14 +208,17 @@ func (idx *VectorIndex) Search(ctx context.Context, embedding []float32, k int,
 		}
 	}
 
-	return results, nil
+	return results
 }
 
 // Insert implements vector.Index.
 func (idx *VectorIndex) Insert(ctx context.Context, node vector.Node) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
-	idx.nodes[node.ID] = node
+	if err := idx.checkDimensions(node); err != nil {
+		return err
+	}
+	idx.put(ctx, node)
 	return nil
 }
 
@@ -80,18 +226,94 @@ func (idx *VectorIndex) Insert(ctx context.Context, node vector.Node) error {
 func (idx *VectorIndex) Upsert(ctx context.Context, node vector.Node) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
-	idx.nodes[node.ID] = node
+	if err := idx.checkDimensions(node); err != nil {
+		return err
+	}
+	idx.put(ctx, node)
 	return nil
 }
 
+// put stores node in its namespace. The caller must hold the write lock.
+func (idx *VectorIndex) put(ctx context.Context, node vector.Node) {
+	node.Namespace = vector.NodeNamespace(ctx, node)
+	idx.nodes[nodeKey{node.Namespace, node.ID}] = node
+	if idx.dims == 0 {
+		idx.dims = len(node.Embedding)
+	}
+}
+
+// checkDimensions returns an error wrapping vector.ErrDimensionMismatch for
+// the first node whose embedding doesn't match the index's dimensions, or
+// those of the first embedded node if the index has none yet. Nodes without
+// an embedding are accepted. The caller must hold the write lock.
+func (idx *VectorIndex) checkDimensions(nodes ...vector.Node) error {
+	dims := idx.dims
+	for _, node := range nodes {
+		if len(node.Embedding) == 0 {
+			continue
+		}
+		if dims == 0 {
+			dims = len(node.Embedding)
+		}
+		if len(node.Embedding) != dims {
+			return fmt.Errorf("%w: node %s has %d dimensions, expected %d",
+				vector.ErrDimensionMismatch, node.ID, len(node.Embedding), dims)
+		}
+	}
+	return nil
+}
+
+// checkQuery returns an error wrapping vector.ErrDimensionMismatch if a
+// query embedding doesn't match the index's dimensions.
+func (idx *VectorIndex) checkQuery(embedding []float32) error {
+	idx.mu.RLock()
+	dims := idx.dims
+	idx.mu.RUnlock()
+	if dims != 0 && len(embedding) != dims {
+		return fmt.Errorf("%w: query has %d dimensions, expected %d",
+			vector.ErrDimensionMismatch, len(embedding), dims)
+	}
+	return nil
+}
+
+// nodeKeyOf returns the key of id in the context's namespace.
+func nodeKeyOf(ctx context.Context, id string) nodeKey {
+	return nodeKey{vector.ContextNamespace(ctx), id}
+}
+
 // Delete implements vector.Index.
 func (idx *VectorIndex) Delete(ctx context.Context, id string) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
-	delete(idx.nodes, id)
+	delete(idx.nodes, nodeKeyOf(ctx, id))
 	return nil
 }
 
+// Get implements vector.GetIndex.
+func (idx *VectorIndex) Get(ctx context.Context, id string) (vector.Node, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	node, ok := idx.nodes[nodeKeyOf(ctx, id)]
+	if !ok || node.Expired(time.Now()) {
+		return vector.Node{}, vector.ErrNodeNotFound
+	}
+	return node, nil
+}
+
+// GetBatch implements vector.GetIndex.
+func (idx *VectorIndex) GetBatch(ctx context.Context, ids []string) ([]vector.Node, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	now := time.Now()
+	nodes := make([]vector.Node, 0, len(ids))
+	for _, id := range ids {
+		if node, ok := idx.nodes[nodeKeyOf(ctx, id)]; ok && !node.Expired(now) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
 // Name implements vector.Index.
 func (idx *VectorIndex) Name() string {
 	return idx.name
@@ -101,8 +323,11 @@ func (idx *VectorIndex) Name() string {
 func (idx *VectorIndex) InsertBatch(ctx context.Context, nodes []vector.Node) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
+	if err := idx.checkDimensions(nodes...); err != nil {
+		return err
+	}
 	for _, node := range nodes {
-		idx.nodes[node.ID] = node
+		idx.put(ctx, node)
 	}
 	return nil
 }
@@ -111,8 +336,11 @@ func (idx *VectorIndex) InsertBatch(ctx context.Context, nodes []vector.Node) er
 func (idx *VectorIndex) UpsertBatch(ctx context.Context, nodes []vector.Node) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
+	if err := idx.checkDimensions(nodes...); err != nil {
+		return err
+	}
 	for _, node := range nodes {
-		idx.nodes[node.ID] = node
+		idx.put(ctx, node)
 	}
 	return nil
 }
@@ -122,36 +350,86 @@ func (idx *VectorIndex) DeleteBatch(ctx context.Context, ids []string) error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 	for _, id := range ids {
-		delete(idx.nodes, id)
+		delete(idx.nodes, nodeKeyOf(ctx, id))
 	}
 	return nil
 }
 
-// Count returns the number of nodes in the index.
-func (idx *VectorIndex) Count() int {
+// Namespaces implements vector.NamespaceIndex.
+func (idx *VectorIndex) Namespaces(ctx context.Context) ([]string, error) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
-	return len(idx.nodes)
+	seen := make(map[string]bool)
+	for k := range idx.nodes {
+		seen[k.namespace] = true
+	}
+	return slices.Sorted(maps.Keys(seen)), nil
+}
+
+// Exists implements vector.IntrospectableIndex.
+func (idx *VectorIndex) Exists(ctx context.Context, id string) (bool, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	node, ok := idx.nodes[nodeKeyOf(ctx, id)]
+	return ok && !node.Expired(time.Now()), nil
 }
 
-// cosineSimilarity calculates the cosine similarity between two vectors.
-func cosineSimilarity(a, b []float32) float64 {
-	if len(a) != len(b) || len(a) == 0 {
-		return 0
+// PurgeExpired implements vector.ExpiringIndex.
+func (idx *VectorIndex) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	var purged int64
+	for k, node := range idx.nodes {
+		if node.Expired(now) {
+			delete(idx.nodes, k)
+			purged++
+		}
 	}
+	return purged, nil
+}
 
-	var dotProduct, normA, normB float64
-	for i := range a {
-		dotProduct += float64(a[i]) * float64(b[i])
-		normA += float64(a[i]) * float64(a[i])
-		normB += float64(b[i]) * float64(b[i])
+// Compact implements retrieve.Compactor by purging expired nodes and
+// rebuilding the node map, since Go maps don't release the space of
+// deleted entries.
+func (idx *VectorIndex) Compact(ctx context.Context) error {
+	now := time.Now()
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	nodes := make(map[nodeKey]vector.Node, len(idx.nodes))
+	for k, node := range idx.nodes {
+		if !node.Expired(now) {
+			nodes[k] = node
+		}
 	}
+	idx.nodes = nodes
+	return nil
+}
 
-	if normA == 0 || normB == 0 {
-		return 0
+// Describe implements vector.IntrospectableIndex. Count is the number of
+// nodes in the context's namespace.
+func (idx *VectorIndex) Describe(ctx context.Context) (vector.IndexDescription, error) {
+	namespace := vector.ContextNamespace(ctx)
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var count int64
+	for k := range idx.nodes {
+		if k.namespace == namespace {
+			count++
+		}
 	}
+	return vector.IndexDescription{
+		Name:           idx.name,
+		Dimensions:     idx.dims,
+		DistanceMetric: vector.DistanceCosine,
+		Count:          count,
+	}, nil
+}
 
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+// Count returns the number of nodes in the index, across namespaces.
+func (idx *VectorIndex) Count() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.nodes)
 }
 
 // matchesFilters checks if metadata matches all filters.
@@ -166,6 +444,18 @@ func matchesFilters(metadata, filters map[string]string) bool {
 
 // Verify interface compliance
 var (
-	_ vector.Index      = (*VectorIndex)(nil)
-	_ vector.BatchIndex = (*VectorIndex)(nil)
+	_ vector.Index               = (*VectorIndex)(nil)
+	_ vector.BatchIndex          = (*VectorIndex)(nil)
+	_ vector.FilterIndex         = (*VectorIndex)(nil)
+	_ vector.TunableIndex        = (*VectorIndex)(nil)
+	_ vector.GetIndex            = (*VectorIndex)(nil)
+	_ vector.MultiVectorIndex    = (*VectorIndex)(nil)
+	_ vector.NamespaceIndex      = (*VectorIndex)(nil)
+	_ vector.PageIndex           = (*VectorIndex)(nil)
+	_ vector.IntrospectableIndex = (*VectorIndex)(nil)
+	_ vector.GroupIndex          = (*VectorIndex)(nil)
+	_ vector.SparseIndex         = (*VectorIndex)(nil)
+	_ vector.ExpiringIndex       = (*VectorIndex)(nil)
+	_ retrieve.Compactor         = (*VectorIndex)(nil)
+	_ vector.IndexOpener         = OpenVectorIndex
 )
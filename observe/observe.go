@@ -19,9 +19,11 @@ type SpanType string
 const (
 	SpanTypeRetrieval     SpanType = "retrieval"
 	SpanTypeVectorSearch  SpanType = "retrieve.vector.search"
+	SpanTypeKeywordSearch SpanType = "retrieve.keyword.search"
 	SpanTypeGraphTraverse SpanType = "retrieve.graph.traverse"
 	SpanTypeHybridMerge   SpanType = "retrieve.hybrid.merge"
 	SpanTypeRerank        SpanType = "retrieve.rerank"
+	SpanTypeMaintenance   SpanType = "retrieve.maintenance"
 )
 
 // Span represents a traced operation.
@@ -44,12 +46,24 @@ type Span struct {
 	Attributes map[string]any
 	// Artifacts are larger objects attached to this span.
 	Artifacts map[string]any
+	// Events are timestamped occurrences within this span.
+	Events []SpanEvent
 	// Status indicates success or failure.
 	Status SpanStatus
 	// Error contains error details if Status is Error.
 	Error string
 }
 
+// SpanEvent is a timestamped occurrence within a span, such as a retrieved document.
+type SpanEvent struct {
+	// Name identifies the event type.
+	Name string
+	// Time is when the event occurred.
+	Time time.Time
+	// Attributes are key-value pairs for this event.
+	Attributes map[string]any
+}
+
 // SpanStatus indicates the outcome of a span.
 type SpanStatus string
 
@@ -145,6 +159,8 @@ func (o *Observer) OnRetrieveStart(ctx context.Context, q retrieve.Query) contex
 			"retrieval.top_k":      q.TopK,
 			"retrieval.modes":      q.Modes,
 			"retrieval.min_score":  q.MinScore,
+			AttrGenAIOperationName: OperationRetrieve,
+			AttrGenAIRequestTopK:   q.TopK,
 		},
 		Artifacts: make(map[string]any),
 		Status:    SpanStatusOK,
@@ -186,7 +202,10 @@ func (o *Observer) OnRetrieveEnd(ctx context.Context, r *retrieve.Result, err er
 		span.Attributes["retrieval.latency_ms"] = r.Metadata.LatencyMS
 		span.Attributes["retrieval.modes_used"] = r.Metadata.ModesUsed
 		span.Attributes["retrieval.cache_hit"] = r.Metadata.CacheHit
+		span.Attributes["retrieval.partial_result"] = r.Metadata.PartialResult
+		span.Attributes[AttrGenAIRetrievalDocumentCount] = len(r.Items)
 		span.Artifacts["retrieved.context"] = summarizeItems(r.Items)
+		span.Events = append(span.Events, documentEvents(r.Items, span.EndTime)...)
 	}
 
 	// Export spans for this trace
@@ -215,10 +234,51 @@ func (o *Observer) OnVectorSearch(ctx context.Context, backend string, topK int,
 		StartTime: time.Now().Add(-time.Duration(latencyMS) * time.Millisecond),
 		EndTime:   time.Now(),
 		Attributes: map[string]any{
-			"vector.backend":      backend,
-			"vector.top_k":        topK,
-			"vector.result_count": resultCount,
-			"vector.latency_ms":   latencyMS,
+			"vector.backend":       backend,
+			"vector.top_k":         topK,
+			"vector.result_count":  resultCount,
+			"vector.latency_ms":    latencyMS,
+			AttrGenAIOperationName: OperationVectorSearch,
+			AttrGenAIDataSourceID:  backend,
+			AttrGenAIRequestTopK:   topK,
+		},
+		Artifacts: make(map[string]any),
+		Status:    SpanStatusOK,
+	}
+
+	o.spans[spanID] = span
+	o.traces[sc.TraceID] = append(o.traces[sc.TraceID], spanID)
+}
+
+// OnKeywordSearch implements retrieve.KeywordObserver.
+//
+//nolint:dupl // Similar structure to OnVectorSearch, but different attributes
+func (o *Observer) OnKeywordSearch(ctx context.Context, backend string, topK int, resultCount int, latencyMS int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	sc := FromContext(ctx)
+	if sc == nil {
+		return
+	}
+
+	spanID := generateID()
+	span := &Span{
+		ID:        spanID,
+		TraceID:   sc.TraceID,
+		ParentID:  sc.SpanID,
+		Type:      SpanTypeKeywordSearch,
+		Name:      "retrieve.keyword.search",
+		StartTime: time.Now().Add(-time.Duration(latencyMS) * time.Millisecond),
+		EndTime:   time.Now(),
+		Attributes: map[string]any{
+			"keyword.backend":      backend,
+			"keyword.top_k":        topK,
+			"keyword.result_count": resultCount,
+			"keyword.latency_ms":   latencyMS,
+			AttrGenAIOperationName: OperationKeywordSearch,
+			AttrGenAIDataSourceID:  backend,
+			AttrGenAIRequestTopK:   topK,
 		},
 		Artifacts: make(map[string]any),
 		Status:    SpanStatusOK,
@@ -228,6 +288,30 @@ func (o *Observer) OnVectorSearch(ctx context.Context, backend string, topK int,
 	o.traces[sc.TraceID] = append(o.traces[sc.TraceID], spanID)
 }
 
+// OnEmbeddingCache implements retrieve.EmbeddingCacheObserver. Hits and
+// misses are counted on the active span.
+func (o *Observer) OnEmbeddingCache(ctx context.Context, model string, hit bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	sc := FromContext(ctx)
+	if sc == nil {
+		return
+	}
+	span, ok := o.spans[sc.SpanID]
+	if !ok {
+		return
+	}
+
+	attr := "embedding_cache.misses"
+	if hit {
+		attr = "embedding_cache.hits"
+	}
+	count, _ := span.Attributes[attr].(int)
+	span.Attributes[attr] = count + 1
+	span.Attributes["embedding_cache.model"] = model
+}
+
 // OnGraphTraverse implements retrieve.Observer.
 //
 //nolint:dupl // Similar structure to OnVectorSearch/OnRerank, but different attributes
@@ -250,14 +334,53 @@ func (o *Observer) OnGraphTraverse(ctx context.Context, backend string, depth in
 		StartTime: time.Now().Add(-time.Duration(latencyMS) * time.Millisecond),
 		EndTime:   time.Now(),
 		Attributes: map[string]any{
-			"graph.backend":    backend,
-			"graph.depth":      depth,
-			"graph.node_count": nodeCount,
-			"graph.latency_ms": latencyMS,
+			"graph.backend":        backend,
+			"graph.depth":          depth,
+			"graph.node_count":     nodeCount,
+			"graph.latency_ms":     latencyMS,
+			AttrGenAIOperationName: OperationGraphTraverse,
+			AttrGenAIDataSourceID:  backend,
+		},
+		Artifacts: make(map[string]any),
+		Status:    SpanStatusOK,
+	}
+
+	o.spans[spanID] = span
+	o.traces[sc.TraceID] = append(o.traces[sc.TraceID], spanID)
+}
+
+// OnHybridMerge implements retrieve.MergeObserver.
+func (o *Observer) OnHybridMerge(ctx context.Context, fusion string, branchCounts map[string]int, dedupCount int, outputCount int, latencyMS int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	sc := FromContext(ctx)
+	if sc == nil {
+		return
+	}
+
+	spanID := generateID()
+	span := &Span{
+		ID:        spanID,
+		TraceID:   sc.TraceID,
+		ParentID:  sc.SpanID,
+		Type:      SpanTypeHybridMerge,
+		Name:      "retrieve.hybrid.merge",
+		StartTime: time.Now().Add(-time.Duration(latencyMS) * time.Millisecond),
+		EndTime:   time.Now(),
+		Attributes: map[string]any{
+			"hybrid.fusion":        fusion,
+			"hybrid.dedup_count":   dedupCount,
+			"hybrid.output_count":  outputCount,
+			"hybrid.latency_ms":    latencyMS,
+			AttrGenAIOperationName: OperationHybridMerge,
 		},
 		Artifacts: make(map[string]any),
 		Status:    SpanStatusOK,
 	}
+	for branch, count := range branchCounts {
+		span.Attributes["hybrid.branch."+branch+".count"] = count
+	}
 
 	o.spans[spanID] = span
 	o.traces[sc.TraceID] = append(o.traces[sc.TraceID], spanID)
@@ -289,6 +412,8 @@ func (o *Observer) OnRerank(ctx context.Context, model string, inputCount int, o
 			"reranker.input_count":  inputCount,
 			"reranker.output_count": outputCount,
 			"reranker.latency_ms":   latencyMS,
+			AttrGenAIOperationName:  OperationRerank,
+			AttrGenAIRequestModel:   model,
 		},
 		Artifacts: make(map[string]any),
 		Status:    SpanStatusOK,
@@ -298,6 +423,39 @@ func (o *Observer) OnRerank(ctx context.Context, model string, inputCount int, o
 	o.traces[sc.TraceID] = append(o.traces[sc.TraceID], spanID)
 }
 
+// OnMaintenance implements retrieve.MaintenanceObserver. Maintenance runs
+// outside any retrieval, so each action is exported as its own trace.
+func (o *Observer) OnMaintenance(ctx context.Context, target string, action string, reason string, err error, latencyMS int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	spanID := generateID()
+	span := &Span{
+		ID:        spanID,
+		TraceID:   spanID,
+		Type:      SpanTypeMaintenance,
+		Name:      "retrieve.maintenance",
+		StartTime: time.Now().Add(-time.Duration(latencyMS) * time.Millisecond),
+		EndTime:   time.Now(),
+		Attributes: map[string]any{
+			"maintenance.target":     target,
+			"maintenance.action":     action,
+			"maintenance.reason":     reason,
+			"maintenance.latency_ms": latencyMS,
+		},
+		Artifacts: make(map[string]any),
+		Status:    SpanStatusOK,
+	}
+	if err != nil {
+		span.Status = SpanStatusError
+		span.Error = err.Error()
+	}
+
+	o.spans[spanID] = span
+	o.traces[spanID] = []string{spanID}
+	o.exportTrace(ctx, spanID)
+}
+
 // exportTrace exports all spans for a trace.
 func (o *Observer) exportTrace(ctx context.Context, traceID string) {
 	spanIDs, ok := o.traces[traceID]
@@ -378,4 +536,8 @@ func (n *NoOpObserver) OnRerank(_ context.Context, _ string, _ int, _ int, _ int
 
 // Verify interface compliance
 var _ retrieve.Observer = (*Observer)(nil)
+var _ retrieve.MaintenanceObserver = (*Observer)(nil)
+var _ retrieve.KeywordObserver = (*Observer)(nil)
+var _ retrieve.EmbeddingCacheObserver = (*Observer)(nil)
+var _ retrieve.MergeObserver = (*Observer)(nil)
 var _ retrieve.Observer = (*NoOpObserver)(nil)
@@ -53,6 +53,14 @@ func TestObserver(t *testing.T) {
 	// Report graph traverse
 	observer.OnGraphTraverse(ctx, "test-graph", 2, 3, 50)
 
+	// Report keyword search
+	observer.OnKeywordSearch(ctx, "test-keywords", 10, 4, 20)
+
+	// Report query embedding cache lookups
+	observer.OnEmbeddingCache(ctx, "test-model", false)
+	observer.OnEmbeddingCache(ctx, "test-model", true)
+	observer.OnEmbeddingCache(ctx, "test-model", true)
+
 	// End retrieval
 	result := &retrieve.Result{
 		Items: []retrieve.ContextItem{
@@ -69,8 +77,8 @@ func TestObserver(t *testing.T) {
 
 	// Verify spans were exported
 	spans := exporter.Spans()
-	if len(spans) != 3 {
-		t.Errorf("expected 3 spans, got %d", len(spans))
+	if len(spans) != 4 {
+		t.Errorf("expected 4 spans, got %d", len(spans))
 	}
 
 	// Check span types
@@ -88,6 +96,18 @@ func TestObserver(t *testing.T) {
 	if !spanTypes[observe.SpanTypeGraphTraverse] {
 		t.Error("expected graph traverse span")
 	}
+	if !spanTypes[observe.SpanTypeKeywordSearch] {
+		t.Error("expected keyword search span")
+	}
+
+	for _, span := range spans {
+		if span.Type != observe.SpanTypeRetrieval {
+			continue
+		}
+		if span.Attributes["embedding_cache.hits"] != 2 || span.Attributes["embedding_cache.misses"] != 1 {
+			t.Errorf("expected 2 embedding cache hits and 1 miss, got %v", span.Attributes)
+		}
+	}
 }
 
 func TestObserverTraceContext(t *testing.T) {
@@ -154,3 +174,94 @@ func TestObserverWithError(t *testing.T) {
 		t.Error("expected error message")
 	}
 }
+
+func TestObserverGenAIConventions(t *testing.T) {
+	exporter := &mockExporter{}
+	observer := observe.NewObserver(observe.ObserverConfig{
+		Exporters: []observe.SpanExporter{exporter},
+	})
+
+	ctx := observer.OnRetrieveStart(context.Background(), retrieve.Query{Text: "test", TopK: 5})
+	observer.OnVectorSearch(ctx, "docs", 5, 2, 10)
+	observer.OnRetrieveEnd(ctx, &retrieve.Result{
+		Items: []retrieve.ContextItem{
+			{ID: "a", Score: 0.9, Source: "s1"},
+			{ID: "b", Score: 0.7, Source: "s2"},
+		},
+	}, nil)
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	for _, span := range spans {
+		switch span.Type {
+		case observe.SpanTypeRetrieval:
+			if span.Attributes[observe.AttrGenAIOperationName] != observe.OperationRetrieve {
+				t.Errorf("unexpected operation name: %v", span.Attributes[observe.AttrGenAIOperationName])
+			}
+			if len(span.Events) != 2 {
+				t.Fatalf("expected 2 document events, got %d", len(span.Events))
+			}
+			event := span.Events[1]
+			if event.Name != observe.EventRetrievalDocument ||
+				event.Attributes[observe.AttrGenAIRetrievalDocumentID] != "b" ||
+				event.Attributes[observe.AttrGenAIRetrievalDocumentScore] != 0.7 ||
+				event.Attributes[observe.AttrGenAIRetrievalDocumentRank] != 1 {
+				t.Errorf("unexpected document event: %+v", event)
+			}
+		case observe.SpanTypeVectorSearch:
+			if span.Attributes[observe.AttrGenAIOperationName] != observe.OperationVectorSearch {
+				t.Errorf("unexpected operation name: %v", span.Attributes[observe.AttrGenAIOperationName])
+			}
+			if span.Attributes[observe.AttrGenAIDataSourceID] != "docs" {
+				t.Errorf("unexpected data source: %v", span.Attributes[observe.AttrGenAIDataSourceID])
+			}
+		}
+	}
+}
+
+func TestObserverMaintenance(t *testing.T) {
+	exporter := &mockExporter{}
+	observer := observe.NewObserver(observe.ObserverConfig{
+		Exporters: []observe.SpanExporter{exporter},
+	})
+
+	observer.OnMaintenance(context.Background(), "docs", "rebuild", "grew", nil, 250)
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Type != observe.SpanTypeMaintenance || span.Status != observe.SpanStatusOK {
+		t.Errorf("unexpected span: %+v", span)
+	}
+	if span.Attributes["maintenance.target"] != "docs" || span.Attributes["maintenance.action"] != "rebuild" {
+		t.Errorf("unexpected attributes: %v", span.Attributes)
+	}
+}
+
+func TestObserverHybridMerge(t *testing.T) {
+	exporter := &mockExporter{}
+	observer := observe.NewObserver(observe.ObserverConfig{
+		Exporters: []observe.SpanExporter{exporter},
+	})
+
+	ctx := observer.OnRetrieveStart(context.Background(), retrieve.Query{Text: "q"})
+	observer.OnHybridMerge(ctx, "rrf", map[string]int{"vector": 3, "graph": 2}, 1, 4, 5)
+	observer.OnRetrieveEnd(ctx, &retrieve.Result{}, nil)
+
+	for _, span := range exporter.Spans() {
+		if span.Type != observe.SpanTypeHybridMerge {
+			continue
+		}
+		if span.Attributes["hybrid.fusion"] != "rrf" || span.Attributes["hybrid.dedup_count"] != 1 ||
+			span.Attributes["hybrid.branch.vector.count"] != 3 || span.Attributes["hybrid.branch.graph.count"] != 2 {
+			t.Errorf("unexpected attributes: %v", span.Attributes)
+		}
+		return
+	}
+	t.Error("expected hybrid merge span")
+}
@@ -0,0 +1,66 @@
+package observe
+
+import (
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// OpenTelemetry GenAI semantic convention attribute names. These are emitted
+// alongside the OmniRetrieve-specific attributes so traces interoperate with
+// dashboards that key off the standard names.
+const (
+	// AttrGenAIOperationName is the name of the operation being performed.
+	AttrGenAIOperationName = "gen_ai.operation.name"
+	// AttrGenAIRequestModel is the model used by the operation (e.g., a reranker).
+	AttrGenAIRequestModel = "gen_ai.request.model"
+	// AttrGenAIRequestTopK is the requested number of results.
+	AttrGenAIRequestTopK = "gen_ai.request.top_k"
+	// AttrGenAIDataSourceID identifies the index or graph queried.
+	AttrGenAIDataSourceID = "gen_ai.data_source.id"
+	// AttrGenAIRetrievalDocumentCount is the number of documents returned.
+	AttrGenAIRetrievalDocumentCount = "gen_ai.retrieval.document.count"
+)
+
+// Attribute names used on retrieved document events.
+const (
+	// AttrGenAIRetrievalDocumentID is the ID of a retrieved document.
+	AttrGenAIRetrievalDocumentID = "gen_ai.retrieval.document.id"
+	// AttrGenAIRetrievalDocumentScore is the relevance score of a retrieved document.
+	AttrGenAIRetrievalDocumentScore = "gen_ai.retrieval.document.score"
+	// AttrGenAIRetrievalDocumentRank is the zero-based rank of a retrieved document.
+	AttrGenAIRetrievalDocumentRank = "gen_ai.retrieval.document.rank"
+	// AttrGenAIRetrievalDocumentSource is the source of a retrieved document.
+	AttrGenAIRetrievalDocumentSource = "gen_ai.retrieval.document.source"
+)
+
+// Operation names reported in AttrGenAIOperationName.
+const (
+	OperationRetrieve      = "retrieve"
+	OperationVectorSearch  = "vector_search"
+	OperationKeywordSearch = "keyword_search"
+	OperationGraphTraverse = "graph_traverse"
+	OperationHybridMerge   = "hybrid_merge"
+	OperationRerank        = "rerank"
+)
+
+// EventRetrievalDocument is the name of the event recorded for each retrieved document.
+const EventRetrievalDocument = "gen_ai.retrieval.document"
+
+// documentEvents creates one event per retrieved item, in rank order.
+func documentEvents(items []retrieve.ContextItem, at time.Time) []SpanEvent {
+	events := make([]SpanEvent, len(items))
+	for i, item := range items {
+		events[i] = SpanEvent{
+			Name: EventRetrievalDocument,
+			Time: at,
+			Attributes: map[string]any{
+				AttrGenAIRetrievalDocumentID:     item.ID,
+				AttrGenAIRetrievalDocumentScore:  item.Score,
+				AttrGenAIRetrievalDocumentRank:   i,
+				AttrGenAIRetrievalDocumentSource: item.Source,
+			},
+		}
+	}
+	return events
+}
@@ -0,0 +1,60 @@
+// Package pggraph provides a PostgreSQL implementation of OmniRetrieve's
+// graph.KnowledgeGraph interface, so GraphRAG can run on the same database
+// as pgvector.
+//
+// # Features
+//
+//   - Full graph.KnowledgeGraph, graph.BatchKnowledgeGraph, and
+//     graph.GraphManager support
+//   - Breadth-first traversal with one query per level, honoring depth,
+//     edge and node types, minimum edge weight, edge metadata, and a node
+//     limit
+//   - Outgoing, incoming, or undirected traversal (TraversalOptions.Direction, Undirected)
+//   - Point-in-time traversal over edge validity intervals
+//     (TraversalOptions.AsOf)
+//   - Streaming traversal that scans rows as they are visited
+//     (graph.StreamingTraverser)
+//   - Weighted shortest and k-shortest paths (graph.PathFinder)
+//   - Edge listing for whole-graph analytics such as centrality
+//     (graph.EdgeLister)
+//   - Node lookup by type and metadata, served by a JSONB GIN index
+//   - Batch writes in a single statement per batch
+//   - Node and edge types restricted with CHECK constraints (CreateGraph)
+//
+// # Schema
+//
+// A graph named "kg" is stored in two tables:
+//
+//	CREATE TABLE kg_nodes (
+//		id TEXT PRIMARY KEY,
+//		type TEXT NOT NULL DEFAULT '',
+//		content TEXT,
+//		source TEXT,
+//		metadata JSONB NOT NULL DEFAULT '{}'::jsonb
+//	);
+//	CREATE TABLE kg_edges (
+//		from_id TEXT NOT NULL,
+//		to_id TEXT NOT NULL,
+//		type TEXT NOT NULL DEFAULT '',
+//		weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+//		metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+//		valid_from TIMESTAMPTZ,
+//		valid_to TIMESTAMPTZ,
+//		PRIMARY KEY (from_id, to_id, type)
+//	);
+//
+// Edges don't reference nodes with foreign keys, so edges may be loaded
+// before their nodes; traversals only reach nodes that exist. Deleting a
+// node deletes its edges. Edge tables created without the validity columns
+// gain them when the graph is opened with CreateTablesIfNotExist.
+//
+// # Usage
+//
+//	db, _ := sql.Open("postgres", dsn)
+//	kg, err := pggraph.New(db, pggraph.DefaultConfig("kg"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	retriever := graph.NewRetriever(graph.RetrieverConfig{Graph: kg})
+package pggraph
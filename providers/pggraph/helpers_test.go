@@ -0,0 +1,127 @@
+package pggraph
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+func TestParseGraphRef(t *testing.T) {
+	ref, err := parseGraphRef("", "kg.docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.nodes() != `"kg"."docs_nodes"` || ref.edges() != `"kg"."docs_edges"` || ref.String() != "kg.docs" {
+		t.Errorf("unexpected tables: %s, %s", ref.nodes(), ref.edges())
+	}
+
+	ref, err = parseGraphRef("", "docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.nodes() != `"docs_nodes"` {
+		t.Errorf("unexpected node table: %s", ref.nodes())
+	}
+
+	for _, name := range []string{"", ".docs", "kg.", "a.b.c"} {
+		if _, err := parseGraphRef("", name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+	if _, err := parseGraphRef("kg", "other.docs"); err == nil {
+		t.Error("expected error for qualified name with schema")
+	}
+}
+
+func TestTypeCheck(t *testing.T) {
+	if got := typeCheck(nil); got != "" {
+		t.Errorf("expected no constraint, got %q", got)
+	}
+	if got, want := typeCheck([]string{"concept", "it's"}), ` CHECK (type IN ('concept', 'it''s'))`; got != want {
+		t.Errorf("typeCheck = %q, want %q", got, want)
+	}
+}
+
+func TestLastByKey(t *testing.T) {
+	nodes := []graph.Node{{ID: "a", Content: "1"}, {ID: "b"}, {ID: "a", Content: "2"}}
+	got := lastByKey(nodes, func(n graph.Node) string { return n.ID })
+	if len(got) != 2 || got[0].ID != "b" || got[1].Content != "2" {
+		t.Errorf("unexpected nodes: %+v", got)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	s, err := marshalMetadata(nil)
+	if err != nil || s != "{}" {
+		t.Errorf("marshalMetadata(nil) = %q, %v", s, err)
+	}
+	m, err := unmarshalMetadata([]byte(`{"lang":"en"}`))
+	if err != nil || m["lang"] != "en" {
+		t.Errorf("unmarshalMetadata = %v, %v", m, err)
+	}
+	if m, err := unmarshalMetadata([]byte(`{}`)); err != nil || m != nil {
+		t.Errorf("expected nil metadata, got %v, %v", m, err)
+	}
+}
+
+func TestTraverseQuery(t *testing.T) {
+	const reverse = `SELECT to_id AS src, from_id AS dst, from_id, to_id, type, weight, metadata, valid_from, valid_to FROM "kg_edges"`
+	g := &Graph{graph: graphRef{name: "kg"}}
+	if q := g.traverseQuery(""); strings.Contains(q, reverse) {
+		t.Errorf("directed query follows reverse edges: %s", q)
+	}
+	if q := g.traverseQuery(graph.DirectionIn); !strings.Contains(q, "AS (SELECT to_id AS src") || strings.Contains(q, "UNION ALL SELECT") {
+		t.Errorf("incoming query doesn't follow only reverse edges: %s", q)
+	}
+	if q := g.traverseQuery(graph.DirectionBoth); !strings.Contains(q, "UNION ALL "+reverse) {
+		t.Errorf("bidirectional query doesn't follow reverse edges: %s", q)
+	}
+
+	g.config.Undirected = true
+	if q := g.traverseQuery(graph.DirectionOut); !strings.Contains(q, "UNION ALL "+reverse) {
+		t.Errorf("undirected query doesn't follow reverse edges: %s", q)
+	}
+
+	// Each level reaches every node once and skips reached nodes
+	if q := g.traverseQuery(""); !strings.Contains(q, "DISTINCT ON (h.dst)") || !strings.Contains(q, "h.dst <> ALL ($2::text[])") {
+		t.Errorf("level query doesn't deduplicate reached nodes: %s", q)
+	}
+}
+
+func TestNeighborsQuery(t *testing.T) {
+	g := &Graph{graph: graphRef{name: "kg"}}
+	if q := g.neighborsQuery(); strings.Contains(q, "UNION ALL") || !strings.Contains(q, `JOIN "kg_nodes" n ON n.id = e.to_id`) {
+		t.Errorf("unexpected directed query: %s", q)
+	}
+
+	g.config.Undirected = true
+	if q := g.neighborsQuery(); !strings.Contains(q, "SELECT e.to_id, e.from_id, e.type") || !strings.Contains(q, "WHERE e.to_id = $1") {
+		t.Errorf("undirected query doesn't follow reverse edges: %s", q)
+	}
+}
+
+func TestTimeArg(t *testing.T) {
+	if got := timeArg(time.Time{}); got != "" {
+		t.Errorf("expected the zero time to encode as NULL, got %q", got)
+	}
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 500, time.FixedZone("CET", 3600))
+	if got := timeArg(ts); got != "2024-03-01T11:00:00.0000005Z" {
+		t.Errorf("timeArg = %q", got)
+	}
+}
+
+func TestAdjacency(t *testing.T) {
+	tests := map[graph.Direction]string{
+		"":                  "from_id = $1",
+		graph.DirectionOut:  "from_id = $1",
+		graph.DirectionIn:   "to_id = $1",
+		graph.DirectionBoth: "(from_id = $1 OR to_id = $1)",
+	}
+	for direction, want := range tests {
+		if got := adjacency(direction); got != want {
+			t.Errorf("adjacency(%q) = %q, want %q", direction, got, want)
+		}
+	}
+}
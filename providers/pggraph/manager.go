@@ -0,0 +1,176 @@
+package pggraph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/graph"
+)
+
+// Manager implements graph.GraphManager for PostgreSQL. Graph names may be
+// schema-qualified as "schema.name".
+type Manager struct {
+	db     *sql.DB
+	schema string
+}
+
+// NewManager creates a new graph manager. Unqualified graph names resolve
+// through search_path, and ListGraphs lists the current schema.
+func NewManager(db *sql.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// NewSchemaManager creates a new graph manager whose unqualified graph names
+// refer to tables in schema.
+func NewSchemaManager(db *sql.DB, schema string) *Manager {
+	return &Manager{db: db, schema: schema}
+}
+
+// CreateGraph implements graph.GraphManager. Non-empty NodeTypes and
+// EdgeTypes are enforced with CHECK constraints. Directedness is chosen
+// when opening the graph (Config.Undirected), since the tables are the same.
+func (m *Manager) CreateGraph(ctx context.Context, cfg graph.GraphConfig) error {
+	ref, err := parseGraphRef(m.schema, cfg.Name)
+	if err != nil {
+		return err
+	}
+	return withDDLLock(ctx, m.db, func(tx *sql.Tx) error {
+		return createSchema(ctx, tx, ref, cfg.NodeTypes, cfg.EdgeTypes)
+	})
+}
+
+// DropGraph implements graph.GraphManager.
+func (m *Manager) DropGraph(ctx context.Context, name string) error {
+	ref, err := parseGraphRef(m.schema, name)
+	if err != nil {
+		return err
+	}
+	//nolint:gosec // Table names escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s, %s", ref.edges(), ref.nodes())
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to drop graph: %w", err)
+	}
+	return nil
+}
+
+// GraphExists implements graph.GraphManager.
+func (m *Manager) GraphExists(ctx context.Context, name string) (bool, error) {
+	ref, err := parseGraphRef(m.schema, name)
+	if err != nil {
+		return false, err
+	}
+	return graphExists(ctx, m.db, ref)
+}
+
+// GraphStats implements graph.GraphManager. It returns
+// graph.ErrGraphNotFound for missing graphs.
+func (m *Manager) GraphStats(ctx context.Context, name string) (*graph.GraphStats, error) {
+	ref, err := parseGraphRef(m.schema, name)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := graphExists(ctx, m.db, ref)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", graph.ErrGraphNotFound, ref)
+	}
+
+	nodeTypes, err := typeCounts(ctx, m.db, ref.nodes())
+	if err != nil {
+		return nil, err
+	}
+	edgeTypes, err := typeCounts(ctx, m.db, ref.edges())
+	if err != nil {
+		return nil, err
+	}
+	return &graph.GraphStats{
+		Name:          ref.String(),
+		NodeCount:     sum(nodeTypes),
+		EdgeCount:     sum(edgeTypes),
+		NodeTypeStats: nodeTypes,
+		EdgeTypeStats: edgeTypes,
+	}, nil
+}
+
+// ListGraphs implements graph.GraphManager. It lists the graphs that have
+// both a node and an edge table.
+func (m *Manager) ListGraphs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT left(c.relname, -length('_nodes'))
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+		  AND c.relkind = 'r' AND c.relname LIKE '%\_nodes'
+		  AND EXISTS (
+			SELECT FROM pg_class e
+			WHERE e.relnamespace = c.relnamespace AND e.relkind = 'r'
+			  AND e.relname = left(c.relname, -length('_nodes')) || '_edges'
+		  )
+		ORDER BY 1
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graphs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var graphs []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan graph name: %w", err)
+		}
+		graphs = append(graphs, name)
+	}
+	return graphs, rows.Err()
+}
+
+// graphExists reports whether both tables of g exist.
+func graphExists(ctx context.Context, db *sql.DB, g graphRef) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		"SELECT to_regclass($1) IS NOT NULL AND to_regclass($2) IS NOT NULL",
+		g.nodes(), g.edges(),
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check graph existence: %w", err)
+	}
+	return exists, nil
+}
+
+// typeCounts returns the number of rows of each type in table.
+func typeCounts(ctx context.Context, db *sql.DB, table string) (map[string]int64, error) {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT type, count(*) FROM %s GROUP BY type", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var typ string
+		var n int64
+		if err := rows.Scan(&typ, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan count: %w", err)
+		}
+		counts[typ] = n
+	}
+	return counts, rows.Err()
+}
+
+// sum returns the total of counts.
+func sum(counts map[string]int64) int64 {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// Verify interface compliance
+var _ graph.GraphManager = (*Manager)(nil)
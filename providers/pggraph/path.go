@@ -0,0 +1,57 @@
+package pggraph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/lib/pq"
+)
+
+// FindPaths implements graph.PathFinder with graph.ShortestPaths, loading the
+// edges of each node the search expands with one query. With Undirected,
+// edges are also followed backwards and are returned oriented along the
+// path. Paths only pass through nodes that exist.
+func (g *Graph) FindPaths(ctx context.Context, from, to string, opts graph.PathOptions) ([]graph.Path, error) {
+	endpoints := []string{from, to}
+	if from == to {
+		endpoints = endpoints[:1]
+	}
+	var found int
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE id = ANY($1)", g.graph.nodes())
+	if err := g.db.QueryRowContext(ctx, query, pq.Array(endpoints)).Scan(&found); err != nil {
+		return nil, fmt.Errorf("failed to look up path endpoints: %w", err)
+	}
+	if found < len(endpoints) {
+		return nil, nil
+	}
+	return graph.ShortestPaths(ctx, from, to, opts, g.neighbors)
+}
+
+// neighbors returns the edges leaving id to existing nodes.
+func (g *Graph) neighbors(ctx context.Context, id string) ([]graph.Edge, error) {
+	return g.queryEdges(ctx, g.neighborsQuery(), id)
+}
+
+// neighborsQuery returns the query selecting the edges leaving the node $1,
+// oriented away from it.
+func (g *Graph) neighborsQuery() string {
+	//nolint:gosec // Table names escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		SELECT e.from_id, e.to_id, e.type, e.weight, e.metadata, e.valid_from, e.valid_to
+		FROM %[1]s e JOIN %[2]s n ON n.id = e.to_id
+		WHERE e.from_id = $1`, g.graph.edges(), g.graph.nodes())
+	if g.config.Undirected {
+		//nolint:gosec // Table names escaped via pq.QuoteIdentifier
+		query += fmt.Sprintf(`
+		UNION ALL
+		SELECT e.to_id, e.from_id, e.type, e.weight, e.metadata, e.valid_from, e.valid_to
+		FROM %[1]s e JOIN %[2]s n ON n.id = e.from_id
+		WHERE e.to_id = $1`, g.graph.edges(), g.graph.nodes())
+	}
+	return query
+}
+
+// Verify interface compliance
+var _ graph.PathFinder = (*Graph)(nil)
@@ -0,0 +1,466 @@
+package pggraph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/lib/pq"
+)
+
+// Config configures a Postgres knowledge graph.
+type Config struct {
+	// Name is the graph name. Nodes and edges are stored in the tables
+	// <name>_nodes and <name>_edges. It may be schema-qualified as
+	// "schema.name".
+	Name string
+	// Schema is the schema of the graph's tables (optional; default is the
+	// search_path).
+	Schema string
+	// CreateTablesIfNotExist creates the tables and their indexes if they
+	// don't exist.
+	CreateTablesIfNotExist bool
+	// Undirected makes traversals follow edges in both directions, whatever
+	// TraversalOptions.Direction.
+	Undirected bool
+}
+
+// DefaultConfig returns a default configuration.
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:                   name,
+		CreateTablesIfNotExist: true,
+	}
+}
+
+// Graph is a knowledge graph stored in PostgreSQL. It implements
+// graph.KnowledgeGraph and graph.BatchKnowledgeGraph; traversals run one
+// query per level.
+type Graph struct {
+	db     *sql.DB
+	config Config
+	graph  graphRef
+}
+
+// New creates a new Postgres knowledge graph.
+func New(db *sql.DB, cfg Config) (*Graph, error) {
+	ref, err := parseGraphRef(cfg.Schema, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{db: db, config: cfg, graph: ref}
+	if cfg.CreateTablesIfNotExist {
+		err := withDDLLock(context.Background(), db, func(tx *sql.Tx) error {
+			return createSchema(context.Background(), tx, ref, nil, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// FindNodes implements graph.KnowledgeGraph. Metadata filters match with
+// JSONB containment, which the metadata GIN index serves.
+func (g *Graph) FindNodes(ctx context.Context, nodeType string, filters map[string]string) ([]graph.Node, error) {
+	filterJSON, err := marshalMetadata(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filters: %w", err)
+	}
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		SELECT id, type, content, source, metadata
+		FROM %s
+		WHERE ($1 = '' OR type = $1) AND metadata @> $2::jsonb
+		ORDER BY id
+	`, g.graph.nodes())
+
+	rows, err := g.db.QueryContext(ctx, query, nodeType, filterJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nodes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var nodes []graph.Node
+	for rows.Next() {
+		var node graph.Node
+		var content, source sql.NullString
+		var metadata []byte
+		if err := rows.Scan(&node.ID, &node.Type, &content, &source, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		node.Content, node.Source = content.String, source.String
+		if node.Metadata, err = unmarshalMetadata(metadata); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+// GetNode implements graph.KnowledgeGraph.
+func (g *Graph) GetNode(ctx context.Context, id string) (graph.Node, error) {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf("SELECT id, type, content, source, metadata FROM %s WHERE id = $1", g.graph.nodes())
+
+	var node graph.Node
+	var content, source sql.NullString
+	var metadata []byte
+	err := g.db.QueryRowContext(ctx, query, id).Scan(&node.ID, &node.Type, &content, &source, &metadata)
+	if errors.Is(err, sql.ErrNoRows) {
+		return graph.Node{}, graph.ErrNodeNotFound
+	}
+	if err != nil {
+		return graph.Node{}, fmt.Errorf("failed to get node: %w", err)
+	}
+	node.Content, node.Source = content.String, source.String
+	if node.Metadata, err = unmarshalMetadata(metadata); err != nil {
+		return graph.Node{}, err
+	}
+	return node, nil
+}
+
+// GetEdges implements graph.KnowledgeGraph.
+func (g *Graph) GetEdges(ctx context.Context, nodeID string, direction graph.Direction) ([]graph.Edge, error) {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		SELECT from_id, to_id, type, weight, metadata, valid_from, valid_to
+		FROM %s
+		WHERE %s
+		ORDER BY from_id, to_id, type
+	`, g.graph.edges(), adjacency(direction))
+	edges, err := g.queryEdges(ctx, query, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edges: %w", err)
+	}
+	return edges, nil
+}
+
+// NeighborCount implements graph.KnowledgeGraph.
+func (g *Graph) NeighborCount(ctx context.Context, nodeID string, direction graph.Direction) (int, error) {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		SELECT count(DISTINCT CASE WHEN from_id = $1 THEN to_id ELSE from_id END)
+		FROM %s
+		WHERE %s
+	`, g.graph.edges(), adjacency(direction))
+
+	var count int
+	if err := g.db.QueryRowContext(ctx, query, nodeID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count neighbors: %w", err)
+	}
+	return count, nil
+}
+
+// adjacency returns the predicate selecting the edges of the node $1 in
+// direction.
+func adjacency(direction graph.Direction) string {
+	switch direction {
+	case graph.DirectionIn:
+		return "to_id = $1"
+	case graph.DirectionBoth:
+		return "(from_id = $1 OR to_id = $1)"
+	default:
+		return "from_id = $1"
+	}
+}
+
+// Edges implements graph.EdgeLister.
+func (g *Graph) Edges(ctx context.Context) ([]graph.Edge, error) {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		SELECT from_id, to_id, type, weight, metadata, valid_from, valid_to
+		FROM %s
+		ORDER BY from_id, to_id, type
+	`, g.graph.edges())
+	edges, err := g.queryEdges(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edges: %w", err)
+	}
+	return edges, nil
+}
+
+// queryEdges runs a query selecting from_id, to_id, type, weight, metadata,
+// valid_from, and valid_to, and returns the edges.
+func (g *Graph) queryEdges(ctx context.Context, query string, args ...any) ([]graph.Edge, error) {
+	rows, err := g.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var edges []graph.Edge
+	for rows.Next() {
+		var edge graph.Edge
+		var metadata []byte
+		var validFrom, validTo sql.NullTime
+		if err := rows.Scan(&edge.From, &edge.To, &edge.Type, &edge.Weight, &metadata, &validFrom, &validTo); err != nil {
+			return nil, fmt.Errorf("failed to scan edge: %w", err)
+		}
+		edge.ValidFrom, edge.ValidTo = validFrom.Time, validTo.Time
+		if edge.Metadata, err = unmarshalMetadata(metadata); err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	return edges, rows.Err()
+}
+
+// AddNode implements graph.KnowledgeGraph. Adding a node with an existing ID
+// fails; use UpsertNode to replace it.
+func (g *Graph) AddNode(ctx context.Context, node graph.Node) error {
+	return g.AddNodeBatch(ctx, []graph.Node{node})
+}
+
+// UpsertNode implements graph.KnowledgeGraph.
+func (g *Graph) UpsertNode(ctx context.Context, node graph.Node) error {
+	return g.UpsertNodeBatch(ctx, []graph.Node{node})
+}
+
+// AddEdge implements graph.KnowledgeGraph. Adding an edge that already
+// exists with the same endpoints and type fails; use UpsertEdge to replace
+// it.
+func (g *Graph) AddEdge(ctx context.Context, edge graph.Edge) error {
+	return g.AddEdgeBatch(ctx, []graph.Edge{edge})
+}
+
+// UpsertEdge implements graph.KnowledgeGraph.
+func (g *Graph) UpsertEdge(ctx context.Context, edge graph.Edge) error {
+	return g.UpsertEdgeBatch(ctx, []graph.Edge{edge})
+}
+
+// DeleteNode implements graph.KnowledgeGraph.
+func (g *Graph) DeleteNode(ctx context.Context, id string) error {
+	return g.DeleteNodeBatch(ctx, []string{id})
+}
+
+// DeleteEdge implements graph.KnowledgeGraph.
+func (g *Graph) DeleteEdge(ctx context.Context, from, to, edgeType string) error {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf("DELETE FROM %s WHERE from_id = $1 AND to_id = $2 AND type = $3", g.graph.edges())
+	if _, err := g.db.ExecContext(ctx, query, from, to, edgeType); err != nil {
+		return fmt.Errorf("failed to delete edge: %w", err)
+	}
+	return nil
+}
+
+// Name implements graph.KnowledgeGraph.
+func (g *Graph) Name() string {
+	return g.graph.String()
+}
+
+// AddNodeBatch implements graph.BatchKnowledgeGraph. The nodes are inserted
+// with a single statement.
+func (g *Graph) AddNodeBatch(ctx context.Context, nodes []graph.Node) error {
+	if err := g.writeNodes(ctx, nodes, ""); err != nil {
+		return fmt.Errorf("failed to add nodes: %w", err)
+	}
+	return nil
+}
+
+// UpsertNodeBatch implements graph.BatchKnowledgeGraph. If nodes contains an
+// ID more than once, the last node wins.
+func (g *Graph) UpsertNodeBatch(ctx context.Context, nodes []graph.Node) error {
+	nodes = lastByKey(nodes, func(n graph.Node) string { return n.ID })
+	conflict := `ON CONFLICT (id) DO UPDATE SET
+		type = EXCLUDED.type,
+		content = EXCLUDED.content,
+		source = EXCLUDED.source,
+		metadata = EXCLUDED.metadata`
+	if err := g.writeNodes(ctx, nodes, conflict); err != nil {
+		return fmt.Errorf("failed to upsert nodes: %w", err)
+	}
+	return nil
+}
+
+// AddEdgeBatch implements graph.BatchKnowledgeGraph. The edges are inserted
+// with a single statement.
+func (g *Graph) AddEdgeBatch(ctx context.Context, edges []graph.Edge) error {
+	if err := g.writeEdges(ctx, edges, ""); err != nil {
+		return fmt.Errorf("failed to add edges: %w", err)
+	}
+	return nil
+}
+
+// UpsertEdgeBatch implements graph.BatchKnowledgeGraph. If edges contains an
+// edge more than once, the last edge wins.
+func (g *Graph) UpsertEdgeBatch(ctx context.Context, edges []graph.Edge) error {
+	edges = lastByKey(edges, func(e graph.Edge) [3]string { return [3]string{e.From, e.To, e.Type} })
+	conflict := `ON CONFLICT (from_id, to_id, type) DO UPDATE SET
+		weight = EXCLUDED.weight,
+		metadata = EXCLUDED.metadata,
+		valid_from = EXCLUDED.valid_from,
+		valid_to = EXCLUDED.valid_to`
+	if err := g.writeEdges(ctx, edges, conflict); err != nil {
+		return fmt.Errorf("failed to upsert edges: %w", err)
+	}
+	return nil
+}
+
+// DeleteNodeBatch implements graph.BatchKnowledgeGraph. The nodes and all
+// edges from or to them are deleted in one transaction.
+func (g *Graph) DeleteNodeBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return withTx(ctx, g.db, func(tx *sql.Tx) error {
+		//nolint:gosec // Table names escaped via pq.QuoteIdentifier
+		stmts := []string{
+			fmt.Sprintf("DELETE FROM %s WHERE from_id = ANY($1) OR to_id = ANY($1)", g.graph.edges()),
+			fmt.Sprintf("DELETE FROM %s WHERE id = ANY($1)", g.graph.nodes()),
+		}
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt, pq.Array(ids)); err != nil {
+				return fmt.Errorf("failed to delete nodes: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Warmup implements retrieve.Warmer. It verifies that the graph's tables
+// exist, so configuration problems surface at startup.
+func (g *Graph) Warmup(ctx context.Context) error {
+	exists, err := graphExists(ctx, g.db, g.graph)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("graph %s does not exist", g.graph)
+	}
+	return nil
+}
+
+// writeNodes inserts nodes with a single statement unnesting one array per
+// column, followed by conflict.
+func (g *Graph) writeNodes(ctx context.Context, nodes []graph.Node, conflict string) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(nodes))
+	types := make([]string, len(nodes))
+	contents := make([]string, len(nodes))
+	sources := make([]string, len(nodes))
+	metadata := make([]string, len(nodes))
+	for i, node := range nodes {
+		m, err := marshalMetadata(node.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata for node %s: %w", node.ID, err)
+		}
+		ids[i], types[i], contents[i], sources[i], metadata[i] = node.ID, node.Type, node.Content, node.Source, m
+	}
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, type, content, source, metadata)
+		SELECT id, type, content, source, metadata::jsonb
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[])
+			AS t(id, type, content, source, metadata)
+		%s
+	`, g.graph.nodes(), conflict)
+	_, err := g.db.ExecContext(ctx, query,
+		pq.Array(ids), pq.Array(types), pq.Array(contents), pq.Array(sources), pq.Array(metadata))
+	return err
+}
+
+// writeEdges inserts edges with a single statement unnesting one array per
+// column, followed by conflict.
+func (g *Graph) writeEdges(ctx context.Context, edges []graph.Edge, conflict string) error {
+	if len(edges) == 0 {
+		return nil
+	}
+	froms := make([]string, len(edges))
+	tos := make([]string, len(edges))
+	types := make([]string, len(edges))
+	weights := make([]float64, len(edges))
+	metadata := make([]string, len(edges))
+	validFroms := make([]string, len(edges))
+	validTos := make([]string, len(edges))
+	for i, edge := range edges {
+		m, err := marshalMetadata(edge.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata for edge %s->%s: %w", edge.From, edge.To, err)
+		}
+		froms[i], tos[i], types[i], weights[i], metadata[i] = edge.From, edge.To, edge.Type, edge.Weight, m
+		validFroms[i], validTos[i] = timeArg(edge.ValidFrom), timeArg(edge.ValidTo)
+	}
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	query := fmt.Sprintf(`
+		INSERT INTO %s (from_id, to_id, type, weight, metadata, valid_from, valid_to)
+		SELECT from_id, to_id, type, weight, metadata::jsonb,
+			NULLIF(valid_from, '')::timestamptz, NULLIF(valid_to, '')::timestamptz
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::float8[], $5::text[], $6::text[], $7::text[])
+			AS t(from_id, to_id, type, weight, metadata, valid_from, valid_to)
+		%s
+	`, g.graph.edges(), conflict)
+	_, err := g.db.ExecContext(ctx, query,
+		pq.Array(froms), pq.Array(tos), pq.Array(types), pq.Array(weights), pq.Array(metadata),
+		pq.Array(validFroms), pq.Array(validTos))
+	return err
+}
+
+// timeArg encodes a time for a text array parameter, with "" for the zero
+// time.
+func timeArg(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// lastByKey returns items without earlier duplicates of a key, since a
+// single upsert statement can't update a row twice.
+func lastByKey[T any, K comparable](items []T, key func(T) K) []T {
+	last := make(map[K]int, len(items))
+	for i, item := range items {
+		last[key(item)] = i
+	}
+	if len(last) == len(items) {
+		return items
+	}
+	out := make([]T, 0, len(last))
+	for i, item := range items {
+		if last[key(item)] == i {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// marshalMetadata encodes metadata as a JSON object.
+func marshalMetadata(m map[string]string) (string, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+// unmarshalMetadata decodes a JSONB metadata object, returning nil for an
+// empty object.
+func unmarshalMetadata(b []byte) (map[string]string, error) {
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// Verify interface compliance
+var (
+	_ graph.KnowledgeGraph      = (*Graph)(nil)
+	_ graph.BatchKnowledgeGraph = (*Graph)(nil)
+	_ graph.EdgeLister          = (*Graph)(nil)
+	_ retrieve.Warmer           = (*Graph)(nil)
+)
@@ -0,0 +1,110 @@
+package pggraph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ddlLockKey is the advisory lock key held while running schema DDL, so that
+// concurrently starting instances don't race on CREATE TABLE.
+var ddlLockKey = func() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("omniretrieve/pggraph:ddl"))
+	return int64(h.Sum64())
+}()
+
+// withDDLLock runs fn in a transaction holding a transaction-scoped advisory
+// lock. The lock is released when the transaction commits or rolls back.
+func withDDLLock(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", ddlLockKey); err != nil {
+			return fmt.Errorf("failed to acquire advisory lock: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+// withTx runs fn in a transaction, committing it if fn succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// createSchema creates the node and edge tables of g. Non-empty nodeTypes
+// and edgeTypes restrict the types the tables accept.
+func createSchema(ctx context.Context, tx *sql.Tx, g graphRef, nodeTypes, edgeTypes []string) error {
+	//nolint:gosec // Identifiers escaped via pq.QuoteIdentifier, types via pq.QuoteLiteral
+	stmts := []string{
+		fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				id TEXT PRIMARY KEY,
+				type TEXT NOT NULL DEFAULT ''%s,
+				content TEXT,
+				source TEXT,
+				metadata JSONB NOT NULL DEFAULT '{}'::jsonb
+			)
+		`, g.nodes(), typeCheck(nodeTypes)),
+		fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				from_id TEXT NOT NULL,
+				to_id TEXT NOT NULL,
+				type TEXT NOT NULL DEFAULT ''%s,
+				weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+				metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+				valid_from TIMESTAMPTZ,
+				valid_to TIMESTAMPTZ,
+				PRIMARY KEY (from_id, to_id, type)
+			)
+		`, g.edges(), typeCheck(edgeTypes)),
+		// Edge validity intervals, added to tables created without them
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS valid_from TIMESTAMPTZ, ADD COLUMN IF NOT EXISTS valid_to TIMESTAMPTZ",
+			g.edges()),
+		// Node lookups by type and metadata containment (FindNodes)
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (type)",
+			pq.QuoteIdentifier(g.name+"_nodes_type_idx"), g.nodes()),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING gin (metadata jsonb_path_ops)",
+			pq.QuoteIdentifier(g.name+"_nodes_metadata_idx"), g.nodes()),
+		// Incoming edges, for undirected traversal and node deletion
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (to_id)",
+			pq.QuoteIdentifier(g.name+"_edges_to_idx"), g.edges()),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create graph tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// typeCheck returns a column CHECK constraint restricting the type column to
+// types, or "" if types is empty.
+func typeCheck(types []string) string {
+	if len(types) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(types))
+	for i, t := range types {
+		quoted[i] = pq.QuoteLiteral(t)
+	}
+	return fmt.Sprintf(" CHECK (type IN (%s))", strings.Join(quoted, ", "))
+}
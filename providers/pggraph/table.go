@@ -0,0 +1,59 @@
+package pggraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// graphRef is a graph name, optionally qualified with a schema. A graph is
+// stored in the tables <name>_nodes and <name>_edges.
+type graphRef struct {
+	schema string
+	name   string
+}
+
+// parseGraphRef returns the graph named name in schema. If schema is empty,
+// name may itself be schema-qualified as "schema.graph".
+func parseGraphRef(schema, name string) (graphRef, error) {
+	if name == "" {
+		return graphRef{}, fmt.Errorf("graph name is required")
+	}
+	if s, n, ok := strings.Cut(name, "."); ok {
+		if schema != "" {
+			return graphRef{}, fmt.Errorf("graph name %q is schema-qualified and schema %q is also set", name, schema)
+		}
+		if s == "" || n == "" || strings.Contains(n, ".") {
+			return graphRef{}, fmt.Errorf("invalid graph name %q", name)
+		}
+		return graphRef{schema: s, name: n}, nil
+	}
+	return graphRef{schema: schema, name: name}, nil
+}
+
+// String returns the unquoted, possibly qualified graph name.
+func (g graphRef) String() string {
+	if g.schema == "" {
+		return g.name
+	}
+	return g.schema + "." + g.name
+}
+
+// nodes returns the quoted name of the node table.
+func (g graphRef) nodes() string {
+	return g.quoteRelation(g.name + "_nodes")
+}
+
+// edges returns the quoted name of the edge table.
+func (g graphRef) edges() string {
+	return g.quoteRelation(g.name + "_edges")
+}
+
+// quoteRelation quotes the name of a relation in the graph's schema.
+func (g graphRef) quoteRelation(name string) string {
+	if g.schema == "" {
+		return pq.QuoteIdentifier(name)
+	}
+	return pq.QuoteIdentifier(g.schema) + "." + pq.QuoteIdentifier(name)
+}
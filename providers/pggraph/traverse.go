@@ -0,0 +1,241 @@
+package pggraph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/lib/pq"
+)
+
+// Traverse implements graph.KnowledgeGraph by walking the edge table breadth
+// first, with one query per level that follows the edges of the previous
+// level's nodes to nodes not reached yet. Each node is reached by its
+// shortest path; ties are broken by the lexicographically smallest path.
+// Result edges are the edges along the returned paths. Nodes whose type is
+// filtered out by NodeTypes are neither returned nor traversed. MaxNodes <= 0
+// returns all reached nodes.
+//
+// A traversal runs up to Depth+1 queries in one repeatable read transaction.
+// Each reads the edges of one level's nodes, so the cost grows with the
+// number of nodes and edges reached, not with the number of paths between
+// them.
+func (g *Graph) Traverse(ctx context.Context, startNodes []string, opts graph.TraversalOptions) (*graph.TraversalResult, error) {
+	result := &graph.TraversalResult{Paths: make(map[string][]string)}
+	err := g.traverse(ctx, startNodes, opts, func(step graph.TraversalStep) bool {
+		result.Nodes = append(result.Nodes, step.Node)
+		result.Paths[step.Node.ID] = step.Path
+		if step.Edge != nil {
+			result.Edges = append(result.Edges, *step.Edge)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TraverseStream implements graph.StreamingTraverser. Rows are scanned as
+// visit consumes them, and stopping skips the queries for deeper levels.
+func (g *Graph) TraverseStream(ctx context.Context, startNodes []string, opts graph.TraversalOptions, visit graph.TraversalVisitor) error {
+	return g.traverse(ctx, startNodes, opts, visit)
+}
+
+// traverse runs the traversal queries level by level, passing each row to
+// visit until it returns false.
+func (g *Graph) traverse(ctx context.Context, startNodes []string, opts graph.TraversalOptions, visit graph.TraversalVisitor) error {
+	if len(startNodes) == 0 {
+		return nil
+	}
+
+	var nodeTypes, edgeTypes any
+	if len(opts.NodeTypes) > 0 {
+		nodeTypes = pq.Array(opts.NodeTypes)
+	}
+	if len(opts.EdgeTypes) > 0 {
+		edgeTypes = pq.Array(opts.EdgeTypes)
+	}
+
+	var asOf sql.NullTime
+	if !opts.AsOf.IsZero() {
+		asOf = sql.NullTime{Time: opts.AsOf, Valid: true}
+	}
+	edgeMetadata, err := marshalMetadata(opts.EdgeMetadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal edge metadata filter: %w", err)
+	}
+
+	// Read every level from the same snapshot
+	tx, err := g.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// frontier holds the paths to the previous level's nodes, in order
+	var frontier [][]string
+	var reached []string
+	for depth := 0; depth <= opts.Depth; depth++ {
+		var limit sql.NullInt64
+		if opts.MaxNodes > 0 {
+			limit = sql.NullInt64{Int64: int64(opts.MaxNodes - len(reached)), Valid: true}
+		}
+
+		var rows *sql.Rows
+		if depth == 0 {
+			rows, err = tx.QueryContext(ctx, g.startQuery(), pq.Array(startNodes), nodeTypes, limit)
+		} else {
+			ids := make([]string, len(frontier))
+			for i, path := range frontier {
+				ids[i] = path[len(path)-1]
+			}
+			rows, err = tx.QueryContext(ctx, g.traverseQuery(opts.Direction),
+				pq.Array(ids), pq.Array(reached), opts.MinWeight, nodeTypes, edgeTypes, limit, asOf, edgeMetadata)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to traverse graph: %w", err)
+		}
+
+		next, stopped, err := scanLevel(rows, frontier, visit)
+		if err != nil || stopped || len(next) == 0 {
+			return err
+		}
+		for _, path := range next {
+			reached = append(reached, path[len(path)-1])
+		}
+		if opts.MaxNodes > 0 && len(reached) >= opts.MaxNodes {
+			return nil
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// scanLevel passes the rows of one level's query to visit, and returns the
+// paths to the level's nodes in order. A row's rank is the 1-based position
+// in frontier of the path it extends, or NULL for start nodes. It reports
+// whether visit stopped the traversal.
+func scanLevel(rows *sql.Rows, frontier [][]string, visit graph.TraversalVisitor) ([][]string, bool, error) {
+	defer func() { _ = rows.Close() }()
+
+	var level [][]string
+	for rows.Next() {
+		var (
+			node                       graph.Node
+			rank                       sql.NullInt64
+			content, source            sql.NullString
+			metadata, edgeMetadata     []byte
+			edgeFrom, edgeTo, edgeType sql.NullString
+			edgeWeight                 sql.NullFloat64
+			validFrom, validTo         sql.NullTime
+		)
+		if err := rows.Scan(&node.ID, &rank, &node.Type, &content, &source, &metadata,
+			&edgeFrom, &edgeTo, &edgeType, &edgeWeight, &edgeMetadata, &validFrom, &validTo); err != nil {
+			return nil, false, fmt.Errorf("failed to scan traversal row: %w", err)
+		}
+		node.Content, node.Source = content.String, source.String
+		var err error
+		if node.Metadata, err = unmarshalMetadata(metadata); err != nil {
+			return nil, false, err
+		}
+
+		path := []string{node.ID}
+		if rank.Valid {
+			parent := frontier[rank.Int64-1]
+			path = append(append(make([]string, 0, len(parent)+1), parent...), node.ID)
+		}
+		level = append(level, path)
+		step := graph.TraversalStep{Node: node, Path: path}
+
+		if edgeFrom.Valid {
+			step.Edge = &graph.Edge{
+				From:      edgeFrom.String,
+				To:        edgeTo.String,
+				Type:      edgeType.String,
+				Weight:    edgeWeight.Float64,
+				ValidFrom: validFrom.Time,
+				ValidTo:   validTo.Time,
+			}
+			if step.Edge.Metadata, err = unmarshalMetadata(edgeMetadata); err != nil {
+				return nil, false, err
+			}
+		}
+		if !visit(step) {
+			return level, true, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to traverse graph: %w", err)
+	}
+	return level, false, nil
+}
+
+// startQuery returns the query selecting the start nodes of a traversal, in
+// the row layout of traverseQuery. Its parameters are the start node IDs,
+// the node types (NULL for any), and the node limit (NULL for all).
+func (g *Graph) startQuery() string {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier
+	return fmt.Sprintf(`
+		SELECT n.id, NULL::bigint, n.type, n.content, n.source, n.metadata,
+			NULL::text, NULL::text, NULL::text, NULL::double precision, NULL::jsonb,
+			NULL::timestamptz, NULL::timestamptz
+		FROM %s n
+		WHERE n.id = ANY($1) AND ($2::text[] IS NULL OR n.type = ANY($2))
+		ORDER BY n.id
+		LIMIT $3
+	`, g.graph.nodes())
+}
+
+// traverseQuery returns the query for one level of a traversal, following
+// edges in direction, or both ways with Undirected. Each node not reached yet
+// is reached once, from the first frontier node in order, and the level is
+// ordered by path. Its parameters are the frontier node IDs in path order,
+// the IDs of the nodes reached so far, the minimum edge weight, the node and
+// edge types (NULL for any), the node limit (NULL for all), the time edges
+// must be valid at (NULL for any), and the JSONB object edge metadata must
+// contain.
+func (g *Graph) traverseQuery(direction graph.Direction) string {
+	if g.config.Undirected {
+		direction = graph.DirectionBoth
+	}
+	const columns = "from_id, to_id, type, weight, metadata, valid_from, valid_to"
+	forward := fmt.Sprintf("SELECT from_id AS src, to_id AS dst, %s FROM %s", columns, g.graph.edges())
+	reverse := fmt.Sprintf("SELECT to_id AS src, from_id AS dst, %s FROM %s", columns, g.graph.edges())
+	var hops string
+	switch direction {
+	case graph.DirectionIn:
+		hops = reverse
+	case graph.DirectionBoth:
+		hops = forward + " UNION ALL " + reverse
+	default:
+		hops = forward
+	}
+
+	//nolint:gosec // Table names escaped via pq.QuoteIdentifier
+	return fmt.Sprintf(`
+		WITH hops AS (%[1]s)
+		SELECT * FROM (
+			SELECT DISTINCT ON (h.dst) h.dst AS id, f.rank, n.type, n.content, n.source, n.metadata,
+				h.from_id, h.to_id, h.type AS edge_type, h.weight, h.metadata AS edge_metadata,
+				h.valid_from, h.valid_to
+			FROM unnest($1::text[]) WITH ORDINALITY AS f(id, rank)
+			JOIN hops h ON h.src = f.id
+			JOIN %[2]s n ON n.id = h.dst
+			WHERE h.dst <> ALL ($2::text[])
+			  AND h.weight >= $3
+			  AND ($7::timestamptz IS NULL OR ((h.valid_from IS NULL OR h.valid_from <= $7)
+			       AND (h.valid_to IS NULL OR h.valid_to > $7)))
+			  AND ($5::text[] IS NULL OR h.type = ANY($5))
+			  AND h.metadata @> $8::jsonb
+			  AND ($4::text[] IS NULL OR n.type = ANY($4))
+			ORDER BY h.dst, f.rank, h.type
+		) reached
+		ORDER BY rank, id
+		LIMIT $6
+	`, hops, g.graph.nodes())
+}
+
+// Verify interface compliance
+var _ graph.StreamingTraverser = (*Graph)(nil)
@@ -0,0 +1,67 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SwapAlias implements vector.AliasManager. An alias is a simple view over
+// the target table; PostgreSQL inlines it into queries, so searches still use
+// the table's vector index, and it is automatically updatable, so writes pass
+// through. The view is replaced in one transaction, so concurrent queries see
+// either the old or the new target. Open indexes over an alias with
+// Config.VerifySchema, since DDL can't create tables or indexes on a view.
+func (m *Manager) SwapAlias(ctx context.Context, alias, target string) error {
+	aliasTable, err := m.table(alias)
+	if err != nil {
+		return err
+	}
+	targetTable, err := m.table(target)
+	if err != nil {
+		return err
+	}
+
+	return withDDLLock(ctx, m.db, func(tx *sql.Tx) error {
+		// DROP VIEW fails if alias names a table, so real data is never replaced
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s", aliasTable.quoted())); err != nil {
+			return fmt.Errorf("failed to drop alias %s: %w", alias, err)
+		}
+		query := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", aliasTable.quoted(), targetTable.quoted())
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to point alias %s at %s: %w", alias, target, err)
+		}
+		return nil
+	})
+}
+
+// ResolveAlias implements vector.AliasManager. The target is
+// schema-qualified if it is in a different schema than the alias.
+func (m *Manager) ResolveAlias(ctx context.Context, alias string) (string, error) {
+	aliasTable, err := m.table(alias)
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		SELECT DISTINCT CASE WHEN t.relnamespace = v.relnamespace
+		                     THEN t.relname
+		                     ELSE n.nspname || '.' || t.relname END
+		FROM pg_rewrite r
+		JOIN pg_class v ON v.oid = r.ev_class
+		JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid
+		JOIN pg_class t ON t.oid = d.refobjid AND t.relkind = 'r'
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE r.ev_class = to_regclass($1)
+	`
+	var target string
+	err = m.db.QueryRowContext(ctx, query, aliasTable.quoted()).Scan(&target)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("alias %s does not exist", alias)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+	return target, nil
+}
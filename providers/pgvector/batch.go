@@ -2,20 +2,63 @@ package pgvector
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/agentplexus/omniretrieve/vector"
-	"github.com/lib/pq"
 )
 
+// maxParams is the PostgreSQL limit on bind parameters per statement.
+const maxParams = 65535
+
+// upsertParamsPerNode is the number of bind parameters UpsertBatch uses per
+// node, plus one for partitioned tables.
+const upsertParamsPerNode = 5
+
+// maxBatchSize is the largest number of nodes that fit in one upsert statement.
+const maxBatchSize = maxParams / upsertParamsPerNode
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// MaxBatchSize implements vector.BatchSizer. It returns the number of nodes
+// written per statement, so callers that chunk batches themselves don't
+// produce chunks the index would split again.
+func (idx *Index) MaxBatchSize() int {
+	return idx.config.BatchSize
+}
+
 // InsertBatch implements vector.BatchIndex.
 func (idx *Index) InsertBatch(ctx context.Context, nodes []vector.Node) error {
 	if len(nodes) == 0 {
 		return nil
 	}
+	nodes, err := idx.scopeNodes(ctx, nodes)
+	if err != nil {
+		return err
+	}
+
+	args, err := idx.writeArgs(ctx, nodes, idx.encodeTextArg)
+	if err != nil {
+		return err
+	}
+	return idx.retry(ctx, func() error {
+		return idx.copyNodes(ctx, nodes, args)
+	})
+}
 
+// copyNodes writes nodes with COPY in a transaction, given their writeArgs.
+func (idx *Index) copyNodes(ctx context.Context, nodes []vector.Node, args []any) (err error) {
 	// Use a transaction for atomicity
 	tx, err := idx.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -27,29 +70,32 @@ func (idx *Index) InsertBatch(ctx context.Context, nodes []vector.Node) error {
 		}
 	}()
 
+	if err = applySettings(ctx, tx, idx.tenantSettings(ctx)); err != nil {
+		return err
+	}
+	if err = idx.copyIn(ctx, tx, nodes, args); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// copyIn writes nodes with COPY in tx, given their writeArgs.
+func (idx *Index) copyIn(ctx context.Context, tx *sql.Tx, nodes []vector.Node, args []any) error {
 	// Prepare statement for batch insert
-	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
-		idx.tableName,
-		"id", "content", "embedding", "source", "metadata",
-	))
+	columns := idx.columns()
+	stmt, err := tx.PrepareContext(ctx, idx.table.copyIn(columns...))
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer func() { _ = stmt.Close() }()
 
-	for _, node := range nodes {
-		metadataJSON, err := json.Marshal(node.Metadata)
-		if err != nil {
-			return fmt.Errorf("failed to marshal metadata for node %s: %w", node.ID, err)
-		}
-
-		_, err = stmt.ExecContext(ctx,
-			node.ID,
-			node.Content,
-			vectorToString(node.Embedding),
-			node.Source,
-			string(metadataJSON),
-		)
+	for i, node := range nodes {
+		_, err = stmt.ExecContext(ctx, args[i*len(columns):(i+1)*len(columns)]...)
 		if err != nil {
 			return fmt.Errorf("failed to exec for node %s: %w", node.ID, err)
 		}
@@ -61,70 +107,112 @@ func (idx *Index) InsertBatch(ctx context.Context, nodes []vector.Node) error {
 		return fmt.Errorf("failed to flush COPY: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
-// UpsertBatch implements vector.BatchIndex.
+// UpsertBatch implements vector.BatchIndex. Batches larger than the
+// configured BatchSize are split into chunks.
 func (idx *Index) UpsertBatch(ctx context.Context, nodes []vector.Node) error {
 	if len(nodes) == 0 {
 		return nil
 	}
+	nodes, err := idx.scopeNodes(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	if err := idx.checkNodes(nodes); err != nil {
+		return err
+	}
+	// Create partitions before a transaction holds locks on the table
+	if err := idx.ensurePartitions(ctx, nodes); err != nil {
+		return err
+	}
+	return idx.inChunks(ctx, len(nodes), func(db execer, start, end int) error {
+		return idx.upsertChunk(ctx, db, nodes[start:end])
+	})
+}
 
-	// Build a multi-row upsert query
-	// PostgreSQL supports ON CONFLICT for bulk upserts
-	valueStrings := make([]string, 0, len(nodes))
-	valueArgs := make([]any, 0, len(nodes)*5)
+// upsertChunk writes nodes with a single multi-row upsert statement. The
+// caller validates the nodes and creates their partitions.
+func (idx *Index) upsertChunk(ctx context.Context, db execer, nodes []vector.Node) error {
+	args, err := idx.nodeArgs(nodes, idx.encodeTextArg)
+	if err != nil {
+		return err
+	}
 
-	for i, node := range nodes {
+	res, err := db.ExecContext(ctx, idx.upsertQuery(len(nodes)), args...)
+	if err != nil {
+		return fmt.Errorf("upsert batch failed: %w", err)
+	}
+
+	return upserted(res, len(nodes))
+}
+
+// writeArgs returns the bind arguments for insertQuery and upsertQuery, one
+// per column for each node, encoding embeddings with encode. It validates
+// the embedding dimensions and promoted column values and creates the
+// partitions the nodes are written to first.
+func (idx *Index) writeArgs(ctx context.Context, nodes []vector.Node, encode func([]float32) any) ([]any, error) {
+	if err := idx.checkNodes(nodes); err != nil {
+		return nil, err
+	}
+	if err := idx.ensurePartitions(ctx, nodes); err != nil {
+		return nil, err
+	}
+	return idx.nodeArgs(nodes, encode)
+}
+
+// nodeArgs returns the bind arguments of nodes without validating them.
+func (idx *Index) nodeArgs(nodes []vector.Node, encode func([]float32) any) ([]any, error) {
+	args := make([]any, 0, len(nodes)*len(idx.columns()))
+	for _, node := range nodes {
 		metadataJSON, err := json.Marshal(node.Metadata)
 		if err != nil {
-			return fmt.Errorf("failed to marshal metadata for node %s: %w", node.ID, err)
+			return nil, fmt.Errorf("failed to marshal metadata for node %s: %w", node.ID, err)
 		}
-
-		base := i * 5
-		valueStrings = append(valueStrings,
-			fmt.Sprintf("($%d, $%d, $%d::vector, $%d, $%d::jsonb)",
-				base+1, base+2, base+3, base+4, base+5))
-
-		valueArgs = append(valueArgs,
+		content, compressed, err := idx.encodeContent(node.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress content for node %s: %w", node.ID, err)
+		}
+		args = append(args,
 			node.ID,
-			node.Content,
-			vectorToString(node.Embedding),
+			content,
+			encode(node.Embedding),
 			node.Source,
 			string(metadataJSON),
 		)
+		if idx.config.Partitioning != nil {
+			args = append(args, idx.partitionOf(node))
+		}
+		if idx.config.CompressContent {
+			args = append(args, compressed)
+		}
+		if idx.config.Expiration {
+			args = append(args, expiresArg(node))
+		} else if !node.ExpiresAt.IsZero() {
+			return nil, fmt.Errorf("%w: node %s has an expiration time", vector.ErrExpirationUnsupported, node.ID)
+		}
 	}
-
-	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, values are parameterized
-	query := fmt.Sprintf(`
-		INSERT INTO %s (id, content, embedding, source, metadata)
-		VALUES %s
-		ON CONFLICT (id) DO UPDATE SET
-			content = EXCLUDED.content,
-			embedding = EXCLUDED.embedding,
-			source = EXCLUDED.source,
-			metadata = EXCLUDED.metadata,
-			updated_at = NOW()
-	`, pq.QuoteIdentifier(idx.tableName), strings.Join(valueStrings, ","))
-
-	_, err := idx.db.ExecContext(ctx, query, valueArgs...)
-	if err != nil {
-		return fmt.Errorf("upsert batch failed: %w", err)
-	}
-
-	return nil
+	return args, nil
 }
 
-// DeleteBatch implements vector.BatchIndex.
+// DeleteBatch implements vector.BatchIndex. Batches larger than the
+// configured BatchSize are split into chunks.
 func (idx *Index) DeleteBatch(ctx context.Context, ids []string) error {
 	if len(ids) == 0 {
 		return nil
 	}
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return err
+	}
+	return idx.inChunks(ctx, len(ids), func(db execer, start, end int) error {
+		return idx.deleteChunk(ctx, db, ids[start:end], s)
+	})
+}
 
+// deleteChunk removes ids within s with a single statement.
+func (idx *Index) deleteChunk(ctx context.Context, db execer, ids []string, s scope) error {
 	// Build parameterized IN clause
 	placeholders := make([]string, len(ids))
 	args := make([]any, len(ids))
@@ -133,12 +221,10 @@ func (idx *Index) DeleteBatch(ctx context.Context, ids []string) error {
 		args[i] = id
 	}
 
-	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, IDs are parameterized
-	query := fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)",
-		pq.QuoteIdentifier(idx.tableName),
-		strings.Join(placeholders, ","))
+	where, args := idx.restrict(fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ",")), args, s)
+	query := idx.deleteQuery(where)
 
-	_, err := idx.db.ExecContext(ctx, query, args...)
+	_, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("delete batch failed: %w", err)
 	}
@@ -146,5 +232,116 @@ func (idx *Index) DeleteBatch(ctx context.Context, ids []string) error {
 	return nil
 }
 
+// inChunks calls fn for consecutive [start, end) ranges of at most BatchSize
+// items. Chunks run in one transaction unless CommitPerChunk is set; each
+// transaction is retried on transient errors as a whole.
+func (idx *Index) inChunks(ctx context.Context, n int, fn func(db execer, start, end int) error) error {
+	size := idx.config.BatchSize
+	chunk := func(start, end int) error {
+		return idx.withWrite(ctx, func(db execer) error { return fn(db, start, end) })
+	}
+	if n <= size {
+		return idx.retry(ctx, func() error { return chunk(0, n) })
+	}
+
+	if idx.config.BatchConcurrency > 1 {
+		return idx.parallelChunks(ctx, n, chunk)
+	}
+	if idx.config.CommitPerChunk {
+		for start := 0; start < n; start += size {
+			end := min(start+size, n)
+			if err := idx.retry(ctx, func() error { return chunk(start, end) }); err != nil {
+				return fmt.Errorf("chunk [%d:%d] failed: %w", start, end, err)
+			}
+		}
+		return nil
+	}
+	return idx.retry(ctx, func() error { return idx.chunksInTx(ctx, n, fn) })
+}
+
+// chunksInTx calls fn for each chunk of n items in a single transaction.
+func (idx *Index) chunksInTx(ctx context.Context, n int, fn func(db execer, start, end int) error) (err error) {
+	size := idx.config.BatchSize
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = applySettings(ctx, tx, idx.tenantSettings(ctx)); err != nil {
+		return err
+	}
+	for start := 0; start < n; start += size {
+		end := min(start+size, n)
+		if err = fn(tx, start, end); err != nil {
+			return fmt.Errorf("chunk [%d:%d] failed: %w", start, end, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// parallelChunks calls fn for each chunk of n items on up to
+// BatchConcurrency goroutines, retrying each chunk on transient errors.
+// Chunks left unstarted when ctx is done fail with ctx.Err(). Failed chunks
+// are returned as a *vector.BatchError.
+func (idx *Index) parallelChunks(ctx context.Context, n int, fn func(start, end int) error) error {
+	size := idx.config.BatchSize
+	chunks := (n + size - 1) / size
+	errs := make([]error, chunks)
+	attempts := make([]int, chunks)
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(idx.config.BatchConcurrency, chunks) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				start, end := i*size, min((i+1)*size, n)
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				errs[i] = idx.retry(ctx, func() error {
+					attempts[i]++
+					return fn(start, end)
+				})
+			}
+		}()
+	}
+	for i := range chunks {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var failed []*vector.ChunkError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &vector.ChunkError{
+				Offset:   i * size,
+				Size:     min((i+1)*size, n) - i*size,
+				Attempts: attempts[i],
+				Err:      err,
+			})
+		}
+	}
+	if len(failed) > 0 {
+		return &vector.BatchError{Chunks: failed}
+	}
+	return nil
+}
+
 // Verify interface compliance
-var _ vector.BatchIndex = (*Index)(nil)
+var (
+	_ vector.BatchIndex = (*Index)(nil)
+	_ vector.BatchSizer = (*Index)(nil)
+)
@@ -0,0 +1,265 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// defaultPollInterval is how often build progress is polled by default.
+const defaultPollInterval = time.Second
+
+// IndexProgress is a snapshot of a vector index build, read from
+// pg_stat_progress_create_index (PostgreSQL 12+).
+type IndexProgress struct {
+	// Index is the name of the index being built.
+	Index string
+	// Phase is the current build phase (e.g., "building index: loading tuples").
+	Phase string
+	// BlocksDone and BlocksTotal count heap blocks scanned in the current phase.
+	BlocksDone, BlocksTotal int64
+	// TuplesDone and TuplesTotal count tuples processed in the current phase.
+	TuplesDone, TuplesTotal int64
+}
+
+// Fraction returns the completed fraction of the current phase in [0, 1],
+// based on tuples when reported and blocks otherwise.
+func (p IndexProgress) Fraction() float64 {
+	switch {
+	case p.TuplesTotal > 0:
+		return float64(p.TuplesDone) / float64(p.TuplesTotal)
+	case p.BlocksTotal > 0:
+		return float64(p.BlocksDone) / float64(p.BlocksTotal)
+	default:
+		return 0
+	}
+}
+
+// ProgressFunc receives index build progress.
+type ProgressFunc func(IndexProgress)
+
+// AsyncIndexOptions configures CreateIndexAsync.
+type AsyncIndexOptions struct {
+	// Concurrently builds the index with CREATE INDEX CONCURRENTLY, so
+	// writes to the table are not blocked during the build. The build is
+	// slower, and a failed build is retried from scratch on the next call.
+	Concurrently bool
+	// Progress is called on every poll while the build runs (optional).
+	Progress ProgressFunc
+	// PollInterval is how often progress is polled (default 1s).
+	PollInterval time.Duration
+}
+
+// IndexBuild is a vector index build running in the background.
+type IndexBuild struct {
+	done chan struct{}
+	err  error
+}
+
+// Done returns a channel that is closed when the build finishes.
+func (b *IndexBuild) Done() <-chan struct{} {
+	return b.done
+}
+
+// Wait blocks until the build finishes and returns its error. If ctx is done
+// first, Wait returns ctx.Err() and the build continues.
+func (b *IndexBuild) Wait(ctx context.Context) error {
+	select {
+	case <-b.done:
+		return b.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CreateIndexAsync creates the table synchronously, then builds the vector
+// index in the background on a dedicated connection. Cancelling ctx cancels
+// the build. Use it with IndexTypeNone in Config so that New returns without
+// waiting for the index:
+//
+//	build, err := mgr.CreateIndexAsync(ctx, cfg, pgvector.AsyncIndexOptions{Concurrently: true})
+//	idx, err := pgvector.New(db, pgvector.Config{TableName: cfg.Name, IndexType: pgvector.IndexTypeNone, ...})
+//	// ... serve brute-force searches, then later:
+//	err = build.Wait(ctx)
+func (m *Manager) CreateIndexAsync(ctx context.Context, cfg vector.IndexConfig, opts AsyncIndexOptions) (*IndexBuild, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	table, err := m.table(cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tableCfg := cfg
+	tableCfg.IndexType = vector.IndexTypeFlat
+	if err := m.CreateIndex(ctx, tableCfg); err != nil {
+		return nil, err
+	}
+
+	build := &IndexBuild{done: make(chan struct{})}
+	stmt := vectorIndexSQL(table, cfg, opts.Concurrently)
+	if stmt == "" {
+		close(build.done)
+		return build, nil
+	}
+
+	if opts.Concurrently {
+		if err := m.dropInvalidIndex(ctx, table.quoteRelation(vectorIndexName(table.name))); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	var pid int
+	if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to get backend pid: %w", err)
+	}
+
+	go func() {
+		defer close(build.done)
+		defer func() { _ = conn.Close() }()
+
+		stop := make(chan struct{})
+		polled := make(chan struct{})
+		go func() {
+			defer close(polled)
+			if opts.Progress != nil {
+				m.pollProgress(ctx, "p.pid = $1", pid, opts.PollInterval, opts.Progress, stop)
+			}
+		}()
+
+		_, err := conn.ExecContext(ctx, stmt)
+		close(stop)
+		<-polled
+		if err != nil {
+			build.err = fmt.Errorf("failed to create vector index: %w", err)
+		}
+	}()
+
+	return build, nil
+}
+
+// WaitForIndex blocks until the vector index on a table is ready, reporting
+// progress of any running build to progress (optional). It also waits for
+// builds started elsewhere, such as by another instance; if no index exists
+// yet it keeps waiting until ctx is done. It returns an error if the index
+// was left invalid by a failed concurrent build.
+func (m *Manager) WaitForIndex(ctx context.Context, name string, progress ProgressFunc) error {
+	table, err := m.table(name)
+	if err != nil {
+		return err
+	}
+	relation := table.quoted()
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Check progress first: once no build is running, validity is final.
+		p, err := m.indexProgress(ctx, "p.relid = $1::regclass", relation)
+		if err != nil {
+			return err
+		}
+		if p != nil {
+			if progress != nil {
+				progress(*p)
+			}
+		} else {
+			var count int
+			var valid sql.NullBool
+			err := m.db.QueryRowContext(ctx, `
+				SELECT count(*), bool_and(i.indisvalid AND i.indisready)
+				FROM pg_index i
+				JOIN pg_class c ON c.oid = i.indexrelid
+				JOIN pg_am am ON am.oid = c.relam
+				WHERE i.indrelid = $1::regclass
+				  AND am.amname IN ('hnsw', 'ivfflat', 'diskann')
+			`, relation).Scan(&count, &valid)
+			if err != nil {
+				return fmt.Errorf("failed to check vector index: %w", err)
+			}
+			if count > 0 {
+				if !valid.Bool {
+					return fmt.Errorf("vector index on %s is invalid; the build failed and must be retried", name)
+				}
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollProgress reports build progress every interval until stop is closed.
+// Poll errors are ignored, since the build itself reports failures.
+func (m *Manager) pollProgress(ctx context.Context, where string, arg any, interval time.Duration, progress ProgressFunc, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if p, err := m.indexProgress(ctx, where, arg); err == nil && p != nil {
+			progress(*p)
+		}
+	}
+}
+
+// indexProgress returns the progress of the index build matching where, or
+// nil if none is running.
+func (m *Manager) indexProgress(ctx context.Context, where string, arg any) (*IndexProgress, error) {
+	var p IndexProgress
+	err := m.db.QueryRowContext(ctx, `
+		SELECT COALESCE(c.relname, ''), p.phase,
+		       p.blocks_done, p.blocks_total, p.tuples_done, p.tuples_total
+		FROM pg_stat_progress_create_index p
+		LEFT JOIN pg_class c ON c.oid = p.index_relid
+		WHERE `+where+`
+		LIMIT 1
+	`, arg).Scan(&p.Index, &p.Phase, &p.BlocksDone, &p.BlocksTotal, &p.TuplesDone, &p.TuplesTotal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index build progress: %w", err)
+	}
+	return &p, nil
+}
+
+// dropInvalidIndex drops an index left invalid by a failed concurrent build,
+// which CREATE INDEX CONCURRENTLY IF NOT EXISTS would otherwise skip. The
+// index name must be quoted.
+func (m *Manager) dropInvalidIndex(ctx context.Context, index string) error {
+	var invalid bool
+	err := m.db.QueryRowContext(ctx,
+		"SELECT NOT indisvalid FROM pg_index WHERE indexrelid = to_regclass($1)",
+		index,
+	).Scan(&invalid)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !invalid) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check index %s: %w", index, err)
+	}
+
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", index)); err != nil {
+		return fmt.Errorf("failed to drop invalid index %s: %w", index, err)
+	}
+	return nil
+}
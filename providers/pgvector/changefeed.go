@@ -0,0 +1,252 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lib/pq"
+)
+
+const (
+	// changeBuffer is the capacity of the channels returned by Changes.
+	changeBuffer = 256
+	// changeReconnectInterval is how long Changes waits before reconnecting.
+	changeReconnectInterval = time.Second
+	// changeMaxReconnectInterval caps the reconnect backoff of lib/pq.
+	changeMaxReconnectInterval = time.Minute
+	// changeUnlistenTimeout bounds stopping to listen when a feed ends.
+	changeUnlistenTimeout = 5 * time.Second
+)
+
+// ChangeOp is the kind of change reported by a ChangeEvent.
+type ChangeOp string
+
+const (
+	// ChangeInsert reports a new node.
+	ChangeInsert ChangeOp = "insert"
+	// ChangeUpdate reports an updated node, including upserts of existing
+	// nodes.
+	ChangeUpdate ChangeOp = "update"
+	// ChangeDelete reports a deleted node, including soft deletes.
+	ChangeDelete ChangeOp = "delete"
+	// ChangeResync reports that the feed reconnected and changes may have
+	// been missed; caches should be invalidated entirely. ID is empty.
+	ChangeResync ChangeOp = "resync"
+)
+
+// ChangeEvent is a change to the table, as reported by Changes.
+type ChangeEvent struct {
+	// ID is the ID of the changed node.
+	ID string
+	// Op is the kind of change.
+	Op ChangeOp
+	// Timestamp is the start time of the transaction making the change.
+	Timestamp time.Time
+}
+
+// changeChannel returns the notification channel of the change feed.
+func (idx *Index) changeChannel() string {
+	return idx.table.String() + "_changes"
+}
+
+// createChangeFeed creates the trigger notifying changeChannel of every
+// row change. Notifications are delivered when the transaction commits.
+func (idx *Index) createChangeFeed(ctx context.Context, tx *sql.Tx) error {
+	// Soft deletes are updates setting deleted_at
+	op := "lower(TG_OP)"
+	if idx.config.SoftDelete {
+		op = fmt.Sprintf("CASE WHEN TG_OP = 'UPDATE' AND NEW.%s IS NOT NULL THEN 'delete' ELSE lower(TG_OP) END", deletedColumn)
+	}
+
+	function := idx.table.quoteRelation(idx.table.name + "_notify")
+	//nolint:gosec // Identifiers escaped via pq.QuoteIdentifier, channel via pq.QuoteLiteral
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			PERFORM pg_notify(%s, json_build_object(
+				'id', CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END,
+				'op', %s,
+				'ts', now()
+			)::text);
+			RETURN NULL;
+		END
+		$$
+	`, function, pq.QuoteLiteral(idx.changeChannel()), op))
+	if err != nil {
+		return fmt.Errorf("failed to create change feed function: %w", err)
+	}
+
+	trigger := pq.QuoteIdentifier(idx.table.name + "_changes")
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", trigger, idx.table.quoted())); err != nil {
+		return fmt.Errorf("failed to replace change feed trigger: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION %s()",
+		trigger, idx.table.quoted(), function,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create change feed trigger: %w", err)
+	}
+	return nil
+}
+
+// parseChange decodes a change feed notification payload.
+func parseChange(payload string) (ChangeEvent, error) {
+	var raw struct {
+		ID string    `json:"id"`
+		Op ChangeOp  `json:"op"`
+		TS time.Time `json:"ts"`
+	}
+	if err := json.Unmarshal([]byte(payload), &raw); err != nil {
+		return ChangeEvent{}, fmt.Errorf("invalid change notification %q: %w", payload, err)
+	}
+	return ChangeEvent{ID: raw.ID, Op: raw.Op, Timestamp: raw.TS}, nil
+}
+
+// Changes streams changes to the table until ctx is done, when the channel
+// is closed. It listens on a dedicated connection opened with dsn, since
+// LISTEN can't share pooled connections, and reconnects automatically,
+// sending a ChangeResync event after each reconnect. Events are buffered;
+// a consumer that falls behind delays further notifications. The index
+// must be configured with ChangeFeed.
+func (idx *Index) Changes(ctx context.Context, dsn string) (<-chan ChangeEvent, error) {
+	if !idx.config.ChangeFeed {
+		return nil, fmt.Errorf("changes require ChangeFeed to be enabled")
+	}
+
+	listener := pq.NewListener(dsn, changeReconnectInterval, changeMaxReconnectInterval, nil)
+	if err := listener.Listen(idx.changeChannel()); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to listen for changes: %w", err)
+	}
+
+	events := make(chan ChangeEvent, changeBuffer)
+	go func() {
+		defer close(events)
+		defer func() { _ = listener.Close() }()
+
+		for {
+			var event ChangeEvent
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-listener.Notify:
+				// lib/pq sends nil after reconnecting
+				if n == nil {
+					event = ChangeEvent{Op: ChangeResync, Timestamp: time.Now()}
+				} else {
+					var err error
+					if event, err = parseChange(n.Extra); err != nil {
+						continue
+					}
+				}
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Changes is Index.Changes listening on a connection acquired from the
+// pool, which is held until ctx is done.
+func (idx *PgxIndex) Changes(ctx context.Context) (<-chan ChangeEvent, error) {
+	if !idx.config.ChangeFeed {
+		return nil, fmt.Errorf("changes require ChangeFeed to be enabled")
+	}
+	conn, err := idx.listenConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ChangeEvent, changeBuffer)
+	send := func(event ChangeEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(events)
+		for {
+			err := forwardChanges(ctx, conn.Conn(), send)
+			if err == nil {
+				releaseListenConn(ctx, conn)
+				return
+			}
+			// Destroy the broken connection instead of returning it
+			_ = conn.Conn().Close(context.Background())
+			conn.Release()
+
+			for conn = nil; conn == nil; {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(changeReconnectInterval):
+				}
+				conn, _ = idx.listenConn(ctx)
+			}
+			if !send(ChangeEvent{Op: ChangeResync, Timestamp: time.Now()}) {
+				releaseListenConn(ctx, conn)
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// listenConn acquires a connection listening on the change channel.
+func (idx *PgxIndex) listenConn(ctx context.Context) (*pgxpool.Conn, error) {
+	conn, err := idx.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pq.QuoteIdentifier(idx.changeChannel())); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to listen for changes: %w", err)
+	}
+	return conn, nil
+}
+
+// releaseListenConn stops conn listening and returns it to the pool, so
+// that notifications don't queue up on it for its next user. A connection
+// that fails to stop listening is destroyed instead.
+func releaseListenConn(ctx context.Context, conn *pgxpool.Conn) {
+	defer conn.Release()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), changeUnlistenTimeout)
+	defer cancel()
+	if _, err := conn.Exec(ctx, "UNLISTEN *"); err != nil {
+		_ = conn.Conn().Close(ctx)
+	}
+}
+
+// forwardChanges sends the notifications received on conn until ctx is done
+// or send returns false, when it returns nil, or the connection fails.
+func forwardChanges(ctx context.Context, conn *pgx.Conn, send func(ChangeEvent) bool) error {
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		event, err := parseChange(n.Payload)
+		if err != nil {
+			continue
+		}
+		if !send(event) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// Opener returns a vector.IndexOpener that opens collections as pgvector
+// tables named after the collection's index name. Settings in base that are
+// not derived from the collection (e.g., CreateTableIfNotExists, BatchSize)
+// apply to every collection.
+func Opener(db *sql.DB, base Config) vector.IndexOpener {
+	return func(ctx context.Context, cfg vector.IndexConfig) (vector.Index, error) {
+		return New(db, collectionConfig(base, cfg))
+	}
+}
+
+// collectionConfig derives a pgvector configuration from a collection's
+// provider-neutral index configuration.
+func collectionConfig(base Config, cfg vector.IndexConfig) Config {
+	base.TableName = cfg.Name
+	if cfg.Dimensions > 0 {
+		base.Dimensions = cfg.Dimensions
+	}
+
+	switch cfg.DistanceMetric {
+	case vector.DistanceCosine:
+		base.DistanceMetric = DistanceCosine
+	case vector.DistanceEuclidean:
+		base.DistanceMetric = DistanceEuclidean
+	case vector.DistanceDot:
+		base.DistanceMetric = DistanceInnerProduct
+	}
+
+	switch cfg.IndexType {
+	case vector.IndexTypeHNSW:
+		base.IndexType = IndexTypeHNSW
+	case vector.IndexTypeIVFFlat:
+		base.IndexType = IndexTypeIVFFlat
+	case vector.IndexTypeDiskANN:
+		base.IndexType = IndexTypeDiskANN
+	case vector.IndexTypeFlat:
+		base.IndexType = IndexTypeNone
+	}
+
+	if cfg.HNSWConfig != nil {
+		base.HNSWConfig = &HNSWConfig{
+			M:              cfg.HNSWConfig.M,
+			EfConstruction: cfg.HNSWConfig.EfConstruction,
+		}
+	}
+	base.DiskANNConfig = diskANNConfig(cfg.DiskANNConfig)
+
+	return base
+}
@@ -0,0 +1,88 @@
+package pgvector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+const (
+	// compressedColumn holds gzip-compressed content when CompressContent
+	// is set.
+	compressedColumn = "content_gz"
+	// compressMinSize is the content length below which content is stored
+	// uncompressed, since compression doesn't pay off for short text.
+	compressMinSize = 1024
+)
+
+// gzipMagic starts every gzip stream. It is not valid UTF-8, so it never
+// starts uncompressed content.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// createCompressed adds the compressed content column. Its storage is set to
+// EXTERNAL so PostgreSQL doesn't try to compress it again when toasting.
+func (idx *Index) createCompressed(ctx context.Context, tx *sql.Tx) error {
+	table := idx.table.quoted()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s BYTEA", table, compressedColumn,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column: %w", compressedColumn, err)
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(
+		"ALTER TABLE %s ALTER COLUMN %s SET STORAGE EXTERNAL", table, compressedColumn,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to set %s storage: %w", compressedColumn, err)
+	}
+	return nil
+}
+
+// contentColumn returns the expression selecting a row's content, with
+// columns qualified by prefix (e.g., "d."). With CompressContent, it yields
+// the compressed bytes if present and the UTF-8 content otherwise, so rows
+// written before compression was enabled stay readable.
+func (idx *Index) contentColumn(prefix string) string {
+	if !idx.config.CompressContent {
+		return prefix + "content"
+	}
+	return fmt.Sprintf("COALESCE(%[1]s%[2]s, convert_to(%[1]scontent, 'UTF8'))", prefix, compressedColumn)
+}
+
+// encodeContent returns the content and compressed content column values
+// for a node.
+func (idx *Index) encodeContent(content string) (any, any, error) {
+	if !idx.config.CompressContent || len(content) < compressMinSize {
+		return content, nil, nil
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(w, content); err != nil {
+		return nil, nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+	return nil, buf.Bytes(), nil
+}
+
+// decodeContent returns the content selected by contentColumn.
+func (idx *Index) decodeContent(raw []byte) (string, error) {
+	if !idx.config.CompressContent || !bytes.HasPrefix(raw, gzipMagic) {
+		return string(raw), nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,151 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// CountOptions configures Count.
+type CountOptions struct {
+	// Filter restricts the nodes counted.
+	Filter vector.Filter
+	// Approximate returns an estimate instead of scanning the table, for
+	// dashboards on large tables. Without a filter it is the row count
+	// maintained by VACUUM and ANALYZE (pg_class.reltuples), which includes
+	// soft-deleted rows; with a filter it is the query planner's estimate.
+	// Tables that were never analyzed are counted exactly.
+	Approximate bool
+}
+
+// Count returns the number of nodes matching opts.Filter. Soft-deleted nodes
+// are not counted, except in approximate counts without a filter.
+func (idx *Index) Count(ctx context.Context, opts CountOptions) (int64, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return 0, err
+	}
+	if err := opts.Filter.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	if opts.Approximate {
+		n, ok, err := idx.approximateCount(ctx, opts.Filter)
+		if err != nil || ok {
+			return n, err
+		}
+	}
+
+	b := &filterBuilder{columns: idx.promoted}
+	where, err := idx.where(b, opts.Filter, false)
+	if err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", err)
+	}
+	query := fmt.Sprintf("SELECT count(*) FROM %s", idx.table.quoted())
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	var count int64
+	err = idx.withSettings(ctx, idx.sessionSettings(ctx), func(db querier) error {
+		return queryRow(ctx, db, query, pqArgs(b.args), &count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count nodes: %w", idx.timeoutError(ctx, err))
+	}
+	return count, nil
+}
+
+// approximateCount estimates the number of nodes matching filter. It
+// returns false if no estimate is available.
+func (idx *Index) approximateCount(ctx context.Context, filter vector.Filter) (int64, bool, error) {
+	if filter.Op != "" {
+		return idx.plannerCount(ctx, filter)
+	}
+
+	// Partitioned tables have no rows of their own; sum their partitions
+	var (
+		count      sql.NullInt64
+		unanalyzed bool
+	)
+	err := idx.db.QueryRowContext(ctx, `
+		SELECT SUM(reltuples)::bigint, COALESCE(bool_or(reltuples < 0), false)
+		FROM pg_class
+		WHERE relkind = 'r' AND (
+			oid = $1::regclass OR
+			oid IN (SELECT inhrelid FROM pg_inherits WHERE inhparent = $1::regclass)
+		)
+	`, idx.table.quoted()).Scan(&count, &unanalyzed)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to estimate node count: %w", err)
+	}
+	if !count.Valid || unanalyzed {
+		return 0, false, nil
+	}
+	return count.Int64, true, nil
+}
+
+// plannerCount returns the planner's row estimate for the nodes matching
+// filter.
+func (idx *Index) plannerCount(ctx context.Context, filter vector.Filter) (int64, bool, error) {
+	b := &filterBuilder{columns: idx.promoted}
+	where, err := idx.where(b, filter, false)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var plan []byte
+	err = idx.db.QueryRowContext(ctx,
+		fmt.Sprintf("EXPLAIN (FORMAT JSON) SELECT 1 FROM %s WHERE %s", idx.table.quoted(), where),
+		pqArgs(b.args)...,
+	).Scan(&plan)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to estimate node count: %w", err)
+	}
+	n, err := planRows(plan)
+	if err != nil {
+		return 0, false, err
+	}
+	return n, true, nil
+}
+
+// planRows returns the estimated rows of the top plan node in EXPLAIN
+// (FORMAT JSON) output.
+func planRows(plan []byte) (int64, error) {
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return 0, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+	if len(explain) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int64(explain[0].Plan.Rows), nil
+}
+
+// queryRow scans the single row returned by query into dest.
+func queryRow(ctx context.Context, db querier, query string, args []any, dest ...any) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Close()
+}
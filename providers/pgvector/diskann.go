@@ -0,0 +1,128 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// DiskANNStorage defines how a DiskANN index stores vectors.
+type DiskANNStorage string
+
+const (
+	// DiskANNMemoryOptimized compresses vectors with statistical binary
+	// quantization (the pgvectorscale default).
+	DiskANNMemoryOptimized DiskANNStorage = "memory_optimized"
+	// DiskANNPlain stores full vectors in the index.
+	DiskANNPlain DiskANNStorage = "plain"
+)
+
+// DiskANNConfig contains DiskANN index parameters. Zero values use the
+// pgvectorscale defaults.
+type DiskANNConfig struct {
+	// StorageLayout is memory_optimized (default) or plain.
+	StorageLayout DiskANNStorage
+	// NumNeighbors is the maximum number of neighbors per node (default 50).
+	NumNeighbors int
+	// SearchListSize is the size of the candidate list during construction
+	// (default 100).
+	SearchListSize int
+	// MaxAlpha is the pruning parameter (default 1.2).
+	MaxAlpha float64
+	// NumBitsPerDimension is the number of bits per dimension of
+	// memory-optimized storage (default 2 below 900 dimensions, else 1).
+	NumBitsPerDimension int
+	// QuerySearchListSize is the size of the candidate list during searches
+	// (diskann.query_search_list_size, default 100).
+	QuerySearchListSize int
+	// QueryRescore is the number of candidates re-ranked by exact distance
+	// with memory-optimized storage (diskann.query_rescore, default 50).
+	QueryRescore int
+}
+
+// withOptions returns the WITH clause of the index build parameters that
+// are set, or "".
+func (c *DiskANNConfig) withOptions() string {
+	if c == nil {
+		return ""
+	}
+	var options []string
+	if c.StorageLayout != "" {
+		options = append(options, "storage_layout = "+pq.QuoteLiteral(string(c.StorageLayout)))
+	}
+	if c.NumNeighbors > 0 {
+		options = append(options, fmt.Sprintf("num_neighbors = %d", c.NumNeighbors))
+	}
+	if c.SearchListSize > 0 {
+		options = append(options, fmt.Sprintf("search_list_size = %d", c.SearchListSize))
+	}
+	if c.MaxAlpha > 0 {
+		options = append(options, "max_alpha = "+strconv.FormatFloat(c.MaxAlpha, 'g', -1, 64))
+	}
+	if c.NumBitsPerDimension > 0 {
+		options = append(options, fmt.Sprintf("num_bits_per_dimension = %d", c.NumBitsPerDimension))
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return "WITH (" + strings.Join(options, ", ") + ")"
+}
+
+// settings returns the SET LOCAL statements applying the search parameters
+// that are set.
+func (c *DiskANNConfig) settings() []string {
+	if c == nil {
+		return nil
+	}
+	var settings []string
+	if c.QuerySearchListSize > 0 {
+		settings = append(settings, fmt.Sprintf("SET LOCAL diskann.query_search_list_size = %d", c.QuerySearchListSize))
+	}
+	if c.QueryRescore > 0 {
+		settings = append(settings, fmt.Sprintf("SET LOCAL diskann.query_rescore = %d", c.QueryRescore))
+	}
+	return settings
+}
+
+// validate checks the configuration.
+func (c *DiskANNConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	switch c.StorageLayout {
+	case "", DiskANNMemoryOptimized, DiskANNPlain:
+	default:
+		return fmt.Errorf("unknown diskann storage layout %q", c.StorageLayout)
+	}
+	if c.NumNeighbors < 0 || c.SearchListSize < 0 || c.MaxAlpha < 0 || c.NumBitsPerDimension < 0 ||
+		c.QuerySearchListSize < 0 || c.QueryRescore < 0 {
+		return fmt.Errorf("diskann parameters must not be negative")
+	}
+	return nil
+}
+
+// createVectorscaleExtension ensures the pgvectorscale extension providing
+// the diskann access method is available.
+func createVectorscaleExtension(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vectorscale CASCADE"); err != nil {
+		return fmt.Errorf("failed to create vectorscale extension: %w", err)
+	}
+	return nil
+}
+
+// diskANNConfig converts the DiskANN settings of a vector.IndexConfig.
+func diskANNConfig(cfg *vector.DiskANNConfig) *DiskANNConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &DiskANNConfig{
+		NumNeighbors:   cfg.NumNeighbors,
+		SearchListSize: cfg.SearchListSize,
+		MaxAlpha:       cfg.MaxAlpha,
+	}
+}
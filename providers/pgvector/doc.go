@@ -4,10 +4,62 @@
 // # Features
 //
 //   - Full vector.Index, vector.BatchIndex, and vector.IndexManager support
-//   - HNSW and IVFFlat index types
-//   - Cosine, Euclidean, and Inner Product distance metrics
-//   - Efficient batch upsert using PostgreSQL's ON CONFLICT
-//   - Metadata filtering via JSONB
+//   - Lookup of nodes by ID, including embeddings (vector.GetIndex), and
+//     paginated listing of all nodes (List), e.g. to re-embed a corpus
+//   - Exact filtered counts and cheap approximate counts (Count)
+//   - HNSW and IVFFlat index types, with per-search ef_search and probes
+//     tuning (vector.TunableIndex)
+//   - pgvectorscale DiskANN indexes (IndexTypeDiskANN) with storage, build,
+//     and search parameters (DiskANNConfig)
+//   - Cosine, Euclidean, and Inner Product distance metrics, with optional
+//     scores normalized to [0, 1] for every metric (NormalizeScores)
+//   - vector, halfvec, and sparsevec column types (halfvec and sparsevec
+//     require pgvector 0.7+); embeddings are always returned dense
+//   - Efficient batch upsert using PostgreSQL's ON CONFLICT, automatically
+//     chunked to stay under the 65535 bind parameter limit
+//   - Embedding lengths validated against Dimensions before writing, with
+//     a descriptive *DimensionError (ErrDimensionMismatch)
+//   - Per-search distance metric overrides (SearchOptions.Metric), indexed
+//     for the metrics listed in ExtraMetrics
+//   - Metadata filtering via JSONB, including comparison, list, and
+//     existence filter expressions (vector.FilterIndex)
+//   - Promoted columns: frequently filtered metadata keys mapped to typed,
+//     b-tree indexed generated columns that filters use automatically
+//   - Hybrid full-text + vector search fused in a single SQL statement
+//   - Streaming search (SearchIter) over a server-side cursor for large k
+//   - Introspection (vector.IntrospectableIndex): Exists, and Describe
+//     reporting the table's actual dimensions for startup validation
+//   - Grouped search (vector.GroupIndex): the best result per metadata
+//     value, e.g. one chunk per document, with DISTINCT ON
+//   - Keyset-paginated search (SearchPage) with opaque cursor tokens
+//   - Maximal marginal relevance search (SearchMMR) for diverse results
+//   - LIST partitioning by a metadata value (e.g., tenant or month), with
+//     partitions created on write and pruned by eq and in filters
+//   - Transparent gzip compression of large content (CompressContent)
+//   - Binary and halfvec quantization (Quantization): searches use a small
+//     quantized index and re-rank the candidates by exact distance
+//   - Soft delete (SoftDelete): deleted rows are kept with a deleted_at
+//     timestamp, hidden from searches unless IncludeDeleted is set, and
+//     removed with Purge
+//   - Node expiration (Expiration): expired rows are hidden from reads and
+//     removed with PurgeExpired, e.g. by a vector.Reaper
+//   - Writes in a caller-managed transaction (WithTx), atomic with the
+//     caller's own tables
+//   - Tenant scoping (TenantKey): every operation is restricted to the tenant
+//     passed with WithTenant, optionally also set for row-level security
+//     policies (TenantSetting)
+//   - Namespaces (NamespaceKey): vector.NamespaceIndex support, scoping
+//     operations to the namespace passed with vector.WithNamespace
+//   - Change feed (ChangeFeed): a trigger and LISTEN/NOTIFY stream every
+//     insert, update, and delete as a ChangeEvent (Changes)
+//   - Index aliases for blue-green reindexing
+//   - Table migration to new dimensions or distance metrics (Migrate), with
+//     optional re-embedding and an atomic table swap
+//   - Background index builds (optionally CONCURRENTLY) with progress
+//     reporting from pg_stat_progress_create_index
+//   - vector.IndexOptimizer support for background maintenance with
+//     vector.Optimizer: ANALYZE, and REINDEX CONCURRENTLY (PostgreSQL 12+)
+//     that re-sizes IVFFlat lists to the current row count
 //
 // # Usage
 //
@@ -35,20 +87,94 @@
 //		Embedder: myEmbedder,
 //	})
 //
+// # pgx
+//
+// NewPgx creates an index over a pgx connection pool that sends embeddings
+// in pgvector's binary wire format and uses binary COPY for InsertBatch.
+// For 1536-dimensional embeddings, encoding and decoding alone is roughly
+// two orders of magnitude cheaper than the text format (see the benchmarks
+// in helpers_test.go and, against a live database, pgvector_test.go):
+//
+//	poolCfg, _ := pgxpool.ParseConfig(dsn)
+//	poolCfg.AfterConnect = pgvector.RegisterTypes
+//	pool, _ := pgxpool.NewWithConfig(ctx, poolCfg)
+//	idx, err := pgvector.NewPgx(pool, pgvector.DefaultConfig("embeddings", 1536))
+//
 // # Configuration
 //
 // The Config struct allows customization of:
 //
-//   - Table name and vector dimensions
+//   - Table name, vector dimensions, and column type (vector, halfvec, sparsevec)
+//   - Schema, set with Schema or as "schema.table"; qualified names are
+//     quoted in all statements and don't depend on search_path
 //   - Distance metric (cosine, euclidean, inner_product)
 //   - Index type (HNSW, IVFFlat, or none)
 //   - HNSW parameters (M, ef_construction)
 //   - IVFFlat parameters (lists)
+//   - Batch chunk size, whether chunks share one transaction, and how many
+//     chunks are written in parallel (BatchConcurrency)
+//   - Statement timeouts for searches and maintenance (StatementTimeout,
+//     overridable per call with WithStatementTimeout), reported as
+//     *TimeoutError
+//   - Prepared statement caching for hot query shapes (StatementCacheSize)
+//   - Retries of transient errors (serialization failures, deadlocks,
+//     dropped connections) with exponential backoff (Retry)
+//   - Schema handling: create on startup (CreateTableIfNotExists) or verify
+//     an externally managed schema without running DDL (VerifySchema)
+//
+// Schema DDL runs in a transaction holding a PostgreSQL advisory lock, so
+// multiple application instances can start concurrently without racing on
+// CREATE EXTENSION, CREATE TABLE, or CREATE INDEX.
+//
+// # Hybrid Search
+//
+// With FullText set, the table gets a generated tsvector column over content
+// and a GIN index. HybridSearch then ranks nodes by ts_rank_cd and by vector
+// distance and fuses both rankings in one round trip, using reciprocal rank
+// fusion or a weighted sum of scores:
+//
+//	cfg := pgvector.DefaultConfig("docs", 1536)
+//	cfg.FullText = true
+//	idx, _ := pgvector.New(db, cfg)
+//	results, err := idx.HybridSearch(ctx, embedding, "postgres \"connection pool\"", 10,
+//		pgvector.HybridOptions{Fusion: pgvector.FusionWeighted, VectorWeight: 0.7, TextWeight: 0.3})
+//
+// # Background Index Builds
+//
+// Building an HNSW index over millions of rows can take a long time. Open
+// the index with IndexTypeNone so New returns immediately, and build the
+// vector index in the background with Manager.CreateIndexAsync:
+//
+//	build, err := mgr.CreateIndexAsync(ctx, vector.IndexConfig{
+//		Name: "docs", Dimensions: 1536, IndexType: vector.IndexTypeHNSW,
+//	}, pgvector.AsyncIndexOptions{
+//		Concurrently: true,
+//		Progress: func(p pgvector.IndexProgress) {
+//			log.Printf("%s: %.0f%%", p.Phase, 100*p.Fraction())
+//		},
+//	})
+//	// ... later
+//	err = build.Wait(ctx)
+//
+// Manager.WaitForIndex waits for a build started by any connection, such as
+// another application instance.
+//
+// # Aliases
+//
+// Manager.SwapAlias points an alias (a view) at a table in one transaction,
+// enabling zero-downtime re-embedding: build and validate a new table, then
+// flip the alias. Open indexes by alias name with VerifySchema set. Use
+// NewSchemaManager to manage tables in a schema other than the current one:
+//
+//	mgr := pgvector.NewManager(db)
+//	_ = mgr.CreateIndex(ctx, vector.IndexConfig{Name: "docs_v2", Dimensions: 3072})
+//	// ... backfill and validate docs_v2 ...
+//	_ = mgr.SwapAlias(ctx, "docs", "docs_v2")
 //
 // # Requirements
 //
 //   - PostgreSQL 11+ with pgvector extension installed
-//   - CREATE EXTENSION permissions (or pre-installed extension)
+//   - CREATE EXTENSION permissions (or pre-installed extension and VerifySchema)
 //
 // # Index Types
 //
@@ -0,0 +1,133 @@
+package pgvector
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// ErrDimensionMismatch is matched by errors.Is for every *DimensionError. It
+// is vector.ErrDimensionMismatch.
+var ErrDimensionMismatch = vector.ErrDimensionMismatch
+
+// DimensionError reports a node whose embedding length doesn't match
+// Config.Dimensions. Writes are validated before any statement is sent, so
+// a batch containing such a node writes nothing.
+type DimensionError struct {
+	// ID is the ID of the offending node.
+	ID string
+	// Expected is the configured number of dimensions.
+	Expected int
+	// Actual is the length of the node's embedding.
+	Actual int
+}
+
+// Error implements error.
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("%s: node %s has %d dimensions, expected %d",
+		ErrDimensionMismatch, e.ID, e.Actual, e.Expected)
+}
+
+// Is reports whether target is ErrDimensionMismatch.
+func (e *DimensionError) Is(target error) bool {
+	return target == ErrDimensionMismatch
+}
+
+// Permanent implements retrieve.PermanentError.
+func (e *DimensionError) Permanent() bool {
+	return true
+}
+
+// checkNodes validates the embedding dimensions and promoted column values
+// of nodes before they are written.
+func (idx *Index) checkNodes(nodes []vector.Node) error {
+	if err := idx.checkDimensions(nodes); err != nil {
+		return err
+	}
+	return idx.checkPromoted(nodes)
+}
+
+// checkDimensions returns a *DimensionError for the first node whose
+// embedding length doesn't match the configured dimensions.
+func (idx *Index) checkDimensions(nodes []vector.Node) error {
+	for _, node := range nodes {
+		if len(node.Embedding) != idx.config.Dimensions {
+			return &DimensionError{ID: node.ID, Expected: idx.config.Dimensions, Actual: len(node.Embedding)}
+		}
+	}
+	return nil
+}
+
+// checkUpserted returns an error wrapping vector.ErrConflict if fewer than n
+// of the nodes written by upsertQuery were affected. The statement skips
+// nodes whose ID is taken by another tenant's or namespace's node.
+func checkUpserted(affected int64, n int) error {
+	if affected < int64(n) {
+		return fmt.Errorf("%w: %d of %d nodes have an ID taken in another tenant or namespace", vector.ErrConflict, int64(n)-affected, n)
+	}
+	return nil
+}
+
+// upserted is checkUpserted for a database/sql result.
+func upserted(res sql.Result, n int) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return checkUpserted(affected, n)
+}
+
+// backendError wraps err with the vector package error its SQLSTATE maps
+// to: vector.ErrIndexNotReady for a missing table or database, or a server
+// starting up, vector.ErrRateLimited for exhausted connection slots, and
+// vector.ErrDimensionMismatch for a query embedding of the wrong length.
+func backendError(err error) error {
+	var kind error
+	switch code := sqlState(err); {
+	case code == "42P01", code == "3D000", code == "57P03": // undefined_table, invalid_catalog_name, cannot_connect_now
+		kind = vector.ErrIndexNotReady
+	case code == "53300": // too_many_connections
+		kind = vector.ErrRateLimited
+	case code == "22000" && strings.Contains(err.Error(), "different vector dimensions"):
+		kind = vector.ErrDimensionMismatch
+	}
+	if kind == nil || errors.Is(err, kind) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", kind, err)
+}
+
+// ErrNoTenant is returned by operations on a tenant-scoped index whose
+// context carries no tenant.
+var ErrNoTenant = retrieve.NewPermanentError("no tenant in context; use pgvector.WithTenant")
+
+// ErrStatementTimeout is matched by errors.Is for every *TimeoutError.
+var ErrStatementTimeout = errors.New("statement timeout")
+
+// TimeoutError reports a statement canceled by the statement timeout.
+type TimeoutError struct {
+	// Timeout is the statement timeout that was exceeded.
+	Timeout time.Duration
+	// Err is the error returned by the driver.
+	Err error
+}
+
+// Error implements error.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s of %s exceeded: %v", ErrStatementTimeout, e.Timeout, e.Err)
+}
+
+// Unwrap returns the driver error.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrStatementTimeout.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrStatementTimeout
+}
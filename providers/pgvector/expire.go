@@ -0,0 +1,77 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+const (
+	// expiresColumn records when a row expires (NULL: never).
+	expiresColumn = "expires_at"
+	// notExpired is the predicate excluding expired rows.
+	notExpired = "(" + expiresColumn + " IS NULL OR " + expiresColumn + " > NOW())"
+)
+
+// createExpiration adds the expires_at column and a partial index on it for
+// PurgeExpired.
+func (idx *Index) createExpiration(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s TIMESTAMP WITH TIME ZONE",
+		idx.table.quoted(), expiresColumn,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column: %w", expiresColumn, err)
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (%s) WHERE %[3]s IS NOT NULL",
+		pq.QuoteIdentifier(idx.table.name+"_"+expiresColumn+"_idx"), idx.table.quoted(), expiresColumn,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create %s index: %w", expiresColumn, err)
+	}
+	return nil
+}
+
+// expiresArg returns the expires_at bind argument for a node.
+func expiresArg(node vector.Node) any {
+	if node.ExpiresAt.IsZero() {
+		return nil
+	}
+	return node.ExpiresAt
+}
+
+// PurgeExpired implements vector.ExpiringIndex. It removes the rows that
+// expired at or before now across tenants and namespaces, so with row-level
+// security (TenantSetting) the role must bypass the policies. It fails with
+// vector.ErrExpirationUnsupported unless Expiration is enabled.
+func (idx *Index) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	if !idx.config.Expiration {
+		return 0, vector.ErrExpirationUnsupported
+	}
+
+	var purged int64
+	err := idx.retry(ctx, func() error {
+		res, err := idx.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s <= $1",
+			idx.table.quoted(), expiresColumn), now)
+		if err != nil {
+			return err
+		}
+		purged, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("purge failed: %w", idx.timeoutError(ctx, err))
+	}
+	return purged, nil
+}
+
+// Verify interface compliance
+var (
+	_ vector.ExpiringIndex = (*Index)(nil)
+	_ vector.ExpiringIndex = (*PgxIndex)(nil)
+)
@@ -0,0 +1,139 @@
+package pgvector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// numericPattern matches metadata values that can be cast to numeric. Values
+// are checked before casting so that non-numeric values never match instead
+// of failing the query.
+const numericPattern = `^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`
+
+// comparisonOperators maps comparison filter operators to SQL.
+var comparisonOperators = map[vector.FilterOp]string{
+	vector.FilterGt:  ">",
+	vector.FilterGte: ">=",
+	vector.FilterLt:  "<",
+	vector.FilterLte: "<=",
+}
+
+// filterBuilder translates filter expressions into parameterized JSONB
+// predicates, appending bind arguments to args. List operands are bound as
+// []string, which callers adapt to their driver. Keys in columns are
+// filtered on their promoted column instead.
+type filterBuilder struct {
+	args    []any
+	columns map[string]PromotedColumn
+}
+
+// arg adds a bind argument and returns its placeholder.
+func (b *filterBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// build returns the SQL predicate for f. The filter must be valid.
+func (b *filterBuilder) build(f vector.Filter) (string, error) {
+	if c, ok := b.columns[f.Key]; ok && f.Key != "" {
+		return b.buildColumn(f, c)
+	}
+
+	switch f.Op {
+	case "":
+		return "TRUE", nil
+	case vector.FilterAnd, vector.FilterOr:
+		if len(f.Filters) == 0 {
+			if f.Op == vector.FilterAnd {
+				return "TRUE", nil
+			}
+			return "FALSE", nil
+		}
+		parts := make([]string, len(f.Filters))
+		for i, sub := range f.Filters {
+			part, err := b.build(sub)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = "(" + part + ")"
+		}
+		return strings.Join(parts, " "+strings.ToUpper(string(f.Op))+" "), nil
+	case vector.FilterExists:
+		return fmt.Sprintf("metadata ? %s", b.arg(f.Key)), nil
+	case vector.FilterIn:
+		values, err := vector.FilterValues(f.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("metadata->>%s = ANY(%s::text[])", b.arg(f.Key), b.arg(values)), nil
+	case vector.FilterContains:
+		return fmt.Sprintf("strpos(metadata->>%s, %s) > 0", b.arg(f.Key), b.arg(f.Value)), nil
+	}
+
+	if op, ok := comparisonOperators[f.Op]; ok {
+		key := b.arg(f.Key)
+		if n, ok := vector.FilterNumber(f.Value); ok {
+			return fmt.Sprintf("CASE WHEN metadata->>%[1]s ~ '%[2]s' THEN (metadata->>%[1]s)::numeric %[3]s %[4]s::numeric ELSE FALSE END",
+				key, numericPattern, op, b.arg(n)), nil
+		}
+		// Compare bytes, matching Go string ordering regardless of collation
+		return fmt.Sprintf(`metadata->>%s COLLATE "C" %s %s`, key, op, b.arg(f.Value)), nil
+	}
+
+	value, err := vector.FormatFilterValue(f.Value)
+	if err != nil {
+		return "", err
+	}
+	switch f.Op {
+	case vector.FilterEq:
+		return fmt.Sprintf("metadata->>%s = %s", b.arg(f.Key), b.arg(value)), nil
+	case vector.FilterNeq:
+		return fmt.Sprintf("metadata->>%s IS DISTINCT FROM %s", b.arg(f.Key), b.arg(value)), nil
+	}
+	return "", fmt.Errorf("unknown filter operator %q", f.Op)
+}
+
+// buildColumn returns the SQL predicate for a leaf filter on a promoted
+// column. Text columns keep the JSONB semantics; operands of other types are
+// cast to the column type.
+func (b *filterBuilder) buildColumn(f vector.Filter, c PromotedColumn) (string, error) {
+	column := pq.QuoteIdentifier(c.Column)
+
+	switch f.Op {
+	case vector.FilterExists:
+		return column + " IS NOT NULL", nil
+	case vector.FilterIn:
+		values, err := vector.FilterValues(f.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s = ANY(%s::%s[])", column, b.arg(values), c.Type), nil
+	case vector.FilterContains:
+		return fmt.Sprintf("strpos(%s::text, %s) > 0", column, b.arg(f.Value)), nil
+	}
+
+	if op, ok := comparisonOperators[f.Op]; ok && c.Type == ColumnText {
+		if n, ok := vector.FilterNumber(f.Value); ok {
+			return fmt.Sprintf("CASE WHEN %[1]s ~ '%[2]s' THEN %[1]s::numeric %[3]s %[4]s::numeric ELSE FALSE END",
+				column, numericPattern, op, b.arg(n)), nil
+		}
+	}
+
+	value, err := vector.FormatFilterValue(f.Value)
+	if err != nil {
+		return "", err
+	}
+	if op, ok := comparisonOperators[f.Op]; ok {
+		return fmt.Sprintf("%s %s %s::%s", column, op, b.arg(value), c.Type), nil
+	}
+	switch f.Op {
+	case vector.FilterEq:
+		return fmt.Sprintf("%s = %s::%s", column, b.arg(value), c.Type), nil
+	case vector.FilterNeq:
+		return fmt.Sprintf("%s IS DISTINCT FROM %s::%s", column, b.arg(value), c.Type), nil
+	}
+	return "", fmt.Errorf("unknown filter operator %q", f.Op)
+}
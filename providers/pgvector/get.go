@@ -0,0 +1,108 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// Get implements vector.GetIndex. Soft-deleted nodes are not returned.
+func (idx *Index) Get(ctx context.Context, id string) (vector.Node, error) {
+	return getOne(idx.GetBatch(ctx, []string{id}))
+}
+
+// GetBatch implements vector.GetIndex.
+func (idx *Index) GetBatch(ctx context.Context, ids []string) ([]vector.Node, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query, args := idx.getQuery(ids, s)
+	return collectNodes(ids, func(fn func(vector.SearchResult) bool) error {
+		return idx.withSettings(ctx, idx.tenantSettings(ctx), func(db querier) error {
+			return idx.scan(ctx, db, query, pqArgs(args), fn)
+		})
+	})
+}
+
+// Get implements vector.GetIndex.
+func (idx *PgxIndex) Get(ctx context.Context, id string) (vector.Node, error) {
+	return getOne(idx.GetBatch(ctx, []string{id}))
+}
+
+// GetBatch implements vector.GetIndex.
+func (idx *PgxIndex) GetBatch(ctx context.Context, ids []string) ([]vector.Node, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query, args := idx.getQuery(ids, s)
+	return collectNodes(ids, func(fn func(vector.SearchResult) bool) error {
+		return idx.withSettings(ctx, idx.tenantSettings(ctx), func(db pgxQuerier) error {
+			return idx.scan(ctx, db, query, args, fn)
+		})
+	})
+}
+
+// getQuery builds the statement selecting nodes by ID within s, returning
+// the columns scanned by search with a zero score.
+func (idx *Index) getQuery(ids []string, s scope) (string, []any) {
+	where, args := idx.restrict("id = ANY($1)", []any{ids}, s)
+	if idx.config.SoftDelete {
+		where += " AND " + deletedColumn + " IS NULL"
+	}
+	if idx.config.Expiration {
+		where += " AND " + notExpired
+	}
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, IDs are parameterized
+	query := fmt.Sprintf(`
+		SELECT id, %s, embedding::vector, source, metadata, 0::float8
+		FROM %s
+		WHERE %s
+	`, idx.contentColumn(""), idx.table.quoted(), where)
+	return query, args
+}
+
+// collectNodes scans nodes with scan and returns them in the order of ids.
+func collectNodes(ids []string, scan func(fn func(vector.SearchResult) bool) error) ([]vector.Node, error) {
+	found := make(map[string]vector.Node, len(ids))
+	err := scan(func(r vector.SearchResult) bool {
+		found[r.Node.ID] = r.Node
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get failed: %w", err)
+	}
+
+	nodes := make([]vector.Node, 0, len(found))
+	for _, id := range ids {
+		if node, ok := found[id]; ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// getOne returns the single node of a GetBatch result.
+func getOne(nodes []vector.Node, err error) (vector.Node, error) {
+	if err != nil {
+		return vector.Node{}, err
+	}
+	if len(nodes) == 0 {
+		return vector.Node{}, vector.ErrNodeNotFound
+	}
+	return nodes[0], nil
+}
+
+// Verify interface compliance
+var (
+	_ vector.GetIndex = (*Index)(nil)
+	_ vector.GetIndex = (*PgxIndex)(nil)
+)
@@ -0,0 +1,92 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// SearchGroups implements vector.GroupIndex with DISTINCT ON over the
+// groupBy metadata value. Every matching row is ranked exactly, bypassing
+// approximate indexes, so narrow large tables down with a filter.
+func (idx *Index) SearchGroups(ctx context.Context, embedding []float32, k int, groupBy string, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := idx.groupQuery(idx.encodeText(embedding), k, groupBy, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchWithSettings(ctx, query, pqArgs(args), settings)
+}
+
+// SearchGroups is Index.SearchGroups using the binary vector encoding.
+func (idx *PgxIndex) SearchGroups(ctx context.Context, embedding []float32, k int, groupBy string, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := idx.groupQuery(idx.encodeBinary(embedding), k, groupBy, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchWithSettings(ctx, query, args, settings)
+}
+
+// groupQuery builds the grouped search statement: the best row per groupBy
+// value, ranked by score.
+func (idx *Index) groupQuery(embedding any, k int, groupBy string, opts vector.SearchOptions) (string, []any, error) {
+	if groupBy == "" {
+		return "", nil, fmt.Errorf("group key is required")
+	}
+	if err := opts.Filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	metric, err := idx.searchMetric(opts.Metric)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b := &filterBuilder{args: []any{embedding}, columns: idx.promoted}
+	where, err := idx.where(b, opts.Filter, opts.IncludeDeleted)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	group := "metadata->>" + pq.QuoteLiteral(groupBy)
+	if where != "" {
+		where += " AND "
+	}
+	where += group + " IS NOT NULL"
+
+	distance := fmt.Sprintf("embedding %s $1::%s", metricOperator(metric), idx.config.VectorType)
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, group key via pq.QuoteLiteral
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT DISTINCT ON (%[2]s) id, %[3]s, embedding::vector, source, metadata,
+			       %[4]s as score
+			FROM %[1]s
+			WHERE %[5]s
+			ORDER BY %[2]s, %[6]s, id
+		) groups
+		ORDER BY score DESC, id LIMIT %[7]s
+	`, idx.table.quoted(), group, idx.contentColumn(""), idx.metricScoreExpr(metric, distance), where, distance, b.arg(k))
+	return query, b.args, nil
+}
+
+// Verify interface compliance
+var (
+	_ vector.GroupIndex = (*Index)(nil)
+	_ vector.GroupIndex = (*PgxIndex)(nil)
+)
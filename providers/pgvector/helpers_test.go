@@ -1,7 +1,20 @@
 package pgvector
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	pgv "github.com/pgvector/pgvector-go"
 )
 
 func TestVectorToString(t *testing.T) {
@@ -83,18 +96,21 @@ func TestParseVector(t *testing.T) {
 
 func TestDistanceOpClass(t *testing.T) {
 	tests := []struct {
-		metric   DistanceMetric
-		expected string
+		vectorType VectorType
+		metric     DistanceMetric
+		expected   string
 	}{
-		{DistanceCosine, "vector_cosine_ops"},
-		{DistanceEuclidean, "vector_l2_ops"},
-		{DistanceInnerProduct, "vector_ip_ops"},
-		{"unknown", "vector_cosine_ops"}, // Default
+		{VectorTypeVector, DistanceCosine, "vector_cosine_ops"},
+		{VectorTypeVector, DistanceEuclidean, "vector_l2_ops"},
+		{VectorTypeVector, DistanceInnerProduct, "vector_ip_ops"},
+		{VectorTypeVector, "unknown", "vector_cosine_ops"}, // Default
+		{VectorTypeHalfVec, DistanceCosine, "halfvec_cosine_ops"},
+		{VectorTypeSparseVec, DistanceEuclidean, "sparsevec_l2_ops"},
 	}
 
 	for _, tt := range tests {
-		t.Run(string(tt.metric), func(t *testing.T) {
-			idx := &Index{config: Config{DistanceMetric: tt.metric}}
+		t.Run(tt.expected, func(t *testing.T) {
+			idx := &Index{config: Config{VectorType: tt.vectorType, DistanceMetric: tt.metric}}
 			result := idx.distanceOpClass()
 			if result != tt.expected {
 				t.Errorf("distanceOpClass() = %s, want %s", result, tt.expected)
@@ -154,3 +170,1358 @@ func TestDefaultConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRelOptions(t *testing.T) {
+	params := parseRelOptions([]string{"m=32", "ef_construction=128", "invalid"})
+
+	if len(params) != 2 {
+		t.Fatalf("expected 2 params, got %d", len(params))
+	}
+	if params["m"] != "32" {
+		t.Errorf("params[m] = %s, want 32", params["m"])
+	}
+	if params["ef_construction"] != "128" {
+		t.Errorf("params[ef_construction] = %s, want 128", params["ef_construction"])
+	}
+
+	if params := parseRelOptions(nil); len(params) != 0 {
+		t.Errorf("expected empty params for nil options, got %v", params)
+	}
+}
+
+func TestCheckColumns(t *testing.T) {
+	valid := map[string]string{
+		"id":         "text",
+		"content":    "text",
+		"embedding":  "vector(128)",
+		"source":     "text",
+		"metadata":   "jsonb",
+		"created_at": "timestamp with time zone",
+	}
+
+	if err := checkColumns(valid, VectorTypeVector, 128); err != nil {
+		t.Errorf("unexpected error for valid schema: %v", err)
+	}
+
+	if err := checkColumns(valid, VectorTypeVector, 256); err == nil {
+		t.Error("expected error for dimension mismatch")
+	}
+
+	if err := checkColumns(valid, VectorTypeHalfVec, 128); err == nil {
+		t.Error("expected error for vector type mismatch")
+	}
+
+	missing := map[string]string{"id": "text", "embedding": "vector(128)"}
+	if err := checkColumns(missing, VectorTypeVector, 128); err == nil {
+		t.Error("expected error for missing columns")
+	}
+}
+
+func TestVectorType(t *testing.T) {
+	// No table DDL runs, so a nil database is fine here
+	idx, err := New(nil, Config{TableName: "t", Dimensions: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.config.VectorType != VectorTypeVector {
+		t.Errorf("expected default vector type, got %s", idx.config.VectorType)
+	}
+
+	idx, err = New(nil, Config{TableName: "t", Dimensions: 4, VectorType: VectorTypeSparseVec})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := idx.encodeText([]float32{1, 0, 2.5, 0}); got != "{1:1,3:2.5}/4" {
+		t.Errorf("unexpected sparsevec encoding: %s", got)
+	}
+
+	if _, err := New(nil, Config{TableName: "t", Dimensions: 4, VectorType: VectorTypeSparseVec, IndexType: IndexTypeIVFFlat}); err == nil {
+		t.Error("expected error for sparsevec with ivfflat")
+	}
+	if _, err := New(nil, Config{TableName: "t", Dimensions: 4, VectorType: "bit"}); err == nil {
+		t.Error("expected error for unknown vector type")
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	// No table DDL runs, so a nil database is fine here
+	idx, err := New(nil, Config{TableName: "t", Dimensions: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.MaxBatchSize() != maxBatchSize {
+		t.Errorf("expected default batch size %d, got %d", maxBatchSize, idx.MaxBatchSize())
+	}
+
+	idx, err = New(nil, Config{TableName: "t", Dimensions: 4, BatchSize: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.MaxBatchSize() != 1000 {
+		t.Errorf("expected batch size 1000, got %d", idx.MaxBatchSize())
+	}
+
+	if _, err := New(nil, Config{TableName: "t", Dimensions: 4, BatchSize: maxBatchSize + 1}); err == nil {
+		t.Error("expected error for batch size above the parameter limit")
+	}
+}
+
+func TestCollectionConfig(t *testing.T) {
+	base := DefaultConfig("", 0)
+	base.BatchSize = 500
+
+	cfg := collectionConfig(base, vector.IndexConfig{
+		Name:           "app_docs",
+		Dimensions:     768,
+		DistanceMetric: vector.DistanceDot,
+		IndexType:      vector.IndexTypeHNSW,
+		HNSWConfig:     &vector.HNSWConfig{M: 32, EfConstruction: 200},
+	})
+
+	if cfg.TableName != "app_docs" || cfg.Dimensions != 768 {
+		t.Errorf("unexpected table or dimensions: %s, %d", cfg.TableName, cfg.Dimensions)
+	}
+	if cfg.DistanceMetric != DistanceInnerProduct {
+		t.Errorf("expected inner product metric, got %s", cfg.DistanceMetric)
+	}
+	if cfg.IndexType != IndexTypeHNSW || cfg.HNSWConfig.M != 32 || cfg.HNSWConfig.EfConstruction != 200 {
+		t.Errorf("unexpected index settings: %s %+v", cfg.IndexType, cfg.HNSWConfig)
+	}
+	if cfg.BatchSize != 500 || !cfg.CreateTableIfNotExists {
+		t.Error("expected base settings to be preserved")
+	}
+
+	if cfg := collectionConfig(base, vector.IndexConfig{Name: "flat", IndexType: vector.IndexTypeFlat}); cfg.IndexType != IndexTypeNone {
+		t.Errorf("expected flat collections to use no index, got %s", cfg.IndexType)
+	}
+}
+
+func TestFilterBuilder(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter vector.Filter
+		sql    string
+		args   int
+	}{
+		{
+			name:   "eq",
+			filter: vector.Eq("lang", "go"),
+			sql:    "metadata->>$1 = $2",
+			args:   2,
+		},
+		{
+			name:   "numeric comparison",
+			filter: vector.Gt("year", 2020),
+			sql:    "CASE WHEN metadata->>$1 ~ '" + numericPattern + "' THEN (metadata->>$1)::numeric > $2::numeric ELSE FALSE END",
+			args:   2,
+		},
+		{
+			name:   "string comparison",
+			filter: vector.Lte("date", "2024-01-01"),
+			sql:    `metadata->>$1 COLLATE "C" <= $2`,
+			args:   2,
+		},
+		{
+			name:   "composition",
+			filter: vector.Or(vector.In("tag", "a", "b"), vector.And(vector.Exists("draft"), vector.Neq("owner", "me"))),
+			sql:    "(metadata->>$1 = ANY($2::text[])) OR ((metadata ? $3) AND (metadata->>$4 IS DISTINCT FROM $5))",
+			args:   5,
+		},
+		{
+			name:   "empty or",
+			filter: vector.Or(),
+			sql:    "FALSE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &filterBuilder{}
+			sql, err := b.build(tt.filter)
+			if err != nil {
+				t.Fatalf("build failed: %v", err)
+			}
+			if sql != tt.sql {
+				t.Errorf("expected %s, got %s", tt.sql, sql)
+			}
+			if len(b.args) != tt.args {
+				t.Errorf("expected %d args, got %d", tt.args, len(b.args))
+			}
+		})
+	}
+}
+
+// BenchmarkVectorEncoding compares the per-embedding cost of the text format
+// used with lib/pq against pgvector's binary format used with pgx.
+func BenchmarkVectorEncoding(b *testing.B) {
+	embedding := make([]float32, 1536)
+	for i := range embedding {
+		embedding[i] = float32(i) / 1536
+	}
+
+	b.Run("text", func(b *testing.B) {
+		for b.Loop() {
+			_ = parseVector(vectorToString(embedding))
+		}
+	})
+	b.Run("binary", func(b *testing.B) {
+		var buf []byte
+		for b.Loop() {
+			buf, _ = pgv.NewVector(embedding).EncodeBinary(buf[:0])
+			var v pgv.Vector
+			_ = v.DecodeBinary(buf)
+		}
+	})
+}
+
+func TestTuningSettings(t *testing.T) {
+	settings, err := tuningSettings(vector.SearchOptions{EfSearch: 200, Probes: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(settings) != 2 || settings[0] != "SET LOCAL hnsw.ef_search = 200" || settings[1] != "SET LOCAL ivfflat.probes = 10" {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+
+	if settings, _ := tuningSettings(vector.SearchOptions{}); len(settings) != 0 {
+		t.Errorf("expected no settings by default, got %v", settings)
+	}
+
+	if _, err := tuningSettings(vector.SearchOptions{EfSearch: -1}); err == nil {
+		t.Error("expected error for negative ef_search")
+	}
+}
+
+func TestHybridQuery(t *testing.T) {
+	idx := &Index{table: tableRef{name: "docs"}, config: Config{
+		VectorType:       VectorTypeVector,
+		FullText:         true,
+		TextSearchConfig: "english",
+	}}
+
+	query, args, err := idx.hybridQuery("[1,0]", "go generics", 5, HybridOptions{
+		Filter: vector.Eq("lang", "go"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"websearch_to_tsquery('english'::regconfig, $2)",
+		"content_tsv @@ tsq AND metadata->>$3 = $4",
+		"FULL OUTER JOIN txt",
+		"1.0 / ($7::float8 + vec.rank)",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	// embedding, text, filter key and value, weights, rrf k, candidates, limit
+	if len(args) != 9 || args[1] != "go generics" || args[7] != 20 || args[8] != 5 {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	query, _, err = idx.hybridQuery("[1,0]", "go", 5, HybridOptions{Fusion: FusionWeighted, VectorWeight: 0.7, TextWeight: 0.3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "(1 - (d.embedding <=> $1::vector))") {
+		t.Errorf("expected weighted vector similarity, got:\n%s", query)
+	}
+
+	if _, _, err := idx.hybridQuery("[1,0]", "go", 5, HybridOptions{Fusion: "max"}); err == nil {
+		t.Error("expected error for unknown fusion")
+	}
+	if _, _, err := idx.hybridQuery("[1,0]", "go", 5, HybridOptions{VectorWeight: -1}); err == nil {
+		t.Error("expected error for negative weight")
+	}
+
+	idx.config.FullText = false
+	if _, _, err := idx.hybridQuery("[1,0]", "go", 5, HybridOptions{}); err == nil {
+		t.Error("expected error without FullText")
+	}
+}
+
+func TestVectorIndexSQL(t *testing.T) {
+	table := tableRef{schema: "rag", name: "docs"}
+	cfg := vector.IndexConfig{Name: "docs", IndexType: vector.IndexTypeHNSW}
+	if stmt := vectorIndexSQL(table, cfg, true); !strings.Contains(stmt, `CREATE INDEX CONCURRENTLY IF NOT EXISTS "docs_embedding_idx" ON "rag"."docs"`) {
+		t.Errorf("expected concurrent build, got %s", stmt)
+	}
+	if stmt := vectorIndexSQL(table, cfg, false); strings.Contains(stmt, "CONCURRENTLY") {
+		t.Errorf("expected blocking build, got %s", stmt)
+	}
+
+	cfg.IndexType = vector.IndexTypeFlat
+	if stmt := vectorIndexSQL(table, cfg, true); stmt != "" {
+		t.Errorf("expected no statement for flat index, got %s", stmt)
+	}
+}
+
+func TestIndexProgressFraction(t *testing.T) {
+	tests := []struct {
+		progress IndexProgress
+		want     float64
+	}{
+		{IndexProgress{TuplesDone: 25, TuplesTotal: 100, BlocksDone: 9, BlocksTotal: 10}, 0.25},
+		{IndexProgress{BlocksDone: 5, BlocksTotal: 10}, 0.5},
+		{IndexProgress{}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.progress.Fraction(); got != tt.want {
+			t.Errorf("Fraction(%+v) = %v, want %v", tt.progress, got, tt.want)
+		}
+	}
+}
+
+func TestParseTableRef(t *testing.T) {
+	tests := []struct {
+		schema  string
+		name    string
+		quoted  string
+		wantErr bool
+	}{
+		{name: "docs", quoted: `"docs"`},
+		{schema: "rag", name: "docs", quoted: `"rag"."docs"`},
+		{name: "rag.docs", quoted: `"rag"."docs"`},
+		{name: `My"Docs`, quoted: `"My""Docs"`},
+		{schema: "rag", name: "other.docs", wantErr: true},
+		{name: "a.b.c", wantErr: true},
+		{name: ".docs", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		table, err := parseTableRef(tt.schema, tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTableRef(%q, %q) error = %v, wantErr %v", tt.schema, tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && table.quoted() != tt.quoted {
+			t.Errorf("parseTableRef(%q, %q).quoted() = %s, want %s", tt.schema, tt.name, table.quoted(), tt.quoted)
+		}
+	}
+
+	table := tableRef{schema: "rag", name: "docs"}
+	if got := table.copyIn("id"); got != `COPY "rag"."docs" ("id") FROM STDIN` {
+		t.Errorf("unexpected copy statement: %s", got)
+	}
+	if got := table.String(); got != "rag.docs" {
+		t.Errorf("unexpected name: %s", got)
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	// Three batches: two full, one short
+	remaining := 2*searchFetchSize + 10
+	fetches := 0
+	fetch := func(fn func(vector.SearchResult) bool) error {
+		fetches++
+		for i := 0; i < searchFetchSize && remaining > 0; i++ {
+			remaining--
+			if !fn(vector.SearchResult{}) {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	n := 0
+	err := fetchAll(fetch, func(vector.SearchResult, error) bool {
+		n++
+		return true
+	})
+	if err != nil || n != 2*searchFetchSize+10 || fetches != 3 {
+		t.Errorf("expected all rows in 3 fetches, got %d rows in %d fetches (%v)", n, fetches, err)
+	}
+
+	// Stopping early doesn't fetch further batches
+	remaining, fetches, n = 2*searchFetchSize, 0, 0
+	_ = fetchAll(fetch, func(vector.SearchResult, error) bool {
+		n++
+		return n < 5
+	})
+	if n != 5 || fetches != 1 {
+		t.Errorf("expected to stop after 5 rows in 1 fetch, got %d rows in %d fetches", n, fetches)
+	}
+}
+
+func TestPartitioning(t *testing.T) {
+	idx, err := New(nil, Config{
+		TableName:    "docs",
+		Dimensions:   3,
+		Partitioning: &PartitionConfig{Key: "created_at", Expression: PartitionByMonth},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.MaxBatchSize() != maxParams/6 {
+		t.Errorf("expected batch size to account for the partition column, got %d", idx.MaxBatchSize())
+	}
+
+	query := idx.upsertQuery(2)
+	for _, want := range []string{"partition_key)", "$6)", "$12)", "ON CONFLICT (partition_key, id)"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected upsert to contain %q, got:\n%s", want, query)
+		}
+	}
+
+	query, args, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Filter: vector.And(
+		vector.Eq("lang", "go"),
+		vector.In("created_at", "2026-01-15", "2026-02-01T10:00:00Z"),
+	)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "WHERE partition_key = ANY($") {
+		t.Errorf("expected partition pruning predicate, got:\n%s", query)
+	}
+	found := false
+	for _, arg := range args {
+		if months, ok := arg.([]string); ok && len(months) == 2 && months[0] == "2026-01" && months[1] == "2026-02" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected months as partition values, got %v", args)
+	}
+
+	// Filters that don't pin the key search all partitions
+	query, _, _ = idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{
+		Filter: vector.Or(vector.Eq("created_at", "2026-01"), vector.Eq("lang", "go")),
+	})
+	if strings.Contains(query, "partition_key") {
+		t.Errorf("did not expect pruning for or filters, got:\n%s", query)
+	}
+
+	if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, Partitioning: &PartitionConfig{}}); err == nil {
+		t.Error("expected error without partition key")
+	}
+	if got := PartitionByMonth("2026-10-16T12:00:00Z"); got != "2026-10" {
+		t.Errorf("PartitionByMonth = %q, want 2026-10", got)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, SoftDelete: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := idx.deleteQuery("id = $1"); !strings.HasPrefix(got, `UPDATE "docs" SET deleted_at = NOW()`) {
+		t.Errorf("expected soft delete, got %q", got)
+	}
+	if query := idx.upsertQuery(1); !strings.Contains(query, "deleted_at = NULL") {
+		t.Errorf("expected upsert to restore deleted rows, got:\n%s", query)
+	}
+
+	query, _, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Filter: vector.Eq("lang", "go")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "AND deleted_at IS NULL") {
+		t.Errorf("expected deleted rows to be excluded, got:\n%s", query)
+	}
+	query, _, _ = idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{IncludeDeleted: true})
+	if strings.Contains(query, "WHERE") {
+		t.Errorf("expected no predicate with IncludeDeleted, got:\n%s", query)
+	}
+
+	hard, _ := New(nil, Config{TableName: "docs", Dimensions: 3})
+	if got := hard.deleteQuery("id = $1"); got != `DELETE FROM "docs" WHERE id = $1` {
+		t.Errorf("unexpected delete query %q", got)
+	}
+	if _, err := hard.Purge(context.Background(), time.Now()); err == nil {
+		t.Error("expected Purge to require SoftDelete")
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, Expiration: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query := idx.upsertQuery(1); !strings.Contains(query, "expires_at = EXCLUDED.expires_at") {
+		t.Errorf("expected upsert to update the expiration, got:\n%s", query)
+	}
+	query, _, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "WHERE "+notExpired) {
+		t.Errorf("expected expired rows to be excluded, got:\n%s", query)
+	}
+	if query, _ := idx.getQuery([]string{"a"}, scope{}); !strings.Contains(query, notExpired) {
+		t.Errorf("expected lookups to exclude expired rows, got:\n%s", query)
+	}
+
+	expires := time.Now().Add(time.Hour)
+	args, err := idx.writeArgs(context.Background(), []vector.Node{
+		{ID: "a", Embedding: []float32{1, 0, 0}, ExpiresAt: expires},
+		{ID: "b", Embedding: []float32{1, 0, 0}},
+	}, idx.encodeTextArg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(args) != 12 || args[5] != expires || args[11] != nil {
+		t.Errorf("unexpected write args: %v", args)
+	}
+
+	plain, _ := New(nil, Config{TableName: "docs", Dimensions: 3})
+	err = plain.Upsert(context.Background(), vector.Node{ID: "a", Embedding: []float32{1, 0, 0}, ExpiresAt: expires})
+	if !errors.Is(err, vector.ErrExpirationUnsupported) {
+		t.Errorf("expected ErrExpirationUnsupported, got %v", err)
+	}
+	if _, err := plain.PurgeExpired(context.Background(), time.Now()); !errors.Is(err, vector.ErrExpirationUnsupported) {
+		t.Errorf("expected ErrExpirationUnsupported, got %v", err)
+	}
+}
+
+func TestCheckDimensions(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = idx.UpsertBatch(context.Background(), []vector.Node{
+		{ID: "ok", Embedding: []float32{1, 0, 0}},
+		{ID: "short", Embedding: []float32{1, 0}},
+	})
+	if !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", err)
+	}
+	var dimErr *DimensionError
+	if !errors.As(err, &dimErr) || dimErr.ID != "short" || dimErr.Expected != 3 || dimErr.Actual != 2 {
+		t.Errorf("unexpected error details: %+v", dimErr)
+	}
+
+	if err := idx.Insert(context.Background(), vector.Node{ID: "empty"}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch for missing embedding, got %v", err)
+	}
+}
+
+func TestCompressContent(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, CompressContent: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	long := strings.Repeat("retrieval augmented generation ", 100)
+	for _, content := range []string{"", "short", long} {
+		plain, compressed, err := idx.encodeContent(content)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Simulate contentColumn: compressed bytes if present, else content
+		raw := compressed
+		if compressed == nil {
+			raw = []byte(plain.(string))
+		} else if plain != nil || len(compressed.([]byte)) >= len(content) {
+			t.Errorf("expected %d bytes to be stored compressed only", len(content))
+		}
+		got, err := idx.decodeContent(raw.([]byte))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != content {
+			t.Errorf("round trip of %d bytes returned %d bytes", len(content), len(got))
+		}
+	}
+
+	if query := idx.upsertQuery(2); !strings.Contains(query, "content_gz)") || !strings.Contains(query, "$12)") ||
+		!strings.Contains(query, "content_gz = EXCLUDED.content_gz") {
+		t.Errorf("expected upsert to write content_gz, got:\n%s", query)
+	}
+	query, _, _ := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{})
+	if !strings.Contains(query, "COALESCE(content_gz, convert_to(content, 'UTF8'))") {
+		t.Errorf("expected search to select compressed content, got:\n%s", query)
+	}
+
+	if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, CompressContent: true, FullText: true}); err == nil {
+		t.Error("expected error combining CompressContent and FullText")
+	}
+}
+
+func TestPageQuery(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, args, err := idx.rankedQuery("[1,0,0]", 10, vector.SearchOptions{}, &vector.PageCursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(query, "WHERE") || !strings.Contains(query, "ORDER BY embedding <=> $1::vector, id LIMIT $2") {
+		t.Errorf("unexpected first page query:\n%s", query)
+	}
+	if len(args) != 2 {
+		t.Errorf("expected 2 args, got %v", args)
+	}
+
+	after := vector.PageCursor{Score: 0.5, ID: "b"}
+	query, args, err = idx.rankedQuery("[1,0,0]", 10, vector.SearchOptions{Filter: vector.Eq("lang", "go")}, &after)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "AND (1 - (embedding <=> $1::vector) < $4 OR (1 - (embedding <=> $1::vector) = $4 AND id > $5))") {
+		t.Errorf("expected keyset predicate after the filter, got:\n%s", query)
+	}
+	if len(args) != 6 || args[3] != 0.5 || args[4] != "b" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	idx, _ = New(nil, Config{TableName: "docs", Dimensions: 3, DistanceMetric: DistanceInnerProduct, NormalizeScores: true})
+	if query, _, _ := idx.rankedQuery("[1,0,0]", 10, vector.SearchOptions{}, &after); !strings.Contains(query, "ORDER BY score DESC, id") {
+		t.Errorf("expected clamped scores to be ranked by score, got:\n%s", query)
+	}
+
+	if _, err := pageCursor("", 0); err == nil {
+		t.Error("expected error for zero limit")
+	}
+	if _, err := pageCursor("not a cursor", 10); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+}
+
+func TestMetricOverride(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, NormalizeScores: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, _, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Metric: vector.DistanceDot})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "LEAST(GREATEST((1 - (embedding <#> $1::vector)) / 2, 0), 1) as score") ||
+		!strings.Contains(query, "ORDER BY embedding <#> $1::vector LIMIT") {
+		t.Errorf("expected inner product search, got:\n%s", query)
+	}
+	if _, _, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Metric: "manhattan"}); !errors.Is(err, vector.ErrMetricUnsupported) {
+		t.Errorf("expected ErrMetricUnsupported, got %v", err)
+	}
+
+	idx, err = New(nil, Config{TableName: "docs", Dimensions: 3, Quantization: &QuantizationConfig{Type: QuantizeBinary}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Metric: vector.DistanceEuclidean}); !errors.Is(err, vector.ErrMetricUnsupported) {
+		t.Errorf("expected ErrMetricUnsupported with quantization, got %v", err)
+	}
+	if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, ExtraMetrics: []DistanceMetric{"manhattan"}}); err == nil {
+		t.Error("expected error for unknown extra metric")
+	}
+}
+
+func TestGroupQuery(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, args, err := idx.groupQuery("[1,0,0]", 5, "doc", vector.SearchOptions{Filter: vector.Eq("lang", "go")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"SELECT DISTINCT ON (metadata->>'doc') id",
+		"WHERE metadata->>$2 = $3 AND metadata->>'doc' IS NOT NULL",
+		"ORDER BY metadata->>'doc', embedding <=> $1::vector, id",
+		"ORDER BY score DESC, id LIMIT $4",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if len(args) != 4 || args[3] != 5 {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	if _, _, err := idx.groupQuery("[1,0,0]", 5, "", vector.SearchOptions{}); err == nil {
+		t.Error("expected error for empty group key")
+	}
+}
+
+func TestExistsQuery(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, SoftDelete: true, DistanceMetric: DistanceInnerProduct})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, args := idx.existsQuery("a", scope{})
+	if query != `SELECT EXISTS (SELECT 1 FROM "docs" WHERE id = $1 AND deleted_at IS NULL)` || len(args) != 1 {
+		t.Errorf("unexpected query %q with args %v", query, args)
+	}
+	if metric := idx.distanceMetric(); metric != vector.DistanceDot {
+		t.Errorf("expected dot metric, got %s", metric)
+	}
+}
+
+func TestGetQuery(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, SoftDelete: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, args := idx.getQuery([]string{"a", "b"}, scope{})
+	if !strings.Contains(query, "WHERE id = ANY($1) AND deleted_at IS NULL") {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+	if ids, ok := args[0].([]string); !ok || len(ids) != 2 {
+		t.Errorf("expected IDs bound as []string, got %v", args)
+	}
+
+	nodes, err := collectNodes([]string{"b", "missing", "a"}, func(fn func(vector.SearchResult) bool) error {
+		fn(vector.SearchResult{Node: vector.Node{ID: "a"}})
+		fn(vector.SearchResult{Node: vector.Node{ID: "b"}})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].ID != "b" || nodes[1].ID != "a" {
+		t.Errorf("expected nodes in the order of ids, got %+v", nodes)
+	}
+	if _, err := getOne(nil, nil); !errors.Is(err, vector.ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, SoftDelete: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, args, err := idx.listQuery("b", 0, vector.Eq("lang", "go"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"WHERE id > $1 AND metadata->>$2 = $3 AND deleted_at IS NULL", "ORDER BY id", "LIMIT $4"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if args[0] != "b" || args[3] != defaultListLimit {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	page := func(ids ...string) func(fn func(vector.SearchResult) bool) error {
+		return func(fn func(vector.SearchResult) bool) error {
+			for _, id := range ids {
+				fn(vector.SearchResult{Node: vector.Node{ID: id}})
+			}
+			return nil
+		}
+	}
+	if _, next, _ := listPage(2, page("a", "b")); next != "b" {
+		t.Errorf("expected cursor b after a full page, got %q", next)
+	}
+	if _, next, _ := listPage(2, page("c")); next != "" {
+		t.Errorf("expected no cursor after the last page, got %q", next)
+	}
+}
+
+func TestPromotedColumns(t *testing.T) {
+	idx, err := New(nil, Config{
+		TableName:  "docs",
+		Dimensions: 3,
+		PromotedColumns: []PromotedColumn{
+			{Key: "tenant_id"},
+			{Key: "year", Column: "doc_year", Type: ColumnBigInt},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		filter   vector.Filter
+		expected string
+	}{
+		{vector.Eq("tenant_id", "acme"), `"tenant_id" = $2::text`},
+		{vector.Gte("year", 2020), `"doc_year" >= $2::bigint`},
+		{vector.In("year", 2020, 2021), `"doc_year" = ANY($2::bigint[])`},
+		{vector.Exists("tenant_id"), `"tenant_id" IS NOT NULL`},
+		{vector.Eq("lang", "go"), "metadata->>$2 = $3"},
+	}
+	for _, tt := range tests {
+		query, _, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Filter: tt.filter})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(query, "WHERE "+tt.expected) {
+			t.Errorf("expected %q, got:\n%s", tt.expected, query)
+		}
+	}
+
+	for _, columns := range [][]PromotedColumn{
+		{{Column: "tenant"}},
+		{{Key: "tenant_id", Type: "jsonb"}},
+		{{Key: "content"}},
+		{{Key: "a", Column: "x"}, {Key: "b", Column: "x"}},
+	} {
+		if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, PromotedColumns: columns}); err == nil {
+			t.Errorf("expected error for %+v", columns)
+		}
+	}
+}
+
+func TestQuantization(t *testing.T) {
+	idx, err := New(nil, Config{
+		TableName:    "docs",
+		Dimensions:   3,
+		Quantization: &QuantizationConfig{Type: QuantizeBinary},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, args, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Filter: vector.Eq("lang", "go")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`FROM (SELECT * FROM "docs" WHERE metadata->>$2 = $3 ORDER BY embedding_q <~> binary_quantize($1::vector)::bit(3) LIMIT $4) candidates`,
+		"ORDER BY embedding <=> $1::vector LIMIT $5",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected %q, got:\n%s", want, query)
+		}
+	}
+	if args[3] != 20 {
+		t.Errorf("expected 4x oversampling, got %v", args[3])
+	}
+
+	idx.config.Quantization = &QuantizationConfig{Type: QuantizeHalfVec, Oversampling: 2}
+	idx.config.DistanceMetric = DistanceEuclidean
+	if op, opClass := idx.quantizedDistance(); op != "<->" || opClass != "halfvec_l2_ops" {
+		t.Errorf("unexpected halfvec operator %s and class %s", op, opClass)
+	}
+	if expr := idx.quantize("embedding"); expr != "embedding::halfvec(3)" {
+		t.Errorf("unexpected halfvec expression %s", expr)
+	}
+
+	for _, cfg := range []Config{
+		{Quantization: &QuantizationConfig{Type: "int8"}},
+		{Quantization: &QuantizationConfig{Type: QuantizeHalfVec}, VectorType: VectorTypeHalfVec},
+		{Quantization: &QuantizationConfig{Type: QuantizeBinary}, VectorType: VectorTypeSparseVec},
+		{Quantization: &QuantizationConfig{Type: QuantizeBinary, Oversampling: -1}},
+	} {
+		cfg.TableName, cfg.Dimensions = "docs", 3
+		if _, err := New(nil, cfg); err == nil {
+			t.Errorf("expected error for %+v", *cfg.Quantization)
+		}
+	}
+}
+
+func TestMMR(t *testing.T) {
+	candidates := []vector.SearchResult{
+		{Node: vector.Node{ID: "a", Embedding: []float32{1, 0}}, Score: 0.99},
+		{Node: vector.Node{ID: "a-dup", Embedding: []float32{1, 0.01}}, Score: 0.98},
+		{Node: vector.Node{ID: "b", Embedding: []float32{0, 1}}, Score: 0.7},
+	}
+
+	ids := func(results []vector.SearchResult) []string {
+		var out []string
+		for _, r := range results {
+			out = append(out, r.Node.ID)
+		}
+		return out
+	}
+	if got := ids(mmr(candidates, 2, 0.5)); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("expected near-duplicate to be skipped, got %v", got)
+	}
+	if got := ids(mmr(candidates, 2, 1)); !slices.Equal(got, []string{"a", "a-dup"}) {
+		t.Errorf("expected relevance order with lambda 1, got %v", got)
+	}
+	if got := mmr(candidates, 10, 0.5); len(got) != 3 {
+		t.Errorf("expected all candidates, got %d", len(got))
+	}
+
+	for _, opts := range []MMROptions{{Lambda: 1.5}, {Lambda: -1}, {Candidates: 2}} {
+		if _, err := opts.withDefaults(5); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestTenantScoping(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, TenantKey: "tenant_id", TenantSetting: "app.tenant_id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := idx.scopeFilter(context.Background(), vector.Filter{}); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("expected ErrNoTenant, got %v", err)
+	}
+
+	ctx := WithTenant(context.Background(), "acme")
+	f, err := idx.scopeFilter(ctx, vector.Eq("lang", "go"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, args, err := idx.searchQuery("[1,0,0]", 5, vector.SearchOptions{Filter: f})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "WHERE (metadata->>$2 = $3) AND (metadata->>$4 = $5)") || args[2] != "acme" {
+		t.Errorf("expected tenant predicate, got:\n%s\n%v", query, args)
+	}
+
+	query, args = idx.getQuery([]string{"a"}, scope{tenant: "acme"})
+	if !strings.Contains(query, "WHERE id = ANY($1) AND metadata->>'tenant_id' = $2") || args[1] != "acme" {
+		t.Errorf("expected tenant predicate, got:\n%s", query)
+	}
+	if query := idx.upsertQuery(1); !strings.Contains(query, "WHERE cur.metadata->>'tenant_id' IS NOT DISTINCT FROM EXCLUDED.metadata->>'tenant_id'") {
+		t.Errorf("expected upserts to be guarded, got:\n%s", query)
+	}
+	if err := checkUpserted(1, 2); !errors.Is(err, vector.ErrConflict) {
+		t.Errorf("expected a skipped upsert to fail with ErrConflict, got %v", err)
+	}
+	if err := checkUpserted(2, 2); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if settings := idx.tenantSettings(ctx); len(settings) != 1 || settings[0] != "SELECT set_config('app.tenant_id', 'acme', true)" {
+		t.Errorf("unexpected settings %v", settings)
+	}
+
+	node := vector.Node{ID: "1", Metadata: map[string]string{"lang": "go"}}
+	nodes, err := idx.scopeNodes(ctx, []vector.Node{node})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nodes[0].Metadata["tenant_id"] != "acme" || node.Metadata["tenant_id"] != "" {
+		t.Errorf("expected a stamped copy, got %v (original %v)", nodes[0].Metadata, node.Metadata)
+	}
+	if _, err := idx.scopeNodes(ctx, []vector.Node{{ID: "2", Metadata: map[string]string{"tenant_id": "globex"}}}); err == nil {
+		t.Error("expected writing another tenant's node to fail")
+	}
+
+	if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, TenantSetting: "app.tenant_id"}); err == nil {
+		t.Error("expected TenantSetting without TenantKey to fail")
+	}
+}
+
+func TestNamespaceScoping(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, NamespaceKey: "ns"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Operations without a namespace are scoped to the default namespace
+	f, err := idx.scopeFilter(context.Background(), vector.Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Op != vector.FilterEq || f.Key != "ns" || f.Value != "" {
+		t.Errorf("expected default namespace filter, got %+v", f)
+	}
+
+	ctx := vector.WithNamespace(context.Background(), "docs")
+	query, args := idx.getQuery([]string{"a"}, scope{namespace: "docs"})
+	if !strings.Contains(query, "WHERE id = ANY($1) AND metadata->>'ns' = $2") || args[1] != "docs" {
+		t.Errorf("expected namespace predicate, got:\n%s", query)
+	}
+	if query := idx.upsertQuery(1); !strings.Contains(query, "WHERE cur.metadata->>'ns' IS NOT DISTINCT FROM EXCLUDED.metadata->>'ns'") {
+		t.Errorf("expected upserts to be guarded, got:\n%s", query)
+	}
+
+	nodes, err := idx.scopeNodes(ctx, []vector.Node{{ID: "1"}, {ID: "2", Namespace: "notes"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nodes[0].Metadata["ns"] != "docs" || nodes[1].Metadata["ns"] != "notes" {
+		t.Errorf("expected stamped namespaces, got %v and %v", nodes[0].Metadata, nodes[1].Metadata)
+	}
+	r := idx.namespaced(vector.SearchResult{Node: vector.Node{Metadata: map[string]string{"ns": "docs"}}})
+	if r.Node.Namespace != "docs" {
+		t.Errorf("expected namespace docs, got %q", r.Node.Namespace)
+	}
+
+	// Indexes without a namespace key reject namespaces
+	plain, err := New(nil, Config{TableName: "docs", Dimensions: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := plain.scopeFilter(ctx, vector.Filter{}); !errors.Is(err, vector.ErrNamespacesUnsupported) {
+		t.Errorf("expected ErrNamespacesUnsupported, got %v", err)
+	}
+	if _, err := plain.scopeNodes(context.Background(), []vector.Node{{ID: "1", Namespace: "docs"}}); !errors.Is(err, vector.ErrNamespacesUnsupported) {
+		t.Errorf("expected ErrNamespacesUnsupported, got %v", err)
+	}
+
+	if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, TenantKey: "k", NamespaceKey: "k"}); err == nil {
+		t.Error("expected a namespace key equal to the tenant key to fail")
+	}
+}
+
+func TestParseChange(t *testing.T) {
+	event, err := parseChange(`{"id" : "doc-1", "op" : "delete", "ts" : "2026-01-02T03:04:05.123456+00:00"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ChangeEvent{ID: "doc-1", Op: ChangeDelete, Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)}
+	if event.ID != want.ID || event.Op != want.Op || !event.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("parseChange() = %+v, want %+v", event, want)
+	}
+	if _, err := parseChange("doc-1"); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+}
+
+func TestStatementCacheConfig(t *testing.T) {
+	if _, err := New(nil, Config{TableName: "docs", Dimensions: 3, StatementCacheSize: -1}); err == nil {
+		t.Error("expected negative cache size to fail")
+	}
+
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.stmts != nil || idx.Close() != nil {
+		t.Error("expected statement cache to be disabled by default")
+	}
+
+	idx, err = New(nil, Config{TableName: "docs", Dimensions: 3, StatementCacheSize: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.stmts == nil || idx.stmts.size != 8 {
+		t.Errorf("expected a cache of 8 statements, got %+v", idx.stmts)
+	}
+}
+
+func TestScoreExpr(t *testing.T) {
+	tests := []struct {
+		metric    DistanceMetric
+		normalize bool
+		expected  string
+	}{
+		{DistanceCosine, false, "1 - (d)"},
+		{DistanceEuclidean, false, "1 - (d)"},
+		{DistanceCosine, true, "1 - (d) / 2"},
+		{DistanceEuclidean, true, "1 / (1 + (d))"},
+		{DistanceInnerProduct, true, "LEAST(GREATEST((1 - (d)) / 2, 0), 1)"},
+	}
+	for _, tt := range tests {
+		idx := &Index{config: Config{DistanceMetric: tt.metric, NormalizeScores: tt.normalize}}
+		if got := idx.scoreExpr("d"); got != tt.expected {
+			t.Errorf("scoreExpr(%s, %v) = %s, want %s", tt.metric, tt.normalize, got, tt.expected)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	idx, err := New(nil, Config{
+		TableName:  "docs",
+		Dimensions: 3,
+		Retry:      RetryPolicy{MaxRetries: 2, RetryBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, 3},
+		{"connection exception", &pgconn.PgError{Code: "08006"}, 3},
+		{"dropped connection", io.ErrUnexpectedEOF, 3},
+		{"unique violation", &pq.Error{Code: "23505"}, 1},
+		{"other error", errors.New("boom"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := idx.retry(context.Background(), func() error {
+				attempts++
+				return fmt.Errorf("wrapped: %w", tt.err)
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, attempts)
+			}
+		})
+	}
+
+	attempts := 0
+	err = idx.retry(context.Background(), func() error {
+		attempts++
+		if attempts < 2 {
+			return &pq.Error{Code: "40P01"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("expected success on the second attempt, got %v after %d", err, attempts)
+	}
+}
+
+func TestStatementTimeout(t *testing.T) {
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, StatementTimeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	settings, err := idx.searchSettings(ctx, vector.SearchOptions{EfSearch: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(settings) != 2 || settings[1] != "SET LOCAL statement_timeout = 2000" {
+		t.Errorf("unexpected settings %v", settings)
+	}
+	if settings := idx.timeoutSettings(WithStatementTimeout(ctx, 0)); len(settings) != 0 {
+		t.Errorf("expected override to disable the timeout, got %v", settings)
+	}
+
+	canceled := &pq.Error{Code: "57014"}
+	err = idx.timeoutError(ctx, fmt.Errorf("search query failed: %w", canceled))
+	var timeoutErr *TimeoutError
+	if !errors.Is(err, ErrStatementTimeout) || !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 2*time.Second {
+		t.Errorf("expected *TimeoutError, got %v", err)
+	}
+	if !errors.Is(err, canceled) {
+		t.Error("expected driver error to be wrapped")
+	}
+
+	// Cancellations caused by the context are not timeouts
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := idx.timeoutError(cancelled, canceled); errors.Is(err, ErrStatementTimeout) {
+		t.Errorf("expected context cancellation to pass through, got %v", err)
+	}
+}
+
+func TestBackendError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{&pq.Error{Code: "42P01"}, vector.ErrIndexNotReady},
+		{&pgconn.PgError{Code: "57P03"}, vector.ErrIndexNotReady},
+		{&pq.Error{Code: "53300"}, vector.ErrRateLimited},
+		{&pq.Error{Code: "22000", Message: "different vector dimensions 3 and 4"}, vector.ErrDimensionMismatch},
+		{&pq.Error{Code: "23505"}, nil},
+	}
+	for _, tt := range tests {
+		err := backendError(fmt.Errorf("search query failed: %w", tt.err))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%v: expected driver error to be wrapped", tt.err)
+		}
+		for _, kind := range []error{vector.ErrIndexNotReady, vector.ErrRateLimited, vector.ErrDimensionMismatch} {
+			if errors.Is(err, kind) != (kind == tt.want) {
+				t.Errorf("%v: errors.Is(%v) = %v", tt.err, kind, !(kind == tt.want))
+			}
+		}
+	}
+	if backendError(nil) != nil {
+		t.Error("expected nil error to pass through")
+	}
+	if err := backendError(backendError(&pq.Error{Code: "42P01"})); strings.Count(err.Error(), "index not ready") != 1 {
+		t.Errorf("expected classification once, got %v", err)
+	}
+}
+
+func TestPlanRows(t *testing.T) {
+	n, err := planRows([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1234, "Plan Width": 4}}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1234 {
+		t.Errorf("expected 1234, got %d", n)
+	}
+	if _, err := planRows([]byte(`[]`)); err == nil {
+		t.Error("expected error for empty plan")
+	}
+}
+
+func TestParallelChunks(t *testing.T) {
+	idx := &Index{config: Config{BatchSize: 10, BatchConcurrency: 3}}
+	errBoom := errors.New("boom")
+
+	var mu sync.Mutex
+	var written int
+	err := idx.parallelChunks(context.Background(), 45, func(start, end int) error {
+		if start == 10 || start == 40 {
+			return errBoom
+		}
+		mu.Lock()
+		written += end - start
+		mu.Unlock()
+		return nil
+	})
+
+	var batchErr *vector.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected *vector.BatchError, got %v", err)
+	}
+	if len(batchErr.Chunks) != 2 || batchErr.Chunks[0].Offset != 10 || batchErr.Chunks[1].Offset+batchErr.Chunks[1].Size != 45 {
+		t.Errorf("expected chunks [10:20] and [40:45] to fail, got %v", err)
+	}
+	if !errors.Is(err, errBoom) {
+		t.Error("expected errors.Is to match a chunk error")
+	}
+	if written != 30 {
+		t.Errorf("expected the other chunks to be written, got %d nodes", written)
+	}
+
+	if err := idx.parallelChunks(context.Background(), 45, func(int, int) error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// lengthEmbedder embeds text as its length.
+type lengthEmbedder struct{}
+
+func (lengthEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	return []float32{float32(len(text))}, nil
+}
+
+func (e lengthEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i], _ = e.Embed(ctx, text)
+	}
+	return out, nil
+}
+
+func (lengthEmbedder) Model() string { return "length" }
+
+func TestEmbedderReembed(t *testing.T) {
+	reembed := EmbedderReembed(lengthEmbedder{})
+	got, err := reembed(context.Background(), []vector.Node{{Content: "a"}, {Content: "abc"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0][0] != 1 || got[1][0] != 3 {
+		t.Errorf("expected embeddings of the contents in order, got %v", got)
+	}
+}
+
+func TestMigrateBatchSize(t *testing.T) {
+	reembed := EmbedderReembed(lengthEmbedder{})
+	tests := []struct {
+		opts MigrateOptions
+		want int
+	}{
+		{MigrateOptions{}, defaultMigrateBatchSize},
+		{MigrateOptions{BatchSize: 20000}, 20000},
+		{MigrateOptions{BatchSize: 100, Reembed: reembed}, 100},
+		{MigrateOptions{BatchSize: 20000, Reembed: reembed}, maxParams / reembedParamsPerRow},
+	}
+	for _, tt := range tests {
+		if got := migrateBatchSize(tt.opts); got != tt.want {
+			t.Errorf("migrateBatchSize(%d, reembed=%v) = %d, want %d", tt.opts.BatchSize, tt.opts.Reembed != nil, got, tt.want)
+		}
+	}
+}
+
+func TestDiskANNConfig(t *testing.T) {
+	var none *DiskANNConfig
+	if got := none.withOptions(); got != "" {
+		t.Errorf("nil config options = %q", got)
+	}
+	if got := (&DiskANNConfig{}).withOptions(); got != "" {
+		t.Errorf("empty config options = %q", got)
+	}
+
+	cfg := &DiskANNConfig{
+		StorageLayout:       DiskANNPlain,
+		NumNeighbors:        32,
+		MaxAlpha:            1.5,
+		QuerySearchListSize: 200,
+	}
+	want := "WITH (storage_layout = 'plain', num_neighbors = 32, max_alpha = 1.5)"
+	if got := cfg.withOptions(); got != want {
+		t.Errorf("options = %q, want %q", got, want)
+	}
+	settings := cfg.settings()
+	if len(settings) != 1 || settings[0] != "SET LOCAL diskann.query_search_list_size = 200" {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+
+	idx, err := New(nil, Config{TableName: "docs", Dimensions: 3, IndexType: IndexTypeDiskANN, DiskANNConfig: cfg})
+	if err != nil {
+		t.Fatalf("failed to create index: %v", err)
+	}
+	searchSettings, err := idx.searchSettings(context.Background(), vector.SearchOptions{})
+	if err != nil {
+		t.Fatalf("failed to build settings: %v", err)
+	}
+	if len(searchSettings) != 1 || searchSettings[0] != settings[0] {
+		t.Errorf("unexpected search settings: %v", searchSettings)
+	}
+
+	for name, cfg := range map[string]Config{
+		"halfvec":      {TableName: "docs", Dimensions: 3, IndexType: IndexTypeDiskANN, VectorType: VectorTypeHalfVec},
+		"quantization": {TableName: "docs", Dimensions: 3, IndexType: IndexTypeDiskANN, Quantization: &QuantizationConfig{Type: QuantizeBinary}},
+		"layout":       {TableName: "docs", Dimensions: 3, IndexType: IndexTypeDiskANN, DiskANNConfig: &DiskANNConfig{StorageLayout: "compact"}},
+		"negative":     {TableName: "docs", Dimensions: 3, IndexType: IndexTypeDiskANN, DiskANNConfig: &DiskANNConfig{NumNeighbors: -1}},
+	} {
+		if _, err := New(nil, cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	sql := vectorIndexSQL(tableRef{name: "docs"}, vector.IndexConfig{
+		DistanceMetric: vector.DistanceCosine,
+		IndexType:      vector.IndexTypeDiskANN,
+		DiskANNConfig:  &vector.DiskANNConfig{SearchListSize: 75},
+	}, false)
+	if !strings.Contains(sql, "USING diskann (embedding vector_cosine_ops)") || !strings.Contains(sql, "WITH (search_list_size = 75)") {
+		t.Errorf("unexpected index SQL: %s", sql)
+	}
+}
+
+func TestIVFFlatLists(t *testing.T) {
+	for rows, want := range map[int64]int{
+		0:         1,
+		999:       1,
+		50_000:    50,
+		1_000_000: 1000,
+		4_000_000: 2000,
+	} {
+		if got := ivfflatLists(rows); got != want {
+			t.Errorf("ivfflatLists(%d) = %d, want %d", rows, got, want)
+		}
+	}
+}
+
+func TestCheckPromoted(t *testing.T) {
+	idx, err := New(nil, Config{
+		TableName:  "docs",
+		Dimensions: 3,
+		PromotedColumns: []PromotedColumn{
+			{Key: "tenant_id"},
+			{Key: "year", Type: ColumnBigInt},
+			{Key: "price", Type: ColumnNumeric},
+			{Key: "draft", Type: ColumnBoolean},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	valid := map[string]string{"tenant_id": "acme", "year": " 2024 ", "price": "-1.5e3", "draft": "Yes", "other": "x"}
+	if err := idx.checkPromoted([]vector.Node{{ID: "ok", Metadata: valid}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for key, value := range map[string]string{"year": "2024.5", "price": "0x1p3", "draft": "maybe"} {
+		err := idx.checkPromoted([]vector.Node{{ID: "bad", Metadata: map[string]string{key: value}}})
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("expected error for %s=%q, got %v", key, value, err)
+		}
+	}
+
+	err = idx.Insert(context.Background(), vector.Node{
+		ID:        "bad",
+		Embedding: []float32{1, 0, 0},
+		Metadata:  map[string]string{"year": "next year"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "promoted column year") {
+		t.Errorf("expected promoted column error, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// tsvColumn is the generated tsvector column created when FullText is set.
+const tsvColumn = "content_tsv"
+
+// HybridFusion defines how HybridSearch combines the text and vector rankings.
+type HybridFusion string
+
+const (
+	// FusionRRF uses reciprocal rank fusion, which ignores raw scores and
+	// only considers each node's rank in either list.
+	FusionRRF HybridFusion = "rrf"
+	// FusionWeighted sums the weighted vector similarity and normalized
+	// ts_rank_cd text score.
+	FusionWeighted HybridFusion = "weighted"
+)
+
+// HybridOptions configures HybridSearch.
+type HybridOptions struct {
+	// Filter restricts both rankings.
+	Filter vector.Filter
+	// Fusion combines the rankings (default rrf).
+	Fusion HybridFusion
+	// VectorWeight scales the vector ranking's contribution (default 0.5).
+	VectorWeight float64
+	// TextWeight scales the text ranking's contribution (default 0.5).
+	TextWeight float64
+	// RRFK is the reciprocal rank fusion constant (default 60).
+	RRFK int
+	// Candidates is the number of nodes each ranking contributes before
+	// fusion (default 4*k).
+	Candidates int
+	// IncludeDeleted includes soft-deleted nodes.
+	IncludeDeleted bool
+}
+
+// withDefaults returns opts with defaults applied for k results.
+func (opts HybridOptions) withDefaults(k int) HybridOptions {
+	if opts.Fusion == "" {
+		opts.Fusion = FusionRRF
+	}
+	if opts.VectorWeight == 0 && opts.TextWeight == 0 {
+		opts.VectorWeight, opts.TextWeight = 0.5, 0.5
+	}
+	if opts.RRFK == 0 {
+		opts.RRFK = 60
+	}
+	if opts.Candidates == 0 {
+		opts.Candidates = 4 * k
+	}
+	return opts
+}
+
+// createFullText adds the generated tsvector column and its GIN index.
+func (idx *Index) createFullText(ctx context.Context, tx *sql.Tx) error {
+	table := idx.table.quoted()
+
+	//nolint:gosec // Identifiers escaped via pq.QuoteIdentifier, config via pq.QuoteLiteral
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector
+		GENERATED ALWAYS AS (to_tsvector(%s::regconfig, coalesce(content, ''))) STORED
+	`, table, tsvColumn, pq.QuoteLiteral(idx.config.TextSearchConfig)))
+	if err != nil {
+		return fmt.Errorf("failed to add tsvector column: %w", err)
+	}
+
+	indexName := fmt.Sprintf("%s_%s_idx", idx.table.name, tsvColumn)
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s USING gin (%s)",
+		pq.QuoteIdentifier(indexName), table, tsvColumn,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create tsvector index: %w", err)
+	}
+	return nil
+}
+
+// HybridSearch finds the k best nodes for a query by fusing full-text and
+// vector rankings in a single SQL statement. The text is parsed with
+// websearch_to_tsquery, so quoted phrases, "or", and "-" are supported.
+// Nodes found by only one ranking are still returned. The index must be
+// configured with FullText.
+func (idx *Index) HybridSearch(ctx context.Context, embedding []float32, text string, k int, opts HybridOptions) ([]vector.SearchResult, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, err
+	}
+	query, args, err := idx.hybridQuery(idx.encodeText(embedding), text, k, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchWithSettings(ctx, query, pqArgs(args), idx.sessionSettings(ctx))
+}
+
+// HybridSearch is Index.HybridSearch using the binary vector encoding.
+func (idx *PgxIndex) HybridSearch(ctx context.Context, embedding []float32, text string, k int, opts HybridOptions) ([]vector.SearchResult, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, err
+	}
+	query, args, err := idx.hybridQuery(idx.encodeBinary(embedding), text, k, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchWithSettings(ctx, query, args, idx.sessionSettings(ctx))
+}
+
+// hybridQuery builds the hybrid search statement. Like searchQuery, it binds
+// the embedding as $1 and returns the columns scanned by search. Each ranking
+// is a CTE limited to the candidate count, so both can use their index; the
+// rankings are then full outer joined and fused.
+func (idx *Index) hybridQuery(embedding any, text string, k int, opts HybridOptions) (string, []any, error) {
+	if !idx.config.FullText {
+		return "", nil, fmt.Errorf("hybrid search requires FullText to be enabled")
+	}
+	if err := opts.Filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	opts = opts.withDefaults(k)
+	if opts.VectorWeight < 0 || opts.TextWeight < 0 {
+		return "", nil, fmt.Errorf("hybrid weights must not be negative")
+	}
+
+	b := &filterBuilder{args: []any{embedding}, columns: idx.promoted}
+	textArg := b.arg(text)
+	where, err := idx.where(b, opts.Filter, opts.IncludeDeleted)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if where == "" {
+		where = "TRUE"
+	}
+
+	// With quantization, the vector ranking re-ranks the quantized
+	// candidates
+	vecSource, vecWhere := idx.table.quoted(), where
+	if idx.config.Quantization != nil {
+		vecSource, vecWhere = idx.quantizedSource(b, where, opts.Candidates), "TRUE"
+	}
+
+	distance := fmt.Sprintf("embedding %s $1::%s", idx.distanceOperator(), idx.config.VectorType)
+	vectorWeight := b.arg(opts.VectorWeight) + "::float8"
+	textWeight := b.arg(opts.TextWeight) + "::float8"
+
+	var score string
+	switch opts.Fusion {
+	case FusionRRF:
+		rrfK := b.arg(opts.RRFK) + "::float8"
+		score = fmt.Sprintf("%[1]s * COALESCE(1.0 / (%[3]s + vec.rank), 0) + %[2]s * COALESCE(1.0 / (%[3]s + txt.rank), 0)",
+			vectorWeight, textWeight, rrfK)
+	case FusionWeighted:
+		score = fmt.Sprintf("%s * (%s) + %s * COALESCE(txt.text_score, 0)",
+			vectorWeight, idx.scoreExpr("d."+distance), textWeight)
+	default:
+		return "", nil, fmt.Errorf("unknown hybrid fusion %q", opts.Fusion)
+	}
+
+	table := idx.table.quoted()
+
+	// Normalization 32 scales ts_rank_cd into [0, 1) as rank/(rank+1).
+	//nolint:gosec // Identifiers escaped via pq.QuoteIdentifier, values are parameterized
+	query := fmt.Sprintf(`
+		WITH vec AS (
+			SELECT id, row_number() OVER (ORDER BY distance) AS rank
+			FROM (
+				SELECT id, %[1]s AS distance
+				FROM %[11]s
+				WHERE %[12]s
+				ORDER BY %[1]s
+				LIMIT %[4]s
+			) nearest
+		), txt AS (
+			SELECT id, row_number() OVER (ORDER BY text_score DESC) AS rank, text_score
+			FROM (
+				SELECT id, ts_rank_cd(%[5]s, tsq, 32) AS text_score
+				FROM %[2]s, websearch_to_tsquery(%[6]s::regconfig, %[7]s) tsq
+				WHERE %[5]s @@ tsq AND %[3]s
+				ORDER BY text_score DESC
+				LIMIT %[4]s
+			) matched
+		)
+		SELECT d.id, %[10]s, d.embedding::vector, d.source, d.metadata,
+		       %[8]s AS score
+		FROM vec
+		FULL OUTER JOIN txt ON txt.id = vec.id
+		JOIN %[2]s d ON d.id = COALESCE(vec.id, txt.id)
+		ORDER BY score DESC
+		LIMIT %[9]s
+	`, distance, table, where, b.arg(opts.Candidates), tsvColumn,
+		pq.QuoteLiteral(idx.config.TextSearchConfig), textArg, score, b.arg(k), idx.contentColumn("d."),
+		vecSource, vecWhere)
+
+	return query, b.args, nil
+}
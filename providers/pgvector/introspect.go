@@ -0,0 +1,101 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// Exists implements vector.IntrospectableIndex. Soft-deleted nodes don't
+// exist.
+func (idx *Index) Exists(ctx context.Context, id string) (bool, error) {
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return false, err
+	}
+	query, args := idx.existsQuery(id, s)
+
+	var exists bool
+	err = idx.withSettings(ctx, idx.tenantSettings(ctx), func(db querier) error {
+		return queryRow(ctx, db, query, args, &exists)
+	})
+	if err != nil {
+		return false, fmt.Errorf("exists query failed: %w", idx.timeoutError(ctx, err))
+	}
+	return exists, nil
+}
+
+// existsQuery builds the statement testing for a node by ID within s.
+func (idx *Index) existsQuery(id string, s scope) (string, []any) {
+	where, args := idx.restrict("id = $1", []any{id}, s)
+	if idx.config.SoftDelete {
+		where += " AND " + deletedColumn + " IS NULL"
+	}
+	if idx.config.Expiration {
+		where += " AND " + notExpired
+	}
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, ID is parameterized
+	return fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s)", idx.table.quoted(), where), args
+}
+
+// Describe implements vector.IntrospectableIndex. Dimensions are read from
+// the table, so a table created with other dimensions than configured is
+// detected; a missing table fails with vector.ErrIndexNotReady. Count is an
+// approximate count (see CountOptions.Approximate) of the nodes in the
+// context's tenant and namespace.
+func (idx *Index) Describe(ctx context.Context) (vector.IndexDescription, error) {
+	dimensions, err := embeddingDimensions(ctx, idx.db, idx.table.quoted())
+	if err != nil {
+		return vector.IndexDescription{}, err
+	}
+	if dimensions == 0 {
+		dimensions = idx.config.Dimensions
+	}
+	count, err := idx.Count(ctx, CountOptions{Approximate: true})
+	if err != nil {
+		return vector.IndexDescription{}, err
+	}
+	return vector.IndexDescription{
+		Name:           idx.Name(),
+		Dimensions:     dimensions,
+		DistanceMetric: idx.distanceMetric(),
+		Count:          count,
+	}, nil
+}
+
+// distanceMetric returns the vector package metric of the configured
+// distance metric.
+func (idx *Index) distanceMetric() vector.DistanceMetric {
+	switch idx.config.DistanceMetric {
+	case DistanceEuclidean:
+		return vector.DistanceEuclidean
+	case DistanceInnerProduct:
+		return vector.DistanceDot
+	default: // Cosine
+		return vector.DistanceCosine
+	}
+}
+
+// searchMetric returns the distance metric of a search with the given
+// SearchOptions.Metric override, or the configured metric without one.
+func (idx *Index) searchMetric(metric vector.DistanceMetric) (DistanceMetric, error) {
+	switch metric {
+	case "":
+		return idx.config.DistanceMetric, nil
+	case vector.DistanceCosine:
+		return DistanceCosine, nil
+	case vector.DistanceEuclidean:
+		return DistanceEuclidean, nil
+	case vector.DistanceDot:
+		return DistanceInnerProduct, nil
+	default:
+		return "", fmt.Errorf("%w: %s", vector.ErrMetricUnsupported, metric)
+	}
+}
+
+// Verify interface compliance
+var (
+	_ vector.IntrospectableIndex = (*Index)(nil)
+	_ vector.IntrospectableIndex = (*PgxIndex)(nil)
+)
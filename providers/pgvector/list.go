@@ -0,0 +1,115 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// defaultListLimit is the page size used by List when limit is not positive.
+const defaultListLimit = 1000
+
+// List returns a page of up to limit nodes matching filter, ordered by ID,
+// and the cursor of the next page. Pass "" to start from the beginning; the
+// returned cursor is "" after the last page. Pages are keyset-paginated, so
+// listing stays fast deep into a table and nodes written concurrently are
+// neither skipped nor repeated unless their IDs sort before the cursor.
+// Soft-deleted nodes are not returned. It is intended for maintenance, such
+// as re-embedding a corpus after switching embedding models:
+//
+//	cursor := ""
+//	for {
+//		nodes, next, err := idx.List(ctx, cursor, 500, vector.Filter{})
+//		// ... re-embed and UpsertBatch nodes
+//		if next == "" {
+//			break
+//		}
+//		cursor = next
+//	}
+func (idx *Index) List(ctx context.Context, cursor string, limit int, filter vector.Filter) ([]vector.Node, string, error) {
+	filter, err := idx.scopeFilter(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	query, args, err := idx.listQuery(cursor, limit, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	nodes, next, err := listPage(limit, func(fn func(vector.SearchResult) bool) error {
+		return idx.withSettings(ctx, idx.sessionSettings(ctx), func(db querier) error {
+			return idx.scan(ctx, db, query, pqArgs(args), fn)
+		})
+	})
+	return nodes, next, idx.timeoutError(ctx, err)
+}
+
+// List is Index.List using the binary vector encoding.
+func (idx *PgxIndex) List(ctx context.Context, cursor string, limit int, filter vector.Filter) ([]vector.Node, string, error) {
+	filter, err := idx.scopeFilter(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	query, args, err := idx.listQuery(cursor, limit, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	nodes, next, err := listPage(limit, func(fn func(vector.SearchResult) bool) error {
+		return idx.withSettings(ctx, idx.sessionSettings(ctx), func(db pgxQuerier) error {
+			return idx.scan(ctx, db, query, args, fn)
+		})
+	})
+	return nodes, next, idx.timeoutError(ctx, err)
+}
+
+// listQuery builds the statement selecting a page of nodes after cursor,
+// returning the columns scanned by search with a zero score.
+func (idx *Index) listQuery(cursor string, limit int, filter vector.Filter) (string, []any, error) {
+	if err := filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	b := &filterBuilder{columns: idx.promoted}
+	where := "id > " + b.arg(cursor)
+	pred, err := idx.where(b, filter, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if pred != "" {
+		where += " AND " + pred
+	}
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, values are parameterized
+	query := fmt.Sprintf(`
+		SELECT id, %s, embedding::vector, source, metadata, 0::float8
+		FROM %s
+		WHERE %s
+		ORDER BY id
+		LIMIT %s
+	`, idx.contentColumn(""), idx.table.quoted(), where, b.arg(limit))
+	return query, b.args, nil
+}
+
+// listPage scans a page of nodes and returns them with the next cursor.
+func listPage(limit int, scan func(fn func(vector.SearchResult) bool) error) ([]vector.Node, string, error) {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	var nodes []vector.Node
+	err := scan(func(r vector.SearchResult) bool {
+		nodes = append(nodes, r.Node)
+		return true
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("list failed: %w", err)
+	}
+
+	if len(nodes) < limit {
+		return nodes, "", nil
+	}
+	return nodes, nodes[len(nodes)-1].ID, nil
+}
@@ -4,28 +4,61 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/agentplexus/omniretrieve/vector"
 	"github.com/lib/pq"
 )
 
 // Manager implements vector.IndexManager for PostgreSQL with pgvector.
+// Index names may be schema-qualified as "schema.table".
 type Manager struct {
-	db *sql.DB
+	db     *sql.DB
+	schema string
 }
 
-// NewManager creates a new index manager.
+// NewManager creates a new index manager. Unqualified index names resolve
+// through search_path, and ListIndexes lists the current schema.
 func NewManager(db *sql.DB) *Manager {
 	return &Manager{db: db}
 }
 
+// NewSchemaManager creates a new index manager whose unqualified index names
+// refer to tables in schema.
+func NewSchemaManager(db *sql.DB, schema string) *Manager {
+	return &Manager{db: db, schema: schema}
+}
+
+// table parses an index name into a table reference in the manager's schema.
+func (m *Manager) table(name string) (tableRef, error) {
+	return parseTableRef(m.schema, name)
+}
+
 // CreateIndex implements vector.IndexManager.
 func (m *Manager) CreateIndex(ctx context.Context, cfg vector.IndexConfig) error {
+	table, err := m.table(cfg.Name)
+	if err != nil {
+		return err
+	}
+	return withDDLLock(ctx, m.db, func(tx *sql.Tx) error {
+		return createIndexSchema(ctx, tx, table, cfg)
+	})
+}
+
+// createIndexSchema runs the DDL for an index created through the manager.
+func createIndexSchema(ctx context.Context, tx *sql.Tx, table tableRef, cfg vector.IndexConfig) error {
 	// Ensure pgvector extension is available
-	_, err := m.db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vector")
+	_, err := tx.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vector")
 	if err != nil {
 		return fmt.Errorf("failed to create vector extension: %w", err)
 	}
+	if cfg.IndexType == vector.IndexTypeDiskANN {
+		if err := createVectorscaleExtension(ctx, tx); err != nil {
+			return err
+		}
+	}
 
 	// Create table
 	createTableSQL := fmt.Sprintf(`
@@ -38,62 +71,87 @@ func (m *Manager) CreateIndex(ctx context.Context, cfg vector.IndexConfig) error
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		)
-	`, pq.QuoteIdentifier(cfg.Name), cfg.Dimensions)
+	`, table.quoted(), cfg.Dimensions)
 
-	_, err = m.db.ExecContext(ctx, createTableSQL)
+	_, err = tx.ExecContext(ctx, createTableSQL)
 	if err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
 
 	// Create vector index if specified
-	if cfg.IndexType != "" && cfg.IndexType != vector.IndexTypeFlat {
-		opClass := distanceMetricToOpClass(cfg.DistanceMetric)
-		indexName := fmt.Sprintf("%s_embedding_idx", cfg.Name)
-
-		var createIndexSQL string
-		switch cfg.IndexType {
-		case vector.IndexTypeHNSW:
-			m := 16
-			efConstruction := 64
-			if cfg.HNSWConfig != nil {
-				if cfg.HNSWConfig.M > 0 {
-					m = cfg.HNSWConfig.M
-				}
-				if cfg.HNSWConfig.EfConstruction > 0 {
-					efConstruction = cfg.HNSWConfig.EfConstruction
-				}
-			}
-			createIndexSQL = fmt.Sprintf(`
-				CREATE INDEX IF NOT EXISTS %s ON %s
-				USING hnsw (embedding %s)
-				WITH (m = %d, ef_construction = %d)
-			`, pq.QuoteIdentifier(indexName), pq.QuoteIdentifier(cfg.Name), opClass, m, efConstruction)
-
-		case vector.IndexTypeIVFFlat:
-			lists := 100 // Default
-			createIndexSQL = fmt.Sprintf(`
-				CREATE INDEX IF NOT EXISTS %s ON %s
-				USING ivfflat (embedding %s)
-				WITH (lists = %d)
-			`, pq.QuoteIdentifier(indexName), pq.QuoteIdentifier(cfg.Name), opClass, lists)
+	if createIndexSQL := vectorIndexSQL(table, cfg, false); createIndexSQL != "" {
+		_, err = tx.ExecContext(ctx, createIndexSQL)
+		if err != nil {
+			return fmt.Errorf("failed to create vector index: %w", err)
 		}
+	}
+
+	return nil
+}
 
-		if createIndexSQL != "" {
-			_, err = m.db.ExecContext(ctx, createIndexSQL)
-			if err != nil {
-				return fmt.Errorf("failed to create vector index: %w", err)
+// vectorIndexSQL returns the statement creating the vector index for cfg on
+// table, or "" if cfg has no vector index.
+func vectorIndexSQL(table tableRef, cfg vector.IndexConfig, concurrently bool) string {
+	create := "CREATE INDEX"
+	if concurrently {
+		create += " CONCURRENTLY"
+	}
+	opClass := distanceMetricToOpClass(cfg.DistanceMetric)
+	indexName := vectorIndexName(table.name)
+
+	switch cfg.IndexType {
+	case vector.IndexTypeHNSW:
+		m := 16
+		efConstruction := 64
+		if cfg.HNSWConfig != nil {
+			if cfg.HNSWConfig.M > 0 {
+				m = cfg.HNSWConfig.M
+			}
+			if cfg.HNSWConfig.EfConstruction > 0 {
+				efConstruction = cfg.HNSWConfig.EfConstruction
 			}
 		}
+		return fmt.Sprintf(`
+			%s IF NOT EXISTS %s ON %s
+			USING hnsw (embedding %s)
+			WITH (m = %d, ef_construction = %d)
+		`, create, pq.QuoteIdentifier(indexName), table.quoted(), opClass, m, efConstruction)
+
+	case vector.IndexTypeIVFFlat:
+		lists := 100 // Default
+		return fmt.Sprintf(`
+			%s IF NOT EXISTS %s ON %s
+			USING ivfflat (embedding %s)
+			WITH (lists = %d)
+		`, create, pq.QuoteIdentifier(indexName), table.quoted(), opClass, lists)
+
+	case vector.IndexTypeDiskANN:
+		return fmt.Sprintf(`
+			%s IF NOT EXISTS %s ON %s
+			USING diskann (embedding %s)
+			%s
+		`, create, pq.QuoteIdentifier(indexName), table.quoted(), opClass, diskANNConfig(cfg.DiskANNConfig).withOptions())
+
+	default:
+		return ""
 	}
+}
 
-	return nil
+// vectorIndexName returns the name of the vector index on a table.
+func vectorIndexName(table string) string {
+	return fmt.Sprintf("%s_embedding_idx", table)
 }
 
 // DropIndex implements vector.IndexManager.
 func (m *Manager) DropIndex(ctx context.Context, name string) error {
+	table, err := m.table(name)
+	if err != nil {
+		return err
+	}
+
 	// Drop the table (CASCADE will remove the index too)
-	query := fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", pq.QuoteIdentifier(name))
-	_, err := m.db.ExecContext(ctx, query)
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table.quoted())
+	_, err = m.db.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to drop table: %w", err)
 	}
@@ -102,14 +160,19 @@ func (m *Manager) DropIndex(ctx context.Context, name string) error {
 
 // IndexExists implements vector.IndexManager.
 func (m *Manager) IndexExists(ctx context.Context, name string) (bool, error) {
+	table, err := m.table(name)
+	if err != nil {
+		return false, err
+	}
+
 	query := `
 		SELECT EXISTS (
-			SELECT FROM information_schema.tables
-			WHERE table_name = $1
+			SELECT FROM pg_class
+			WHERE oid = to_regclass($1) AND relkind IN ('r', 'p', 'v')
 		)
 	`
 	var exists bool
-	err := m.db.QueryRowContext(ctx, query, name).Scan(&exists)
+	err = m.db.QueryRowContext(ctx, query, table.quoted()).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check table existence: %w", err)
 	}
@@ -118,48 +181,98 @@ func (m *Manager) IndexExists(ctx context.Context, name string) (bool, error) {
 
 // IndexStats implements vector.IndexManager.
 func (m *Manager) IndexStats(ctx context.Context, name string) (*vector.IndexStats, error) {
+	table, err := m.table(name)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get row count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", pq.QuoteIdentifier(name))
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", table.quoted())
 	var count int64
 	if err := m.db.QueryRowContext(ctx, countQuery).Scan(&count); err != nil {
 		return nil, fmt.Errorf("failed to get row count: %w", err)
 	}
 
-	// Get dimensions from column definition (best effort, ignore errors)
-	dimQuery := `
-		SELECT character_maximum_length
-		FROM information_schema.columns
-		WHERE table_name = $1 AND column_name = 'embedding'
+	stats := &vector.IndexStats{
+		Name:      name,
+		NodeCount: count,
+	}
+	relation := table.quoted()
+
+	// Get dimensions from the column type modifier (best effort, ignore errors)
+	stats.Dimensions, _ = embeddingDimensions(ctx, m.db, relation)
+
+	// Get table size excluding indexes (best effort, ignore errors)
+	_ = m.db.QueryRowContext(ctx, "SELECT pg_table_size($1::regclass)", relation).Scan(&stats.TableSizeBytes)
+
+	// Get dead tuple count (best effort, ignore errors)
+	var deadTuples sql.NullInt64
+	_ = m.db.QueryRowContext(ctx,
+		"SELECT n_dead_tup FROM pg_stat_user_tables WHERE relid = $1::regclass",
+		relation,
+	).Scan(&deadTuples)
+	stats.DeadTuples = deadTuples.Int64
+
+	// Introspect the vector index (best effort, ignore errors)
+	indexQuery := `
+		SELECT am.amname, c.reloptions, pg_relation_size(c.oid)
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = c.relam
+		WHERE i.indrelid = $1::regclass
+		  AND am.amname IN ('hnsw', 'ivfflat', 'diskann')
+		ORDER BY c.relname
+		LIMIT 1
 	`
-	var dimensions sql.NullInt64
-	_ = m.db.QueryRowContext(ctx, dimQuery, name).Scan(&dimensions)
+	var (
+		amName     string
+		relOptions []string
+		indexSize  int64
+	)
+	err = m.db.QueryRowContext(ctx, indexQuery, relation).Scan(&amName, pq.Array(&relOptions), &indexSize)
+	if err == nil {
+		stats.IndexType = vector.IndexType(amName)
+		stats.IndexParams = parseRelOptions(relOptions)
+		stats.IndexSizeBytes = indexSize
+	} else {
+		stats.IndexType = vector.IndexTypeFlat
+	}
 
-	// Get table size (best effort, ignore errors)
-	//nolint:gosec // Table name escaped via pq.QuoteLiteral
-	sizeQuery := fmt.Sprintf("SELECT pg_total_relation_size(%s)", pq.QuoteLiteral(name))
-	var size int64
-	_ = m.db.QueryRowContext(ctx, sizeQuery).Scan(&size)
+	return stats, nil
+}
 
-	return &vector.IndexStats{
-		Name:           name,
-		NodeCount:      count,
-		Dimensions:     int(dimensions.Int64),
-		IndexSizeBytes: size,
-	}, nil
+// parseRelOptions converts PostgreSQL reloptions ("key=value") into a map.
+func parseRelOptions(options []string) map[string]string {
+	params := make(map[string]string, len(options))
+	for _, opt := range options {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			continue
+		}
+		params[key] = value
+	}
+	return params
 }
 
-// ListIndexes implements vector.IndexManager.
+// ListIndexes implements vector.IndexManager. It lists the manager's schema,
+// or the current schema if none is set.
 func (m *Manager) ListIndexes(ctx context.Context) ([]string, error) {
-	// Find tables that have a vector column named 'embedding'
+	// Find tables that have a vector column named 'embedding', skipping alias
+	// views and the partitions of partitioned tables
 	query := `
-		SELECT table_name
-		FROM information_schema.columns
-		WHERE column_name = 'embedding'
-		  AND data_type = 'USER-DEFINED'
-		  AND udt_name = 'vector'
+		SELECT c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid
+		JOIN pg_type t ON t.oid = a.atttypid
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+		  AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+		  AND a.attname = 'embedding' AND NOT a.attisdropped
+		  AND t.typname IN ('vector', 'halfvec', 'sparsevec')
+		ORDER BY c.relname
 	`
 
-	rows, err := m.db.QueryContext(ctx, query)
+	rows, err := m.db.QueryContext(ctx, query, m.schema)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tables: %w", err)
 	}
@@ -177,6 +290,107 @@ func (m *Manager) ListIndexes(ctx context.Context) ([]string, error) {
 	return tables, rows.Err()
 }
 
+// RebuildIndex implements vector.IndexOptimizer. It rebuilds the table's
+// vector indexes with REINDEX CONCURRENTLY (PostgreSQL 12+), so reads and
+// writes continue while the index is rebuilt. IVFFlat indexes are first
+// re-sized to the recommended number of lists for the current row count
+// (rows / 1000 up to 1M rows, sqrt(rows) above), so recall doesn't degrade
+// as the table grows.
+func (m *Manager) RebuildIndex(ctx context.Context, name string) error {
+	table, err := m.table(name)
+	if err != nil {
+		return err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT n.nspname, c.relname, am.amname, c.relkind,
+			COALESCE((SELECT option_value FROM pg_options_to_table(c.reloptions) WHERE option_name = 'lists'), '')
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_am am ON am.oid = c.relam
+		WHERE i.indrelid = $1::regclass
+		  AND am.amname IN ('hnsw', 'ivfflat', 'diskann')
+	`, table.quoted())
+	if err != nil {
+		return fmt.Errorf("failed to find vector indexes: %w", err)
+	}
+	type vectorIndex struct {
+		name        string
+		method      string
+		partitioned bool
+		lists       string
+	}
+	var indexes []vectorIndex
+	for rows.Next() {
+		var ref tableRef
+		var index vectorIndex
+		var relkind string
+		if err := rows.Scan(&ref.schema, &ref.name, &index.method, &relkind, &index.lists); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan index name: %w", err)
+		}
+		index.name, index.partitioned = ref.quoted(), relkind == "I"
+		indexes = append(indexes, index)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to find vector indexes: %w", err)
+	}
+
+	for _, index := range indexes {
+		// Storage parameters of partitioned indexes can't be altered
+		if index.method == "ivfflat" && !index.partitioned {
+			if err := m.resizeLists(ctx, table, index.name, index.lists); err != nil {
+				return err
+			}
+		}
+		if _, err := m.db.ExecContext(ctx, fmt.Sprintf("REINDEX INDEX CONCURRENTLY %s", index.name)); err != nil {
+			return fmt.Errorf("failed to rebuild index %s: %w", index.name, err)
+		}
+	}
+	return nil
+}
+
+// resizeLists sets the lists of an IVFFlat index to ivfflatLists of the
+// table's row count, if it differs from current, so the next rebuild trains
+// that many centroids.
+func (m *Manager) resizeLists(ctx context.Context, table tableRef, index, current string) error {
+	var count int64
+	if err := m.db.QueryRowContext(ctx, fmt.Sprintf("SELECT count(*) FROM %s", table.quoted())).Scan(&count); err != nil {
+		return fmt.Errorf("failed to count rows: %w", err)
+	}
+	lists := strconv.Itoa(ivfflatLists(count))
+	if lists == current {
+		return nil
+	}
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf("ALTER INDEX %s SET (lists = %s)", index, lists)); err != nil {
+		return fmt.Errorf("failed to resize index %s: %w", index, err)
+	}
+	return nil
+}
+
+// ivfflatLists returns the number of IVFFlat lists pgvector recommends for
+// rows: rows / 1000 up to 1M rows, sqrt(rows) above.
+func ivfflatLists(rows int64) int {
+	if rows > 1_000_000 {
+		return int(math.Sqrt(float64(rows)))
+	}
+	return max(int(rows/1000), 1)
+}
+
+// RefreshStats implements vector.IndexOptimizer by running ANALYZE.
+func (m *Manager) RefreshStats(ctx context.Context, name string) error {
+	table, err := m.table(name)
+	if err != nil {
+		return err
+	}
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf("ANALYZE %s", table.quoted())); err != nil {
+		return fmt.Errorf("failed to analyze table: %w", err)
+	}
+	return nil
+}
+
 // distanceMetricToOpClass converts OmniRetrieve distance metric to pgvector operator class.
 func distanceMetricToOpClass(metric vector.DistanceMetric) string {
 	switch metric {
@@ -190,4 +404,8 @@ func distanceMetricToOpClass(metric vector.DistanceMetric) string {
 }
 
 // Verify interface compliance
-var _ vector.IndexManager = (*Manager)(nil)
+var (
+	_ vector.IndexManager   = (*Manager)(nil)
+	_ vector.AliasManager   = (*Manager)(nil)
+	_ vector.IndexOptimizer = (*Manager)(nil)
+)
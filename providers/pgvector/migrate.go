@@ -0,0 +1,312 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// defaultMigrateBatchSize is the number of rows Migrate copies at a time by
+// default.
+const defaultMigrateBatchSize = 500
+
+// reembedParamsPerRow is the number of bind parameters copyBatch uses per
+// re-embedded row.
+const reembedParamsPerRow = 7
+
+// ReembedFunc returns new embeddings for a batch of nodes, in order.
+type ReembedFunc func(ctx context.Context, nodes []vector.Node) ([][]float32, error)
+
+// EmbedderReembed returns a ReembedFunc that embeds each node's content
+// with embedder.
+func EmbedderReembed(embedder vector.Embedder) ReembedFunc {
+	return func(ctx context.Context, nodes []vector.Node) ([][]float32, error) {
+		texts := make([]string, len(nodes))
+		for i, node := range nodes {
+			texts[i] = node.Content
+		}
+		return embedder.EmbedBatch(ctx, texts)
+	}
+}
+
+// MigrateOptions configures Migrate.
+type MigrateOptions struct {
+	// Target configures the new table's dimensions, distance metric, and
+	// vector index. Target.Name is ignored.
+	Target vector.IndexConfig
+	// Reembed computes the new embeddings (optional). It is required when
+	// the dimensions change; otherwise embeddings are copied as is.
+	Reembed ReembedFunc
+	// BatchSize is the number of rows copied at a time (default 500). With
+	// Reembed set, it is capped so that a batch fits in one statement.
+	BatchSize int
+	// Progress is called with the number of rows copied after each batch
+	// (optional).
+	Progress func(copied int64)
+	// KeepOld keeps the old table, renamed to "<name>_old_<unix time>",
+	// instead of dropping it.
+	KeepOld bool
+}
+
+// Migrate moves a table to new dimensions, distance metric, or vector index,
+// e.g. after switching embedding models. It creates a new table, copies the
+// rows in batches (re-embedding them if opts.Reembed is set), builds the
+// vector index, and then swaps the tables atomically, so readers see either
+// the old or the new table. Writes made during the copy are not migrated.
+//
+// The new table has the standard schema; Index options that add columns
+// (FullText, SoftDelete, Expiration, PromotedColumns) recreate them when the
+// Index is opened. Soft-deleted rows are not copied, and expiration times are
+// dropped. Partitioned tables and tables
+// with compressed content are not supported. Aliases keep pointing at the
+// old table, so with KeepOld unset the swap fails until they are moved.
+func (m *Manager) Migrate(ctx context.Context, name string, opts MigrateOptions) (err error) {
+	table, err := m.table(name)
+	if err != nil {
+		return err
+	}
+	if opts.Target.Dimensions <= 0 {
+		return fmt.Errorf("target dimensions must be positive")
+	}
+	opts.BatchSize = migrateBatchSize(opts)
+
+	columns, err := tableColumns(ctx, m.db, table)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+	if _, ok := columns[partitionColumn]; ok {
+		return fmt.Errorf("migrating partitioned tables is not supported")
+	}
+	if _, ok := columns[compressedColumn]; ok {
+		return fmt.Errorf("migrating tables with compressed content is not supported")
+	}
+	dimensions, err := embeddingDimensions(ctx, m.db, table.quoted())
+	if err != nil {
+		return err
+	}
+	if dimensions != opts.Target.Dimensions && opts.Reembed == nil {
+		return fmt.Errorf("changing dimensions from %d to %d requires Reembed", dimensions, opts.Target.Dimensions)
+	}
+
+	suffix := time.Now().Unix()
+	tmp := tableRef{schema: table.schema, name: fmt.Sprintf("%s_migrate_%d", table.name, suffix)}
+	flat := opts.Target
+	flat.IndexType = vector.IndexTypeFlat
+	err = withDDLLock(ctx, m.db, func(tx *sql.Tx) error {
+		return createIndexSchema(ctx, tx, tmp, flat)
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_, _ = m.db.ExecContext(context.WithoutCancel(ctx), fmt.Sprintf("DROP TABLE IF EXISTS %s", tmp.quoted()))
+		}
+	}()
+
+	where := "id > $1"
+	if _, ok := columns[deletedColumn]; ok {
+		where += " AND " + deletedColumn + " IS NULL"
+	}
+	var copied int64
+	for cursor := ""; ; {
+		n, last, err := m.copyBatch(ctx, table, tmp, where, cursor, opts)
+		if err != nil {
+			return fmt.Errorf("failed to copy rows after %q: %w", cursor, err)
+		}
+		copied += int64(n)
+		if opts.Progress != nil && n > 0 {
+			opts.Progress(copied)
+		}
+		if n < opts.BatchSize {
+			break
+		}
+		cursor = last
+	}
+
+	if stmt := vectorIndexSQL(tmp, opts.Target, false); stmt != "" {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create vector index: %w", err)
+		}
+	}
+
+	old := tableRef{schema: table.schema, name: fmt.Sprintf("%s_old_%d", table.name, suffix)}
+	return withDDLLock(ctx, m.db, func(tx *sql.Tx) error {
+		if err := renameTable(ctx, tx, table, old); err != nil {
+			return err
+		}
+		if err := renameTable(ctx, tx, tmp, table); err != nil {
+			return err
+		}
+		if opts.KeepOld {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", old.quoted())); err != nil {
+			return fmt.Errorf("failed to drop old table: %w", err)
+		}
+		return nil
+	})
+}
+
+// migrateBatchSize returns the number of rows Migrate copies at a time.
+func migrateBatchSize(opts MigrateOptions) int {
+	size := opts.BatchSize
+	if size <= 0 {
+		size = defaultMigrateBatchSize
+	}
+	if opts.Reembed != nil {
+		size = min(size, maxParams/reembedParamsPerRow)
+	}
+	return size
+}
+
+// copyBatch copies up to opts.BatchSize rows matching where (which binds
+// the cursor as $1) from table to tmp, in ID order. It returns the number of
+// rows copied and the last ID.
+func (m *Manager) copyBatch(ctx context.Context, table, tmp tableRef, where, cursor string, opts MigrateOptions) (int, string, error) {
+	if opts.Reembed == nil {
+		var (
+			n    int
+			last string
+		)
+		//nolint:gosec // Table names escaped via pq.QuoteIdentifier, values are parameterized
+		err := m.db.QueryRowContext(ctx, fmt.Sprintf(`
+			WITH batch AS (
+				INSERT INTO %s (id, content, embedding, source, metadata, created_at, updated_at)
+				SELECT id, content, embedding::vector, source, metadata, created_at, updated_at
+				FROM %s
+				WHERE %s
+				ORDER BY id
+				LIMIT $2
+				RETURNING id
+			)
+			SELECT count(*), COALESCE(max(id), '') FROM batch
+		`, tmp.quoted(), table.quoted(), where), cursor, opts.BatchSize).Scan(&n, &last)
+		return n, last, err
+	}
+
+	nodes, rows, err := m.readBatch(ctx, table, where, cursor, opts.BatchSize)
+	if err != nil || len(nodes) == 0 {
+		return 0, "", err
+	}
+	embeddings, err := opts.Reembed(ctx, nodes)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to re-embed: %w", err)
+	}
+	if len(embeddings) != len(nodes) {
+		return 0, "", fmt.Errorf("re-embedding returned %d embeddings for %d nodes", len(embeddings), len(nodes))
+	}
+
+	values := make([]string, len(nodes))
+	args := make([]any, 0, len(nodes)*reembedParamsPerRow)
+	for i, node := range nodes {
+		if len(embeddings[i]) != opts.Target.Dimensions {
+			return 0, "", &DimensionError{ID: node.ID, Expected: opts.Target.Dimensions, Actual: len(embeddings[i])}
+		}
+		base := len(args)
+		values[i] = fmt.Sprintf("($%d, $%d, $%d::vector, $%d, $%d::jsonb, $%d, $%d)",
+			base+1, base+2, base+3, base+4, base+5, base+6, base+7)
+		r := rows[i]
+		args = append(args, node.ID, r.content, vectorToString(embeddings[i]), r.source, r.metadata, r.createdAt, r.updatedAt)
+	}
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, values are parameterized
+	_, err = m.db.ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s (id, content, embedding, source, metadata, created_at, updated_at) VALUES %s",
+		tmp.quoted(), strings.Join(values, ", "),
+	), args...)
+	if err != nil {
+		return 0, "", err
+	}
+	return len(nodes), nodes[len(nodes)-1].ID, nil
+}
+
+// migrateRow holds the columns of a migrated row that are copied verbatim.
+type migrateRow struct {
+	content, source      sql.NullString
+	metadata             []byte
+	createdAt, updatedAt sql.NullTime
+}
+
+// readBatch reads up to limit rows matching where after cursor, returning
+// them as nodes and with their verbatim columns.
+func (m *Manager) readBatch(ctx context.Context, table tableRef, where, cursor string, limit int) ([]vector.Node, []migrateRow, error) {
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, values are parameterized
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, content, embedding::vector::text, source, metadata, created_at, updated_at
+		FROM %s
+		WHERE %s
+		ORDER BY id
+		LIMIT $2
+	`, table.quoted(), where), cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var (
+		nodes []vector.Node
+		raw   []migrateRow
+	)
+	for rows.Next() {
+		var (
+			id        string
+			embedding sql.NullString
+			r         migrateRow
+		)
+		if err := rows.Scan(&id, &r.content, &embedding, &r.source, &r.metadata, &r.createdAt, &r.updatedAt); err != nil {
+			return nil, nil, err
+		}
+		nodes = append(nodes, searchResult(id, r.content.String, parseVector(embedding.String), r.source, r.metadata, 0).Node)
+		raw = append(raw, r)
+	}
+	return nodes, raw, rows.Err()
+}
+
+// renameTable renames a table within its schema, along with its indexes
+// named after it (primary key, vector, full-text, and promoted column
+// indexes), so that their names match those of a table created under the
+// new name and don't block creating them later.
+func renameTable(ctx context.Context, tx *sql.Tx, from, to tableRef) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.relname
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE i.indrelid = $1::regclass AND starts_with(c.relname, $2)
+	`, from.quoted(), from.name+"_")
+	if err != nil {
+		return fmt.Errorf("failed to list indexes of %s: %w", from, err)
+	}
+	var indexes []string
+	for rows.Next() {
+		var index string
+		if err := rows.Scan(&index); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan index: %w", err)
+		}
+		indexes = append(indexes, index)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list indexes of %s: %w", from, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from.quoted(), pq.QuoteIdentifier(to.name))); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", from, to, err)
+	}
+	for _, index := range indexes {
+		renamed := to.name + strings.TrimPrefix(index, from.name)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER INDEX %s RENAME TO %s", from.quoteRelation(index), pq.QuoteIdentifier(renamed))); err != nil {
+			return fmt.Errorf("failed to rename index %s: %w", index, err)
+		}
+	}
+	return nil
+}
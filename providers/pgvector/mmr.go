@@ -0,0 +1,107 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// defaultMMRLambda weighs relevance and diversity equally.
+const defaultMMRLambda = 0.5
+
+// MMROptions configures SearchMMR.
+type MMROptions struct {
+	// Search configures the candidate search (filter, tuning, and
+	// IncludeDeleted).
+	Search vector.SearchOptions
+	// Lambda trades relevance (1) against diversity (near 0); default 0.5.
+	Lambda float64
+	// Candidates is the number of nearest nodes to diversify (default 4*k).
+	Candidates int
+}
+
+// withDefaults returns opts with defaults applied for k results.
+func (opts MMROptions) withDefaults(k int) (MMROptions, error) {
+	if opts.Lambda == 0 {
+		opts.Lambda = defaultMMRLambda
+	}
+	if opts.Lambda < 0 || opts.Lambda > 1 {
+		return opts, fmt.Errorf("mmr lambda must be between 0 and 1")
+	}
+	if opts.Candidates == 0 {
+		opts.Candidates = 4 * k
+	}
+	if opts.Candidates < k {
+		return opts, fmt.Errorf("mmr candidates must be at least k")
+	}
+	return opts, nil
+}
+
+// SearchMMR returns k nodes balancing relevance and diversity with maximal
+// marginal relevance: it fetches the nearest candidates, then repeatedly
+// picks the one maximizing Lambda*score - (1-Lambda)*(its highest cosine
+// similarity to the nodes already picked). Results keep their search scores
+// and are in the order picked.
+func (idx *Index) SearchMMR(ctx context.Context, embedding []float32, k int, opts MMROptions) ([]vector.SearchResult, error) {
+	opts, err := opts.withDefaults(k)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := idx.SearchWithOptions(ctx, embedding, opts.Candidates, opts.Search)
+	if err != nil {
+		return nil, err
+	}
+	return mmr(candidates, k, opts.Lambda), nil
+}
+
+// SearchMMR is Index.SearchMMR using the binary vector encoding.
+func (idx *PgxIndex) SearchMMR(ctx context.Context, embedding []float32, k int, opts MMROptions) ([]vector.SearchResult, error) {
+	opts, err := opts.withDefaults(k)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := idx.SearchWithOptions(ctx, embedding, opts.Candidates, opts.Search)
+	if err != nil {
+		return nil, err
+	}
+	return mmr(candidates, k, opts.Lambda), nil
+}
+
+// mmr picks up to k of the candidates by maximal marginal relevance.
+func mmr(candidates []vector.SearchResult, k int, lambda float64) []vector.SearchResult {
+	k = min(k, len(candidates))
+	selected := make([]vector.SearchResult, 0, k)
+	picked := make([]bool, len(candidates))
+	// maxSim[i] is candidate i's highest similarity to a selected node
+	maxSim := make([]float64, len(candidates))
+	for i := range maxSim {
+		maxSim[i] = math.Inf(-1)
+	}
+
+	for len(selected) < k {
+		best, bestScore := -1, math.Inf(-1)
+		for i, c := range candidates {
+			if picked[i] {
+				continue
+			}
+			score := lambda * c.Score
+			if len(selected) > 0 {
+				score -= (1 - lambda) * maxSim[i]
+			}
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		picked[best] = true
+		selected = append(selected, candidates[best])
+		for i, c := range candidates {
+			if !picked[i] {
+				maxSim[i] = max(maxSim[i], vector.Similarity(vector.DistanceCosine, c.Node.Embedding, candidates[best].Node.Embedding))
+			}
+		}
+	}
+	return selected
+}
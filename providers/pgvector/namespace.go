@@ -0,0 +1,62 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// Namespaces implements vector.NamespaceIndex. It requires NamespaceKey and
+// scans the table, which is fast enough for admin tooling but shouldn't run
+// per request.
+func (idx *Index) Namespaces(ctx context.Context) ([]string, error) {
+	key := idx.config.NamespaceKey
+	if key == "" {
+		return nil, vector.ErrNamespacesUnsupported
+	}
+	tenant, err := idx.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := fmt.Sprintf("metadata->>%s IS NOT NULL", pq.QuoteLiteral(key)), []any(nil)
+	if tenant != "" {
+		where += " AND " + keyPredicate(idx.config.TenantKey, 1)
+		args = append(args, tenant)
+	}
+	if idx.config.SoftDelete {
+		where += " AND " + deletedColumn + " IS NULL"
+	}
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, key via pq.QuoteLiteral
+	query := fmt.Sprintf("SELECT DISTINCT metadata->>%s FROM %s WHERE %s ORDER BY 1",
+		pq.QuoteLiteral(key), idx.table.quoted(), where)
+
+	var namespaces []string
+	err = idx.withSettings(ctx, idx.sessionSettings(ctx), func(db querier) error {
+		rows, err := idx.query(ctx, db, query, args)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var namespace string
+			if err := rows.Scan(&namespace); err != nil {
+				return err
+			}
+			namespaces = append(namespaces, namespace)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", idx.timeoutError(ctx, err))
+	}
+	return namespaces, nil
+}
+
+// Verify interface compliance
+var (
+	_ vector.NamespaceIndex = (*Index)(nil)
+	_ vector.NamespaceIndex = (*PgxIndex)(nil)
+)
@@ -0,0 +1,75 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// SearchPage implements vector.PageIndex with keyset pagination: each page
+// continues after the score and ID of the previous page's last result, so
+// deep pages cost no more to rank than the first. Approximate indexes only
+// see EfSearch (or Probes) candidates per query, so raise them when paging
+// deep into an HNSW or IVFFlat index. Pagination does not support
+// Quantization.
+func (idx *Index) SearchPage(ctx context.Context, embedding []float32, cursor string, limit int, opts vector.SearchOptions) ([]vector.SearchResult, string, error) {
+	after, err := pageCursor(cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, "", err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	query, args, err := idx.rankedQuery(idx.encodeText(embedding), limit, opts, &after)
+	if err != nil {
+		return nil, "", err
+	}
+	results, err := idx.searchWithSettings(ctx, query, pqArgs(args), settings)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, vector.NextPageCursor(results, limit), nil
+}
+
+// SearchPage is Index.SearchPage using the binary vector encoding.
+func (idx *PgxIndex) SearchPage(ctx context.Context, embedding []float32, cursor string, limit int, opts vector.SearchOptions) ([]vector.SearchResult, string, error) {
+	after, err := pageCursor(cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, "", err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	query, args, err := idx.rankedQuery(idx.encodeBinary(embedding), limit, opts, &after)
+	if err != nil {
+		return nil, "", err
+	}
+	results, err := idx.searchWithSettings(ctx, query, args, settings)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, vector.NextPageCursor(results, limit), nil
+}
+
+// pageCursor parses a SearchPage cursor and validates the page limit.
+func pageCursor(cursor string, limit int) (vector.PageCursor, error) {
+	if limit <= 0 {
+		return vector.PageCursor{}, fmt.Errorf("invalid page limit %d", limit)
+	}
+	return vector.ParsePageCursor(cursor)
+}
+
+// Verify interface compliance
+var (
+	_ vector.PageIndex = (*Index)(nil)
+	_ vector.PageIndex = (*PgxIndex)(nil)
+)
@@ -0,0 +1,150 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// partitionColumn holds each row's partition value when Partitioning is set.
+const partitionColumn = "partition_key"
+
+// PartitionConfig configures LIST partitioning of the table by a metadata
+// value, such as a tenant ID or a month. Partitions are created
+// automatically when nodes are written. A node's partition value must not
+// change; upserting it with a different value writes a second row.
+type PartitionConfig struct {
+	// Key is the metadata key whose value selects a node's partition
+	// (e.g., "tenant_id"). Nodes without it share the "" partition.
+	Key string
+	// Expression maps the key's value to the partition value (optional).
+	// It defaults to the value itself; PartitionByMonth partitions RFC 3339
+	// timestamps by month.
+	Expression func(value string) string
+}
+
+// PartitionByMonth maps an RFC 3339 timestamp or date ("2006-01-02...") to
+// its month ("2006-01"), for use as PartitionConfig.Expression.
+func PartitionByMonth(value string) string {
+	if len(value) < 7 {
+		return value
+	}
+	return value[:7]
+}
+
+// partitionValue returns the partition a metadata value belongs to.
+func (p *PartitionConfig) partitionValue(value string) string {
+	if p.Expression == nil {
+		return value
+	}
+	return p.Expression(value)
+}
+
+// partitionName returns the name of the partition table for a value. Values
+// are hashed, since they may contain any characters.
+func partitionName(table tableRef, value string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	return fmt.Sprintf("%s_p_%016x", table.name, h.Sum64())
+}
+
+// ensurePartitions creates the partitions nodes will be written to, unless
+// this index already created them.
+func (idx *Index) ensurePartitions(ctx context.Context, nodes []vector.Node) error {
+	if idx.config.Partitioning == nil {
+		return nil
+	}
+
+	idx.partitionsMu.Lock()
+	defer idx.partitionsMu.Unlock()
+
+	missing := idx.missingPartitions(nodes, nil)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	err := withDDLLock(ctx, idx.db, func(tx *sql.Tx) error {
+		return idx.createPartitions(ctx, tx, missing)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, value := range missing {
+		idx.partitions[value] = true
+	}
+	return nil
+}
+
+// missingPartitions returns the partition values of nodes that neither this
+// index nor created has created. The caller must hold partitionsMu.
+func (idx *Index) missingPartitions(nodes []vector.Node, created map[string]bool) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		value := idx.partitionOf(node)
+		if idx.partitions[value] || created[value] || seen[value] {
+			continue
+		}
+		seen[value] = true
+		missing = append(missing, value)
+	}
+	return missing
+}
+
+// createPartitions creates the partitions for values in tx, which must hold
+// the DDL advisory lock.
+func (idx *Index) createPartitions(ctx context.Context, tx *sql.Tx, values []string) error {
+	for _, value := range values {
+		//nolint:gosec // Identifiers escaped via pq.QuoteIdentifier, value via pq.QuoteLiteral
+		stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES IN (%s)",
+			idx.table.quoteRelation(partitionName(idx.table, value)), idx.table.quoted(), pq.QuoteLiteral(value))
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create partition for %q: %w", value, err)
+		}
+	}
+	return nil
+}
+
+// partitionOf returns the partition value of a node.
+func (idx *Index) partitionOf(node vector.Node) string {
+	p := idx.config.Partitioning
+	return p.partitionValue(node.Metadata[p.Key])
+}
+
+// partitionPredicate returns a predicate restricting a search to the
+// partitions the filter can match, so PostgreSQL prunes the others. It
+// returns "" if the filter doesn't pin the partition key with eq or in,
+// either at the top level or within an and.
+func (idx *Index) partitionPredicate(b *filterBuilder, f vector.Filter) string {
+	p := idx.config.Partitioning
+	if p == nil {
+		return ""
+	}
+
+	switch f.Op {
+	case vector.FilterEq:
+		if value, err := vector.FormatFilterValue(f.Value); err == nil && f.Key == p.Key {
+			return fmt.Sprintf("%s = %s", partitionColumn, b.arg(p.partitionValue(value)))
+		}
+	case vector.FilterIn:
+		if values, err := vector.FilterValues(f.Value); err == nil && f.Key == p.Key {
+			partitions := make([]string, len(values))
+			for i, v := range values {
+				partitions[i] = p.partitionValue(v)
+			}
+			return fmt.Sprintf("%s = ANY(%s)", partitionColumn, b.arg(partitions))
+		}
+	case vector.FilterAnd:
+		for _, sub := range f.Filters {
+			if pred := idx.partitionPredicate(b, sub); pred != "" {
+				return pred
+			}
+		}
+	}
+	return ""
+}
@@ -5,37 +5,155 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/agentplexus/omniretrieve/retrieve"
 	"github.com/agentplexus/omniretrieve/vector"
 	"github.com/lib/pq"
+	pgv "github.com/pgvector/pgvector-go"
 )
 
 // Index implements vector.Index using PostgreSQL with pgvector extension.
 type Index struct {
-	db        *sql.DB
-	tableName string
-	config    Config
+	db       *sql.DB
+	table    tableRef
+	config   Config
+	promoted map[string]PromotedColumn
+	stmts    *stmtCache
+
+	partitionsMu sync.Mutex
+	partitions   map[string]bool
 }
 
 // Config configures the pgvector index.
 type Config struct {
-	// TableName is the name of the table to use for vectors.
+	// TableName is the name of the table to use for vectors. It may be
+	// schema-qualified as "schema.table" when Schema is empty.
 	TableName string
+	// Schema is the schema containing the table. If neither Schema nor
+	// TableName names a schema, the table resolves through search_path.
+	Schema string
 	// Dimensions is the vector dimension size.
 	Dimensions int
 	// DistanceMetric is the distance function (cosine, euclidean, inner_product).
 	DistanceMetric DistanceMetric
-	// CreateTableIfNotExists creates the table on first use if true.
+	// ExtraMetrics are additional distance metrics to create vector indexes
+	// for, so searches overriding the metric with SearchOptions.Metric use
+	// an index instead of scanning the table (optional). It only applies
+	// when the table is created, and cannot be combined with Quantization.
+	ExtraMetrics []DistanceMetric
+	// CreateTableIfNotExists creates the table on first use if true. DDL runs
+	// under an advisory lock so concurrent instances don't race.
 	CreateTableIfNotExists bool
-	// IndexType specifies the index algorithm (hnsw, ivfflat, or none).
+	// VerifySchema skips DDL entirely and instead checks that the extension
+	// and table exist with the expected columns and dimensions. It takes
+	// precedence over CreateTableIfNotExists.
+	VerifySchema bool
+	// VectorType is the embedding column type (default vector). halfvec
+	// halves storage; sparsevec stores only non-zero elements.
+	VectorType VectorType
+	// IndexType specifies the index algorithm (hnsw, ivfflat, diskann, or
+	// none).
 	IndexType IndexType
 	// HNSWConfig contains HNSW-specific parameters.
 	HNSWConfig *HNSWConfig
 	// IVFFlatConfig contains IVFFlat-specific parameters.
 	IVFFlatConfig *IVFFlatConfig
+	// DiskANNConfig contains DiskANN-specific parameters.
+	DiskANNConfig *DiskANNConfig
+	// SoftDelete makes Delete mark rows as deleted instead of removing
+	// them, so past retrievals stay reproducible. Searches exclude deleted
+	// rows unless SearchOptions.IncludeDeleted is set; Purge removes them.
+	SoftDelete bool
+	// Expiration adds an expires_at column storing Node.ExpiresAt. Searches,
+	// lookups, and counts skip expired rows, and PurgeExpired, e.g. run by a
+	// vector.Reaper, removes them. Reads don't return ExpiresAt. Without it,
+	// writing a node with ExpiresAt fails with vector.ErrExpirationUnsupported.
+	Expiration bool
+	// Partitioning partitions the table by a metadata value (optional).
+	// It only applies when the table is created, and requires PostgreSQL 11+.
+	Partitioning *PartitionConfig
+	// FullText adds a generated tsvector column over content with a GIN
+	// index, enabling HybridSearch. Requires PostgreSQL 12+.
+	FullText bool
+	// TextSearchConfig is the text search configuration used to parse
+	// content and queries when FullText is set (default "english").
+	TextSearchConfig string
+	// PromotedColumns maps frequently filtered metadata keys to typed,
+	// indexed columns, which filters on those keys then use.
+	PromotedColumns []PromotedColumn
+	// CompressContent stores content of 1 KiB or more gzip-compressed in a
+	// separate bytea column, transparently to callers. Rows written before
+	// it was enabled stay readable. It cannot be combined with FullText.
+	CompressContent bool
+	// TenantKey scopes every operation to the tenant passed with WithTenant,
+	// stored under this metadata key (optional). Searches, lookups, and
+	// deletes only see the tenant's nodes, writes stamp the key into each
+	// node's metadata, and operations without a tenant fail with
+	// ErrNoTenant. IDs are shared across tenants: upserting an ID another
+	// tenant owns leaves that node unchanged and fails with
+	// vector.ErrConflict.
+	TenantKey string
+	// TenantSetting is a configuration parameter (e.g., "app.tenant_id")
+	// set to the tenant with SET LOCAL semantics around every statement,
+	// for row-level security policies (optional). Requires TenantKey.
+	TenantSetting string
+	// NamespaceKey stores each node's namespace under this metadata key,
+	// making the index a vector.NamespaceIndex (optional). Operations are
+	// scoped to the namespace passed with vector.WithNamespace, or the
+	// default namespace "", and nodes without a Namespace are written to it.
+	// Rows written before the key was set belong to no namespace. As with
+	// tenants, IDs are shared across namespaces: upserting an ID another
+	// namespace owns leaves that node unchanged and fails with
+	// vector.ErrConflict.
+	NamespaceKey string
+	// NormalizeScores maps search scores into [0, 1] for every distance
+	// metric, with 1 the best match: (1 + cosine similarity) / 2,
+	// 1 / (1 + Euclidean distance), and (1 + inner product) / 2, clamped
+	// for embeddings that aren't unit-length. By default scores are
+	// 1 - distance, which is only a similarity for cosine distance.
+	NormalizeScores bool
+	// StatementCacheSize caches prepared statements for up to this many
+	// distinct query shapes (filter structure, operator, and options), so
+	// hot searches skip parsing and planning (default 0: disabled). Call
+	// Close to release them. PgxIndex ignores it, since pgx caches prepared
+	// statements on each connection by default.
+	StatementCacheSize int
+	// ChangeFeed adds a trigger notifying listeners of every change to the
+	// table, which Changes streams, e.g. to invalidate caches. Requires
+	// PostgreSQL 11+.
+	ChangeFeed bool
+	// Quantization searches a quantized copy of the embeddings and re-ranks
+	// the candidates by exact distance (optional). It only applies when the
+	// table is created.
+	Quantization *QuantizationConfig
+	// BatchSize is the number of nodes written per statement by UpsertBatch
+	// and DeleteBatch; larger batches are split into chunks. It defaults to,
+	// and may not exceed, the limit imposed by PostgreSQL's 65535 bind
+	// parameters per statement.
+	BatchSize int
+	// StatementTimeout cancels search and maintenance statements running
+	// longer than the timeout with a *TimeoutError (default: no timeout).
+	// WithStatementTimeout overrides it per call.
+	StatementTimeout time.Duration
+	// Retry retries operations failing with transient errors, such as
+	// serialization failures and dropped connections (default: no retries).
+	Retry RetryPolicy
+	// CommitPerChunk commits each chunk of a large batch independently
+	// instead of running all chunks in one transaction. A failure then leaves
+	// earlier chunks written, but avoids one long-running transaction.
+	CommitPerChunk bool
+	// BatchConcurrency is the number of chunks of a large batch written in
+	// parallel, each on its own connection and committed independently, as
+	// with CommitPerChunk (default 1). Failed chunks are reported together
+	// in a *vector.BatchError. Set BatchSize to split batches into enough chunks.
+	BatchConcurrency int
 }
 
 // DistanceMetric defines the distance function for similarity.
@@ -50,6 +168,22 @@ const (
 	DistanceInnerProduct DistanceMetric = "inner_product"
 )
 
+// VectorType defines the embedding column type. halfvec and sparsevec
+// require pgvector 0.7+.
+type VectorType string
+
+const (
+	// VectorTypeVector stores single-precision dense vectors (up to 2000
+	// dimensions with an index).
+	VectorTypeVector VectorType = "vector"
+	// VectorTypeHalfVec stores half-precision dense vectors, halving storage
+	// (up to 4000 dimensions with an index).
+	VectorTypeHalfVec VectorType = "halfvec"
+	// VectorTypeSparseVec stores only non-zero elements (up to 1000 non-zero
+	// elements with an index). Only HNSW indexes are supported.
+	VectorTypeSparseVec VectorType = "sparsevec"
+)
+
 // IndexType defines the vector index algorithm.
 type IndexType string
 
@@ -60,6 +194,9 @@ const (
 	IndexTypeHNSW IndexType = "hnsw"
 	// IndexTypeIVFFlat uses IVFFlat (Inverted File with Flat compression) index.
 	IndexTypeIVFFlat IndexType = "ivfflat"
+	// IndexTypeDiskANN uses the StreamingDiskANN index of the
+	// pgvectorscale extension.
+	IndexTypeDiskANN IndexType = "diskann"
 )
 
 // HNSWConfig contains HNSW index parameters.
@@ -93,8 +230,9 @@ func DefaultConfig(tableName string, dimensions int) Config {
 
 // New creates a new pgvector Index.
 func New(db *sql.DB, cfg Config) (*Index, error) {
-	if cfg.TableName == "" {
-		return nil, fmt.Errorf("table name is required")
+	table, err := parseTableRef(cfg.Schema, cfg.TableName)
+	if err != nil {
+		return nil, err
 	}
 	if cfg.Dimensions <= 0 {
 		return nil, fmt.Errorf("dimensions must be positive")
@@ -102,14 +240,107 @@ func New(db *sql.DB, cfg Config) (*Index, error) {
 	if cfg.DistanceMetric == "" {
 		cfg.DistanceMetric = DistanceCosine
 	}
+	switch cfg.VectorType {
+	case "":
+		cfg.VectorType = VectorTypeVector
+	case VectorTypeVector, VectorTypeHalfVec:
+	case VectorTypeSparseVec:
+		if cfg.IndexType == IndexTypeIVFFlat {
+			return nil, fmt.Errorf("ivfflat indexes do not support sparsevec")
+		}
+	default:
+		return nil, fmt.Errorf("unknown vector type %q", cfg.VectorType)
+	}
+	if cfg.IndexType == IndexTypeDiskANN {
+		if cfg.VectorType != VectorTypeVector {
+			return nil, fmt.Errorf("diskann indexes require the vector type")
+		}
+		if cfg.Quantization != nil {
+			return nil, fmt.Errorf("diskann indexes do not support quantization")
+		}
+	}
+	if err := cfg.DiskANNConfig.validate(); err != nil {
+		return nil, err
+	}
+	for _, metric := range cfg.ExtraMetrics {
+		switch metric {
+		case DistanceCosine, DistanceEuclidean, DistanceInnerProduct:
+		default:
+			return nil, fmt.Errorf("unknown distance metric %q", metric)
+		}
+	}
+	if len(cfg.ExtraMetrics) > 0 && cfg.Quantization != nil {
+		return nil, fmt.Errorf("extra metrics cannot be combined with quantization")
+	}
+	if cfg.Quantization != nil {
+		q, err := cfg.Quantization.withDefaults(cfg.VectorType)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Quantization = &q
+	}
+	if cfg.StatementCacheSize < 0 {
+		return nil, fmt.Errorf("statement cache size must not be negative")
+	}
+	if cfg.TenantSetting != "" && cfg.TenantKey == "" {
+		return nil, fmt.Errorf("tenant setting requires a tenant key")
+	}
+	if cfg.NamespaceKey != "" && cfg.NamespaceKey == cfg.TenantKey {
+		return nil, fmt.Errorf("namespace key must differ from the tenant key")
+	}
+	if cfg.TextSearchConfig == "" {
+		cfg.TextSearchConfig = "english"
+	}
+	cfg.Retry = cfg.Retry.withDefaults()
+	perNode := upsertParamsPerNode
+	if cfg.Partitioning != nil {
+		if cfg.Partitioning.Key == "" {
+			return nil, fmt.Errorf("partition key is required")
+		}
+		perNode++
+	}
+	if cfg.CompressContent {
+		if cfg.FullText {
+			return nil, fmt.Errorf("full-text search requires uncompressed content")
+		}
+		perNode++
+	}
+	if cfg.Expiration {
+		perNode++
+	}
+	limit := maxParams / perNode
+	if cfg.BatchSize > limit {
+		return nil, fmt.Errorf("batch size must not exceed %d", limit)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = limit
+	}
+	if cfg.BatchConcurrency <= 0 {
+		cfg.BatchConcurrency = 1
+	}
+
+	promoted, err := promotedColumns(cfg.PromotedColumns)
+	if err != nil {
+		return nil, err
+	}
 
 	idx := &Index{
-		db:        db,
-		tableName: cfg.TableName,
-		config:    cfg,
+		db:         db,
+		table:      table,
+		config:     cfg,
+		promoted:   promoted,
+		partitions: make(map[string]bool),
+	}
+	if cfg.StatementCacheSize > 0 {
+		idx.stmts = newStmtCache(db, cfg.StatementCacheSize)
 	}
 
-	if cfg.CreateTableIfNotExists {
+	switch {
+	case cfg.VerifySchema:
+		if err := verifySchema(context.Background(), db, table, cfg); err != nil {
+			return nil, fmt.Errorf("failed to verify schema: %w", err)
+		}
+	case cfg.CreateTableIfNotExists:
 		if err := idx.ensureTable(context.Background()); err != nil {
 			return nil, fmt.Errorf("failed to create table: %w", err)
 		}
@@ -120,45 +351,108 @@ func New(db *sql.DB, cfg Config) (*Index, error) {
 
 // ensureTable creates the vector table if it doesn't exist.
 func (idx *Index) ensureTable(ctx context.Context) error {
+	return withDDLLock(ctx, idx.db, func(tx *sql.Tx) error {
+		return idx.createSchema(ctx, tx)
+	})
+}
+
+// createSchema runs the DDL for the extension, table, and vector index.
+func (idx *Index) createSchema(ctx context.Context, tx *sql.Tx) error {
 	// Ensure pgvector extension is available
-	_, err := idx.db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vector")
+	_, err := tx.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vector")
 	if err != nil {
 		return fmt.Errorf("failed to create vector extension: %w", err)
 	}
+	if idx.config.IndexType == IndexTypeDiskANN {
+		if err := createVectorscaleExtension(ctx, tx); err != nil {
+			return err
+		}
+	}
 
-	// Create table
+	// Create table, partitioned if configured. The primary key of a
+	// partitioned table must include the partition column.
+	key, partitionBy := "PRIMARY KEY (id)", ""
+	if idx.config.Partitioning != nil {
+		key = fmt.Sprintf("%s TEXT NOT NULL, PRIMARY KEY (%[1]s, id)", partitionColumn)
+		partitionBy = fmt.Sprintf("PARTITION BY LIST (%s)", partitionColumn)
+	}
 	createSQL := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
-			id TEXT PRIMARY KEY,
+			id TEXT NOT NULL,
 			content TEXT,
-			embedding vector(%d),
+			embedding %s(%d),
 			source TEXT,
 			metadata JSONB DEFAULT '{}'::jsonb,
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		)
-	`, pq.QuoteIdentifier(idx.tableName), idx.config.Dimensions)
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			%s
+		) %s
+	`, idx.table.quoted(), idx.config.VectorType, idx.config.Dimensions, key, partitionBy)
 
-	_, err = idx.db.ExecContext(ctx, createSQL)
+	_, err = tx.ExecContext(ctx, createSQL)
 	if err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
 
-	// Create vector index based on configuration
-	if idx.config.IndexType != IndexTypeNone {
-		if err := idx.createVectorIndex(ctx); err != nil {
-			return fmt.Errorf("failed to create vector index: %w", err)
+	// Create vector index based on configuration. With quantization, the
+	// index is on the quantized column instead.
+	if idx.config.Quantization != nil {
+		if err := idx.createQuantized(ctx, tx); err != nil {
+			return err
+		}
+	} else if err := idx.createVectorIndex(ctx, tx, vectorIndexName(idx.table.name), "embedding", idx.distanceOpClass()); err != nil {
+		return fmt.Errorf("failed to create vector index: %w", err)
+	}
+	for _, metric := range idx.config.ExtraMetrics {
+		if metric == idx.config.DistanceMetric {
+			continue
+		}
+		name := fmt.Sprintf("%s_embedding_%s_idx", idx.table.name, metric)
+		if err := idx.createVectorIndex(ctx, tx, name, "embedding", metricOpClass(idx.config.VectorType, metric)); err != nil {
+			return fmt.Errorf("failed to create %s vector index: %w", metric, err)
+		}
+	}
+
+	if idx.config.FullText {
+		if err := idx.createFullText(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if idx.config.SoftDelete {
+		if err := idx.createSoftDelete(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if idx.config.Expiration {
+		if err := idx.createExpiration(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if idx.config.CompressContent {
+		if err := idx.createCompressed(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if err := idx.createPromoted(ctx, tx); err != nil {
+		return err
+	}
+
+	if idx.config.ChangeFeed {
+		if err := idx.createChangeFeed(ctx, tx); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// createVectorIndex creates the appropriate vector index.
-func (idx *Index) createVectorIndex(ctx context.Context) error {
-	indexName := fmt.Sprintf("%s_embedding_idx", idx.tableName)
-	opClass := idx.distanceOpClass()
-
+// createVectorIndex creates the configured type of vector index on column,
+// if any.
+func (idx *Index) createVectorIndex(ctx context.Context, tx *sql.Tx, indexName, column, opClass string) error {
 	var createSQL string
 	switch idx.config.IndexType {
 	case IndexTypeHNSW:
@@ -174,9 +468,9 @@ func (idx *Index) createVectorIndex(ctx context.Context) error {
 		}
 		createSQL = fmt.Sprintf(`
 			CREATE INDEX IF NOT EXISTS %s ON %s
-			USING hnsw (embedding %s)
+			USING hnsw (%s %s)
 			WITH (m = %d, ef_construction = %d)
-		`, pq.QuoteIdentifier(indexName), pq.QuoteIdentifier(idx.tableName), opClass, m, efConstruction)
+		`, pq.QuoteIdentifier(indexName), idx.table.quoted(), column, opClass, m, efConstruction)
 
 	case IndexTypeIVFFlat:
 		lists := 100 // Default
@@ -185,33 +479,87 @@ func (idx *Index) createVectorIndex(ctx context.Context) error {
 		}
 		createSQL = fmt.Sprintf(`
 			CREATE INDEX IF NOT EXISTS %s ON %s
-			USING ivfflat (embedding %s)
+			USING ivfflat (%s %s)
 			WITH (lists = %d)
-		`, pq.QuoteIdentifier(indexName), pq.QuoteIdentifier(idx.tableName), opClass, lists)
+		`, pq.QuoteIdentifier(indexName), idx.table.quoted(), column, opClass, lists)
+
+	case IndexTypeDiskANN:
+		createSQL = fmt.Sprintf(`
+			CREATE INDEX IF NOT EXISTS %s ON %s
+			USING diskann (%s %s)
+			%s
+		`, pq.QuoteIdentifier(indexName), idx.table.quoted(), column, opClass, idx.config.DiskANNConfig.withOptions())
 
 	default:
 		return nil
 	}
 
-	_, err := idx.db.ExecContext(ctx, createSQL)
+	_, err := tx.ExecContext(ctx, createSQL)
 	return err
 }
 
-// distanceOpClass returns the pgvector operator class for the configured distance metric.
+// distanceOpClass returns the pgvector operator class for the configured
+// vector type and distance metric.
 func (idx *Index) distanceOpClass() string {
-	switch idx.config.DistanceMetric {
+	return metricOpClass(idx.config.VectorType, idx.config.DistanceMetric)
+}
+
+// metricOpClass returns the pgvector operator class for a vector type and
+// distance metric.
+func metricOpClass(vectorType VectorType, metric DistanceMetric) string {
+	switch metric {
 	case DistanceEuclidean:
-		return "vector_l2_ops"
+		return string(vectorType) + "_l2_ops"
 	case DistanceInnerProduct:
-		return "vector_ip_ops"
+		return string(vectorType) + "_ip_ops"
 	default: // Cosine
-		return "vector_cosine_ops"
+		return string(vectorType) + "_cosine_ops"
+	}
+}
+
+// encodeText converts an embedding to the text format of the column type.
+func (idx *Index) encodeText(v []float32) string {
+	if idx.config.VectorType == VectorTypeSparseVec {
+		return pgv.NewSparseVector(v).String()
+	}
+	return vectorToString(v)
+}
+
+// encodeTextArg is encodeText as a bind argument.
+func (idx *Index) encodeTextArg(v []float32) any {
+	return idx.encodeText(v)
+}
+
+// scoreExpr returns the SQL expression converting a distance expression into
+// a score, normalized with NormalizeScores.
+func (idx *Index) scoreExpr(distance string) string {
+	return idx.metricScoreExpr(idx.config.DistanceMetric, distance)
+}
+
+// metricScoreExpr is scoreExpr for a distance expression of metric.
+func (idx *Index) metricScoreExpr(metric DistanceMetric, distance string) string {
+	if !idx.config.NormalizeScores {
+		return fmt.Sprintf("1 - (%s)", distance)
+	}
+	switch metric {
+	case DistanceEuclidean:
+		return fmt.Sprintf("1 / (1 + (%s))", distance)
+	case DistanceInnerProduct:
+		// <#> returns the negative inner product
+		return fmt.Sprintf("LEAST(GREATEST((1 - (%s)) / 2, 0), 1)", distance)
+	default: // Cosine
+		return fmt.Sprintf("1 - (%s) / 2", distance)
 	}
 }
 
 // distanceOperator returns the SQL operator for the configured distance metric.
 func (idx *Index) distanceOperator() string {
-	switch idx.config.DistanceMetric {
+	return metricOperator(idx.config.DistanceMetric)
+}
+
+// metricOperator returns the SQL operator for a distance metric.
+func metricOperator(metric DistanceMetric) string {
+	switch metric {
 	case DistanceEuclidean:
 		return "<->"
 	case DistanceInnerProduct:
@@ -223,107 +571,297 @@ func (idx *Index) distanceOperator() string {
 
 // Search implements vector.Index.
 func (idx *Index) Search(ctx context.Context, embedding []float32, k int, filters map[string]string) ([]vector.SearchResult, error) {
-	// Build query
-	op := idx.distanceOperator()
-	embeddingStr := vectorToString(embedding)
+	return idx.SearchFilter(ctx, embedding, k, vector.FilterFromMap(filters))
+}
 
-	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, operator is from fixed set
-	query := fmt.Sprintf(`
-		SELECT id, content, embedding, source, metadata,
-		       1 - (embedding %s $1::vector) as score
-		FROM %s
-	`, op, pq.QuoteIdentifier(idx.tableName))
+// SearchFilter implements vector.FilterIndex. The filter is translated into
+// parameterized JSONB predicates, so filtering happens in the database.
+func (idx *Index) SearchFilter(ctx context.Context, embedding []float32, k int, filter vector.Filter) ([]vector.SearchResult, error) {
+	return idx.SearchWithOptions(ctx, embedding, k, vector.SearchOptions{Filter: filter})
+}
+
+// SearchWithOptions implements vector.TunableIndex. EfSearch and Probes are
+// applied with SET LOCAL in a read-only transaction around the query, so
+// they don't leak to other queries on the pooled connection. A Metric
+// override without an index (see ExtraMetrics) scans the table exactly.
+func (idx *Index) SearchWithOptions(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := idx.searchQuery(idx.encodeText(embedding), k, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchWithSettings(ctx, query, pqArgs(args), settings)
+}
 
-	args := []any{embeddingStr}
-	argIdx := 2
+// searchWithSettings runs a search statement with settings, retrying
+// transient errors.
+func (idx *Index) searchWithSettings(ctx context.Context, query string, args []any, settings []string) ([]vector.SearchResult, error) {
+	var results []vector.SearchResult
+	err := idx.retry(ctx, func() error {
+		return idx.withSettings(ctx, settings, func(db querier) error {
+			var err error
+			results, err = idx.search(ctx, db, query, args)
+			return err
+		})
+	})
+	return results, idx.timeoutError(ctx, err)
+}
 
-	// Add metadata filters
-	if len(filters) > 0 {
-		conditions := make([]string, 0, len(filters))
-		for key, value := range filters {
-			conditions = append(conditions, fmt.Sprintf("metadata->>$%d = $%d", argIdx, argIdx+1))
-			args = append(args, key, value)
-			argIdx += 2
+// withSettings calls fn with the database, or, if there are settings, with
+// a read-only transaction applying them with SET LOCAL, so they don't leak
+// to other queries on the pooled connection.
+func (idx *Index) withSettings(ctx context.Context, settings []string, fn func(db querier) error) error {
+	if len(settings) == 0 {
+		return fn(idx.db)
+	}
+
+	tx, err := idx.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range settings {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply search options: %w", err)
 		}
-		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
+	return fn(tx)
+}
 
-	query += fmt.Sprintf(" ORDER BY embedding %s $1::vector LIMIT $%d", op, argIdx)
-	args = append(args, k)
+// search runs a search statement built by searchQuery.
+func (idx *Index) search(ctx context.Context, db querier, query string, args []any) ([]vector.SearchResult, error) {
+	var results []vector.SearchResult
+	err := idx.scan(ctx, db, query, args, func(r vector.SearchResult) bool {
+		results = append(results, r)
+		return true
+	})
+	return results, err
+}
 
-	rows, err := idx.db.QueryContext(ctx, query, args...)
+// scan runs a search statement and passes each result to fn until fn
+// returns false.
+func (idx *Index) scan(ctx context.Context, db querier, query string, args []any, fn func(vector.SearchResult) bool) error {
+	rows, err := idx.query(ctx, db, query, args)
 	if err != nil {
-		return nil, fmt.Errorf("search query failed: %w", err)
+		return fmt.Errorf("search query failed: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	var results []vector.SearchResult
 	for rows.Next() {
 		var (
 			id           string
-			content      sql.NullString
+			contentRaw   []byte
 			embeddingRaw string
 			source       sql.NullString
 			metadataRaw  []byte
 			score        float64
 		)
 
-		if err := rows.Scan(&id, &content, &embeddingRaw, &source, &metadataRaw, &score); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+		if err := rows.Scan(&id, &contentRaw, &embeddingRaw, &source, &metadataRaw, &score); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		content, err := idx.decodeContent(contentRaw)
+		if err != nil {
+			return fmt.Errorf("failed to decompress content of node %s: %w", id, err)
 		}
 
-		metadata := make(map[string]string)
-		if len(metadataRaw) > 0 {
-			var rawMap map[string]any
-			if err := json.Unmarshal(metadataRaw, &rawMap); err == nil {
-				for k, v := range rawMap {
-					if s, ok := v.(string); ok {
-						metadata[k] = s
-					}
-				}
+		if !fn(idx.namespaced(searchResult(id, content, parseVector(embeddingRaw), source, metadataRaw, score))) {
+			return nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// searchQuery builds the search statement. The embedding is bound as $1 in
+// whatever encoding the caller's driver uses; list operands of the filter
+// are bound as []string. Embeddings are always returned as dense vectors.
+func (idx *Index) searchQuery(embedding any, k int, opts vector.SearchOptions) (string, []any, error) {
+	return idx.rankedQuery(embedding, k, opts, nil)
+}
+
+// rankedQuery builds the search statement, or, if after is non-nil, the
+// statement for a page of results ranked after it, ties broken by ID.
+func (idx *Index) rankedQuery(embedding any, k int, opts vector.SearchOptions, after *vector.PageCursor) (string, []any, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	metric, err := idx.searchMetric(opts.Metric)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Build query
+	op := metricOperator(metric)
+
+	b := &filterBuilder{args: []any{embedding}, columns: idx.promoted}
+
+	// Add metadata filters
+	where, err := idx.where(b, opts.Filter, opts.IncludeDeleted)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	// With quantization, the filter applies to the candidate search, and
+	// the candidates are re-ranked by exact distance
+	source := idx.table.quoted()
+	if idx.config.Quantization != nil {
+		if after != nil {
+			return "", nil, fmt.Errorf("paginated search does not support quantization")
+		}
+		if metric != idx.config.DistanceMetric {
+			return "", nil, fmt.Errorf("%w: %s with quantization", vector.ErrMetricUnsupported, opts.Metric)
+		}
+		source, where = idx.quantizedSource(b, where, k), ""
+	}
+
+	distance := fmt.Sprintf("embedding %s $1::%s", op, idx.config.VectorType)
+	score := idx.metricScoreExpr(metric, distance)
+	order := distance
+	if after != nil {
+		if !after.IsZero() {
+			if where != "" {
+				where += " AND "
 			}
+			where += fmt.Sprintf("(%[1]s < %[2]s OR (%[1]s = %[2]s AND id > %[3]s))",
+				score, b.arg(after.Score), b.arg(after.ID))
 		}
+		order = distance + ", id"
+		if idx.config.NormalizeScores && metric == DistanceInnerProduct {
+			// Clamped scores tie for different distances
+			order = "score DESC, id"
+		}
+	}
 
-		emb := parseVector(embeddingRaw)
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, operator is from fixed set
+	query := fmt.Sprintf(`
+		SELECT id, %[2]s, embedding::vector, source, metadata,
+		       %[3]s as score
+		FROM %[1]s
+	`, source, idx.contentColumn(""), score)
+	if where != "" {
+		query += " WHERE " + where
+	}
 
-		results = append(results, vector.SearchResult{
-			Node: vector.Node{
-				ID:        id,
-				Content:   content.String,
-				Embedding: emb,
-				Source:    source.String,
-				Metadata:  metadata,
-			},
-			Score: score,
-		})
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %s", order, b.arg(k))
+	return query, b.args, nil
+}
+
+// where returns the WHERE predicate for a search: partition pruning, the
+// filter, and, with SoftDelete and Expiration, the exclusion of deleted and
+// expired rows. It returns ""
+// if nothing restricts the search.
+func (idx *Index) where(b *filterBuilder, f vector.Filter, includeDeleted bool) (string, error) {
+	var predicates []string
+	if pred := idx.partitionPredicate(b, f); pred != "" {
+		predicates = append(predicates, pred)
+	}
+	if f.Op != "" {
+		pred, err := b.build(f)
+		if err != nil {
+			return "", err
+		}
+		predicates = append(predicates, pred)
+	}
+	if idx.config.SoftDelete && !includeDeleted {
+		predicates = append(predicates, deletedColumn+" IS NULL")
 	}
+	if idx.config.Expiration {
+		predicates = append(predicates, notExpired)
+	}
+	return strings.Join(predicates, " AND "), nil
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+// pqArgs adapts []string list operands of search arguments to lib/pq.
+func pqArgs(args []any) []any {
+	for i, arg := range args {
+		if values, ok := arg.([]string); ok {
+			args[i] = pq.Array(values)
+		}
+	}
+	return args
+}
+
+// tuningSettings returns the SET LOCAL statements applying opts.
+func tuningSettings(opts vector.SearchOptions) ([]string, error) {
+	if opts.EfSearch < 0 || opts.Probes < 0 {
+		return nil, fmt.Errorf("search options must not be negative")
+	}
+
+	var settings []string
+	if opts.EfSearch > 0 {
+		settings = append(settings, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", opts.EfSearch))
+	}
+	if opts.Probes > 0 {
+		settings = append(settings, fmt.Sprintf("SET LOCAL ivfflat.probes = %d", opts.Probes))
 	}
+	return settings, nil
+}
+
+// searchResult converts a scanned row into a search result. Non-string
+// metadata values are dropped.
+func searchResult(id string, content string, embedding []float32, source sql.NullString, metadataRaw []byte, score float64) vector.SearchResult {
+	metadata := make(map[string]string)
+	if len(metadataRaw) > 0 {
+		var rawMap map[string]any
+		if err := json.Unmarshal(metadataRaw, &rawMap); err == nil {
+			for k, v := range rawMap {
+				if s, ok := v.(string); ok {
+					metadata[k] = s
+				}
+			}
+		}
+	}
+
+	return vector.SearchResult{
+		Node: vector.Node{
+			ID:        id,
+			Content:   content,
+			Embedding: embedding,
+			Source:    source.String,
+			Metadata:  metadata,
+		},
+		Score: score,
+	}
+}
 
-	return results, nil
+// namespaced sets the namespace of a result's node from its metadata.
+func (idx *Index) namespaced(r vector.SearchResult) vector.SearchResult {
+	if key := idx.config.NamespaceKey; key != "" {
+		r.Node.Namespace = r.Node.Metadata[key]
+	}
+	return r
 }
 
 // Insert implements vector.Index.
 func (idx *Index) Insert(ctx context.Context, node vector.Node) error {
-	metadataJSON, err := json.Marshal(node.Metadata)
+	nodes, err := idx.scopeNodes(ctx, []vector.Node{node})
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return err
+	}
+	args, err := idx.writeArgs(ctx, nodes, idx.encodeTextArg)
+	if err != nil {
+		return err
 	}
 
-	query := fmt.Sprintf(`
-		INSERT INTO %s (id, content, embedding, source, metadata)
-		VALUES ($1, $2, $3::vector, $4, $5::jsonb)
-	`, pq.QuoteIdentifier(idx.tableName))
-
-	_, err = idx.db.ExecContext(ctx, query,
-		node.ID,
-		node.Content,
-		vectorToString(node.Embedding),
-		node.Source,
-		string(metadataJSON),
-	)
+	err = idx.retry(ctx, func() error {
+		return idx.withWrite(ctx, func(db execer) error {
+			_, err := db.ExecContext(ctx, idx.insertQuery(), args...)
+			return err
+		})
+	})
 	if err != nil {
 		return fmt.Errorf("insert failed: %w", err)
 	}
@@ -333,29 +871,24 @@ func (idx *Index) Insert(ctx context.Context, node vector.Node) error {
 
 // Upsert implements vector.Index.
 func (idx *Index) Upsert(ctx context.Context, node vector.Node) error {
-	metadataJSON, err := json.Marshal(node.Metadata)
+	nodes, err := idx.scopeNodes(ctx, []vector.Node{node})
+	if err != nil {
+		return err
+	}
+	args, err := idx.writeArgs(ctx, nodes, idx.encodeTextArg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return err
 	}
 
-	query := fmt.Sprintf(`
-		INSERT INTO %s (id, content, embedding, source, metadata)
-		VALUES ($1, $2, $3::vector, $4, $5::jsonb)
-		ON CONFLICT (id) DO UPDATE SET
-			content = EXCLUDED.content,
-			embedding = EXCLUDED.embedding,
-			source = EXCLUDED.source,
-			metadata = EXCLUDED.metadata,
-			updated_at = NOW()
-	`, pq.QuoteIdentifier(idx.tableName))
-
-	_, err = idx.db.ExecContext(ctx, query,
-		node.ID,
-		node.Content,
-		vectorToString(node.Embedding),
-		node.Source,
-		string(metadataJSON),
-	)
+	err = idx.retry(ctx, func() error {
+		return idx.withWrite(ctx, func(db execer) error {
+			res, err := db.ExecContext(ctx, idx.upsertQuery(1), args...)
+			if err != nil {
+				return err
+			}
+			return upserted(res, 1)
+		})
+	})
 	if err != nil {
 		return fmt.Errorf("upsert failed: %w", err)
 	}
@@ -363,10 +896,107 @@ func (idx *Index) Upsert(ctx context.Context, node vector.Node) error {
 	return nil
 }
 
-// Delete implements vector.Index.
+// columns returns the columns written for each node.
+func (idx *Index) columns() []string {
+	columns := requiredColumns
+	if idx.config.Partitioning != nil {
+		columns = append(slices.Clip(columns), partitionColumn)
+	}
+	if idx.config.CompressContent {
+		columns = append(slices.Clip(columns), compressedColumn)
+	}
+	if idx.config.Expiration {
+		columns = append(slices.Clip(columns), expiresColumn)
+	}
+	return columns
+}
+
+// valuesRow returns the placeholders for one node's columns, starting after
+// parameter base.
+func (idx *Index) valuesRow(base int) string {
+	row := fmt.Sprintf("$%d, $%d, $%d::%s, $%d, $%d::jsonb",
+		base+1, base+2, base+3, idx.config.VectorType, base+4, base+5)
+	for i := len(requiredColumns); i < len(idx.columns()); i++ {
+		row += fmt.Sprintf(", $%d", base+i+1)
+	}
+	return "(" + row + ")"
+}
+
+// insertQuery returns the statement inserting a single node.
+func (idx *Index) insertQuery() string {
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES %s
+	`, idx.table.quoted(), strings.Join(idx.columns(), ", "), idx.valuesRow(0))
+}
+
+// upsertQuery returns the statement upserting n nodes, binding one parameter
+// per column for each node.
+func (idx *Index) upsertQuery(n int) string {
+	columns := idx.columns()
+	valueStrings := make([]string, n)
+	for i := range valueStrings {
+		valueStrings[i] = idx.valuesRow(i * len(columns))
+	}
+
+	conflict := "id"
+	if idx.config.Partitioning != nil {
+		conflict = partitionColumn + ", id"
+	}
+	// Upserting a soft-deleted node restores it
+	extra := ""
+	if idx.config.SoftDelete {
+		extra += deletedColumn + " = NULL,"
+	}
+	if idx.config.CompressContent {
+		extra += fmt.Sprintf("%[1]s = EXCLUDED.%[1]s,", compressedColumn)
+	}
+	if idx.config.Expiration {
+		extra += fmt.Sprintf("%[1]s = EXCLUDED.%[1]s,", expiresColumn)
+	}
+	// Never update another tenant's or namespace's node
+	target, guard := idx.table.quoted(), ""
+	var guards []string
+	for _, key := range []string{idx.config.TenantKey, idx.config.NamespaceKey} {
+		if key != "" {
+			guards = append(guards, fmt.Sprintf("cur.metadata->>%[1]s IS NOT DISTINCT FROM EXCLUDED.metadata->>%[1]s",
+				pq.QuoteLiteral(key)))
+		}
+	}
+	if len(guards) > 0 {
+		target += " AS cur"
+		guard = "WHERE " + strings.Join(guards, " AND ")
+	}
+
+	//nolint:gosec // Table name escaped via pq.QuoteIdentifier, values are parameterized
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES %s
+		ON CONFLICT (%s) DO UPDATE SET
+			content = EXCLUDED.content,
+			embedding = EXCLUDED.embedding,
+			source = EXCLUDED.source,
+			metadata = EXCLUDED.metadata,
+			%s
+			updated_at = NOW()
+		%s
+	`, target, strings.Join(columns, ", "), strings.Join(valueStrings, ","), conflict, extra, guard)
+}
+
+// Delete implements vector.Index. With SoftDelete, the node is marked as
+// deleted instead.
 func (idx *Index) Delete(ctx context.Context, id string) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", pq.QuoteIdentifier(idx.tableName))
-	_, err := idx.db.ExecContext(ctx, query, id)
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return err
+	}
+	where, args := idx.restrict("id = $1", []any{id}, s)
+	err = idx.retry(ctx, func() error {
+		return idx.withWrite(ctx, func(db execer) error {
+			_, err := db.ExecContext(ctx, idx.deleteQuery(where), args...)
+			return err
+		})
+	})
 	if err != nil {
 		return fmt.Errorf("delete failed: %w", err)
 	}
@@ -375,7 +1005,46 @@ func (idx *Index) Delete(ctx context.Context, id string) error {
 
 // Name implements vector.Index.
 func (idx *Index) Name() string {
-	return idx.tableName
+	return idx.table.String()
+}
+
+// Warmup implements retrieve.Warmer. It opens a connection to the database
+// and verifies that the table exists with the configured vector dimensions,
+// so configuration problems surface at startup rather than on the first query.
+func (idx *Index) Warmup(ctx context.Context) error {
+	if err := idx.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	dimensions, err := embeddingDimensions(ctx, idx.db, idx.table.quoted())
+	if err != nil {
+		return err
+	}
+	if dimensions > 0 && dimensions != idx.config.Dimensions {
+		return fmt.Errorf("%w: table %s has %d dimensions, configured for %d",
+			vector.ErrDimensionMismatch, idx.table, dimensions, idx.config.Dimensions)
+	}
+
+	return nil
+}
+
+// embeddingDimensions returns the declared dimensions of a table's embedding
+// column, or 0 if the column type has none, e.g. an unsized vector column. A
+// missing table or column fails with vector.ErrIndexNotReady.
+func embeddingDimensions(ctx context.Context, db *sql.DB, relation string) (int, error) {
+	var dimensions sql.NullInt64
+	err := db.QueryRowContext(ctx, `
+		SELECT atttypmod
+		FROM pg_attribute
+		WHERE attrelid = to_regclass($1) AND attname = 'embedding' AND NOT attisdropped
+	`, relation).Scan(&dimensions)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: table %s does not exist or has no embedding column", vector.ErrIndexNotReady, relation)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get embedding dimensions: %w", backendError(err))
+	}
+	return int(max(dimensions.Int64, 0)), nil
 }
 
 // vectorToString converts a float32 slice to pgvector string format.
@@ -407,4 +1076,9 @@ func parseVector(s string) []float32 {
 }
 
 // Verify interface compliance
-var _ vector.Index = (*Index)(nil)
+var (
+	_ vector.Index        = (*Index)(nil)
+	_ vector.FilterIndex  = (*Index)(nil)
+	_ vector.TunableIndex = (*Index)(nil)
+	_ retrieve.Warmer     = (*Index)(nil)
+)
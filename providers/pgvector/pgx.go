@@ -0,0 +1,362 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	pgv "github.com/pgvector/pgvector-go"
+	pgxvec "github.com/pgvector/pgvector-go/pgx"
+)
+
+// PgxIndex implements vector.Index over a pgx connection pool. Embeddings
+// are sent and received in pgvector's binary wire format instead of text,
+// and InsertBatch uses binary COPY, which avoids formatting and parsing
+// thousands of floats per row for high-dimensional embeddings.
+//
+// The pool must register the pgvector types on each connection:
+//
+//	poolCfg.AfterConnect = pgvector.RegisterTypes
+//
+// Schema handling and deletes run through database/sql on the same pool.
+type PgxIndex struct {
+	*Index
+	pool *pgxpool.Pool
+}
+
+// pgxExecer is implemented by both *pgxpool.Pool and pgx.Tx.
+type pgxExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// pgxWriter is implemented by both *pgxpool.Pool and pgx.Tx.
+type pgxWriter interface {
+	pgxExecer
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// pgxQuerier is implemented by both *pgxpool.Pool and pgx.Tx.
+type pgxQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// RegisterTypes registers the pgvector types on a pgx connection. Use it as
+// the pgxpool.Config AfterConnect hook.
+func RegisterTypes(ctx context.Context, conn *pgx.Conn) error {
+	return pgxvec.RegisterTypes(ctx, conn)
+}
+
+// NewPgx creates a new pgvector index backed by a pgx connection pool.
+func NewPgx(pool *pgxpool.Pool, cfg Config) (*PgxIndex, error) {
+	// pgx caches prepared statements itself
+	cfg.StatementCacheSize = 0
+	idx, err := New(stdlib.OpenDBFromPool(pool), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &PgxIndex{Index: idx, pool: pool}, nil
+}
+
+// Search implements vector.Index.
+func (idx *PgxIndex) Search(ctx context.Context, embedding []float32, k int, filters map[string]string) ([]vector.SearchResult, error) {
+	return idx.SearchFilter(ctx, embedding, k, vector.FilterFromMap(filters))
+}
+
+// SearchFilter implements vector.FilterIndex.
+func (idx *PgxIndex) SearchFilter(ctx context.Context, embedding []float32, k int, filter vector.Filter) ([]vector.SearchResult, error) {
+	return idx.SearchWithOptions(ctx, embedding, k, vector.SearchOptions{Filter: filter})
+}
+
+// SearchWithOptions implements vector.TunableIndex.
+func (idx *PgxIndex) SearchWithOptions(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions) ([]vector.SearchResult, error) {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return nil, err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := idx.searchQuery(idx.encodeBinary(embedding), k, opts)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchWithSettings(ctx, query, args, settings)
+}
+
+// searchWithSettings runs a search statement with settings, retrying
+// transient errors.
+func (idx *PgxIndex) searchWithSettings(ctx context.Context, query string, args []any, settings []string) ([]vector.SearchResult, error) {
+	var results []vector.SearchResult
+	err := idx.retry(ctx, func() error {
+		return idx.withSettings(ctx, settings, func(db pgxQuerier) error {
+			var err error
+			results, err = idx.search(ctx, db, query, args)
+			return err
+		})
+	})
+	return results, idx.timeoutError(ctx, err)
+}
+
+// withSettings is Index.withSettings for the pgx pool.
+func (idx *PgxIndex) withSettings(ctx context.Context, settings []string, fn func(db pgxQuerier) error) error {
+	if len(settings) == 0 {
+		return fn(idx.pool)
+	}
+
+	return pgx.BeginTxFunc(ctx, idx.pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		for _, stmt := range settings {
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to apply search options: %w", err)
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// search runs a search statement built by searchQuery.
+func (idx *PgxIndex) search(ctx context.Context, db pgxQuerier, query string, args []any) ([]vector.SearchResult, error) {
+	var results []vector.SearchResult
+	err := idx.scan(ctx, db, query, args, func(r vector.SearchResult) bool {
+		results = append(results, r)
+		return true
+	})
+	return results, err
+}
+
+// scan runs a search statement and passes each result to fn until fn
+// returns false.
+func (idx *PgxIndex) scan(ctx context.Context, db pgxQuerier, query string, args []any, fn func(vector.SearchResult) bool) error {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id          string
+			contentRaw  []byte
+			emb         pgv.Vector
+			source      sql.NullString
+			metadataRaw []byte
+			score       float64
+		)
+
+		if err := rows.Scan(&id, &contentRaw, &emb, &source, &metadataRaw, &score); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		content, err := idx.decodeContent(contentRaw)
+		if err != nil {
+			return fmt.Errorf("failed to decompress content of node %s: %w", id, err)
+		}
+
+		if !fn(idx.namespaced(searchResult(id, content, emb.Slice(), source, metadataRaw, score))) {
+			return nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// Insert implements vector.Index.
+func (idx *PgxIndex) Insert(ctx context.Context, node vector.Node) error {
+	nodes, err := idx.scopeNodes(ctx, []vector.Node{node})
+	if err != nil {
+		return err
+	}
+	args, err := idx.writeArgs(ctx, nodes, idx.encodeBinary)
+	if err != nil {
+		return err
+	}
+	err = idx.retry(ctx, func() error {
+		return idx.withWrite(ctx, func(db pgxWriter) error {
+			_, err := db.Exec(ctx, idx.insertQuery(), args...)
+			return err
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("insert failed: %w", err)
+	}
+	return nil
+}
+
+// Upsert implements vector.Index.
+func (idx *PgxIndex) Upsert(ctx context.Context, node vector.Node) error {
+	nodes, err := idx.scopeNodes(ctx, []vector.Node{node})
+	if err != nil {
+		return err
+	}
+	args, err := idx.writeArgs(ctx, nodes, idx.encodeBinary)
+	if err != nil {
+		return err
+	}
+	err = idx.retry(ctx, func() error {
+		return idx.withWrite(ctx, func(db pgxWriter) error {
+			tag, err := db.Exec(ctx, idx.upsertQuery(1), args...)
+			if err != nil {
+				return err
+			}
+			return checkUpserted(tag.RowsAffected(), 1)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("upsert failed: %w", err)
+	}
+	return nil
+}
+
+// InsertBatch implements vector.BatchIndex using binary COPY.
+func (idx *PgxIndex) InsertBatch(ctx context.Context, nodes []vector.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	nodes, err := idx.scopeNodes(ctx, nodes)
+	if err != nil {
+		return err
+	}
+
+	args, err := idx.writeArgs(ctx, nodes, idx.encodeBinary)
+	if err != nil {
+		return err
+	}
+	columns := idx.columns()
+	rows := make([][]any, len(nodes))
+	for i := range nodes {
+		rows[i] = args[i*len(columns) : (i+1)*len(columns)]
+	}
+
+	err = idx.retry(ctx, func() error {
+		return idx.withWrite(ctx, func(db pgxWriter) error {
+			_, err := db.CopyFrom(ctx,
+				pgx.Identifier(idx.table.identifier()),
+				columns,
+				pgx.CopyFromRows(rows),
+			)
+			return err
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy nodes: %w", err)
+	}
+	return nil
+}
+
+// UpsertBatch implements vector.BatchIndex. Batches larger than the
+// configured BatchSize are split into chunks.
+func (idx *PgxIndex) UpsertBatch(ctx context.Context, nodes []vector.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	nodes, err := idx.scopeNodes(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	if err := idx.checkNodes(nodes); err != nil {
+		return err
+	}
+	// Create partitions before a transaction holds locks on the table
+	if err := idx.ensurePartitions(ctx, nodes); err != nil {
+		return err
+	}
+
+	chunk := func(db pgxExecer, start, end int) error {
+		args, err := idx.writeArgs(ctx, nodes[start:end], idx.encodeBinary)
+		if err != nil {
+			return err
+		}
+		tag, err := db.Exec(ctx, idx.upsertQuery(end-start), args...)
+		if err != nil {
+			return fmt.Errorf("upsert batch failed: %w", err)
+		}
+		return checkUpserted(tag.RowsAffected(), end-start)
+	}
+	write := func(start, end int) error {
+		return idx.withWrite(ctx, func(db pgxWriter) error { return chunk(db, start, end) })
+	}
+
+	size := idx.config.BatchSize
+	if len(nodes) <= size {
+		return idx.retry(ctx, func() error { return write(0, len(nodes)) })
+	}
+
+	if idx.config.BatchConcurrency > 1 {
+		return idx.parallelChunks(ctx, len(nodes), write)
+	}
+	if idx.config.CommitPerChunk {
+		for start := 0; start < len(nodes); start += size {
+			end := min(start+size, len(nodes))
+			if err := idx.retry(ctx, func() error { return write(start, end) }); err != nil {
+				return fmt.Errorf("chunk [%d:%d] failed: %w", start, end, err)
+			}
+		}
+		return nil
+	}
+	return idx.retry(ctx, func() error {
+		return pgx.BeginFunc(ctx, idx.pool, func(tx pgx.Tx) error {
+			for _, stmt := range idx.tenantSettings(ctx) {
+				if _, err := tx.Exec(ctx, stmt); err != nil {
+					return fmt.Errorf("failed to apply settings: %w", err)
+				}
+			}
+			for start := 0; start < len(nodes); start += size {
+				end := min(start+size, len(nodes))
+				if err := chunk(tx, start, end); err != nil {
+					return fmt.Errorf("chunk [%d:%d] failed: %w", start, end, err)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// encodeBinary wraps an embedding in the pgvector-go type matching the
+// column type, so pgx sends it in binary format.
+func (idx *PgxIndex) encodeBinary(v []float32) any {
+	switch idx.config.VectorType {
+	case VectorTypeHalfVec:
+		return pgv.NewHalfVector(v)
+	case VectorTypeSparseVec:
+		return pgv.NewSparseVector(v)
+	default:
+		return pgv.NewVector(v)
+	}
+}
+
+// Warmup implements retrieve.Warmer. In addition to the checks of
+// Index.Warmup, it verifies that the pgvector types are registered on the
+// pool's connections.
+func (idx *PgxIndex) Warmup(ctx context.Context) error {
+	if err := idx.Index.Warmup(ctx); err != nil {
+		return err
+	}
+
+	conn, err := idx.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Release()
+
+	if _, ok := conn.Conn().TypeMap().TypeForName("vector"); !ok {
+		return errors.New("pgvector types are not registered; set pgvector.RegisterTypes as the pool's AfterConnect hook")
+	}
+	return nil
+}
+
+// Verify interface compliance
+var (
+	_ vector.FilterIndex  = (*PgxIndex)(nil)
+	_ vector.TunableIndex = (*PgxIndex)(nil)
+	_ vector.BatchIndex   = (*PgxIndex)(nil)
+	_ vector.BatchSizer   = (*PgxIndex)(nil)
+)
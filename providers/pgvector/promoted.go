@@ -0,0 +1,164 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/lib/pq"
+)
+
+// ColumnType is the SQL type of a promoted column.
+type ColumnType string
+
+// Column types have immutable casts from text, as required by generated
+// columns.
+const (
+	ColumnText    ColumnType = "text"
+	ColumnBigInt  ColumnType = "bigint"
+	ColumnNumeric ColumnType = "numeric"
+	ColumnDouble  ColumnType = "double precision"
+	ColumnBoolean ColumnType = "boolean"
+)
+
+// PromotedColumn maps a metadata key to a typed, b-tree indexed column, so
+// filters on the key don't go through JSONB. The column is generated from
+// the metadata, so writes need no changes and existing rows are backfilled
+// when it is added. Requires PostgreSQL 12+.
+type PromotedColumn struct {
+	// Key is the metadata key (e.g., "tenant_id").
+	Key string
+	// Column is the column name (default: Key).
+	Column string
+	// Type is the column type (default text). Writes of nodes whose value
+	// doesn't cast to it are rejected.
+	Type ColumnType
+}
+
+// reservedColumns are the columns promoted columns must not shadow.
+var reservedColumns = map[string]bool{
+	"id": true, "content": true, "embedding": true, "source": true, "metadata": true,
+	"created_at": true, "updated_at": true,
+	partitionColumn: true, tsvColumn: true, deletedColumn: true, compressedColumn: true,
+	quantizedColumn: true, expiresColumn: true,
+}
+
+// promotedColumns validates promoted columns, applies defaults, and returns
+// them by metadata key.
+func promotedColumns(columns []PromotedColumn) (map[string]PromotedColumn, error) {
+	byKey := make(map[string]PromotedColumn, len(columns))
+	names := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		if c.Key == "" {
+			return nil, fmt.Errorf("promoted column key is required")
+		}
+		if c.Column == "" {
+			c.Column = c.Key
+		}
+		switch c.Type {
+		case "":
+			c.Type = ColumnText
+		case ColumnText, ColumnBigInt, ColumnNumeric, ColumnDouble, ColumnBoolean:
+		default:
+			return nil, fmt.Errorf("unsupported promoted column type %q", c.Type)
+		}
+		if reservedColumns[c.Column] || names[c.Column] {
+			return nil, fmt.Errorf("promoted column %q conflicts with another column", c.Column)
+		}
+		if _, ok := byKey[c.Key]; ok {
+			return nil, fmt.Errorf("metadata key %q is promoted twice", c.Key)
+		}
+		names[c.Column] = true
+		byKey[c.Key] = c
+	}
+	return byKey, nil
+}
+
+// checkPromoted returns an error for the first node with a metadata value
+// that doesn't cast to the type of its promoted column, which would
+// otherwise fail the write with a bare cast error from PostgreSQL.
+func (idx *Index) checkPromoted(nodes []vector.Node) error {
+	for _, node := range nodes {
+		for key, value := range node.Metadata {
+			c, ok := idx.promoted[key]
+			if !ok || validCast(c.Type, value) {
+				continue
+			}
+			return fmt.Errorf("node %s: metadata %q value %q is not a valid %s for promoted column %s",
+				node.ID, key, value, c.Type, c.Column)
+		}
+	}
+	return nil
+}
+
+// validCast reports whether PostgreSQL casts value to typ.
+func validCast(typ ColumnType, value string) bool {
+	value = strings.TrimSpace(value)
+	switch typ {
+	case ColumnBigInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case ColumnNumeric, ColumnDouble:
+		// Go, unlike PostgreSQL, parses hexadecimal floats
+		if strings.ContainsAny(value, "xX") {
+			return false
+		}
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case ColumnBoolean:
+		return validBool(strings.ToLower(value))
+	default:
+		return true
+	}
+}
+
+// validBool reports whether value is a PostgreSQL boolean literal: a prefix
+// of true, false, yes or no, one of on, off or its prefix "of", 1 or 0.
+func validBool(value string) bool {
+	switch value {
+	case "":
+		return false
+	case "on", "of", "off", "1", "0":
+		return true
+	}
+	for _, word := range []string{"true", "false", "yes", "no"} {
+		if strings.HasPrefix(word, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// createPromoted adds the promoted columns and their indexes.
+func (idx *Index) createPromoted(ctx context.Context, tx *sql.Tx) error {
+	table := idx.table.quoted()
+	for _, c := range idx.promoted {
+		// Text columns compare bytewise, matching JSONB filter semantics
+		typ := string(c.Type)
+		if c.Type == ColumnText {
+			typ = `text COLLATE "C"`
+		}
+
+		//nolint:gosec // Identifiers escaped via pq.QuoteIdentifier, key via pq.QuoteLiteral, type from fixed set
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s GENERATED ALWAYS AS ((metadata->>%s)::%s) STORED",
+			table, pq.QuoteIdentifier(c.Column), typ, pq.QuoteLiteral(c.Key), c.Type,
+		))
+		if err != nil {
+			return fmt.Errorf("failed to add promoted column %s: %w", c.Column, err)
+		}
+
+		indexName := fmt.Sprintf("%s_%s_idx", idx.table.name, c.Column)
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
+			pq.QuoteIdentifier(indexName), table, pq.QuoteIdentifier(c.Column),
+		))
+		if err != nil {
+			return fmt.Errorf("failed to create index on promoted column %s: %w", c.Column, err)
+		}
+	}
+	return nil
+}
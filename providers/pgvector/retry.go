@@ -0,0 +1,111 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// DefaultRetryableCodes are the SQLSTATE classes and codes retried by
+// default: connection exceptions, serialization failures, deadlocks, and
+// server shutdown or startup.
+var DefaultRetryableCodes = []string{"08", "40001", "40P01", "57P01", "57P02", "57P03"}
+
+// RetryPolicy configures retries of operations failing with transient
+// errors. Each retried operation runs again as a whole, including its
+// transaction, so that a serialization failure is retried correctly. Writes
+// are idempotent except Insert: retried after a dropped connection, it may
+// fail with a duplicate key if the first attempt was committed.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed operation is retried
+	// (default 0).
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry (default 100ms).
+	RetryBackoff time.Duration
+	// MaxBackoff caps the delay between retries (default 5s).
+	MaxBackoff time.Duration
+	// RetryableCodes are the SQLSTATE classes (two characters, e.g. "08")
+	// and codes (five characters, e.g. "40001") that are retried (default
+	// DefaultRetryableCodes). Dropped connections are always retried.
+	RetryableCodes []string
+}
+
+// withDefaults returns p with defaults applied.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxRetries < 0 {
+		p.MaxRetries = 0
+	}
+	if p.RetryBackoff <= 0 {
+		p.RetryBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	if p.RetryableCodes == nil {
+		p.RetryableCodes = DefaultRetryableCodes
+	}
+	return p
+}
+
+// retryable reports whether err is transient under the policy.
+func (p RetryPolicy) retryable(err error) bool {
+	code := sqlState(err)
+	if code == "" {
+		return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || isNetError(err)
+	}
+	for _, c := range p.RetryableCodes {
+		if strings.HasPrefix(code, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs fn, retrying transient errors according to the configured
+// policy until ctx is done.
+func (idx *Index) retry(ctx context.Context, fn func() error) error {
+	policy := idx.config.Retry
+	backoff := policy.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxRetries || ctx.Err() != nil || !policy.retryable(err) {
+			return backendError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(backendError(err), ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, policy.MaxBackoff)
+	}
+}
+
+// sqlState returns the SQLSTATE code of a PostgreSQL error from either
+// driver, or "" if err is not one.
+func sqlState(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// isNetError reports whether err is a network error, such as a connection
+// reset by the server.
+func isNetError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
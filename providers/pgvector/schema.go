@@ -0,0 +1,149 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// ddlLockKey is the advisory lock key held while running schema DDL. A single
+// key serializes all OmniRetrieve DDL on a database, which also covers the
+// shared CREATE EXTENSION statement across tables.
+var ddlLockKey = func() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("omniretrieve/pgvector:ddl"))
+	return int64(h.Sum64())
+}()
+
+// requiredColumns are the columns the index reads and writes.
+var requiredColumns = []string{"id", "content", "embedding", "source", "metadata"}
+
+// withDDLLock runs fn in a transaction holding a transaction-scoped advisory
+// lock, so that concurrently starting instances don't race on DDL. The lock
+// is released when the transaction commits or rolls back.
+func withDDLLock(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", ddlLockKey); err != nil {
+		return fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// verifySchema checks that the pgvector extension is installed and that the
+// table exists with the expected columns, without running any DDL.
+func verifySchema(ctx context.Context, db *sql.DB, table tableRef, cfg Config) error {
+	var installed bool
+	err := db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT FROM pg_extension WHERE extname = 'vector')",
+	).Scan(&installed)
+	if err != nil {
+		return fmt.Errorf("failed to check vector extension: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("vector extension is not installed")
+	}
+
+	columns, err := tableColumns(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+	if err := checkColumns(columns, cfg.VectorType, cfg.Dimensions); err != nil {
+		return err
+	}
+	if _, ok := columns[tsvColumn]; cfg.FullText && !ok {
+		return fmt.Errorf("missing columns: %s", tsvColumn)
+	}
+	if _, ok := columns[partitionColumn]; cfg.Partitioning != nil && !ok {
+		return fmt.Errorf("missing columns: %s", partitionColumn)
+	}
+	if _, ok := columns[deletedColumn]; cfg.SoftDelete && !ok {
+		return fmt.Errorf("missing columns: %s", deletedColumn)
+	}
+	if _, ok := columns[expiresColumn]; cfg.Expiration && !ok {
+		return fmt.Errorf("missing columns: %s", expiresColumn)
+	}
+	if _, ok := columns[compressedColumn]; cfg.CompressContent && !ok {
+		return fmt.Errorf("missing columns: %s", compressedColumn)
+	}
+	if _, ok := columns[quantizedColumn]; cfg.Quantization != nil && !ok {
+		return fmt.Errorf("missing columns: %s", quantizedColumn)
+	}
+	for _, c := range cfg.PromotedColumns {
+		name := c.Column
+		if name == "" {
+			name = c.Key
+		}
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("missing columns: %s", name)
+		}
+	}
+	return nil
+}
+
+// checkColumns validates introspected column types against the schema the
+// index expects.
+func checkColumns(columns map[string]string, vectorType VectorType, dimensions int) error {
+	var missing []string
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	if want := fmt.Sprintf("%s(%d)", vectorType, dimensions); columns["embedding"] != want {
+		return fmt.Errorf("embedding column has type %s, want %s", columns["embedding"], want)
+	}
+	return nil
+}
+
+// tableColumns returns the column types of a table, or an empty map if it
+// doesn't exist.
+func tableColumns(ctx context.Context, db *sql.DB, table tableRef) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT attname, format_type(atttypid, atttypmod)
+		FROM pg_attribute
+		WHERE attrelid = to_regclass($1) AND attnum > 0 AND NOT attisdropped
+	`, table.quoted())
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect table: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		columns[name] = typ
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to inspect table: %w", err)
+	}
+	return columns, nil
+}
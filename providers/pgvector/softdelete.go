@@ -0,0 +1,70 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// deletedColumn records when a row was soft-deleted.
+const deletedColumn = "deleted_at"
+
+// createSoftDelete adds the deleted_at column.
+func (idx *Index) createSoftDelete(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s TIMESTAMP WITH TIME ZONE",
+		idx.table.quoted(), deletedColumn,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column: %w", deletedColumn, err)
+	}
+	return nil
+}
+
+// deleteQuery returns the statement deleting the rows matching where, or
+// marking them as deleted with SoftDelete.
+func (idx *Index) deleteQuery(where string) string {
+	if idx.config.SoftDelete {
+		return fmt.Sprintf("UPDATE %s SET %s = NOW() WHERE %s AND %[2]s IS NULL",
+			idx.table.quoted(), deletedColumn, where)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s", idx.table.quoted(), where)
+}
+
+// Purge permanently removes nodes soft-deleted before the given time and
+// returns how many were removed. Pass time.Now() to remove all of them.
+func (idx *Index) Purge(ctx context.Context, before time.Time) (int64, error) {
+	if !idx.config.SoftDelete {
+		return 0, fmt.Errorf("purge requires SoftDelete to be enabled")
+	}
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	where, args := idx.restrict(deletedColumn+" < $1", []any{before}, s)
+
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range idx.sessionSettings(ctx) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return 0, fmt.Errorf("failed to apply settings: %w", err)
+		}
+	}
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", idx.table.quoted(), where), args...)
+	if err != nil {
+		return 0, fmt.Errorf("purge failed: %w", idx.timeoutError(ctx, err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purged rows: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return n, nil
+}
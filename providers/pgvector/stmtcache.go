@@ -0,0 +1,87 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// stmtCache caches prepared statements by their SQL text. Queries are
+// parameterized, so the text identifies a query shape: the filter's
+// structure, the distance operator, and the enabled options.
+type stmtCache struct {
+	db   *sql.DB
+	size int
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// newStmtCache returns a cache of up to size statements prepared on db.
+func newStmtCache(db *sql.DB, size int) *stmtCache {
+	return &stmtCache{db: db, size: size, stmts: make(map[string]*sql.Stmt)}
+}
+
+// prepare returns the cached statement for query, preparing it if needed.
+// It returns nil once the cache is full, so callers fall back to unprepared
+// queries instead of evicting hot statements for one-off shapes.
+func (c *stmtCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	if len(c.stmts) >= c.size {
+		return nil, nil
+	}
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// close closes and removes all cached statements.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for query, stmt := range c.stmts {
+		errs = append(errs, stmt.Close())
+		delete(c.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+// query runs a query on db, a *sql.DB or *sql.Tx, through a cached prepared
+// statement when StatementCacheSize is set.
+func (idx *Index) query(ctx context.Context, db querier, query string, args []any) (*sql.Rows, error) {
+	if idx.stmts == nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+	stmt, err := idx.stmts.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+	if tx, ok := db.(*sql.Tx); ok {
+		stmt = tx.StmtContext(ctx, stmt)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// Close releases the statements cached with StatementCacheSize. It doesn't
+// close the database.
+func (idx *Index) Close() error {
+	if idx.stmts == nil {
+		return nil
+	}
+	return idx.stmts.close()
+}
@@ -0,0 +1,152 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// searchCursor is the name of the server-side cursor used by SearchIter.
+	searchCursor = "omniretrieve_search"
+	// searchFetchSize is the number of rows fetched from the cursor at a time.
+	searchFetchSize = 256
+)
+
+// SearchIter streams up to k search results in order of similarity. Rows
+// are fetched in batches from a server-side cursor in a read-only
+// transaction, so results are never materialized as a whole and breaking
+// out of the loop stops the scan in the database:
+//
+//	for res, err := range idx.SearchIter(ctx, embedding, 10000, vector.SearchOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+//
+// An error is yielded at most once, as the last element.
+func (idx *Index) SearchIter(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions) iter.Seq2[vector.SearchResult, error] {
+	return func(yield func(vector.SearchResult, error) bool) {
+		if err := idx.searchCursor(ctx, embedding, k, opts, yield); err != nil {
+			yield(vector.SearchResult{}, idx.timeoutError(ctx, err))
+		}
+	}
+}
+
+// searchCursor runs a search through a cursor, passing results to yield
+// until it returns false.
+func (idx *Index) searchCursor(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions, yield func(vector.SearchResult, error) bool) error {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return err
+	}
+	query, args, err := idx.searchQuery(idx.encodeText(embedding), k, opts)
+	if err != nil {
+		return err
+	}
+
+	tx, err := idx.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range settings {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply search options: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, declareCursor(query), pqArgs(args)...); err != nil {
+		return fmt.Errorf("search query failed: %w", err)
+	}
+
+	return fetchAll(func(fn func(vector.SearchResult) bool) error {
+		return idx.scan(ctx, tx, fetchCursor(), nil, fn)
+	}, yield)
+}
+
+// SearchIter is Index.SearchIter using the binary vector encoding.
+func (idx *PgxIndex) SearchIter(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions) iter.Seq2[vector.SearchResult, error] {
+	return func(yield func(vector.SearchResult, error) bool) {
+		if err := idx.searchCursor(ctx, embedding, k, opts, yield); err != nil {
+			yield(vector.SearchResult{}, idx.timeoutError(ctx, err))
+		}
+	}
+}
+
+// searchCursor runs a search through a cursor, passing results to yield
+// until it returns false.
+func (idx *PgxIndex) searchCursor(ctx context.Context, embedding []float32, k int, opts vector.SearchOptions, yield func(vector.SearchResult, error) bool) error {
+	var err error
+	if opts.Filter, err = idx.scopeFilter(ctx, opts.Filter); err != nil {
+		return err
+	}
+	settings, err := idx.searchSettings(ctx, opts)
+	if err != nil {
+		return err
+	}
+	query, args, err := idx.searchQuery(idx.encodeBinary(embedding), k, opts)
+	if err != nil {
+		return err
+	}
+
+	tx, err := idx.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, stmt := range settings {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply search options: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, declareCursor(query), args...); err != nil {
+		return fmt.Errorf("search query failed: %w", err)
+	}
+
+	return fetchAll(func(fn func(vector.SearchResult) bool) error {
+		return idx.scan(ctx, tx, fetchCursor(), nil, fn)
+	}, yield)
+}
+
+// declareCursor returns the statement declaring the search cursor for query.
+func declareCursor(query string) string {
+	return fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", searchCursor, query)
+}
+
+// fetchCursor returns the statement fetching the next batch from the cursor.
+func fetchCursor() string {
+	return fmt.Sprintf("FETCH %d FROM %s", searchFetchSize, searchCursor)
+}
+
+// fetchAll calls fetch until a batch comes back short or yield returns false.
+func fetchAll(fetch func(fn func(vector.SearchResult) bool) error, yield func(vector.SearchResult, error) bool) error {
+	for {
+		n := 0
+		stopped := false
+		err := fetch(func(r vector.SearchResult) bool {
+			n++
+			if !yield(r, nil) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if stopped || n < searchFetchSize {
+			return nil
+		}
+	}
+}
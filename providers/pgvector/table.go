@@ -0,0 +1,73 @@
+package pgvector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// tableRef is a table name, optionally qualified with a schema. Unqualified
+// names resolve through the connection's search_path; qualified names are
+// quoted as two identifiers, so a table can never be shadowed by a
+// same-named table in another schema on the search_path.
+type tableRef struct {
+	schema string
+	name   string
+}
+
+// parseTableRef returns the table named name in schema. If schema is empty,
+// name may itself be schema-qualified as "schema.table".
+func parseTableRef(schema, name string) (tableRef, error) {
+	if name == "" {
+		return tableRef{}, fmt.Errorf("table name is required")
+	}
+	if s, n, ok := strings.Cut(name, "."); ok {
+		if schema != "" {
+			return tableRef{}, fmt.Errorf("table name %q is schema-qualified and schema %q is also set", name, schema)
+		}
+		if s == "" || n == "" || strings.Contains(n, ".") {
+			return tableRef{}, fmt.Errorf("invalid table name %q", name)
+		}
+		return tableRef{schema: s, name: n}, nil
+	}
+	return tableRef{schema: schema, name: name}, nil
+}
+
+// String returns the unquoted, possibly qualified table name.
+func (t tableRef) String() string {
+	if t.schema == "" {
+		return t.name
+	}
+	return t.schema + "." + t.name
+}
+
+// quoted returns the quoted table name for use in SQL.
+func (t tableRef) quoted() string {
+	return t.quoteRelation(t.name)
+}
+
+// quoteRelation quotes the name of a relation, such as an index, in the
+// table's schema.
+func (t tableRef) quoteRelation(name string) string {
+	if t.schema == "" {
+		return pq.QuoteIdentifier(name)
+	}
+	return pq.QuoteIdentifier(t.schema) + "." + pq.QuoteIdentifier(name)
+}
+
+// identifier returns the table name parts, as used by pgx.Identifier.
+func (t tableRef) identifier() []string {
+	if t.schema == "" {
+		return []string{t.name}
+	}
+	return []string{t.schema, t.name}
+}
+
+// copyIn returns the COPY statement for lib/pq's CopyIn protocol.
+func (t tableRef) copyIn(columns ...string) string {
+	if t.schema == "" {
+		return pq.CopyIn(t.name, columns...)
+	}
+	return pq.CopyInSchema(t.schema, t.name, columns...)
+}
@@ -0,0 +1,219 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+)
+
+// tenantKey is the context key of WithTenant.
+type tenantKey struct{}
+
+// WithTenant returns a context that restricts the operations it is passed
+// to a tenant, for indexes configured with TenantKey.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// tenant returns the tenant of an operation, or "" if the index isn't
+// tenant-scoped. It returns ErrNoTenant if the index is tenant-scoped and
+// ctx carries no tenant.
+func (idx *Index) tenant(ctx context.Context) (string, error) {
+	if idx.config.TenantKey == "" {
+		return "", nil
+	}
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	if !ok || tenant == "" {
+		return "", ErrNoTenant
+	}
+	return tenant, nil
+}
+
+// scope is the tenant and namespace an operation is restricted to.
+type scope struct {
+	tenant    string
+	namespace string
+}
+
+// scope returns the scope of an operation. Like tenant, it returns
+// ErrNoTenant if the index is tenant-scoped and ctx carries no tenant, and
+// vector.ErrNamespacesUnsupported for namespaces without NamespaceKey.
+func (idx *Index) scope(ctx context.Context) (scope, error) {
+	tenant, err := idx.tenant(ctx)
+	if err != nil {
+		return scope{}, err
+	}
+	namespace := vector.ContextNamespace(ctx)
+	if namespace != "" && idx.config.NamespaceKey == "" {
+		return scope{}, vector.ErrNamespacesUnsupported
+	}
+	return scope{tenant: tenant, namespace: namespace}, nil
+}
+
+// scopeFilter restricts a filter to the operation's tenant and namespace.
+// Since these are ordinary metadata filters, they use a promoted column or
+// partition pruning when the keys are promoted or partitioned by.
+func (idx *Index) scopeFilter(ctx context.Context, f vector.Filter) (vector.Filter, error) {
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return f, err
+	}
+	var scopes []vector.Filter
+	if s.tenant != "" {
+		scopes = append(scopes, vector.Eq(idx.config.TenantKey, s.tenant))
+	}
+	if idx.config.NamespaceKey != "" {
+		scopes = append(scopes, vector.Eq(idx.config.NamespaceKey, s.namespace))
+	}
+	if len(scopes) == 0 {
+		return f, nil
+	}
+	if f.Op != "" {
+		scopes = append(scopes, f)
+	}
+	if len(scopes) == 1 {
+		return scopes[0], nil
+	}
+	return vector.And(scopes...), nil
+}
+
+// scopeNodes returns nodes with the operation's tenant and each node's
+// namespace set in their metadata. It returns an error for nodes belonging
+// to another tenant. The caller's nodes are not modified.
+func (idx *Index) scopeNodes(ctx context.Context, nodes []vector.Node) ([]vector.Node, error) {
+	s, err := idx.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenant := s.tenant
+	if idx.config.NamespaceKey == "" {
+		for _, node := range nodes {
+			if node.Namespace != "" {
+				return nil, vector.ErrNamespacesUnsupported
+			}
+		}
+		if tenant == "" {
+			return nodes, nil
+		}
+	}
+	scoped := make([]vector.Node, len(nodes))
+	for i, node := range nodes {
+		if tenant != "" {
+			if value, ok := node.Metadata[idx.config.TenantKey]; ok && value != tenant {
+				return nil, fmt.Errorf("node %s belongs to tenant %q, not %q", node.ID, value, tenant)
+			}
+		}
+		node.Metadata = maps.Clone(node.Metadata)
+		if node.Metadata == nil {
+			node.Metadata = make(map[string]string, 2)
+		}
+		if tenant != "" {
+			node.Metadata[idx.config.TenantKey] = tenant
+		}
+		if key := idx.config.NamespaceKey; key != "" {
+			node.Namespace = vector.NodeNamespace(ctx, node)
+			node.Metadata[key] = node.Namespace
+		}
+		scoped[i] = node
+	}
+	return scoped, nil
+}
+
+// restrict appends the predicates restricting a statement to s to where,
+// binding their values after args.
+func (idx *Index) restrict(where string, args []any, s scope) (string, []any) {
+	if s.tenant != "" {
+		where += " AND " + keyPredicate(idx.config.TenantKey, len(args)+1)
+		args = append(args, s.tenant)
+	}
+	if idx.config.NamespaceKey != "" {
+		where += " AND " + keyPredicate(idx.config.NamespaceKey, len(args)+1)
+		args = append(args, s.namespace)
+	}
+	return where, args
+}
+
+// keyPredicate returns a predicate matching the metadata value of key to
+// parameter n.
+func keyPredicate(key string, n int) string {
+	return fmt.Sprintf("metadata->>%s = $%d", pq.QuoteLiteral(key), n)
+}
+
+// tenantSettings returns the statement setting TenantSetting to the
+// operation's tenant for the current transaction, if configured.
+func (idx *Index) tenantSettings(ctx context.Context) []string {
+	tenant, err := idx.tenant(ctx)
+	if idx.config.TenantSetting == "" || err != nil || tenant == "" {
+		return nil
+	}
+	return []string{fmt.Sprintf("SELECT set_config(%s, %s, true)",
+		pq.QuoteLiteral(idx.config.TenantSetting), pq.QuoteLiteral(tenant))}
+}
+
+// sessionSettings returns the statements applying the tenant setting and
+// statement timeout of an operation.
+func (idx *Index) sessionSettings(ctx context.Context) []string {
+	return append(idx.tenantSettings(ctx), idx.timeoutSettings(ctx)...)
+}
+
+// withWrite calls fn with the database, or, with TenantSetting, with a
+// transaction applying it, so row-level security policies see the tenant.
+func (idx *Index) withWrite(ctx context.Context, fn func(db execer) error) (err error) {
+	settings := idx.tenantSettings(ctx)
+	if len(settings) == 0 {
+		return fn(idx.db)
+	}
+
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = applySettings(ctx, tx, settings); err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// applySettings runs settings in a transaction.
+func applySettings(ctx context.Context, tx *sql.Tx, settings []string) error {
+	for _, stmt := range settings {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply settings: %w", err)
+		}
+	}
+	return nil
+}
+
+// withWrite is Index.withWrite for the pgx pool.
+func (idx *PgxIndex) withWrite(ctx context.Context, fn func(db pgxWriter) error) error {
+	settings := idx.tenantSettings(ctx)
+	if len(settings) == 0 {
+		return fn(idx.pool)
+	}
+
+	return pgx.BeginFunc(ctx, idx.pool, func(tx pgx.Tx) error {
+		for _, stmt := range settings {
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to apply settings: %w", err)
+			}
+		}
+		return fn(tx)
+	})
+}
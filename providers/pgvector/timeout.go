@@ -0,0 +1,64 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// queryCanceled is the SQLSTATE of statements canceled by statement_timeout
+// (or by a cancel request, as sent when a context is done).
+const queryCanceled = "57014"
+
+// statementTimeoutKey is the context key of WithStatementTimeout.
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout returns a context overriding Config.StatementTimeout
+// for the operations it is passed to. A zero timeout disables the timeout.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// statementTimeout returns the statement timeout for an operation.
+func (idx *Index) statementTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(statementTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return idx.config.StatementTimeout
+}
+
+// timeoutSettings returns the SET LOCAL statement applying the statement
+// timeout, if any.
+func (idx *Index) timeoutSettings(ctx context.Context) []string {
+	timeout := idx.statementTimeout(ctx)
+	if timeout <= 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("SET LOCAL statement_timeout = %d", max(timeout.Milliseconds(), 1))}
+}
+
+// searchSettings returns the SET LOCAL statements applying opts, the tenant
+// setting, and the statement timeout.
+func (idx *Index) searchSettings(ctx context.Context, opts vector.SearchOptions) ([]string, error) {
+	settings, err := tuningSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	if idx.config.IndexType == IndexTypeDiskANN {
+		settings = append(settings, idx.config.DiskANNConfig.settings()...)
+	}
+	return append(settings, idx.sessionSettings(ctx)...), nil
+}
+
+// timeoutError converts a statement canceled by the statement timeout into
+// a *TimeoutError. Cancellations caused by ctx are returned unchanged, and
+// other errors are classified by backendError.
+func (idx *Index) timeoutError(ctx context.Context, err error) error {
+	timeout := idx.statementTimeout(ctx)
+	if err == nil || timeout <= 0 || ctx.Err() != nil || sqlState(err) != queryCanceled {
+		return backendError(err)
+	}
+	return &TimeoutError{Timeout: timeout, Err: err}
+}
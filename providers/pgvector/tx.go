@@ -0,0 +1,175 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// TxIndex writes to an Index within a caller-managed transaction, so index
+// writes commit or roll back together with the caller's own statements.
+// The caller owns the transaction: TxIndex never commits, rolls back, or
+// retries it. Partitions for new partition values are created in the
+// transaction too, which then holds the DDL advisory lock until it ends,
+// delaying partition creation by other writers.
+type TxIndex struct {
+	idx *Index
+	tx  *sql.Tx
+	// partitions are the partitions created in the transaction
+	partitions map[string]bool
+}
+
+// WithTx returns a TxIndex writing to the index through tx.
+func (idx *Index) WithTx(tx *sql.Tx) *TxIndex {
+	return &TxIndex{idx: idx, tx: tx, partitions: make(map[string]bool)}
+}
+
+// Insert adds a node in the transaction.
+func (t *TxIndex) Insert(ctx context.Context, node vector.Node) error {
+	args, err := t.writeArgs(ctx, []vector.Node{node})
+	if err != nil {
+		return err
+	}
+	if _, err := t.tx.ExecContext(ctx, t.idx.insertQuery(), args...); err != nil {
+		return fmt.Errorf("insert failed: %w", err)
+	}
+	return nil
+}
+
+// Upsert adds or updates a node in the transaction.
+func (t *TxIndex) Upsert(ctx context.Context, node vector.Node) error {
+	args, err := t.writeArgs(ctx, []vector.Node{node})
+	if err != nil {
+		return err
+	}
+	res, err := t.tx.ExecContext(ctx, t.idx.upsertQuery(1), args...)
+	if err != nil {
+		return fmt.Errorf("upsert failed: %w", err)
+	}
+	return upserted(res, 1)
+}
+
+// InsertBatch adds nodes in the transaction with COPY.
+func (t *TxIndex) InsertBatch(ctx context.Context, nodes []vector.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	args, err := t.writeArgs(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	return t.idx.copyIn(ctx, t.tx, nodes, args)
+}
+
+// UpsertBatch adds or updates nodes in the transaction. Batches larger than
+// the configured BatchSize are split into chunks.
+func (t *TxIndex) UpsertBatch(ctx context.Context, nodes []vector.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	nodes, err := t.idx.scopeNodes(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	if err := t.idx.checkNodes(nodes); err != nil {
+		return err
+	}
+	if err := t.ensurePartitions(ctx, nodes); err != nil {
+		return err
+	}
+	if err := t.applySettings(ctx); err != nil {
+		return err
+	}
+	size := t.idx.config.BatchSize
+	for start := 0; start < len(nodes); start += size {
+		end := min(start+size, len(nodes))
+		if err := t.idx.upsertChunk(ctx, t.tx, nodes[start:end]); err != nil {
+			return fmt.Errorf("chunk [%d:%d] failed: %w", start, end, err)
+		}
+	}
+	return nil
+}
+
+// Delete removes a node in the transaction. With SoftDelete, the node is
+// marked as deleted instead.
+func (t *TxIndex) Delete(ctx context.Context, id string) error {
+	return t.DeleteBatch(ctx, []string{id})
+}
+
+// DeleteBatch removes nodes in the transaction. Batches larger than the
+// configured BatchSize are split into chunks.
+func (t *TxIndex) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	s, err := t.idx.scope(ctx)
+	if err != nil {
+		return err
+	}
+	if err := t.applySettings(ctx); err != nil {
+		return err
+	}
+	size := t.idx.config.BatchSize
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+		if err := t.idx.deleteChunk(ctx, t.tx, ids[start:end], s); err != nil {
+			return fmt.Errorf("chunk [%d:%d] failed: %w", start, end, err)
+		}
+	}
+	return nil
+}
+
+// writeArgs scopes nodes to the tenant, validates them, creates their
+// partitions, applies the tenant setting, and returns the nodes' bind
+// arguments.
+func (t *TxIndex) writeArgs(ctx context.Context, nodes []vector.Node) ([]any, error) {
+	nodes, err := t.idx.scopeNodes(ctx, nodes)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.idx.checkNodes(nodes); err != nil {
+		return nil, err
+	}
+	if err := t.ensurePartitions(ctx, nodes); err != nil {
+		return nil, err
+	}
+	if err := t.applySettings(ctx); err != nil {
+		return nil, err
+	}
+	return t.idx.nodeArgs(nodes, t.idx.encodeTextArg)
+}
+
+// ensurePartitions creates the partitions nodes will be written to in the
+// transaction, rather than on another connection, which would wait for
+// locks the transaction holds on the table. They are rolled back with the
+// transaction, so the index doesn't record them as created.
+func (t *TxIndex) ensurePartitions(ctx context.Context, nodes []vector.Node) error {
+	if t.idx.config.Partitioning == nil {
+		return nil
+	}
+
+	t.idx.partitionsMu.Lock()
+	missing := t.idx.missingPartitions(nodes, t.partitions)
+	t.idx.partitionsMu.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if _, err := t.tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", ddlLockKey); err != nil {
+		return fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if err := t.idx.createPartitions(ctx, t.tx, missing); err != nil {
+		return err
+	}
+	for _, value := range missing {
+		t.partitions[value] = true
+	}
+	return nil
+}
+
+// applySettings applies the tenant setting for the rest of the transaction.
+func (t *TxIndex) applySettings(ctx context.Context) error {
+	return applySettings(ctx, t.tx, t.idx.tenantSettings(ctx))
+}
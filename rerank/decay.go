@@ -0,0 +1,121 @@
+package rerank
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// DecayFunction defines how a score decays with document age.
+type DecayFunction string
+
+const (
+	// DecayExponential halves the score every HalfLife.
+	DecayExponential DecayFunction = "exponential"
+	// DecayLinear reduces the score linearly until MaxAge.
+	DecayLinear DecayFunction = "linear"
+)
+
+// TimeDecayConfig configures the time decay reranker.
+type TimeDecayConfig struct {
+	// Key is the metadata key holding the item timestamp (default "timestamp").
+	Key string
+	// Layout is the time layout of the timestamp (default time.RFC3339).
+	Layout string
+	// Function is the decay function (default DecayExponential).
+	Function DecayFunction
+	// HalfLife is the age at which DecayExponential halves the score
+	// (default 30 days).
+	HalfLife time.Duration
+	// MaxAge is the age at which DecayLinear reaches Floor (default 365 days).
+	MaxAge time.Duration
+	// Floor is the minimum score multiplier, which keeps old documents
+	// competitive when they are much more relevant (default 0). Items
+	// without a valid timestamp get it too.
+	Floor float64
+	// Now returns the current time (default time.Now).
+	Now func() time.Time
+	// TopK limits output to top K results after reranking.
+	TopK int
+}
+
+// TimeDecay implements reranking that multiplies scores by a decay of the
+// item's age, so newer documents win ties in news or changelog corpora.
+type TimeDecay struct {
+	config TimeDecayConfig
+}
+
+// NewTimeDecay creates a new time decay reranker.
+func NewTimeDecay(cfg TimeDecayConfig) *TimeDecay {
+	if cfg.Key == "" {
+		cfg.Key = "timestamp"
+	}
+	if cfg.Layout == "" {
+		cfg.Layout = time.RFC3339
+	}
+	if cfg.Function == "" {
+		cfg.Function = DecayExponential
+	}
+	if cfg.HalfLife == 0 {
+		cfg.HalfLife = 30 * 24 * time.Hour
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = 365 * 24 * time.Hour
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &TimeDecay{config: cfg}
+}
+
+// Rerank implements retrieve.Reranker.
+func (r *TimeDecay) Rerank(_ context.Context, _ retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	now := r.config.Now()
+	result := make([]retrieve.ContextItem, len(items))
+	for i, item := range items {
+		item.Score *= r.factor(item, now)
+		item.Provenance.RerankerScore = item.Score
+		result[i] = item
+	}
+
+	// Sort by score descending
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	// Apply top-k
+	if r.config.TopK > 0 && len(result) > r.config.TopK {
+		result = result[:r.config.TopK]
+	}
+
+	return result, nil
+}
+
+// factor returns the score multiplier for the item's age, between Floor
+// and 1.
+func (r *TimeDecay) factor(item retrieve.ContextItem, now time.Time) float64 {
+	ts, err := time.Parse(r.config.Layout, item.Metadata[r.config.Key])
+	if err != nil {
+		return r.config.Floor
+	}
+	age := max(now.Sub(ts), 0)
+
+	var decay float64
+	switch r.config.Function {
+	case DecayLinear:
+		decay = math.Max(0, 1-float64(age)/float64(r.config.MaxAge))
+	default:
+		decay = math.Pow(0.5, float64(age)/float64(r.config.HalfLife))
+	}
+	return r.config.Floor + (1-r.config.Floor)*decay
+}
+
+// Verify interface compliance
+var _ retrieve.Reranker = (*TimeDecay)(nil)
@@ -0,0 +1,58 @@
+package rerank_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/rerank"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+func TestTimeDecayReranker(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	items := []retrieve.ContextItem{
+		{ID: "old", Score: 0.8, Metadata: map[string]string{"published": "2025-05-02"}},
+		{ID: "new", Score: 0.8, Metadata: map[string]string{"published": "2025-05-31"}},
+		{ID: "undated", Score: 0.9},
+	}
+
+	tests := []struct {
+		function rerank.DecayFunction
+		// decay is the decay of the new (1 day) and old (30 days) items
+		decay [2]float64
+	}{
+		{rerank.DecayExponential, [2]float64{math.Pow(0.5, 1.0/30), 0.5}},
+		{rerank.DecayLinear, [2]float64{1 - 1.0/60, 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.function), func(t *testing.T) {
+			reranker := rerank.NewTimeDecay(rerank.TimeDecayConfig{
+				Key:      "published",
+				Layout:   time.DateOnly,
+				Function: tt.function,
+				MaxAge:   60 * 24 * time.Hour,
+				Floor:    0.1,
+				Now:      func() time.Time { return now },
+			})
+			result, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, items)
+			if err != nil {
+				t.Fatalf("failed to rerank: %v", err)
+			}
+
+			// Undated items get the floor
+			order := []string{"new", "old", "undated"}
+			scores := []float64{0.8 * (0.1 + 0.9*tt.decay[0]), 0.8 * (0.1 + 0.9*tt.decay[1]), 0.9 * 0.1}
+			if len(result) != len(order) {
+				t.Fatalf("expected %d items, got %d", len(order), len(result))
+			}
+			for i, item := range result {
+				if item.ID != order[i] || math.Abs(item.Score-scores[i]) > 1e-9 || item.Provenance.RerankerScore != item.Score {
+					t.Errorf("item %d: expected %s (%v), got %s (%v)", i, order[i], scores[i], item.ID, item.Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// LLMClient completes prompts with a large language model.
+type LLMClient interface {
+	// Complete returns the model's response to prompt.
+	Complete(ctx context.Context, prompt string) (string, error)
+	// Model returns the model name.
+	Model() string
+}
+
+// PromptFormatter formats a listwise ranking prompt for a query and its
+// candidate passages, which the response must reference by their 1-based
+// identifiers.
+type PromptFormatter func(query string, passages []string) string
+
+// ListwisePrompt is the default PromptFormatter. It asks for a ranking of
+// passage identifiers in the form "[2] > [1] > [3]".
+func ListwisePrompt(query string, passages []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "I will provide you with %d passages, each indicated by a numerical identifier [].\n", len(passages))
+	fmt.Fprintf(&b, "Rank the passages based on their relevance to the search query: %s\n\n", query)
+	for i, p := range passages {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, p)
+	}
+	fmt.Fprintf(&b, "\nSearch Query: %s\n", query)
+	fmt.Fprintf(&b, "Rank the %d passages above based on their relevance to the search query. ", len(passages))
+	b.WriteString("List the identifiers in descending order of relevance, e.g. [2] > [1]. ")
+	b.WriteString("Only respond with the ranking.")
+	return b.String()
+}
+
+// LLMConfig configures the LLM reranker.
+type LLMConfig struct {
+	// Client is the language model to use (required).
+	Client LLMClient
+	// Prompt formats the ranking prompt (default ListwisePrompt).
+	Prompt PromptFormatter
+	// WindowSize is the number of passages ranked per request (default 20).
+	WindowSize int
+	// Step is how far the window slides toward the top of the list between
+	// requests (default WindowSize/2).
+	Step int
+	// MaxPassageChars truncates passages in the prompt (default 500).
+	MaxPassageChars int
+	// TopK limits output to top K results after reranking.
+	TopK int
+}
+
+// LLM implements listwise reranking with a language model. Candidate sets
+// larger than a window are ranked with a window sliding from the bottom of
+// the list to the top, so relevant items can move up across windows.
+type LLM struct {
+	config LLMConfig
+}
+
+// NewLLM creates a new LLM reranker.
+func NewLLM(cfg LLMConfig) *LLM {
+	if cfg.Prompt == nil {
+		cfg.Prompt = ListwisePrompt
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = 20
+	}
+	if cfg.Step <= 0 || cfg.Step > cfg.WindowSize {
+		cfg.Step = max(cfg.WindowSize/2, 1)
+	}
+	if cfg.MaxPassageChars == 0 {
+		cfg.MaxPassageChars = 500
+	}
+	return &LLM{config: cfg}
+}
+
+// Rerank implements retrieve.Reranker. Items get scores by their final rank,
+// from 1 for the first item down toward 0.
+func (r *LLM) Rerank(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if r.config.Client == nil {
+		return nil, fmt.Errorf("%w: llm reranker client is required", retrieve.ErrInvalidConfig)
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	ranked := make([]retrieve.ContextItem, len(items))
+	copy(ranked, items)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	// Slide the window from the bottom of the list to the top
+	for end := len(ranked); ; end -= r.config.Step {
+		start := max(end-r.config.WindowSize, 0)
+		if err := r.rankWindow(ctx, q.Text, ranked[start:end]); err != nil {
+			return nil, err
+		}
+		if start == 0 {
+			break
+		}
+	}
+
+	for i := range ranked {
+		score := 1 - float64(i)/float64(len(ranked))
+		ranked[i].Score = score
+		ranked[i].Provenance.RerankerScore = score
+	}
+
+	// Apply top-k
+	if r.config.TopK > 0 && len(ranked) > r.config.TopK {
+		ranked = ranked[:r.config.TopK]
+	}
+
+	return ranked, nil
+}
+
+// rankWindow reorders window in place by the model's ranking.
+func (r *LLM) rankWindow(ctx context.Context, query string, window []retrieve.ContextItem) error {
+	if len(window) <= 1 {
+		return nil
+	}
+	passages := make([]string, len(window))
+	for i, item := range window {
+		passages[i] = truncate(item.Content, r.config.MaxPassageChars)
+	}
+
+	response, err := r.config.Client.Complete(ctx, r.config.Prompt(query, passages))
+	if err != nil {
+		return fmt.Errorf("failed to rank passages: %w", err)
+	}
+
+	order := parseRanking(response, len(window))
+	reordered := make([]retrieve.ContextItem, len(window))
+	for i, idx := range order {
+		reordered[i] = window[idx]
+	}
+	copy(window, reordered)
+	return nil
+}
+
+// rankingID matches a passage identifier such as "[3]".
+var rankingID = regexp.MustCompile(`\[(\d+)\]`)
+
+// parseRanking returns the 0-based passage order in response. Unknown and
+// repeated identifiers are ignored, and passages the response leaves out
+// keep their relative order after the ranked ones.
+func parseRanking(response string, n int) []int {
+	order := make([]int, 0, n)
+	seen := make([]bool, n)
+	for _, m := range rankingID.FindAllStringSubmatch(response, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || id < 1 || id > n || seen[id-1] {
+			continue
+		}
+		seen[id-1] = true
+		order = append(order, id-1)
+	}
+	for i := range n {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// truncate shortens text to at most maxChars characters.
+func truncate(text string, maxChars int) string {
+	if maxChars <= 0 {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	return string(runes[:maxChars])
+}
+
+// Verify interface compliance
+var _ retrieve.Reranker = (*LLM)(nil)
@@ -0,0 +1,131 @@
+package rerank_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/rerank"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// mockLLMClient ranks the passages of a listwise prompt by content in
+// descending order, or returns a fixed response if set.
+type mockLLMClient struct {
+	response string
+	err      error
+	prompts  []string
+}
+
+var passageLine = regexp.MustCompile(`(?m)^\[(\d+)\] (.*)$`)
+
+func (m *mockLLMClient) Complete(_ context.Context, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	if m.err != nil || m.response != "" {
+		return m.response, m.err
+	}
+
+	matches := passageLine.FindAllStringSubmatch(prompt, -1)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i][2] > matches[j][2]
+	})
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = "[" + m[1] + "]"
+	}
+	return strings.Join(ids, " > "), nil
+}
+
+func (m *mockLLMClient) Model() string {
+	return "mock-llm"
+}
+
+func llmTestItems(n int) []retrieve.ContextItem {
+	items := make([]retrieve.ContextItem, n)
+	for i := range items {
+		// Retrieval scores rank the passages in the reverse of their relevance
+		items[i] = retrieve.ContextItem{ID: fmt.Sprint(i), Content: fmt.Sprintf("passage %d of the corpus", i), Score: 1 - float64(i)/10}
+	}
+	return items
+}
+
+func itemIDs(items []retrieve.ContextItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestLLMReranker(t *testing.T) {
+	client := &mockLLMClient{}
+	reranker := rerank.NewLLM(rerank.LLMConfig{Client: client, TopK: 3})
+
+	result, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, llmTestItems(4))
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+
+	if want := []string{"3", "2", "1"}; !slices.Equal(itemIDs(result), want) {
+		t.Errorf("expected %v, got %v", want, itemIDs(result))
+	}
+	if result[0].Score != 1 || result[0].Provenance.RerankerScore != 1 || result[1].Score >= result[0].Score {
+		t.Errorf("expected rank scores, got %v and %v", result[0].Score, result[1].Score)
+	}
+	if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], "search query: q") {
+		t.Errorf("expected a single prompt with the query, got %q", client.prompts)
+	}
+}
+
+func TestLLMRerankerWindows(t *testing.T) {
+	client := &mockLLMClient{}
+	reranker := rerank.NewLLM(rerank.LLMConfig{Client: client, WindowSize: 3, Step: 2, MaxPassageChars: 9})
+
+	result, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, llmTestItems(5))
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+
+	// Windows [2, 5) and [0, 3) carry the most relevant passage to the top
+	if len(client.prompts) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(client.prompts))
+	}
+	if result[0].ID != "4" {
+		t.Errorf("expected passage 4 first, got %v", itemIDs(result))
+	}
+	if strings.Contains(client.prompts[0], "corpus") {
+		t.Errorf("expected truncated passages, got %q", client.prompts[0])
+	}
+}
+
+func TestLLMRerankerParsing(t *testing.T) {
+	// Unknown and repeated identifiers are ignored and missing ones appended
+	client := &mockLLMClient{response: "[3] > [9] > [3] > [1]"}
+	reranker := rerank.NewLLM(rerank.LLMConfig{Client: client})
+
+	result, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, llmTestItems(4))
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+	if want := []string{"2", "0", "1", "3"}; !slices.Equal(itemIDs(result), want) {
+		t.Errorf("expected %v, got %v", want, itemIDs(result))
+	}
+
+	errLLM := errors.New("rate limited")
+	reranker = rerank.NewLLM(rerank.LLMConfig{Client: &mockLLMClient{err: errLLM}})
+	if _, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, llmTestItems(2)); !errors.Is(err, errLLM) {
+		t.Errorf("expected LLM error, got %v", err)
+	}
+}
+
+func TestLLMRequiresClient(t *testing.T) {
+	reranker := rerank.NewLLM(rerank.LLMConfig{})
+	if _, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, llmTestItems(2)); !errors.Is(err, retrieve.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig without a client, got %v", err)
+	}
+}
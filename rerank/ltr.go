@@ -0,0 +1,333 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Feature names produced by DefaultFeatures.
+const (
+	FeatureScore           = "score"
+	FeatureSimilarity      = "similarity"
+	FeatureRerankScore     = "rerank_score"
+	FeatureRecency         = "recency"
+	FeatureSourcePrior     = "source_prior"
+	FeatureGraphCentrality = "graph_centrality"
+)
+
+// FeatureVector maps feature names to values for a single item.
+type FeatureVector map[string]float64
+
+// FeatureExtractor computes ranking features for candidate items.
+type FeatureExtractor interface {
+	// FeatureNames returns the names of the extracted features in a stable
+	// order, used when exporting training data.
+	FeatureNames() []string
+	// Extract returns one feature vector per item, in item order.
+	Extract(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]FeatureVector, error)
+}
+
+// RankingModel scores an item from its feature vector.
+type RankingModel interface {
+	// Predict returns the ranking score for a feature vector.
+	Predict(features FeatureVector) float64
+}
+
+// FeaturesConfig configures the default feature extractor.
+type FeaturesConfig struct {
+	// RecencyKey is the metadata key holding an RFC 3339 timestamp
+	// (default "timestamp"). Items without it get a recency of 0.
+	RecencyKey string
+	// RecencyHalfLife is the age at which recency decays to 0.5 (default 30 days).
+	RecencyHalfLife time.Duration
+	// SourcePriors maps item sources to prior quality scores.
+	SourcePriors map[string]float64
+	// Now returns the current time (default time.Now).
+	Now func() time.Time
+}
+
+// DefaultFeatures extracts similarity, rerank score, recency, source prior,
+// and graph centrality features from item scores, provenance, and metadata.
+type DefaultFeatures struct {
+	config FeaturesConfig
+}
+
+// NewDefaultFeatures creates the default feature extractor.
+func NewDefaultFeatures(cfg FeaturesConfig) *DefaultFeatures {
+	if cfg.RecencyKey == "" {
+		cfg.RecencyKey = "timestamp"
+	}
+	if cfg.RecencyHalfLife == 0 {
+		cfg.RecencyHalfLife = 30 * 24 * time.Hour
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &DefaultFeatures{config: cfg}
+}
+
+// FeatureNames implements FeatureExtractor.
+func (f *DefaultFeatures) FeatureNames() []string {
+	return []string{
+		FeatureScore,
+		FeatureSimilarity,
+		FeatureRerankScore,
+		FeatureRecency,
+		FeatureSourcePrior,
+		FeatureGraphCentrality,
+	}
+}
+
+// Extract implements FeatureExtractor.
+func (f *DefaultFeatures) Extract(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]FeatureVector, error) {
+	now := f.config.Now()
+	centrality := graphCentrality(items)
+
+	vectors := make([]FeatureVector, len(items))
+	for i, item := range items {
+		vectors[i] = FeatureVector{
+			FeatureScore:           item.Score,
+			FeatureSimilarity:      item.Provenance.SimilarityScore,
+			FeatureRerankScore:     item.Provenance.RerankerScore,
+			FeatureRecency:         f.recency(item, now),
+			FeatureSourcePrior:     f.config.SourcePriors[item.Source],
+			FeatureGraphCentrality: centrality[i],
+		}
+	}
+	return vectors, nil
+}
+
+// recency returns an exponential decay of the item's age.
+func (f *DefaultFeatures) recency(item retrieve.ContextItem, now time.Time) float64 {
+	ts, err := time.Parse(time.RFC3339, item.Metadata[f.config.RecencyKey])
+	if err != nil {
+		return 0
+	}
+	age := now.Sub(ts)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(f.config.RecencyHalfLife))
+}
+
+// graphCentrality returns, for each item, the fraction of other candidates
+// whose graph path passes through it.
+func graphCentrality(items []retrieve.ContextItem) []float64 {
+	centrality := make([]float64, len(items))
+	if len(items) < 2 {
+		return centrality
+	}
+
+	onPath := make(map[string]int)
+	for _, item := range items {
+		seen := make(map[string]bool)
+		for _, id := range item.Provenance.GraphPath {
+			if id != item.ID && !seen[id] {
+				seen[id] = true
+				onPath[id]++
+			}
+		}
+	}
+	for i, item := range items {
+		centrality[i] = float64(onPath[item.ID]) / float64(len(items)-1)
+	}
+	return centrality
+}
+
+// LinearModel scores items as a weighted sum of features plus a bias.
+type LinearModel struct {
+	// Weights maps feature names to weights. Missing features contribute 0.
+	Weights map[string]float64
+	// Bias is added to every score.
+	Bias float64
+}
+
+// Predict implements RankingModel.
+func (m LinearModel) Predict(features FeatureVector) float64 {
+	score := m.Bias
+	for name, weight := range m.Weights {
+		score += weight * features[name]
+	}
+	return score
+}
+
+// TreeNode is a node of a regression tree. Internal nodes route to Left when
+// the feature value is less than Threshold, and to Right otherwise.
+type TreeNode struct {
+	// Feature is the feature tested by an internal node.
+	Feature string
+	// Threshold is the split value of an internal node.
+	Threshold float64
+	// Left and Right are indexes of the child nodes within the tree.
+	Left, Right int
+	// Leaf marks a leaf node.
+	Leaf bool
+	// Value is the output of a leaf node.
+	Value float64
+}
+
+// Tree is a regression tree stored as a flat node list rooted at index 0.
+type Tree []TreeNode
+
+// Predict returns the leaf value reached by the feature vector.
+func (t Tree) Predict(features FeatureVector) float64 {
+	i := 0
+	for steps := 0; i >= 0 && i < len(t) && steps <= len(t); steps++ {
+		node := t[i]
+		if node.Leaf {
+			return node.Value
+		}
+		if features[node.Feature] < node.Threshold {
+			i = node.Left
+		} else {
+			i = node.Right
+		}
+	}
+	return 0 // malformed tree
+}
+
+// GBDTModel scores items with a gradient-boosted ensemble of regression trees,
+// such as a model trained with XGBoost or LightGBM and converted to Trees.
+type GBDTModel struct {
+	// Trees are summed to produce the score.
+	Trees []Tree
+	// BaseScore is added to the sum of tree outputs.
+	BaseScore float64
+}
+
+// Predict implements RankingModel.
+func (m GBDTModel) Predict(features FeatureVector) float64 {
+	score := m.BaseScore
+	for _, tree := range m.Trees {
+		score += tree.Predict(features)
+	}
+	return score
+}
+
+// LTRConfig configures the learning-to-rank reranker.
+type LTRConfig struct {
+	// Model scores each item from its features (required).
+	Model RankingModel
+	// Extractor computes item features (default NewDefaultFeatures).
+	Extractor FeatureExtractor
+	// TopK limits output to top K results after reranking.
+	TopK int
+	// MinScore filters results below this threshold.
+	MinScore float64
+}
+
+// LTR implements learning-to-rank reranking with pluggable feature
+// extraction and a user-supplied ranking model.
+type LTR struct {
+	config LTRConfig
+}
+
+// NewLTR creates a new learning-to-rank reranker.
+func NewLTR(cfg LTRConfig) *LTR {
+	if cfg.Extractor == nil {
+		cfg.Extractor = NewDefaultFeatures(FeaturesConfig{})
+	}
+	return &LTR{config: cfg}
+}
+
+// Rerank implements retrieve.Reranker.
+func (r *LTR) Rerank(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if r.config.Model == nil {
+		return nil, fmt.Errorf("%w: ltr reranker model is required", retrieve.ErrInvalidConfig)
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	features, err := r.config.Extractor.Extract(ctx, q, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract features: %w", err)
+	}
+	if len(features) != len(items) {
+		return nil, fmt.Errorf("extractor returned %d feature vectors for %d items", len(features), len(items))
+	}
+
+	result := make([]retrieve.ContextItem, 0, len(items))
+	for i, item := range items {
+		score := r.config.Model.Predict(features[i])
+		item.Provenance.RerankerScore = score
+		item.Score = score
+		if item.Score >= r.config.MinScore {
+			result = append(result, item)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	if r.config.TopK > 0 && len(result) > r.config.TopK {
+		result = result[:r.config.TopK]
+	}
+
+	return result, nil
+}
+
+// ExportTrainingData writes recorded feedback as LTR training data in the
+// SVMlight/LETOR text format ("<relevance> qid:<n> 1:<v> 2:<v> ... # <id>"),
+// readable by XGBoost, LightGBM, and RankLib. Features are numbered in
+// extractor FeatureNames order and computed per query group, so set-level
+// features such as graph centrality match what the reranker sees.
+func ExportTrainingData(ctx context.Context, w io.Writer, store retrieve.FeedbackStore, extractor FeatureExtractor) error {
+	feedback, err := store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list feedback: %w", err)
+	}
+
+	// Group feedback by query, preserving first-seen order
+	var order []string
+	groups := make(map[string][]retrieve.Feedback)
+	for _, fb := range feedback {
+		if _, ok := groups[fb.QueryID]; !ok {
+			order = append(order, fb.QueryID)
+		}
+		groups[fb.QueryID] = append(groups[fb.QueryID], fb)
+	}
+
+	names := extractor.FeatureNames()
+	for qid, queryID := range order {
+		group := groups[queryID]
+		items := make([]retrieve.ContextItem, len(group))
+		for i, fb := range group {
+			items[i] = fb.Item
+		}
+
+		features, err := extractor.Extract(ctx, group[0].Query, items)
+		if err != nil {
+			return fmt.Errorf("failed to extract features for query %s: %w", queryID, err)
+		}
+
+		for i, fb := range group {
+			var line strings.Builder
+			fmt.Fprintf(&line, "%g qid:%d", fb.Relevance, qid+1)
+			for j, name := range names {
+				fmt.Fprintf(&line, " %d:%g", j+1, features[i][name])
+			}
+			fmt.Fprintf(&line, " # %s\n", fb.Item.ID)
+			if _, err := io.WriteString(w, line.String()); err != nil {
+				return fmt.Errorf("failed to write training data: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Verify interface compliance
+var (
+	_ retrieve.Reranker = (*LTR)(nil)
+	_ FeatureExtractor  = (*DefaultFeatures)(nil)
+	_ RankingModel      = LinearModel{}
+	_ RankingModel      = GBDTModel{}
+)
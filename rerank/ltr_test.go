@@ -0,0 +1,162 @@
+package rerank_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/rerank"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+func TestDefaultFeatures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	extractor := rerank.NewDefaultFeatures(rerank.FeaturesConfig{
+		RecencyHalfLife: 30 * 24 * time.Hour,
+		SourcePriors:    map[string]float64{"docs": 0.9},
+		Now:             func() time.Time { return now },
+	})
+
+	items := []retrieve.ContextItem{
+		{
+			ID:         "hub",
+			Score:      0.8,
+			Source:     "docs",
+			Metadata:   map[string]string{"timestamp": "2025-01-01T00:00:00Z"},
+			Provenance: retrieve.Provenance{SimilarityScore: 0.7, GraphPath: []string{"hub"}},
+		},
+		{ID: "leaf1", Score: 0.5, Provenance: retrieve.Provenance{GraphPath: []string{"hub", "leaf1"}}},
+		{ID: "leaf2", Score: 0.4, Provenance: retrieve.Provenance{GraphPath: []string{"hub", "leaf2"}}},
+	}
+
+	features, err := extractor.Extract(ctx, retrieve.Query{}, items)
+	if err != nil {
+		t.Fatalf("failed to extract features: %v", err)
+	}
+
+	hub := features[0]
+	if hub[rerank.FeatureSimilarity] != 0.7 || hub[rerank.FeatureSourcePrior] != 0.9 {
+		t.Errorf("unexpected hub features: %v", hub)
+	}
+	if math.Abs(hub[rerank.FeatureRecency]-0.5) > 1e-9 {
+		t.Errorf("expected recency 0.5 after one half-life, got %f", hub[rerank.FeatureRecency])
+	}
+	if hub[rerank.FeatureGraphCentrality] != 1 {
+		t.Errorf("expected hub centrality 1, got %f", hub[rerank.FeatureGraphCentrality])
+	}
+	if features[1][rerank.FeatureGraphCentrality] != 0 || features[1][rerank.FeatureRecency] != 0 {
+		t.Errorf("unexpected leaf features: %v", features[1])
+	}
+}
+
+func TestLTRLinearModel(t *testing.T) {
+	ctx := context.Background()
+
+	reranker := rerank.NewLTR(rerank.LTRConfig{
+		Model: rerank.LinearModel{
+			Weights: map[string]float64{
+				rerank.FeatureScore:       0.5,
+				rerank.FeatureSourcePrior: 1.0,
+			},
+		},
+		Extractor: rerank.NewDefaultFeatures(rerank.FeaturesConfig{
+			SourcePriors: map[string]float64{"trusted": 1.0},
+		}),
+		TopK: 2,
+	})
+
+	items := []retrieve.ContextItem{
+		{ID: "a", Score: 0.9, Source: "forum"},
+		{ID: "b", Score: 0.6, Source: "trusted"},
+		{ID: "c", Score: 0.1, Source: "forum"},
+	}
+
+	result, err := reranker.Rerank(ctx, retrieve.Query{}, items)
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+
+	if len(result) != 2 || result[0].ID != "b" || result[1].ID != "a" {
+		t.Fatalf("expected [b a], got %+v", result)
+	}
+	if result[0].Score != 1.3 || result[0].Provenance.RerankerScore != 1.3 {
+		t.Errorf("expected score 1.3, got %f", result[0].Score)
+	}
+}
+
+func TestGBDTModel(t *testing.T) {
+	model := rerank.GBDTModel{
+		BaseScore: 0.1,
+		Trees: []rerank.Tree{
+			{
+				{Feature: rerank.FeatureScore, Threshold: 0.5, Left: 1, Right: 2},
+				{Leaf: true, Value: -1},
+				{Leaf: true, Value: 1},
+			},
+			{
+				{Feature: rerank.FeatureRecency, Threshold: 0.2, Left: 1, Right: 2},
+				{Leaf: true, Value: 0},
+				{Leaf: true, Value: 0.5},
+			},
+		},
+	}
+
+	if got := model.Predict(rerank.FeatureVector{rerank.FeatureScore: 0.9, rerank.FeatureRecency: 0.8}); got != 1.6 {
+		t.Errorf("expected 1.6, got %f", got)
+	}
+	if got := model.Predict(rerank.FeatureVector{rerank.FeatureScore: 0.1}); got != -0.9 {
+		t.Errorf("expected -0.9, got %f", got)
+	}
+}
+
+func TestExportTrainingData(t *testing.T) {
+	ctx := context.Background()
+
+	store := memory.NewFeedbackStore()
+	feedback := []retrieve.Feedback{
+		{QueryID: "q1", Item: retrieve.ContextItem{ID: "a", Score: 0.9}, Relevance: 2},
+		{QueryID: "q2", Item: retrieve.ContextItem{ID: "c", Score: 0.4}, Relevance: 1},
+		{QueryID: "q1", Item: retrieve.ContextItem{ID: "b", Score: 0.3}, Relevance: 0},
+	}
+	for _, fb := range feedback {
+		if err := store.Record(ctx, fb); err != nil {
+			t.Fatalf("failed to record feedback: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	extractor := rerank.NewDefaultFeatures(rerank.FeaturesConfig{})
+	if err := rerank.ExportTrainingData(ctx, &buf, store, extractor); err != nil {
+		t.Fatalf("failed to export training data: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"2 qid:1 1:0.9 2:0 3:0 4:0 5:0 6:0 # a",
+		"0 qid:1 1:0.3 2:0 3:0 4:0 5:0 6:0 # b",
+		"1 qid:2 1:0.4 2:0 3:0 4:0 5:0 6:0 # c",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(expected), len(lines), buf.String())
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestLTRRequiresModel(t *testing.T) {
+	reranker := rerank.NewLTR(rerank.LTRConfig{})
+	items := []retrieve.ContextItem{{ID: "1", Score: 0.5}}
+	if _, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, items); !errors.Is(err, retrieve.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig without a model, got %v", err)
+	}
+}
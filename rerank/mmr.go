@@ -0,0 +1,154 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// MMRConfig configures the maximal marginal relevance reranker.
+type MMRConfig struct {
+	// Embedder embeds item contents to compare them (optional; default:
+	// token overlap).
+	Embedder vector.Embedder
+	// Lambda trades relevance (1) against diversity (near 0) (default 0.5).
+	Lambda float64
+	// TopK limits output to top K results after reranking.
+	TopK int
+}
+
+// MMR implements maximal marginal relevance reranking, which penalizes items
+// similar to higher-ranked ones so near-duplicate chunks don't crowd the
+// context. Item scores are kept; only their order changes.
+type MMR struct {
+	config MMRConfig
+}
+
+// NewMMR creates a new MMR reranker.
+func NewMMR(cfg MMRConfig) *MMR {
+	if cfg.Lambda == 0 {
+		cfg.Lambda = 0.5
+	}
+	return &MMR{config: cfg}
+}
+
+// Rerank implements retrieve.Reranker. It repeatedly picks the item
+// maximizing Lambda*relevance - (1-Lambda)*(its highest similarity to the
+// items already picked), where relevance is the score rescaled to [0, 1].
+func (r *MMR) Rerank(ctx context.Context, _ retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if len(items) < 2 {
+		return items, nil
+	}
+
+	ranked := make([]retrieve.ContextItem, len(items))
+	copy(ranked, items)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	similarity, err := r.similarity(ctx, ranked)
+	if err != nil {
+		return nil, err
+	}
+
+	lambda := r.config.Lambda
+	lo, hi := ranked[len(ranked)-1].Score, ranked[0].Score
+	relevance := func(i int) float64 {
+		if hi == lo {
+			return 1
+		}
+		return (ranked[i].Score - lo) / (hi - lo)
+	}
+
+	n := len(ranked)
+	if r.config.TopK > 0 && r.config.TopK < n {
+		n = r.config.TopK
+	}
+	result := make([]retrieve.ContextItem, 0, n)
+	picked := make([]bool, len(ranked))
+	// maxSim[i] is item i's highest similarity to a picked item
+	maxSim := make([]float64, len(ranked))
+	for len(result) < n {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range ranked {
+			if picked[i] {
+				continue
+			}
+			score := lambda * relevance(i)
+			if len(result) > 0 {
+				score -= (1 - lambda) * maxSim[i]
+			}
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		// NaN scores never compare greater; keep the score order then
+		if best == -1 {
+			best = slices.Index(picked, false)
+		}
+
+		picked[best] = true
+		result = append(result, ranked[best])
+		for i := range ranked {
+			if !picked[i] {
+				maxSim[i] = max(maxSim[i], similarity(i, best))
+			}
+		}
+	}
+	return result, nil
+}
+
+// similarity returns a function computing the similarity of two items.
+func (r *MMR) similarity(ctx context.Context, items []retrieve.ContextItem) (func(i, j int) float64, error) {
+	contents := make([]string, len(items))
+	for i, item := range items {
+		contents[i] = item.Content
+	}
+
+	if r.config.Embedder != nil {
+		embeddings, err := r.config.Embedder.EmbedBatch(ctx, contents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed items: %w", err)
+		}
+		if len(embeddings) != len(items) {
+			return nil, fmt.Errorf("failed to embed items: got %d embeddings for %d items", len(embeddings), len(items))
+		}
+		return func(i, j int) float64 {
+			return vector.Similarity(vector.DistanceCosine, embeddings[i], embeddings[j])
+		}, nil
+	}
+
+	tokens := make([]map[string]bool, len(items))
+	for i, content := range contents {
+		tokens[i] = make(map[string]bool)
+		for _, w := range strings.Fields(strings.ToLower(content)) {
+			tokens[i][w] = true
+		}
+	}
+	return func(i, j int) float64 {
+		return jaccard(tokens[i], tokens[j])
+	}, nil
+}
+
+// jaccard returns the Jaccard similarity of two token sets.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Verify interface compliance
+var _ retrieve.Reranker = (*MMR)(nil)
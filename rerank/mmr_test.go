@@ -0,0 +1,107 @@
+package rerank_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/rerank"
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
+)
+
+func mmrTestItems() []retrieve.ContextItem {
+	return []retrieve.ContextItem{
+		{ID: "1", Content: "reset your password from the login page", Score: 0.9},
+		{ID: "2", Content: "reset your password from the login page today", Score: 0.88},
+		{ID: "3", Content: "contact support for locked accounts", Score: 0.8},
+		{ID: "4", Content: "billing runs monthly", Score: 0.2},
+	}
+}
+
+func TestMMRReranker(t *testing.T) {
+	// The near-duplicate drops below the other relevant item
+	reranker := rerank.NewMMR(rerank.MMRConfig{TopK: 3})
+	result, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, mmrTestItems())
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+	if want := []string{"1", "3", "2"}; !slices.Equal(itemIDs(result), want) {
+		t.Errorf("expected %v, got %v", want, itemIDs(result))
+	}
+	if result[1].Score != 0.8 {
+		t.Errorf("expected scores to be kept, got %v", result[1].Score)
+	}
+
+	// Lambda 1 ignores diversity
+	reranker = rerank.NewMMR(rerank.MMRConfig{Lambda: 1})
+	result, err = reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, mmrTestItems())
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+	if want := []string{"1", "2", "3", "4"}; !slices.Equal(itemIDs(result), want) {
+		t.Errorf("expected %v, got %v", want, itemIDs(result))
+	}
+}
+
+func TestMMRRerankerEmbedder(t *testing.T) {
+	items := mmrTestItems()
+	embedder := &retrievetest.Embedder{Embeddings: map[string][]float32{
+		items[0].Content: {1, 0},
+		items[1].Content: {0, 1},
+		items[2].Content: {1, 0.1},
+		items[3].Content: {0, 1},
+	}}
+
+	// Embeddings, unlike token overlap, find 1 and 3 redundant
+	reranker := rerank.NewMMR(rerank.MMRConfig{Embedder: embedder})
+	result, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, items)
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+	if want := []string{"1", "2", "3", "4"}; !slices.Equal(itemIDs(result), want) {
+		t.Errorf("expected %v, got %v", want, itemIDs(result))
+	}
+
+	errEmbed := errors.New("embedder down")
+	embedder.FailWith(retrievetest.MethodEmbedBatch, errEmbed)
+	if _, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, items); !errors.Is(err, errEmbed) {
+		t.Errorf("expected embedder error, got %v", err)
+	}
+}
+
+// shortEmbedder drops the last embedding of every batch.
+type shortEmbedder struct {
+	*retrievetest.Embedder
+}
+
+func (e shortEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings, err := e.Embedder.EmbedBatch(ctx, texts)
+	if err != nil || len(embeddings) == 0 {
+		return embeddings, err
+	}
+	return embeddings[:len(embeddings)-1], nil
+}
+
+func TestMMRRerankerInvalidInput(t *testing.T) {
+	// Embedders returning too few embeddings fail instead of panicking
+	reranker := rerank.NewMMR(rerank.MMRConfig{Embedder: shortEmbedder{&retrievetest.Embedder{Dimensions: 2}}})
+	if _, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, mmrTestItems()); err == nil {
+		t.Error("expected an error for missing embeddings")
+	}
+
+	// NaN scores keep every item
+	items := mmrTestItems()
+	for i := range items {
+		items[i].Score = math.NaN()
+	}
+	result, err := rerank.NewMMR(rerank.MMRConfig{}).Rerank(context.Background(), retrieve.Query{Text: "q"}, items)
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+	if len(result) != len(items) {
+		t.Errorf("expected %d items, got %v", len(items), itemIDs(result))
+	}
+}
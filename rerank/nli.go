@@ -0,0 +1,167 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// NLIResult holds natural language inference probabilities for a
+// premise-hypothesis pair.
+type NLIResult struct {
+	// Entailment is the probability that the premise entails the hypothesis.
+	Entailment float64
+	// Contradiction is the probability that the premise contradicts the hypothesis.
+	Contradiction float64
+	// Neutral is the probability that the pair is unrelated.
+	Neutral float64
+}
+
+// NLIScorer classifies the relationship between text pairs using an NLI model.
+type NLIScorer interface {
+	// Classify returns NLI probabilities for the premise against each hypothesis.
+	Classify(ctx context.Context, premise string, hypotheses []string) ([]NLIResult, error)
+	// Model returns the model name.
+	Model() string
+}
+
+// NLIAction defines what happens to a candidate flagged by the NLI filter.
+type NLIAction string
+
+const (
+	// NLIActionDrop removes flagged candidates.
+	NLIActionDrop NLIAction = "drop"
+	// NLIActionDownrank multiplies the score of flagged candidates by the penalty.
+	NLIActionDownrank NLIAction = "downrank"
+)
+
+// NLIConfig configures the NLI filtering reranker.
+type NLIConfig struct {
+	// Scorer is the NLI model to use (required).
+	Scorer NLIScorer
+	// Action is applied to flagged candidates (default drop).
+	Action NLIAction
+	// ContradictionThreshold flags candidates contradicted by higher-ranked
+	// evidence with at least this probability (default 0.8).
+	ContradictionThreshold float64
+	// DuplicateThreshold flags candidates entailed by higher-ranked evidence
+	// with at least this probability as duplicates (default 0.9).
+	DuplicateThreshold float64
+	// Penalty is the score multiplier used by NLIActionDownrank (default 0.5).
+	Penalty float64
+	// MaxEvidence is the number of top-ranked items used as evidence (default 5).
+	MaxEvidence int
+	// DisableDuplicates turns off entailed-duplicate detection.
+	DisableDuplicates bool
+	// TopK limits output to top K results after filtering.
+	TopK int
+}
+
+// NLI implements reranking that drops or down-ranks candidates contradicting
+// higher-ranked evidence or restating it, improving answer consistency.
+type NLI struct {
+	config NLIConfig
+}
+
+// NewNLI creates a new NLI filtering reranker.
+func NewNLI(cfg NLIConfig) *NLI {
+	if cfg.Action == "" {
+		cfg.Action = NLIActionDrop
+	}
+	if cfg.ContradictionThreshold == 0 {
+		cfg.ContradictionThreshold = 0.8
+	}
+	if cfg.DuplicateThreshold == 0 {
+		cfg.DuplicateThreshold = 0.9
+	}
+	if cfg.Penalty == 0 {
+		cfg.Penalty = 0.5
+	}
+	if cfg.MaxEvidence == 0 {
+		cfg.MaxEvidence = 5
+	}
+	return &NLI{config: cfg}
+}
+
+// Rerank implements retrieve.Reranker.
+func (r *NLI) Rerank(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if r.config.Scorer == nil {
+		return nil, fmt.Errorf("%w: nli reranker scorer is required", retrieve.ErrInvalidConfig)
+	}
+	if len(items) <= 1 {
+		return items, nil
+	}
+
+	ranked := make([]retrieve.ContextItem, len(items))
+	copy(ranked, items)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	// Classify each evidence item against every lower-ranked candidate.
+	evidenceCount := r.config.MaxEvidence
+	if evidenceCount > len(ranked)-1 {
+		evidenceCount = len(ranked) - 1
+	}
+	relations := make([][]NLIResult, evidenceCount)
+	for e := 0; e < evidenceCount; e++ {
+		hypotheses := make([]string, 0, len(ranked)-e-1)
+		for _, item := range ranked[e+1:] {
+			hypotheses = append(hypotheses, item.Content)
+		}
+		results, err := r.config.Scorer.Classify(ctx, ranked[e].Content, hypotheses)
+		if err != nil {
+			return nil, err
+		}
+		relations[e] = results
+	}
+
+	// Walk the ranking; only candidates that survive serve as evidence.
+	flagged := make([]bool, len(ranked))
+	for i := 1; i < len(ranked); i++ {
+		for e := 0; e < evidenceCount && e < i; e++ {
+			if flagged[e] {
+				continue
+			}
+			offset := i - e - 1
+			if offset >= len(relations[e]) {
+				continue
+			}
+			rel := relations[e][offset]
+			if rel.Contradiction >= r.config.ContradictionThreshold ||
+				(!r.config.DisableDuplicates && rel.Entailment >= r.config.DuplicateThreshold) {
+				flagged[i] = true
+				break
+			}
+		}
+	}
+
+	result := make([]retrieve.ContextItem, 0, len(ranked))
+	for i, item := range ranked {
+		if flagged[i] {
+			if r.config.Action == NLIActionDrop {
+				continue
+			}
+			item.Score *= r.config.Penalty
+			item.Provenance.RerankerScore = item.Score
+		}
+		result = append(result, item)
+	}
+
+	// Sort by score descending
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	// Apply top-k
+	if r.config.TopK > 0 && len(result) > r.config.TopK {
+		result = result[:r.config.TopK]
+	}
+
+	return result, nil
+}
+
+// Verify interface compliance
+var _ retrieve.Reranker = (*NLI)(nil)
@@ -0,0 +1,100 @@
+package rerank_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/rerank"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// mockNLIScorer returns fixed NLI results keyed by premise and hypothesis.
+type mockNLIScorer struct {
+	relations map[[2]string]rerank.NLIResult
+}
+
+func (m *mockNLIScorer) Classify(_ context.Context, premise string, hypotheses []string) ([]rerank.NLIResult, error) {
+	results := make([]rerank.NLIResult, len(hypotheses))
+	for i, h := range hypotheses {
+		if rel, ok := m.relations[[2]string{premise, h}]; ok {
+			results[i] = rel
+		} else {
+			results[i] = rerank.NLIResult{Neutral: 1}
+		}
+	}
+	return results, nil
+}
+
+func (m *mockNLIScorer) Model() string {
+	return "mock-nli"
+}
+
+func nliTestItems() []retrieve.ContextItem {
+	return []retrieve.ContextItem{
+		{ID: "1", Content: "The bridge opened in 1932", Score: 0.9},
+		{ID: "2", Content: "The bridge opened in 1950", Score: 0.8},
+		{ID: "3", Content: "The bridge was opened in 1932", Score: 0.7},
+		{ID: "4", Content: "The bridge is made of steel", Score: 0.6},
+	}
+}
+
+func nliTestScorer() *mockNLIScorer {
+	return &mockNLIScorer{relations: map[[2]string]rerank.NLIResult{
+		{"The bridge opened in 1932", "The bridge opened in 1950"}:     {Contradiction: 0.95},
+		{"The bridge opened in 1932", "The bridge was opened in 1932"}: {Entailment: 0.97},
+	}}
+}
+
+func TestNLIRerankerDrop(t *testing.T) {
+	ctx := context.Background()
+
+	reranker := rerank.NewNLI(rerank.NLIConfig{Scorer: nliTestScorer()})
+
+	result, err := reranker.Rerank(ctx, retrieve.Query{Text: "when did the bridge open"}, nliTestItems())
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("expected contradiction and duplicate to be dropped, got %d items", len(result))
+	}
+	if result[0].ID != "1" || result[1].ID != "4" {
+		t.Errorf("expected items [1 4], got [%s %s]", result[0].ID, result[1].ID)
+	}
+}
+
+func TestNLIRerankerDownrank(t *testing.T) {
+	ctx := context.Background()
+
+	reranker := rerank.NewNLI(rerank.NLIConfig{
+		Scorer:            nliTestScorer(),
+		Action:            rerank.NLIActionDownrank,
+		Penalty:           0.5,
+		DisableDuplicates: true,
+	})
+
+	result, err := reranker.Rerank(ctx, retrieve.Query{}, nliTestItems())
+	if err != nil {
+		t.Fatalf("failed to rerank: %v", err)
+	}
+
+	if len(result) != 4 {
+		t.Fatalf("expected all items to be kept, got %d", len(result))
+	}
+
+	// Item 2 (0.8 * 0.5 = 0.4) falls below items 3 and 4
+	if result[len(result)-1].ID != "2" {
+		t.Errorf("expected contradicted item 2 last, got %s", result[len(result)-1].ID)
+	}
+	if result[len(result)-1].Score != 0.4 {
+		t.Errorf("expected down-ranked score 0.4, got %f", result[len(result)-1].Score)
+	}
+}
+
+func TestNLIRequiresScorer(t *testing.T) {
+	reranker := rerank.NewNLI(rerank.NLIConfig{})
+	if _, err := reranker.Rerank(context.Background(), retrieve.Query{Text: "q"}, nliTestItems()); !errors.Is(err, retrieve.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig without a scorer, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package retrieve
+
+import "math"
+
+// AutocutMode selects the heuristic used to truncate a ranked result list.
+type AutocutMode string
+
+const (
+	// AutocutOff disables autocut; only TopK limits the results.
+	AutocutOff AutocutMode = ""
+	// AutocutLargestGap cuts the list at the largest drop between adjacent scores.
+	AutocutLargestGap AutocutMode = "largest_gap"
+	// AutocutKnee cuts the list at the knee of the score curve, the point
+	// furthest from the line joining the first and last scores.
+	AutocutKnee AutocutMode = "knee"
+)
+
+// Autocut configures score-based truncation of ranked results, so that
+// irrelevant tail items are dropped instead of relying on a fixed TopK.
+type Autocut struct {
+	// Mode is the truncation heuristic. The zero value disables autocut.
+	Mode AutocutMode
+	// MinItems is the minimum number of items to keep (default 1).
+	MinItems int
+}
+
+// Apply truncates items, which must be sorted by score descending, according
+// to the configured heuristic. It returns items unchanged when disabled.
+func (a Autocut) Apply(items []ContextItem) []ContextItem {
+	scores := make([]float64, len(items))
+	for i, item := range items {
+		scores[i] = item.Score
+	}
+	return items[:a.Cut(scores)]
+}
+
+// Cut returns the number of leading scores to keep. Scores must be sorted
+// in descending order.
+func (a Autocut) Cut(scores []float64) int {
+	n := len(scores)
+	minItems := a.MinItems
+	if minItems <= 0 {
+		minItems = 1
+	}
+	if n <= minItems {
+		return n
+	}
+
+	var keep int
+	switch a.Mode {
+	case AutocutLargestGap:
+		keep = largestGapCut(scores)
+	case AutocutKnee:
+		keep = kneeCut(scores)
+	default:
+		return n
+	}
+
+	if keep < minItems {
+		keep = minItems
+	}
+	return keep
+}
+
+// largestGapCut keeps scores up to the largest drop between neighbors.
+func largestGapCut(scores []float64) int {
+	bestGap := 0.0
+	keep := len(scores)
+	for i := 0; i < len(scores)-1; i++ {
+		if gap := scores[i] - scores[i+1]; gap > bestGap {
+			bestGap = gap
+			keep = i + 1
+		}
+	}
+	return keep
+}
+
+// kneeCut finds the point with the largest distance from the chord between
+// the first and last scores. Points below the chord start the flat tail and
+// are excluded; points above it end the plateau and are included.
+func kneeCut(scores []float64) int {
+	n := len(scores)
+	if n < 3 {
+		return n
+	}
+
+	first, last := scores[0], scores[n-1]
+	if first == last {
+		return n
+	}
+
+	bestDist := 0.0
+	keep := n
+	for i := 1; i < n-1; i++ {
+		// Normalize both axes to [0, 1] and measure against the chord y = 1 - x.
+		x := float64(i) / float64(n-1)
+		y := (scores[i] - last) / (first - last)
+		dist := y - (1 - x)
+		if math.Abs(dist) > bestDist {
+			bestDist = math.Abs(dist)
+			if dist < 0 {
+				keep = i
+			} else {
+				keep = i + 1
+			}
+		}
+	}
+	return keep
+}
@@ -0,0 +1,60 @@
+package retrieve
+
+import "unicode/utf8"
+
+// TokenCounter counts the tokens of a text, e.g. with a model's tokenizer.
+type TokenCounter func(text string) int
+
+// EstimateTokens approximates the token count of text at four characters
+// per token, a common rule of thumb for English with BPE tokenizers.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// ContextBudget limits the total size of item contents, since the context
+// window of the downstream model is the real constraint rather than a
+// fixed number of items. The zero value has no limit.
+type ContextBudget struct {
+	// MaxTokens is the maximum total number of tokens (0: no limit).
+	MaxTokens int
+	// MaxChars is the maximum total number of characters (0: no limit).
+	MaxChars int
+	// CountTokens counts tokens for MaxTokens (default EstimateTokens).
+	CountTokens TokenCounter
+}
+
+// Apply selects items greedily in order, skipping any item that would
+// exceed the budget so that smaller, lower-ranked items can still fill it.
+// Items should be sorted by relevance. It returns items unchanged when the
+// budget has no limit.
+func (b ContextBudget) Apply(items []ContextItem) []ContextItem {
+	if b.MaxTokens <= 0 && b.MaxChars <= 0 {
+		return items
+	}
+	count := b.CountTokens
+	if count == nil {
+		count = EstimateTokens
+	}
+
+	selected := make([]ContextItem, 0, len(items))
+	var tokens, chars int
+	for _, item := range items {
+		var itemTokens, itemChars int
+		if b.MaxTokens > 0 {
+			itemTokens = count(item.Content)
+			if tokens+itemTokens > b.MaxTokens {
+				continue
+			}
+		}
+		if b.MaxChars > 0 {
+			itemChars = utf8.RuneCountInString(item.Content)
+			if chars+itemChars > b.MaxChars {
+				continue
+			}
+		}
+		tokens += itemTokens
+		chars += itemChars
+		selected = append(selected, item)
+	}
+	return selected
+}
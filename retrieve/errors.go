@@ -0,0 +1,43 @@
+package retrieve
+
+import "errors"
+
+// PermanentError is implemented by errors that retrying can't fix, such as
+// invalid configuration or embeddings of the wrong size. Retry doesn't
+// retry them by default.
+type PermanentError interface {
+	error
+	// Permanent reports whether the error is permanent.
+	Permanent() bool
+}
+
+// ErrInvalidConfig reports a component used with missing or invalid
+// configuration. It is a PermanentError.
+var ErrInvalidConfig = NewPermanentError("invalid configuration")
+
+// NewPermanentError returns an error with the given text that implements
+// PermanentError, for use as a sentinel error.
+func NewPermanentError(text string) error {
+	return &permanentError{text: text}
+}
+
+// permanentError is a sentinel error that retrying can't fix.
+type permanentError struct {
+	text string
+}
+
+// Error implements error.
+func (e *permanentError) Error() string {
+	return e.text
+}
+
+// Permanent implements PermanentError.
+func (e *permanentError) Permanent() bool {
+	return true
+}
+
+// isPermanent reports whether err wraps a permanent PermanentError.
+func isPermanent(err error) bool {
+	var permanent PermanentError
+	return errors.As(err, &permanent) && permanent.Permanent()
+}
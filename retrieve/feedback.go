@@ -0,0 +1,29 @@
+package retrieve
+
+import (
+	"context"
+	"time"
+)
+
+// Feedback is a relevance judgment recorded for a retrieved item, e.g. from
+// user clicks, thumbs up/down, or an agent citing the item in its answer.
+type Feedback struct {
+	// QueryID groups judgments made for the same retrieval.
+	QueryID string
+	// Query is the query that produced the item.
+	Query Query
+	// Item is the item as it was retrieved, including scores and provenance.
+	Item ContextItem
+	// Relevance is the graded relevance of the item (0 means irrelevant).
+	Relevance float64
+	// Timestamp is when the judgment was recorded.
+	Timestamp time.Time
+}
+
+// FeedbackStore records relevance feedback for offline training and tuning.
+type FeedbackStore interface {
+	// Record stores a feedback entry.
+	Record(ctx context.Context, fb Feedback) error
+	// List returns all recorded feedback in recording order.
+	List(ctx context.Context) ([]Feedback, error)
+}
@@ -0,0 +1,79 @@
+package retrieve
+
+import (
+	"context"
+	"sync"
+)
+
+// MutationOp identifies the kind of write that produced a mutation.
+type MutationOp string
+
+const (
+	// MutationUpsert indicates items were inserted or updated.
+	MutationUpsert MutationOp = "upsert"
+	// MutationDelete indicates items were removed.
+	MutationDelete MutationOp = "delete"
+)
+
+// Mutation describes a change to indexed content that may invalidate
+// cached retrieval results.
+type Mutation struct {
+	// Namespace identifies the index or graph that changed (its Name).
+	Namespace string
+	// Op is the kind of write.
+	Op MutationOp
+	// IDs are the IDs of the mutated items.
+	IDs []string
+	// Flush requests eviction of every entry in Namespace, or of all entries
+	// when Namespace is empty, regardless of IDs.
+	Flush bool
+}
+
+// Invalidator is implemented by caches that can evict entries made stale
+// by a mutation.
+type Invalidator interface {
+	// Invalidate evicts cached entries affected by the mutation.
+	Invalidate(ctx context.Context, m Mutation)
+}
+
+// InvalidationBus fans out mutation events from index writers to caches.
+// It is safe for concurrent use.
+type InvalidationBus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]Invalidator
+}
+
+// NewInvalidationBus creates a new invalidation bus.
+func NewInvalidationBus() *InvalidationBus {
+	return &InvalidationBus{subscribers: make(map[int]Invalidator)}
+}
+
+// Subscribe registers an invalidator and returns a function that removes it.
+func (b *InvalidationBus) Subscribe(inv Invalidator) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = inv
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Publish delivers a mutation to every subscriber synchronously, so that
+// caches are consistent by the time the write returns.
+func (b *InvalidationBus) Publish(ctx context.Context, m Mutation) {
+	b.mu.RLock()
+	subscribers := make([]Invalidator, 0, len(b.subscribers))
+	for _, inv := range b.subscribers {
+		subscribers = append(subscribers, inv)
+	}
+	b.mu.RUnlock()
+
+	for _, inv := range subscribers {
+		inv.Invalidate(ctx, m)
+	}
+}
@@ -0,0 +1,17 @@
+package retrieve
+
+import "context"
+
+// Compactor is implemented by components that can reclaim memory or storage
+// in the background, e.g. by purging expired cache entries.
+type Compactor interface {
+	// Compact reclaims unused space.
+	Compact(ctx context.Context) error
+}
+
+// MaintenanceObserver is an optional Observer extension that receives
+// background maintenance events, such as index rebuilds and compactions.
+type MaintenanceObserver interface {
+	// OnMaintenance is called after a maintenance action completes.
+	OnMaintenance(ctx context.Context, target string, action string, reason string, err error, latencyMS int64)
+}
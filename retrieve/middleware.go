@@ -0,0 +1,160 @@
+package retrieve
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Middleware wraps a Retriever with cross-cutting behavior, such as
+// timeouts, retries, or caching, independently of the retriever type.
+type Middleware func(Retriever) Retriever
+
+// Chain wraps r in middlewares. The first middleware is the outermost: it
+// sees the query first and the result last.
+//
+//	r = retrieve.Chain(r,
+//		retrieve.Logging(logger),
+//		retrieve.Cached(cache),
+//		retrieve.Timeout(2*time.Second),
+//	)
+func Chain(r Retriever, middlewares ...Middleware) Retriever {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		r = middlewares[i](r)
+	}
+	return r
+}
+
+// Timeout bounds each retrieval by d.
+func Timeout(d time.Duration) Middleware {
+	return func(next Retriever) Retriever {
+		return RetrieverFunc(func(ctx context.Context, q Query) (*Result, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next.Retrieve(ctx, q)
+		})
+	}
+}
+
+// RetryConfig configures Retry.
+type RetryConfig struct {
+	// MaxRetries is the number of times a failed retrieval is retried
+	// (default 2).
+	MaxRetries int
+	// Backoff is the delay before the first retry; it doubles on each
+	// subsequent retry (default 100ms).
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries (default 5s).
+	MaxBackoff time.Duration
+	// Retryable reports whether an error is worth retrying (default: every
+	// error except context cancellation, deadlines, and PermanentErrors).
+	Retryable func(error) bool
+}
+
+// Retry retries failed retrievals with exponential backoff until they
+// succeed, fail with an error that isn't retryable, or ctx is done.
+func Retry(cfg RetryConfig) Middleware {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 2
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	if cfg.Retryable == nil {
+		cfg.Retryable = func(err error) bool {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !isPermanent(err)
+		}
+	}
+
+	return func(next Retriever) Retriever {
+		return RetrieverFunc(func(ctx context.Context, q Query) (*Result, error) {
+			backoff := cfg.Backoff
+			for attempt := 0; ; attempt++ {
+				res, err := next.Retrieve(ctx, q)
+				if err == nil || attempt >= cfg.MaxRetries || ctx.Err() != nil || !cfg.Retryable(err) {
+					return res, err
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil, errors.Join(err, ctx.Err())
+				case <-time.After(backoff):
+				}
+				backoff = min(2*backoff, cfg.MaxBackoff)
+			}
+		})
+	}
+}
+
+// Cached serves results from cache and caches the results of successful
+// retrievals. Partial results are not cached. Cached results have
+// Metadata.CacheHit set.
+func Cached(cache Cache) Middleware {
+	return func(next Retriever) Retriever {
+		return RetrieverFunc(func(ctx context.Context, q Query) (*Result, error) {
+			if cached, ok := cache.Get(ctx, q); ok {
+				res := *cached
+				res.Metadata.CacheHit = true
+				return &res, nil
+			}
+
+			res, err := next.Retrieve(ctx, q)
+			if err != nil || res == nil || res.Metadata.PartialResult {
+				return res, err
+			}
+			// A failure to cache doesn't fail the retrieval
+			_ = cache.Set(ctx, q, res)
+			return res, nil
+		})
+	}
+}
+
+// FilterItems drops the result items for which keep returns false, e.g. to
+// enforce access control on every retriever.
+func FilterItems(keep func(q Query, item ContextItem) bool) Middleware {
+	return func(next Retriever) Retriever {
+		return RetrieverFunc(func(ctx context.Context, q Query) (*Result, error) {
+			res, err := next.Retrieve(ctx, q)
+			if err != nil || res == nil {
+				return res, err
+			}
+
+			filtered := *res
+			filtered.Items = make([]ContextItem, 0, len(res.Items))
+			for _, item := range res.Items {
+				if keep(q, item) {
+					filtered.Items = append(filtered.Items, item)
+				}
+			}
+			return &filtered, nil
+		})
+	}
+}
+
+// Logging logs each retrieval to logger: successes at debug level, failures
+// at error level. Query text is not logged.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Retriever) Retriever {
+		return RetrieverFunc(func(ctx context.Context, q Query) (*Result, error) {
+			start := time.Now()
+			res, err := next.Retrieve(ctx, q)
+			attrs := []any{
+				slog.Int("top_k", q.TopK),
+				slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "retrieval failed", append(attrs, slog.Any("error", err))...)
+				return res, err
+			}
+			if res != nil {
+				attrs = append(attrs, slog.Int("items", len(res.Items)), slog.Bool("cache_hit", res.Metadata.CacheHit))
+			}
+			logger.DebugContext(ctx, "retrieval", attrs...)
+			return res, err
+		})
+	}
+}
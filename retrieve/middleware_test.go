@@ -0,0 +1,165 @@
+package retrieve_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	fake := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "public"}, {ID: "secret"}},
+	}}}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	retriever := retrieve.Chain(fake,
+		retrieve.Logging(logger),
+		retrieve.Cached(memory.NewResultCache(memory.ResultCacheConfig{})),
+		retrieve.FilterItems(func(q retrieve.Query, item retrieve.ContextItem) bool { return item.ID != "secret" }),
+	)
+
+	for range 2 {
+		res, err := retriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+		if err != nil {
+			t.Fatalf("failed to retrieve: %v", err)
+		}
+		if len(res.Items) != 1 || res.Items[0].ID != "public" {
+			t.Errorf("expected filtered result, got %+v", res.Items)
+		}
+	}
+
+	// The cache sits outside the filter, so it holds filtered results and
+	// the logger, outermost, sees the cache hit
+	if n := fake.CallCount(retrievetest.MethodRetrieve); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+	if !strings.Contains(logs.String(), "cache_hit=true") {
+		t.Errorf("expected cache hit to be logged, got:\n%s", logs.String())
+	}
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	attempts := 0
+	flaky := retrieve.RetrieverFunc(func(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
+		if attempts++; attempts < 3 {
+			return nil, errors.New("flaky")
+		}
+		return &retrieve.Result{}, nil
+	})
+
+	retriever := retrieve.Chain(flaky, retrieve.Retry(retrieve.RetryConfig{Backoff: time.Millisecond}))
+	if _, err := retriever.Retrieve(context.Background(), retrieve.Query{Text: "q"}); err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	// Retries stop after MaxRetries
+	fake := &retrievetest.Retriever{}
+	fake.FailWith(retrievetest.MethodRetrieve, errors.New("down"))
+	retriever = retrieve.Chain(fake, retrieve.Retry(retrieve.RetryConfig{MaxRetries: 1, Backoff: time.Millisecond}))
+	if _, err := retriever.Retrieve(context.Background(), retrieve.Query{Text: "q"}); err == nil {
+		t.Error("expected error after exhausting retries")
+	}
+	if n := fake.CallCount(retrievetest.MethodRetrieve); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
+
+func TestRetryPermanentErrors(t *testing.T) {
+	for _, err := range []error{
+		context.DeadlineExceeded,
+		context.Canceled,
+		fmt.Errorf("search failed: %w", vector.ErrDimensionMismatch),
+		fmt.Errorf("%w: no model", retrieve.ErrInvalidConfig),
+	} {
+		fake := &retrievetest.Retriever{}
+		fake.FailWith(retrievetest.MethodRetrieve, err)
+		retriever := retrieve.Chain(fake, retrieve.Retry(retrieve.RetryConfig{Backoff: time.Millisecond}))
+
+		if _, got := retriever.Retrieve(context.Background(), retrieve.Query{Text: "q"}); !errors.Is(got, err) {
+			t.Errorf("expected %v, got %v", err, got)
+		}
+		if n := fake.CallCount(retrievetest.MethodRetrieve); n != 1 {
+			t.Errorf("expected %v not to be retried, got %d calls", err, n)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	blocking := retrieve.RetrieverFunc(func(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	retriever := retrieve.Chain(blocking, retrieve.Timeout(10*time.Millisecond))
+	start := time.Now()
+	if _, err := retriever.Retrieve(context.Background(), retrieve.Query{Text: "q"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the timeout to fire, took %v", elapsed)
+	}
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	fake := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a"}},
+	}}}
+	retriever := retrieve.Chain(fake, retrieve.Cached(memory.NewResultCache(memory.ResultCacheConfig{})))
+
+	res, err := retriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if res.Metadata.CacheHit {
+		t.Error("expected the first retrieval to miss the cache")
+	}
+
+	res, err = retriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if !res.Metadata.CacheHit || len(res.Items) != 1 || res.Items[0].ID != "a" {
+		t.Errorf("expected a cache hit, got %+v", res)
+	}
+	if n := fake.CallCount(retrievetest.MethodRetrieve); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+}
+
+func TestCachedSkipsPartialResults(t *testing.T) {
+	ctx := context.Background()
+	fake := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items:    []retrieve.ContextItem{{ID: "a"}},
+		Metadata: retrieve.ResultMetadata{PartialResult: true},
+	}}}
+	retriever := retrieve.Chain(fake, retrieve.Cached(memory.NewResultCache(memory.ResultCacheConfig{})))
+
+	for range 2 {
+		res, err := retriever.Retrieve(ctx, retrieve.Query{Text: "q"})
+		if err != nil {
+			t.Fatalf("failed to retrieve: %v", err)
+		}
+		if res.Metadata.CacheHit {
+			t.Error("expected partial results not to be served from cache")
+		}
+	}
+	if n := fake.CallCount(retrievetest.MethodRetrieve); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
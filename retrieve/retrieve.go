@@ -5,6 +5,8 @@ package retrieve
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
 // Mode represents the retrieval strategy to use.
@@ -17,6 +19,8 @@ const (
 	ModeGraph Mode = "graph"
 	// ModeHybrid combines vector and graph retrieval.
 	ModeHybrid Mode = "hybrid"
+	// ModeKeyword uses lexical keyword search (e.g., BM25).
+	ModeKeyword Mode = "keyword"
 )
 
 // EntityHint provides hints for entity-based retrieval in graph traversal.
@@ -42,6 +46,9 @@ type Query struct {
 	Entities []EntityHint
 	// Filters are key-value filters to apply to results.
 	Filters map[string]string
+	// Namespace restricts vector retrieval to a namespace of indexes that
+	// support them (optional).
+	Namespace string
 	// MaxDepth is the maximum traversal depth for graph retrieval.
 	MaxDepth int
 	// TopK is the maximum number of results to return.
@@ -51,8 +58,20 @@ type Query struct {
 	Modes []Mode
 	// MinScore is the minimum relevance score threshold (0.0-1.0).
 	MinScore float64
+	// Metric overrides the vector distance metric ("cosine", "euclidean",
+	// or "dot") for this query, for indexes that support it (optional).
+	Metric string
+	// Oversample overrides the vector retriever's oversampling factor for
+	// this query: TopK * Oversample candidates are fetched and re-scored
+	// exactly (optional; 1 disables it).
+	Oversample float64
+	// AsOf restricts graph retrieval to relationships valid at that time
+	// (optional).
+	AsOf time.Time
 	// Metadata contains additional query metadata.
 	Metadata map[string]any
+	// Explain requests that retrievers populate Result.Debug.
+	Explain bool
 }
 
 // ContextItem represents a single piece of retrieved context.
@@ -83,8 +102,54 @@ type Provenance struct {
 	SimilarityScore float64
 	// RerankerScore is the score after reranking (if applied).
 	RerankerScore float64
+	// SubScores maps each branch that found a merged item to the raw score
+	// it reported (hybrid only), e.g. "vector" and "graph".
+	SubScores map[string]float64
+	// Explanation describes how a merged score was derived (hybrid only).
+	Explanation *Explanation
 }
 
+// Explanation records how a hybrid retriever derived an item's final score.
+type Explanation struct {
+	// Contributions lists the score contributed by each retrieval branch.
+	Contributions []Contribution
+	// MergedScore is the sum of the weighted contributions, before
+	// reranking or other later adjustments of the item score.
+	MergedScore float64
+	// Dedup records how duplicate hits for this item were handled.
+	Dedup DedupDecision
+}
+
+// Contribution is the score a single retrieval branch contributed to an item.
+type Contribution struct {
+	// Mode identifies the branch that produced the score.
+	Mode Mode
+	// Branch names the branch that produced the score, which distinguishes
+	// hybrid branches sharing a mode.
+	Branch string
+	// RawScore is the score reported by the branch before weighting.
+	RawScore float64
+	// FusedScore is RawScore on the fusion scale (normalized, or the
+	// reciprocal rank term for RRF); it equals RawScore for weighted fusion.
+	FusedScore float64
+	// Weight is the weight applied to the fused score.
+	Weight float64
+	// WeightedScore is FusedScore multiplied by Weight.
+	WeightedScore float64
+}
+
+// DedupDecision describes how duplicate hits for an item were resolved.
+type DedupDecision string
+
+const (
+	// DedupUnique indicates the item was returned by a single branch only.
+	DedupUnique DedupDecision = "unique"
+	// DedupMerged indicates hits from several branches were combined into one item.
+	DedupMerged DedupDecision = "merged"
+	// DedupKeptHighest indicates duplicates were dropped in favor of the highest score.
+	DedupKeptHighest DedupDecision = "kept_highest"
+)
+
 // Result contains the complete retrieval response.
 type Result struct {
 	// Items are the retrieved context items, ordered by relevance.
@@ -93,6 +158,36 @@ type Result struct {
 	Query Query
 	// Metadata contains response metadata.
 	Metadata ResultMetadata
+	// Debug contains diagnostic details, populated only when Query.Explain is set.
+	Debug *Debug
+}
+
+// Debug describes how a retrieval was executed, for tuning pipelines.
+type Debug struct {
+	// Filters are the filters that were applied to the query.
+	Filters map[string]string
+	// MinScore is the effective minimum score threshold.
+	MinScore float64
+	// Stages records candidate counts for each pipeline stage, in execution order.
+	Stages []StageDebug
+	// DroppedByMinScore is the number of candidates removed by the MinScore threshold.
+	DroppedByMinScore int
+	// RerankDeltas maps item IDs to the score change produced by reranking.
+	RerankDeltas map[string]float64
+}
+
+// StageDebug records what happened in a single retrieval stage.
+type StageDebug struct {
+	// Name identifies the stage (e.g., "vector.search", "hybrid.merge").
+	Name string
+	// Backend identifies the backend used by the stage, if any.
+	Backend string
+	// Candidates is the number of items entering or produced by the stage.
+	Candidates int
+	// Returned is the number of items the stage passed on.
+	Returned int
+	// LatencyMS is the stage latency in milliseconds.
+	LatencyMS int64
 }
 
 // ResultMetadata contains metadata about the retrieval operation.
@@ -105,6 +200,34 @@ type ResultMetadata struct {
 	ModesUsed []Mode
 	// CacheHit indicates if results came from cache.
 	CacheHit bool
+	// PartialResult indicates a best-effort retriever returned what it had
+	// gathered when the deadline approached, skipping slower stages, or
+	// skipped failed branches.
+	PartialResult bool
+	// BranchErrors lists the branches of a composite retriever that failed
+	// without failing the retrieval.
+	BranchErrors []BranchError
+	// AnsweredBy is the mode of the last stage a cascading retriever ran,
+	// i.e. the cheap stage if it answered confidently on its own.
+	AnsweredBy Mode
+}
+
+// BranchError is the error of a failed branch of a composite retriever.
+type BranchError struct {
+	// Branch names the branch.
+	Branch string
+	// Err is the error the branch returned.
+	Err error
+}
+
+// Error implements error.
+func (e BranchError) Error() string {
+	return e.Branch + ": " + e.Err.Error()
+}
+
+// Unwrap returns the branch error.
+func (e BranchError) Unwrap() error {
+	return e.Err
 }
 
 // Retriever is the core interface for all retrieval operations.
@@ -122,6 +245,29 @@ func (f RetrieverFunc) Retrieve(ctx context.Context, q Query) (*Result, error) {
 	return f(ctx, q)
 }
 
+// Warmer is implemented by retrievers and backends that can prepare
+// themselves before serving queries, e.g. by opening connections,
+// preparing statements, priming caches, or validating configuration.
+type Warmer interface {
+	// Warmup prepares the component for use and reports configuration problems.
+	Warmup(ctx context.Context) error
+}
+
+// Warmup calls Warmup on every component that implements Warmer.
+// Components that do not implement Warmer are skipped. All components are
+// warmed even if one fails; the returned error joins every failure.
+func Warmup(ctx context.Context, components ...any) error {
+	var errs []error
+	for _, c := range components {
+		if w, ok := c.(Warmer); ok {
+			if err := w.Warmup(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Option configures a retrieval operation.
 type Option func(*Options)
 
@@ -162,3 +308,27 @@ type Observer interface {
 	// OnRerank is called during reranking.
 	OnRerank(ctx context.Context, model string, inputCount int, outputCount int, latencyMS int64)
 }
+
+// KeywordObserver is an optional Observer extension that receives keyword
+// search events.
+type KeywordObserver interface {
+	// OnKeywordSearch is called during keyword search.
+	OnKeywordSearch(ctx context.Context, backend string, topK int, resultCount int, latencyMS int64)
+}
+
+// EmbeddingCacheObserver is an optional Observer extension that receives
+// query embedding cache hits and misses.
+type EmbeddingCacheObserver interface {
+	// OnEmbeddingCache is called for each cache lookup of a query embedding
+	// created by model.
+	OnEmbeddingCache(ctx context.Context, model string, hit bool)
+}
+
+// MergeObserver is an optional Observer extension that receives the merge
+// step of composite retrievers.
+type MergeObserver interface {
+	// OnHybridMerge is called after branch results are fused. branchCounts
+	// maps each branch to the number of items it contributed, and dedupCount
+	// is the number of duplicate hits combined or removed.
+	OnHybridMerge(ctx context.Context, fusion string, branchCounts map[string]int, dedupCount int, outputCount int, latencyMS int64)
+}
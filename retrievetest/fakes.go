@@ -0,0 +1,322 @@
+package retrievetest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/agentplexus/omniretrieve/graph"
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// Retriever is a fake retrieve.Retriever.
+type Retriever struct {
+	Fake
+	// Results are returned by successive Retrieve calls. Once exhausted, the
+	// last result is repeated. If empty, an empty result is returned.
+	Results []*retrieve.Result
+}
+
+// Retrieve implements retrieve.Retriever.
+func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
+	n, err := r.record(MethodRetrieve, q)
+	if err != nil {
+		return nil, err
+	}
+	if res := scripted(r.Results, n); res != nil {
+		return res, nil
+	}
+	return &retrieve.Result{Items: []retrieve.ContextItem{}, Query: q}, nil
+}
+
+// Index is a fake vector.BatchIndex. Writes are recorded but not stored.
+type Index struct {
+	Fake
+	// IndexName is returned by Name.
+	IndexName string
+	// SearchResults are returned by successive Search calls. Once exhausted,
+	// the last response is repeated. Results are truncated to k.
+	SearchResults [][]vector.SearchResult
+}
+
+// Search implements vector.Index.
+func (idx *Index) Search(ctx context.Context, embedding []float32, k int, filters map[string]string) ([]vector.SearchResult, error) {
+	n, err := idx.record(MethodSearch, embedding, k, filters)
+	if err != nil {
+		return nil, err
+	}
+	results := scripted(idx.SearchResults, n)
+	if k >= 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Insert implements vector.Index.
+func (idx *Index) Insert(ctx context.Context, node vector.Node) error {
+	_, err := idx.record(MethodInsert, node)
+	return err
+}
+
+// Upsert implements vector.Index.
+func (idx *Index) Upsert(ctx context.Context, node vector.Node) error {
+	_, err := idx.record(MethodUpsert, node)
+	return err
+}
+
+// Delete implements vector.Index.
+func (idx *Index) Delete(ctx context.Context, id string) error {
+	_, err := idx.record(MethodDelete, id)
+	return err
+}
+
+// Name implements vector.Index.
+func (idx *Index) Name() string {
+	return idx.IndexName
+}
+
+// InsertBatch implements vector.BatchIndex.
+func (idx *Index) InsertBatch(ctx context.Context, nodes []vector.Node) error {
+	_, err := idx.record(MethodInsertBatch, nodes)
+	return err
+}
+
+// UpsertBatch implements vector.BatchIndex.
+func (idx *Index) UpsertBatch(ctx context.Context, nodes []vector.Node) error {
+	_, err := idx.record(MethodUpsertBatch, nodes)
+	return err
+}
+
+// DeleteBatch implements vector.BatchIndex.
+func (idx *Index) DeleteBatch(ctx context.Context, ids []string) error {
+	_, err := idx.record(MethodDeleteBatch, ids)
+	return err
+}
+
+// Embedder is a fake vector.Embedder.
+type Embedder struct {
+	Fake
+	// Embeddings maps texts to the embeddings returned for them.
+	Embeddings map[string][]float32
+	// Dimensions is the size of the zero vector returned for unknown texts.
+	Dimensions int
+	// ModelName is returned by Model.
+	ModelName string
+}
+
+// Embed implements vector.Embedder.
+func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if _, err := e.record(MethodEmbed, text); err != nil {
+		return nil, err
+	}
+	return e.lookup(text), nil
+}
+
+// EmbedBatch implements vector.Embedder.
+func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if _, err := e.record(MethodEmbedBatch, texts); err != nil {
+		return nil, err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = e.lookup(text)
+	}
+	return embeddings, nil
+}
+
+// Model implements vector.Embedder.
+func (e *Embedder) Model() string {
+	return e.ModelName
+}
+
+// lookup returns the configured embedding for text or a zero vector.
+func (e *Embedder) lookup(text string) []float32 {
+	if emb, ok := e.Embeddings[text]; ok {
+		return emb
+	}
+	return make([]float32, e.Dimensions)
+}
+
+// KnowledgeGraph is a fake graph.BatchKnowledgeGraph. Writes are recorded
+// but not stored.
+type KnowledgeGraph struct {
+	Fake
+	// GraphName is returned by Name.
+	GraphName string
+	// Traversals are returned by successive Traverse calls. Once exhausted,
+	// the last result is repeated. If empty, an empty result is returned.
+	Traversals []*graph.TraversalResult
+	// FoundNodes are returned by successive FindNodes calls. Once exhausted,
+	// the last response is repeated.
+	FoundNodes [][]graph.Node
+	// Nodes are returned by GetNode. Other IDs return graph.ErrNodeNotFound.
+	Nodes map[string]graph.Node
+	// NodeEdges are returned by successive GetEdges calls. Once exhausted,
+	// the last response is repeated.
+	NodeEdges [][]graph.Edge
+	// NeighborCounts are returned by successive NeighborCount calls. Once
+	// exhausted, the last count is repeated.
+	NeighborCounts []int
+}
+
+// Traverse implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) Traverse(ctx context.Context, startNodes []string, opts graph.TraversalOptions) (*graph.TraversalResult, error) {
+	n, err := kg.record(MethodTraverse, startNodes, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res := scripted(kg.Traversals, n); res != nil {
+		return res, nil
+	}
+	return &graph.TraversalResult{Paths: map[string][]string{}}, nil
+}
+
+// FindNodes implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) FindNodes(ctx context.Context, nodeType string, filters map[string]string) ([]graph.Node, error) {
+	n, err := kg.record(MethodFindNodes, nodeType, filters)
+	if err != nil {
+		return nil, err
+	}
+	return scripted(kg.FoundNodes, n), nil
+}
+
+// GetNode implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) GetNode(ctx context.Context, id string) (graph.Node, error) {
+	if _, err := kg.record(MethodGetNode, id); err != nil {
+		return graph.Node{}, err
+	}
+	node, ok := kg.Nodes[id]
+	if !ok {
+		return graph.Node{}, graph.ErrNodeNotFound
+	}
+	return node, nil
+}
+
+// GetEdges implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) GetEdges(ctx context.Context, nodeID string, direction graph.Direction) ([]graph.Edge, error) {
+	n, err := kg.record(MethodGetEdges, nodeID, direction)
+	if err != nil {
+		return nil, err
+	}
+	return scripted(kg.NodeEdges, n), nil
+}
+
+// NeighborCount implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) NeighborCount(ctx context.Context, nodeID string, direction graph.Direction) (int, error) {
+	n, err := kg.record(MethodNeighborCount, nodeID, direction)
+	if err != nil {
+		return 0, err
+	}
+	return scripted(kg.NeighborCounts, n), nil
+}
+
+// AddNode implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) AddNode(ctx context.Context, node graph.Node) error {
+	_, err := kg.record(MethodAddNode, node)
+	return err
+}
+
+// UpsertNode implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) UpsertNode(ctx context.Context, node graph.Node) error {
+	_, err := kg.record(MethodUpsertNode, node)
+	return err
+}
+
+// AddEdge implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) AddEdge(ctx context.Context, edge graph.Edge) error {
+	_, err := kg.record(MethodAddEdge, edge)
+	return err
+}
+
+// UpsertEdge implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) UpsertEdge(ctx context.Context, edge graph.Edge) error {
+	_, err := kg.record(MethodUpsertEdge, edge)
+	return err
+}
+
+// DeleteNode implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) DeleteNode(ctx context.Context, id string) error {
+	_, err := kg.record(MethodDeleteNode, id)
+	return err
+}
+
+// DeleteEdge implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) DeleteEdge(ctx context.Context, from, to, edgeType string) error {
+	_, err := kg.record(MethodDeleteEdge, from, to, edgeType)
+	return err
+}
+
+// Name implements graph.KnowledgeGraph.
+func (kg *KnowledgeGraph) Name() string {
+	return kg.GraphName
+}
+
+// AddNodeBatch implements graph.BatchKnowledgeGraph.
+func (kg *KnowledgeGraph) AddNodeBatch(ctx context.Context, nodes []graph.Node) error {
+	_, err := kg.record(MethodAddNodeBatch, nodes)
+	return err
+}
+
+// UpsertNodeBatch implements graph.BatchKnowledgeGraph.
+func (kg *KnowledgeGraph) UpsertNodeBatch(ctx context.Context, nodes []graph.Node) error {
+	_, err := kg.record(MethodUpsertNodeBatch, nodes)
+	return err
+}
+
+// AddEdgeBatch implements graph.BatchKnowledgeGraph.
+func (kg *KnowledgeGraph) AddEdgeBatch(ctx context.Context, edges []graph.Edge) error {
+	_, err := kg.record(MethodAddEdgeBatch, edges)
+	return err
+}
+
+// UpsertEdgeBatch implements graph.BatchKnowledgeGraph.
+func (kg *KnowledgeGraph) UpsertEdgeBatch(ctx context.Context, edges []graph.Edge) error {
+	_, err := kg.record(MethodUpsertEdgeBatch, edges)
+	return err
+}
+
+// DeleteNodeBatch implements graph.BatchKnowledgeGraph.
+func (kg *KnowledgeGraph) DeleteNodeBatch(ctx context.Context, ids []string) error {
+	_, err := kg.record(MethodDeleteNodeBatch, ids)
+	return err
+}
+
+// Reranker is a fake retrieve.Reranker. By default it returns items unchanged.
+type Reranker struct {
+	Fake
+	// Scores maps item IDs to replacement scores. When set, matching items
+	// are rescored and the result is sorted by score descending.
+	Scores map[string]float64
+}
+
+// Rerank implements retrieve.Reranker.
+func (r *Reranker) Rerank(ctx context.Context, q retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if _, err := r.record(MethodRerank, q, items); err != nil {
+		return nil, err
+	}
+	if len(r.Scores) == 0 {
+		return items, nil
+	}
+
+	result := make([]retrieve.ContextItem, len(items))
+	copy(result, items)
+	for i := range result {
+		if score, ok := r.Scores[result[i].ID]; ok {
+			result[i].Score = score
+			result[i].Provenance.RerankerScore = score
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result, nil
+}
+
+// Verify interface compliance
+var (
+	_ retrieve.Retriever        = (*Retriever)(nil)
+	_ vector.BatchIndex         = (*Index)(nil)
+	_ vector.Embedder           = (*Embedder)(nil)
+	_ graph.BatchKnowledgeGraph = (*KnowledgeGraph)(nil)
+	_ retrieve.Reranker         = (*Reranker)(nil)
+)
@@ -0,0 +1,136 @@
+// Package retrievetest provides configurable fakes of the OmniRetrieve
+// interfaces for unit testing applications built on top of them.
+//
+// Each fake returns scripted responses, can be told to fail with an injected
+// error, and records every call so tests can assert on how it was used.
+// Unlike the memory package, the fakes implement no real retrieval behavior.
+package retrievetest
+
+import (
+	"sync"
+)
+
+// Method names used for error injection and call inspection.
+const (
+	MethodRetrieve        = "Retrieve"
+	MethodSearch          = "Search"
+	MethodInsert          = "Insert"
+	MethodUpsert          = "Upsert"
+	MethodDelete          = "Delete"
+	MethodInsertBatch     = "InsertBatch"
+	MethodUpsertBatch     = "UpsertBatch"
+	MethodDeleteBatch     = "DeleteBatch"
+	MethodEmbed           = "Embed"
+	MethodEmbedBatch      = "EmbedBatch"
+	MethodTraverse        = "Traverse"
+	MethodFindNodes       = "FindNodes"
+	MethodGetNode         = "GetNode"
+	MethodGetEdges        = "GetEdges"
+	MethodNeighborCount   = "NeighborCount"
+	MethodAddNode         = "AddNode"
+	MethodUpsertNode      = "UpsertNode"
+	MethodAddEdge         = "AddEdge"
+	MethodUpsertEdge      = "UpsertEdge"
+	MethodDeleteNode      = "DeleteNode"
+	MethodDeleteEdge      = "DeleteEdge"
+	MethodAddNodeBatch    = "AddNodeBatch"
+	MethodUpsertNodeBatch = "UpsertNodeBatch"
+	MethodAddEdgeBatch    = "AddEdgeBatch"
+	MethodUpsertEdgeBatch = "UpsertEdgeBatch"
+	MethodDeleteNodeBatch = "DeleteNodeBatch"
+	MethodRerank          = "Rerank"
+)
+
+// Call records a single invocation of a fake.
+type Call struct {
+	// Method is the name of the invoked method.
+	Method string
+	// Args are the arguments passed to the method, excluding the context.
+	Args []any
+}
+
+// Fake holds the state shared by all fakes: injected errors and recorded calls.
+// It is embedded in every fake and is safe for concurrent use.
+type Fake struct {
+	mu     sync.Mutex
+	errors map[string]error
+	calls  []Call
+}
+
+// FailWith makes subsequent calls to method return err.
+// Passing a nil error clears the injected failure.
+func (f *Fake) FailWith(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.errors == nil {
+		f.errors = make(map[string]error)
+	}
+	if err == nil {
+		delete(f.errors, method)
+		return
+	}
+	f.errors[method] = err
+}
+
+// Calls returns all recorded calls in invocation order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// CallsTo returns the recorded calls to the given method.
+func (f *Fake) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, c := range f.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// CallCount returns the number of recorded calls to the given method.
+func (f *Fake) CallCount(method string) int {
+	return len(f.CallsTo(method))
+}
+
+// Reset clears recorded calls and injected errors.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+	f.errors = nil
+}
+
+// record records a call and returns its zero-based sequence number among
+// calls to the same method, along with any injected error.
+func (f *Fake) record(method string, args ...any) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	return n, f.errors[method]
+}
+
+// scripted returns the n-th scripted response, repeating the last one once
+// the script is exhausted. It returns the zero value for an empty script.
+func scripted[T any](script []T, n int) T {
+	var zero T
+	if len(script) == 0 {
+		return zero
+	}
+	if n >= len(script) {
+		n = len(script) - 1
+	}
+	return script[n]
+}
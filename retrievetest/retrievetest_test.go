@@ -0,0 +1,108 @@
+package retrievetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/hybrid"
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+func TestRetrieverScriptedResults(t *testing.T) {
+	ctx := context.Background()
+
+	fake := &retrievetest.Retriever{
+		Results: []*retrieve.Result{
+			{Items: []retrieve.ContextItem{{ID: "first"}}},
+			{Items: []retrieve.ContextItem{{ID: "second"}}},
+		},
+	}
+
+	for _, want := range []string{"first", "second", "second"} {
+		res, err := fake.Retrieve(ctx, retrieve.Query{Text: "q"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Items[0].ID != want {
+			t.Errorf("expected %s, got %s", want, res.Items[0].ID)
+		}
+	}
+
+	if fake.CallCount(retrievetest.MethodRetrieve) != 3 {
+		t.Errorf("expected 3 calls, got %d", fake.CallCount(retrievetest.MethodRetrieve))
+	}
+	if q := fake.Calls()[0].Args[0].(retrieve.Query); q.Text != "q" {
+		t.Errorf("expected recorded query text q, got %s", q.Text)
+	}
+}
+
+func TestInjectedErrors(t *testing.T) {
+	ctx := context.Background()
+	errBackend := errors.New("backend down")
+
+	idx := &retrievetest.Index{IndexName: "fake"}
+	idx.FailWith(retrievetest.MethodSearch, errBackend)
+
+	if _, err := idx.Search(ctx, nil, 10, nil); !errors.Is(err, errBackend) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+
+	idx.FailWith(retrievetest.MethodSearch, nil)
+	if _, err := idx.Search(ctx, nil, 10, nil); err != nil {
+		t.Errorf("expected no error after clearing injection, got %v", err)
+	}
+}
+
+func TestFakesWithRetrievers(t *testing.T) {
+	ctx := context.Background()
+
+	idx := &retrievetest.Index{
+		IndexName: "fake-index",
+		SearchResults: [][]vector.SearchResult{{
+			{Node: vector.Node{ID: "a", Content: "alpha"}, Score: 0.9},
+			{Node: vector.Node{ID: "b", Content: "beta"}, Score: 0.8},
+		}},
+	}
+	embedder := &retrievetest.Embedder{
+		Embeddings: map[string][]float32{"hello": {1, 0}},
+		ModelName:  "fake-model",
+	}
+	reranker := &retrievetest.Reranker{Scores: map[string]float64{"b": 1.0}}
+
+	retriever := hybrid.NewRetriever(hybrid.RetrieverConfig{
+		Vector: vector.NewRetriever(vector.RetrieverConfig{
+			Index:    idx,
+			Embedder: embedder,
+		}),
+		Reranker: reranker,
+	})
+
+	res, err := retriever.Retrieve(ctx, retrieve.Query{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	if len(res.Items) != 2 || res.Items[0].ID != "b" {
+		t.Fatalf("expected reranked item b first, got %+v", res.Items)
+	}
+
+	embedCalls := embedder.CallsTo(retrievetest.MethodEmbed)
+	if len(embedCalls) != 1 || embedCalls[0].Args[0] != "hello" {
+		t.Errorf("unexpected embed calls: %+v", embedCalls)
+	}
+
+	searchCalls := idx.CallsTo(retrievetest.MethodSearch)
+	if len(searchCalls) != 1 {
+		t.Fatalf("expected 1 search call, got %d", len(searchCalls))
+	}
+	if emb := searchCalls[0].Args[0].([]float32); len(emb) != 2 || emb[0] != 1 {
+		t.Errorf("expected search with scripted embedding, got %v", emb)
+	}
+
+	if reranker.CallCount(retrievetest.MethodRerank) != 1 {
+		t.Errorf("expected 1 rerank call, got %d", reranker.CallCount(retrievetest.MethodRerank))
+	}
+}
@@ -0,0 +1,247 @@
+// Package serve exposes retrievers over HTTP using the OpenAI vector store
+// search wire format, so agent frameworks built for OpenAI file_search can
+// point at an OmniRetrieve server without adapters.
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// maxResultsLimit is the largest max_num_results accepted, matching OpenAI.
+const maxResultsLimit = 50
+
+// HandlerConfig configures the HTTP handler.
+type HandlerConfig struct {
+	// Stores maps vector store IDs to the retrievers that serve them.
+	Stores map[string]retrieve.Retriever
+	// DefaultMaxResults is used when a request omits max_num_results (default 10).
+	DefaultMaxResults int
+}
+
+// Handler serves POST /v1/vector_stores/{vector_store_id}/search.
+type Handler struct {
+	config HandlerConfig
+	mux    *http.ServeMux
+}
+
+// NewHandler creates a new HTTP handler.
+func NewHandler(cfg HandlerConfig) *Handler {
+	if cfg.DefaultMaxResults == 0 {
+		cfg.DefaultMaxResults = 10
+	}
+	h := &Handler{config: cfg, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /v1/vector_stores/{vector_store_id}/search", h.search)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// SearchRequest is the body of a vector store search request.
+type SearchRequest struct {
+	// Query is a string or an array of strings.
+	Query          json.RawMessage `json:"query"`
+	MaxNumResults  int             `json:"max_num_results,omitempty"`
+	Filters        *Filter         `json:"filters,omitempty"`
+	RankingOptions *RankingOptions `json:"ranking_options,omitempty"`
+	RewriteQuery   bool            `json:"rewrite_query,omitempty"`
+}
+
+// Filter is a comparison ("eq", "ne", ...) or compound ("and", "or") filter.
+type Filter struct {
+	Type    string   `json:"type"`
+	Key     string   `json:"key,omitempty"`
+	Value   any      `json:"value,omitempty"`
+	Filters []Filter `json:"filters,omitempty"`
+}
+
+// RankingOptions controls ranking of search results.
+type RankingOptions struct {
+	Ranker         string  `json:"ranker,omitempty"`
+	ScoreThreshold float64 `json:"score_threshold,omitempty"`
+}
+
+// SearchResponse is a page of vector store search results.
+type SearchResponse struct {
+	Object      string         `json:"object"`
+	SearchQuery string         `json:"search_query"`
+	Data        []SearchResult `json:"data"`
+	HasMore     bool           `json:"has_more"`
+	NextPage    *string        `json:"next_page"`
+}
+
+// SearchResult is a single search hit.
+type SearchResult struct {
+	FileID     string            `json:"file_id"`
+	Filename   string            `json:"filename"`
+	Score      float64           `json:"score"`
+	Attributes map[string]string `json:"attributes"`
+	Content    []Content         `json:"content"`
+}
+
+// Content is a piece of result content.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// errorResponse is the OpenAI error envelope.
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
+
+// search handles a vector store search request.
+func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
+	storeID := r.PathValue("vector_store_id")
+	retriever, ok := h.config.Stores[storeID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No vector store found with id '%s'.", storeID))
+		return
+	}
+
+	var req SearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	q, err := h.toQuery(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := retriever.Retrieve(r.Context(), q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Search failed: %v", err))
+		return
+	}
+
+	resp := SearchResponse{
+		Object:      "vector_store.search_results.page",
+		SearchQuery: q.Text,
+		Data:        make([]SearchResult, 0, len(res.Items)),
+	}
+	for _, item := range res.Items {
+		resp.Data = append(resp.Data, toSearchResult(item))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// toQuery converts a search request into a retrieval query.
+func (h *Handler) toQuery(req SearchRequest) (retrieve.Query, error) {
+	text, err := parseQuery(req.Query)
+	if err != nil {
+		return retrieve.Query{}, err
+	}
+
+	maxResults := req.MaxNumResults
+	if maxResults == 0 {
+		maxResults = h.config.DefaultMaxResults
+	}
+	if maxResults < 1 || maxResults > maxResultsLimit {
+		return retrieve.Query{}, fmt.Errorf("max_num_results must be between 1 and %d", maxResultsLimit)
+	}
+
+	q := retrieve.Query{Text: text, TopK: maxResults}
+	if req.Filters != nil {
+		q.Filters = make(map[string]string)
+		if err := collectFilters(*req.Filters, q.Filters); err != nil {
+			return retrieve.Query{}, err
+		}
+	}
+	if req.RankingOptions != nil {
+		q.MinScore = req.RankingOptions.ScoreThreshold
+	}
+	return q, nil
+}
+
+// parseQuery accepts a string or an array of strings, joining the latter.
+func parseQuery(raw json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return "", errors.New("query must not be empty")
+		}
+		return text, nil
+	}
+
+	var parts []string
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) == 0 {
+		return "", errors.New("query must be a string or a non-empty array of strings")
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// collectFilters maps "eq" comparisons, optionally combined with "and",
+// onto exact-match query filters. Other filter types are rejected.
+func collectFilters(f Filter, filters map[string]string) error {
+	switch f.Type {
+	case "eq":
+		if f.Key == "" {
+			return errors.New("filters: eq requires a key")
+		}
+		filters[f.Key] = fmt.Sprint(f.Value)
+		return nil
+	case "and":
+		for _, sub := range f.Filters {
+			if err := collectFilters(sub, filters); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("filters: unsupported filter type %q", f.Type)
+	}
+}
+
+// toSearchResult converts a context item to a search hit. The file ID is
+// taken from the "file_id" metadata key when present, otherwise the item ID.
+func toSearchResult(item retrieve.ContextItem) SearchResult {
+	fileID := item.Metadata["file_id"]
+	if fileID == "" {
+		fileID = item.ID
+	}
+	attributes := item.Metadata
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	return SearchResult{
+		FileID:     fileID,
+		Filename:   item.Source,
+		Score:      item.Score,
+		Attributes: attributes,
+		Content:    []Content{{Type: "text", Text: item.Content}},
+	}
+}
+
+// writeError writes an OpenAI-style error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	errType := "invalid_request_error"
+	if status >= http.StatusInternalServerError {
+		errType = "server_error"
+	}
+	writeJSON(w, status, errorResponse{Error: apiError{Message: message, Type: errType}})
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,115 @@
+package serve_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
+	"github.com/agentplexus/omniretrieve/serve"
+)
+
+func TestSearch(t *testing.T) {
+	retriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{
+			{ID: "chunk-1", Content: "Go is statically typed", Source: "go.md", Score: 0.9, Metadata: map[string]string{"file_id": "file-1", "lang": "en"}},
+			{ID: "chunk-2", Content: "Rust is memory safe", Source: "rust.md", Score: 0.7},
+		},
+	}}}
+	server := httptest.NewServer(serve.NewHandler(serve.HandlerConfig{
+		Stores: map[string]retrieve.Retriever{"vs_docs": retriever},
+	}))
+	defer server.Close()
+
+	body := `{
+		"query": "typed languages",
+		"max_num_results": 5,
+		"filters": {"type": "and", "filters": [{"type": "eq", "key": "lang", "value": "en"}]},
+		"ranking_options": {"score_threshold": 0.5}
+	}`
+	resp, err := http.Post(server.URL+"/v1/vector_stores/vs_docs/search", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var page serve.SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if page.Object != "vector_store.search_results.page" || page.SearchQuery != "typed languages" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+	if len(page.Data) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(page.Data))
+	}
+	first := page.Data[0]
+	if first.FileID != "file-1" || first.Filename != "go.md" || first.Score != 0.9 || first.Content[0].Text != "Go is statically typed" {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	if page.Data[1].FileID != "chunk-2" {
+		t.Errorf("expected item ID as file ID fallback, got %s", page.Data[1].FileID)
+	}
+
+	q := retriever.CallsTo(retrievetest.MethodRetrieve)[0].Args[0].(retrieve.Query)
+	if q.TopK != 5 || q.MinScore != 0.5 || q.Filters["lang"] != "en" {
+		t.Errorf("unexpected query: %+v", q)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	failing := &retrievetest.Retriever{}
+	failing.FailWith(retrievetest.MethodRetrieve, errors.New("backend down"))
+
+	server := httptest.NewServer(serve.NewHandler(serve.HandlerConfig{
+		Stores: map[string]retrieve.Retriever{
+			"vs_docs":    &retrievetest.Retriever{},
+			"vs_failing": failing,
+		},
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		store  string
+		body   string
+		status int
+	}{
+		{name: "unknown store", store: "vs_missing", body: `{"query": "q"}`, status: http.StatusNotFound},
+		{name: "empty query", store: "vs_docs", body: `{"query": ""}`, status: http.StatusBadRequest},
+		{name: "too many results", store: "vs_docs", body: `{"query": "q", "max_num_results": 100}`, status: http.StatusBadRequest},
+		{name: "unsupported filter", store: "vs_docs", body: `{"query": "q", "filters": {"type": "gt", "key": "n", "value": 1}}`, status: http.StatusBadRequest},
+		{name: "retriever error", store: "vs_failing", body: `{"query": ["a", "b"]}`, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/v1/vector_stores/"+tt.store+"/search", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+			var errResp struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error.Message == "" {
+				t.Errorf("expected OpenAI error envelope, got %v", err)
+			}
+		})
+	}
+}
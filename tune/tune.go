@@ -0,0 +1,339 @@
+// Package tune searches hybrid retrieval parameters against labeled examples
+// or recorded feedback and recommends a configuration.
+package tune
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/agentplexus/omniretrieve/hybrid"
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Example is a query with graded relevance judgments.
+type Example struct {
+	// Query is the query to evaluate.
+	Query retrieve.Query
+	// Relevant maps item IDs to graded relevance (values <= 0 are irrelevant).
+	Relevant map[string]float64
+}
+
+// ExamplesFromFeedback groups recorded feedback by QueryID into examples,
+// in first-seen order. The first recorded query of each group is used.
+func ExamplesFromFeedback(feedback []retrieve.Feedback) []Example {
+	var examples []Example
+	index := make(map[string]int)
+	for _, fb := range feedback {
+		i, ok := index[fb.QueryID]
+		if !ok {
+			i = len(examples)
+			index[fb.QueryID] = i
+			examples = append(examples, Example{Query: fb.Query, Relevant: make(map[string]float64)})
+		}
+		examples[i].Relevant[fb.Item.ID] = fb.Relevance
+	}
+	return examples
+}
+
+// Metric scores a ranked list of item IDs against relevance judgments,
+// considering the first k items. Higher is better.
+type Metric func(ranked []string, relevant map[string]float64, k int) float64
+
+// NDCG is normalized discounted cumulative gain with graded relevance.
+func NDCG(ranked []string, relevant map[string]float64, k int) float64 {
+	dcg := 0.0
+	for i, id := range truncate(ranked, k) {
+		if rel := relevant[id]; rel > 0 {
+			dcg += (math.Pow(2, rel) - 1) / math.Log2(float64(i+2))
+		}
+	}
+
+	ideal := make([]float64, 0, len(relevant))
+	for _, rel := range relevant {
+		if rel > 0 {
+			ideal = append(ideal, rel)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+	idcg := 0.0
+	for i, rel := range ideal {
+		if k > 0 && i >= k {
+			break
+		}
+		idcg += (math.Pow(2, rel) - 1) / math.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// Recall is the fraction of relevant items found in the first k results.
+func Recall(ranked []string, relevant map[string]float64, k int) float64 {
+	total := 0
+	for _, rel := range relevant {
+		if rel > 0 {
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	found := 0
+	for _, id := range truncate(ranked, k) {
+		if relevant[id] > 0 {
+			found++
+		}
+	}
+	return float64(found) / float64(total)
+}
+
+// MRR is the reciprocal rank of the first relevant item in the first k results.
+func MRR(ranked []string, relevant map[string]float64, k int) float64 {
+	for i, id := range truncate(ranked, k) {
+		if relevant[id] > 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// truncate returns the first k IDs, or all IDs when k <= 0.
+func truncate(ranked []string, k int) []string {
+	if k > 0 && len(ranked) > k {
+		return ranked[:k]
+	}
+	return ranked
+}
+
+// Space defines the parameter grid to search.
+type Space struct {
+	// Alphas are hybrid alpha values to try (default 0, 0.25, 0.5, 0.75, 1).
+	Alphas []float64
+	// MinScores are query MinScore values to try (default 0).
+	MinScores []float64
+	// RerankerParams maps reranker parameter names to values to try. Every
+	// combination is passed to Config.NewReranker.
+	RerankerParams map[string][]float64
+}
+
+// Config configures a Tuner.
+type Config struct {
+	// Base is the hybrid configuration to tune. Its Vector, Graph, Keyword
+	// and Branches retrievers are queried once per distinct branch query and
+	// reused across trials.
+	Base hybrid.RetrieverConfig
+	// NewReranker builds a reranker from a combination of RerankerParams.
+	// Required when Space.RerankerParams is set.
+	NewReranker func(params map[string]float64) retrieve.Reranker
+	// Space is the parameter grid.
+	Space Space
+	// Metric is the objective to maximize (default NDCG).
+	Metric Metric
+	// K is the cutoff for the metric and the query TopK (default 10).
+	K int
+}
+
+// Trial is the outcome of evaluating one parameter combination.
+type Trial struct {
+	Alpha          float64            `json:"alpha"`
+	MinScore       float64            `json:"min_score"`
+	RerankerParams map[string]float64 `json:"reranker_params,omitempty"`
+	// Score is the metric averaged over all examples.
+	Score float64 `json:"score"`
+}
+
+// Recommendation is the best configuration found by a Tuner.
+type Recommendation struct {
+	// Best is the highest-scoring trial. Ties keep the earliest trial in
+	// grid order.
+	Best Trial `json:"best"`
+	// Weights are the hybrid weights derived from Best.Alpha.
+	Weights hybrid.Weights `json:"weights"`
+	// Trials lists every evaluated combination in grid order.
+	Trials []Trial `json:"trials"`
+}
+
+// JSON returns the recommendation as indented JSON.
+func (r *Recommendation) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Tuner grid-searches hybrid retrieval parameters.
+type Tuner struct {
+	config Config
+}
+
+// New creates a new tuner.
+func New(cfg Config) *Tuner {
+	if len(cfg.Space.Alphas) == 0 {
+		cfg.Space.Alphas = []float64{0, 0.25, 0.5, 0.75, 1}
+	}
+	if len(cfg.Space.MinScores) == 0 {
+		cfg.Space.MinScores = []float64{0}
+	}
+	if cfg.Metric == nil {
+		cfg.Metric = NDCG
+	}
+	if cfg.K == 0 {
+		cfg.K = 10
+	}
+	return &Tuner{config: cfg}
+}
+
+// Tune evaluates every parameter combination against the examples and
+// returns the best one.
+func (t *Tuner) Tune(ctx context.Context, examples []Example) (*Recommendation, error) {
+	if len(examples) == 0 {
+		return nil, errors.New("tune: at least one example is required")
+	}
+	if len(t.config.Space.RerankerParams) > 0 && t.config.NewReranker == nil {
+		return nil, errors.New("tune: NewReranker is required when tuning reranker params")
+	}
+
+	// Branch results don't depend on the merge weights, so reuse them.
+	base := t.config.Base
+	if base.Vector != nil {
+		base.Vector = newMemo(base.Vector)
+	}
+	if base.Graph != nil {
+		base.Graph = newMemo(base.Graph)
+	}
+	if base.Keyword != nil {
+		base.Keyword = newMemo(base.Keyword)
+	}
+	base.Branches = slices.Clone(base.Branches)
+	for i := range base.Branches {
+		if base.Branches[i].Retriever != nil {
+			base.Branches[i].Retriever = newMemo(base.Branches[i].Retriever)
+		}
+	}
+
+	rec := &Recommendation{}
+	bestIndex := -1
+	for _, params := range paramGrid(t.config.Space.RerankerParams) {
+		for _, alpha := range t.config.Space.Alphas {
+			for _, minScore := range t.config.Space.MinScores {
+				cfg := base
+				cfg.Alpha = hybrid.Alpha(alpha)
+				if params != nil {
+					cfg.Reranker = t.config.NewReranker(params)
+				}
+				score, err := t.evaluate(ctx, hybrid.NewRetriever(cfg), examples, minScore)
+				if err != nil {
+					return nil, fmt.Errorf("failed to evaluate alpha=%g min_score=%g: %w", alpha, minScore, err)
+				}
+
+				rec.Trials = append(rec.Trials, Trial{
+					Alpha:          alpha,
+					MinScore:       minScore,
+					RerankerParams: params,
+					Score:          score,
+				})
+				if bestIndex < 0 || score > rec.Trials[bestIndex].Score {
+					bestIndex = len(rec.Trials) - 1
+				}
+			}
+		}
+	}
+
+	rec.Best = rec.Trials[bestIndex]
+	rec.Weights = hybrid.WeightsFromAlpha(rec.Best.Alpha)
+	return rec, nil
+}
+
+// evaluate returns the mean metric over the examples.
+func (t *Tuner) evaluate(ctx context.Context, r retrieve.Retriever, examples []Example, minScore float64) (float64, error) {
+	total := 0.0
+	for _, ex := range examples {
+		q := ex.Query
+		q.TopK = t.config.K
+		q.MinScore = minScore
+
+		res, err := r.Retrieve(ctx, q)
+		if err != nil {
+			return 0, err
+		}
+		ranked := make([]string, len(res.Items))
+		for i, item := range res.Items {
+			ranked[i] = item.ID
+		}
+		total += t.config.Metric(ranked, ex.Relevant, t.config.K)
+	}
+	return total / float64(len(examples)), nil
+}
+
+// paramGrid expands named parameter values into every combination, in
+// sorted name order. It returns a single nil combination when params is empty.
+func paramGrid(params map[string][]float64) []map[string]float64 {
+	if len(params) == 0 {
+		return []map[string]float64{nil}
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	grid := []map[string]float64{{}}
+	for _, name := range names {
+		var next []map[string]float64
+		for _, combo := range grid {
+			for _, v := range params[name] {
+				c := make(map[string]float64, len(combo)+1)
+				for k, cv := range combo {
+					c[k] = cv
+				}
+				c[name] = v
+				next = append(next, c)
+			}
+		}
+		grid = next
+	}
+	return grid
+}
+
+// memo caches branch results by query so each distinct branch query runs
+// once per tuning session.
+type memo struct {
+	retriever retrieve.Retriever
+	mu        sync.Mutex
+	results   map[string]*retrieve.Result
+}
+
+// newMemo wraps a retriever with a result cache.
+func newMemo(r retrieve.Retriever) *memo {
+	return &memo{retriever: r, results: make(map[string]*retrieve.Result)}
+}
+
+// Retrieve implements retrieve.Retriever.
+func (m *memo) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
+	data, err := json.Marshal(q)
+	if err != nil {
+		return m.retriever.Retrieve(ctx, q)
+	}
+	key := string(data)
+
+	m.mu.Lock()
+	res, ok := m.results[key]
+	m.mu.Unlock()
+	if ok {
+		return res, nil
+	}
+
+	res, err = m.retriever.Retrieve(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.results[key] = res
+	m.mu.Unlock()
+	return res, nil
+}
@@ -0,0 +1,137 @@
+package tune_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/hybrid"
+	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
+	"github.com/agentplexus/omniretrieve/tune"
+)
+
+func TestMetrics(t *testing.T) {
+	relevant := map[string]float64{"a": 1, "c": 1}
+	ranked := []string{"b", "a", "c"}
+
+	if got := tune.MRR(ranked, relevant, 10); got != 0.5 {
+		t.Errorf("expected MRR 0.5, got %f", got)
+	}
+	if got := tune.Recall(ranked, relevant, 2); got != 0.5 {
+		t.Errorf("expected Recall@2 0.5, got %f", got)
+	}
+	if got := tune.NDCG([]string{"a", "c"}, relevant, 10); math.Abs(got-1) > 1e-9 {
+		t.Errorf("expected perfect NDCG, got %f", got)
+	}
+	if got := tune.NDCG(ranked, relevant, 10); got <= 0 || got >= 1 {
+		t.Errorf("expected partial NDCG, got %f", got)
+	}
+}
+
+func TestExamplesFromFeedback(t *testing.T) {
+	examples := tune.ExamplesFromFeedback([]retrieve.Feedback{
+		{QueryID: "q1", Query: retrieve.Query{Text: "first"}, Item: retrieve.ContextItem{ID: "a"}, Relevance: 1},
+		{QueryID: "q2", Query: retrieve.Query{Text: "second"}, Item: retrieve.ContextItem{ID: "b"}, Relevance: 2},
+		{QueryID: "q1", Query: retrieve.Query{Text: "first"}, Item: retrieve.ContextItem{ID: "c"}, Relevance: 0},
+	})
+
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 examples, got %d", len(examples))
+	}
+	if examples[0].Query.Text != "first" || len(examples[0].Relevant) != 2 {
+		t.Errorf("unexpected first example: %+v", examples[0])
+	}
+	if examples[1].Relevant["b"] != 2 {
+		t.Errorf("unexpected second example: %+v", examples[1])
+	}
+}
+
+// topKReranker keeps the first n items.
+type topKReranker struct{ n int }
+
+func (r topKReranker) Rerank(_ context.Context, _ retrieve.Query, items []retrieve.ContextItem) ([]retrieve.ContextItem, error) {
+	if len(items) > r.n {
+		items = items[:r.n]
+	}
+	return items, nil
+}
+
+func TestTuner(t *testing.T) {
+	ctx := context.Background()
+
+	// The graph branch ranks the relevant item first; the vector branch doesn't.
+	vectorRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.8}},
+	}}}
+	graphRetriever := &retrievetest.Retriever{Results: []*retrieve.Result{{
+		Items: []retrieve.ContextItem{{ID: "c", Score: 0.9}, {ID: "a", Score: 0.1}},
+	}}}
+	keywordRetriever := &retrievetest.Retriever{}
+	branchRetriever := &retrievetest.Retriever{}
+
+	tuner := tune.New(tune.Config{
+		Base: hybrid.RetrieverConfig{
+			Vector:    vectorRetriever,
+			Graph:     graphRetriever,
+			Keyword:   keywordRetriever,
+			Branches:  []hybrid.Branch{{Name: "extra", Retriever: branchRetriever}},
+			DedupByID: true,
+		},
+		Space: tune.Space{
+			Alphas:         []float64{1, 0.5, 0},
+			RerankerParams: map[string][]float64{"top_k": {1, 3}},
+		},
+		NewReranker: func(params map[string]float64) retrieve.Reranker {
+			return topKReranker{n: int(params["top_k"])}
+		},
+		Metric: tune.MRR,
+	})
+
+	rec, err := tuner.Tune(ctx, []tune.Example{{
+		Query:    retrieve.Query{Text: "q"},
+		Relevant: map[string]float64{"c": 1},
+	}})
+	if err != nil {
+		t.Fatalf("failed to tune: %v", err)
+	}
+
+	if len(rec.Trials) != 6 {
+		t.Fatalf("expected 6 trials, got %d", len(rec.Trials))
+	}
+	// Alpha 0 scores 1 with both top_k values; the tie keeps the earliest trial
+	if rec.Best.Alpha != 0 || rec.Best.RerankerParams["top_k"] != 1 || rec.Best.Score != 1 {
+		t.Errorf("unexpected best trial: %+v", rec.Best)
+	}
+	if rec.Weights != hybrid.WeightsFromAlpha(0) {
+		t.Errorf("unexpected weights: %+v", rec.Weights)
+	}
+
+	// Branch results are reused across trials
+	if n := vectorRetriever.CallCount(retrievetest.MethodRetrieve); n != 1 {
+		t.Errorf("expected vector branch to run once, got %d", n)
+	}
+	if n := keywordRetriever.CallCount(retrievetest.MethodRetrieve); n != 1 {
+		t.Errorf("expected keyword branch to run once, got %d", n)
+	}
+	if n := branchRetriever.CallCount(retrievetest.MethodRetrieve); n != 1 {
+		t.Errorf("expected named branch to run once, got %d", n)
+	}
+
+	data, err := rec.JSON()
+	if err != nil {
+		t.Fatalf("failed to marshal recommendation: %v", err)
+	}
+	var decoded tune.Recommendation
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Trials) != 6 {
+		t.Errorf("failed to round-trip recommendation: %v", err)
+	}
+}
+
+func TestTunerRequiresExamples(t *testing.T) {
+	tuner := tune.New(tune.Config{Base: hybrid.RetrieverConfig{Vector: &retrievetest.Retriever{}}})
+	if _, err := tuner.Tune(context.Background(), nil); err == nil {
+		t.Error("expected error without examples")
+	}
+}
@@ -0,0 +1,41 @@
+package vector
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAliasesUnsupported is returned when an alias operation is requested
+// from an index manager that doesn't implement AliasManager.
+var ErrAliasesUnsupported = errors.New("index manager does not support aliases")
+
+// AliasManager is implemented by index managers that support index aliases.
+type AliasManager interface {
+	// SwapAlias atomically points alias at the target index, creating the
+	// alias if needed. Indexes opened by alias name switch to the target
+	// without downtime, which enables blue-green reindexing: build and
+	// validate a new index, then flip the alias.
+	SwapAlias(ctx context.Context, alias, target string) error
+	// ResolveAlias returns the name of the index an alias points at.
+	ResolveAlias(ctx context.Context, alias string) (string, error)
+}
+
+// SwapAlias points alias at target if m implements AliasManager, and
+// returns ErrAliasesUnsupported otherwise.
+func SwapAlias(ctx context.Context, m IndexManager, alias, target string) error {
+	aliases, ok := m.(AliasManager)
+	if !ok {
+		return ErrAliasesUnsupported
+	}
+	return aliases.SwapAlias(ctx, alias, target)
+}
+
+// ResolveAlias returns the index alias points at if m implements
+// AliasManager, and ErrAliasesUnsupported otherwise.
+func ResolveAlias(ctx context.Context, m IndexManager, alias string) (string, error) {
+	aliases, ok := m.(AliasManager)
+	if !ok {
+		return "", ErrAliasesUnsupported
+	}
+	return aliases.ResolveAlias(ctx, alias)
+}
@@ -0,0 +1,205 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchSizer is implemented by indexes that limit how many nodes a single
+// batch call may contain (e.g., due to statement parameter limits).
+type BatchSizer interface {
+	// MaxBatchSize returns the largest number of nodes per batch call.
+	MaxBatchSize() int
+}
+
+// ChunkedUpserterConfig configures a ChunkedUpserter.
+type ChunkedUpserterConfig struct {
+	// Index is the target index. BatchIndex is used when implemented;
+	// otherwise nodes are upserted one at a time within each chunk.
+	Index Index
+	// ChunkSize is the number of nodes per chunk (default 500). It is capped
+	// at the index's MaxBatchSize when the index implements BatchSizer.
+	ChunkSize int
+	// Concurrency is the number of chunks upserted in parallel (default 4).
+	Concurrency int
+	// MaxRetries is the number of times a failed chunk is retried (default 0).
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry (default 100ms).
+	RetryBackoff time.Duration
+}
+
+// ChunkedUpserter splits large upserts into size-limited chunks executed by
+// a bounded worker pool, so that a large ingestion doesn't hinge on a single
+// giant transaction.
+type ChunkedUpserter struct {
+	config ChunkedUpserterConfig
+}
+
+// NewChunkedUpserter creates a new chunked upserter.
+func NewChunkedUpserter(cfg ChunkedUpserterConfig) *ChunkedUpserter {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 500
+	}
+	if sizer, ok := cfg.Index.(BatchSizer); ok {
+		if limit := sizer.MaxBatchSize(); limit > 0 && cfg.ChunkSize > limit {
+			cfg.ChunkSize = limit
+		}
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	return &ChunkedUpserter{config: cfg}
+}
+
+// ChunkError describes a chunk that failed after all retries.
+type ChunkError struct {
+	// Offset is the index of the chunk's first node in the input.
+	Offset int
+	// Size is the number of nodes in the chunk.
+	Size int
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Err is the error from the last attempt.
+	Err error
+}
+
+// Error implements error.
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk [%d:%d] failed after %d attempts: %v", e.Offset, e.Offset+e.Size, e.Attempts, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError aggregates the chunks that failed during a chunked upsert.
+// Chunks not listed were written successfully.
+type BatchError struct {
+	// Chunks are the failed chunks, ordered by offset.
+	Chunks []*ChunkError
+}
+
+// Error implements error.
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Chunks))
+	for i, c := range e.Chunks {
+		msgs[i] = c.Error()
+	}
+	return fmt.Sprintf("%d chunks failed: %s", len(e.Chunks), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the chunk errors.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Chunks))
+	for i, c := range e.Chunks {
+		errs[i] = c
+	}
+	return errs
+}
+
+// FailedIDs returns the IDs of nodes in failed chunks, given the original input.
+func (e *BatchError) FailedIDs(nodes []Node) []string {
+	var ids []string
+	for _, c := range e.Chunks {
+		for _, node := range nodes[c.Offset : c.Offset+c.Size] {
+			ids = append(ids, node.ID)
+		}
+	}
+	return ids
+}
+
+// Upsert upserts nodes in chunks. It returns a *BatchError describing the
+// failed chunks, or nil if every chunk succeeded. If ctx is canceled,
+// pending chunks are reported as failed with the context error.
+func (u *ChunkedUpserter) Upsert(ctx context.Context, nodes []Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	size := u.config.ChunkSize
+	offsets := make(chan int)
+	var (
+		mu     sync.Mutex
+		failed []*ChunkError
+		wg     sync.WaitGroup
+	)
+
+	for w := 0; w < u.config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				end := min(offset+size, len(nodes))
+				if chunkErr := u.upsertChunk(ctx, nodes[offset:end], offset); chunkErr != nil {
+					mu.Lock()
+					failed = append(failed, chunkErr)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for offset := 0; offset < len(nodes); offset += size {
+		offsets <- offset
+	}
+	close(offsets)
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Offset < failed[j].Offset })
+	return &BatchError{Chunks: failed}
+}
+
+// upsertChunk writes one chunk, retrying with exponential backoff.
+func (u *ChunkedUpserter) upsertChunk(ctx context.Context, chunk []Node, offset int) *ChunkError {
+	chunkErr := &ChunkError{Offset: offset, Size: len(chunk)}
+	backoff := u.config.RetryBackoff
+	for attempt := 0; attempt <= u.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				chunkErr.Err = errors.Join(chunkErr.Err, ctx.Err())
+				return chunkErr
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		} else if err := ctx.Err(); err != nil {
+			chunkErr.Err = err
+			return chunkErr
+		}
+
+		chunkErr.Attempts++
+		if chunkErr.Err = u.write(ctx, chunk); chunkErr.Err == nil {
+			return nil
+		}
+	}
+	return chunkErr
+}
+
+// write upserts a chunk using the most efficient method the index supports.
+func (u *ChunkedUpserter) write(ctx context.Context, chunk []Node) error {
+	if batch, ok := u.config.Index.(BatchIndex); ok {
+		return batch.UpsertBatch(ctx, chunk)
+	}
+	for _, node := range chunk {
+		if err := u.config.Index.Upsert(ctx, node); err != nil {
+			return fmt.Errorf("failed to upsert node %s: %w", node.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package vector
+
+import (
+	"context"
+	"iter"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BulkImporterConfig configures a BulkImporter.
+type BulkImporterConfig struct {
+	// Index is the target index.
+	Index BatchIndex
+	// BatchSize is the number of nodes per batch (default 500). It is capped
+	// at the index's MaxBatchSize when the index implements BatchSizer.
+	BatchSize int
+	// Concurrency is the number of batches written in parallel (default 4).
+	Concurrency int
+	// RequestsPerSecond limits the rate at which batches are started
+	// (default 0, unlimited).
+	RequestsPerSecond float64
+	// MaxRetries is the number of times a failed batch is retried (default 0).
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry (default 100ms).
+	RetryBackoff time.Duration
+	// OnProgress, if set, is called after each batch completes, successfully
+	// or not. Calls are serialized.
+	OnProgress func(BulkProgress)
+}
+
+// BulkProgress is a snapshot of a bulk import.
+type BulkProgress struct {
+	// Imported is the number of nodes written.
+	Imported int
+	// Failed is the number of nodes in batches that failed after all retries.
+	Failed int
+	// Batches is the number of batches completed.
+	Batches int
+	// Elapsed is the time since the import started.
+	Elapsed time.Duration
+	// LastError is the most recent failed batch, if any.
+	LastError *ChunkError
+}
+
+// Throughput returns the number of nodes written per second.
+func (p BulkProgress) Throughput() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Imported) / p.Elapsed.Seconds()
+}
+
+// BulkImporter streams nodes into an index in batches written by a bounded
+// worker pool, so that imports larger than memory can be fed from a channel
+// or iterator while they are being written. Import blocks until the input is
+// exhausted; run it in a goroutine to import in the background:
+//
+//	nodes := make(chan vector.Node)
+//	go func() {
+//		progress, err := importer.ImportChan(ctx, nodes)
+//		// ...
+//	}()
+type BulkImporter struct {
+	config   BulkImporterConfig
+	upserter *ChunkedUpserter
+	limiter  *rateLimiter
+}
+
+// NewBulkImporter creates a new bulk importer.
+func NewBulkImporter(cfg BulkImporterConfig) *BulkImporter {
+	upserter := NewChunkedUpserter(ChunkedUpserterConfig{
+		Index:        cfg.Index,
+		ChunkSize:    cfg.BatchSize,
+		Concurrency:  cfg.Concurrency,
+		MaxRetries:   cfg.MaxRetries,
+		RetryBackoff: cfg.RetryBackoff,
+	})
+	cfg.BatchSize = upserter.config.ChunkSize
+	cfg.Concurrency = upserter.config.Concurrency
+
+	imp := &BulkImporter{config: cfg, upserter: upserter}
+	if cfg.RequestsPerSecond > 0 {
+		imp.limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / cfg.RequestsPerSecond)}
+	}
+	return imp
+}
+
+// bulkBatch is a batch of nodes and the offset of its first node in the
+// input.
+type bulkBatch struct {
+	offset int
+	nodes  []Node
+}
+
+// Import writes nodes in batches and returns the final progress. It returns
+// a *BatchError describing the failed batches, whose offsets refer to
+// positions in nodes, or nil if every batch succeeded. If ctx is canceled,
+// the import stops reading nodes, pending batches are reported as failed,
+// and the context error is returned if no batch failed.
+func (imp *BulkImporter) Import(ctx context.Context, nodes iter.Seq[Node]) (BulkProgress, error) {
+	start := time.Now()
+	batches := make(chan bulkBatch)
+	var (
+		mu       sync.Mutex
+		progress BulkProgress
+		failed   []*ChunkError
+		wg       sync.WaitGroup
+	)
+
+	for w := 0; w < imp.config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				chunkErr := imp.write(ctx, batch)
+
+				mu.Lock()
+				progress.Batches++
+				if chunkErr != nil {
+					progress.Failed += len(batch.nodes)
+					progress.LastError = chunkErr
+					failed = append(failed, chunkErr)
+				} else {
+					progress.Imported += len(batch.nodes)
+				}
+				progress.Elapsed = time.Since(start)
+				if imp.config.OnProgress != nil {
+					imp.config.OnProgress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	batch := bulkBatch{nodes: make([]Node, 0, imp.config.BatchSize)}
+	offset := 0
+	for node := range nodes {
+		if ctx.Err() != nil {
+			break
+		}
+		batch.nodes = append(batch.nodes, node)
+		offset++
+		if len(batch.nodes) == imp.config.BatchSize {
+			batches <- batch
+			batch = bulkBatch{offset: offset, nodes: make([]Node, 0, imp.config.BatchSize)}
+		}
+	}
+	if len(batch.nodes) > 0 {
+		batches <- batch
+	}
+	close(batches)
+	wg.Wait()
+
+	progress.Elapsed = time.Since(start)
+	if len(failed) > 0 {
+		sort.Slice(failed, func(i, j int) bool { return failed[i].Offset < failed[j].Offset })
+		return progress, &BatchError{Chunks: failed}
+	}
+	return progress, ctx.Err()
+}
+
+// ImportChan is Import reading nodes from a channel until it is closed or
+// ctx is done.
+func (imp *BulkImporter) ImportChan(ctx context.Context, nodes <-chan Node) (BulkProgress, error) {
+	return imp.Import(ctx, func(yield func(Node) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case node, ok := <-nodes:
+				if !ok || !yield(node) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// write writes one batch once the rate limit allows.
+func (imp *BulkImporter) write(ctx context.Context, batch bulkBatch) *ChunkError {
+	if err := imp.limiter.wait(ctx); err != nil {
+		return &ChunkError{Offset: batch.offset, Size: len(batch.nodes), Err: err}
+	}
+	return imp.upserter.upsertChunk(ctx, batch.nodes, batch.offset)
+}
@@ -0,0 +1,99 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CalibrationConfig configures Calibrate.
+type CalibrationConfig struct {
+	// Samples are nodes whose embeddings are searched for to sample scores,
+	// typically a random sample of the indexed nodes or of past queries with
+	// their IDs cleared. A sample's own ID is excluded from its neighbors.
+	Samples []Node
+	// K is the number of neighbors sampled per node (default 10).
+	K int
+	// Filters restrict the sampled neighbors (optional).
+	Filters map[string]string
+}
+
+// Calibration is the sampled distribution of an index's search scores. It
+// maps raw scores, whose meaning differs across metrics and backends, to
+// percentiles, so thresholds can be set portably as "top X% relevance".
+type Calibration struct {
+	scores []float64 // Ascending
+}
+
+// Calibrate samples the scores idx returns for cfg.Samples and fits a
+// percentile mapping to them. Recalibrate when the index's content or
+// embedding model changes significantly.
+func Calibrate(ctx context.Context, idx Index, cfg CalibrationConfig) (*Calibration, error) {
+	if len(cfg.Samples) == 0 {
+		return nil, errors.New("calibration requires samples")
+	}
+	if cfg.K <= 0 {
+		cfg.K = 10
+	}
+
+	var scores []float64
+	for _, sample := range cfg.Samples {
+		// Fetch one extra neighbor in case the sample finds itself
+		results, err := idx.Search(ctx, sample.Embedding, cfg.K+1, cfg.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for sample %s: %w", sample.ID, err)
+		}
+		n := 0
+		for _, r := range results {
+			if n == cfg.K {
+				break
+			}
+			if sample.ID != "" && r.Node.ID == sample.ID {
+				continue
+			}
+			scores = append(scores, r.Score)
+			n++
+		}
+	}
+	if len(scores) == 0 {
+		return nil, errors.New("calibration sampled no scores")
+	}
+	return NewCalibration(scores), nil
+}
+
+// NewCalibration creates a calibration from previously sampled scores,
+// e.g. to restore one saved with Scores.
+func NewCalibration(scores []float64) *Calibration {
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	return &Calibration{scores: sorted}
+}
+
+// Scores returns the sampled scores in ascending order.
+func (c *Calibration) Scores() []float64 {
+	return append([]float64(nil), c.scores...)
+}
+
+// Percentile returns the fraction of sampled scores at or below score, in
+// [0, 1]. It maps a raw score to a relevance comparable across indexes.
+func (c *Calibration) Percentile(score float64) float64 {
+	n := sort.Search(len(c.scores), func(i int) bool { return c.scores[i] > score })
+	return float64(n) / float64(len(c.scores))
+}
+
+// Quantile returns the sampled score below which a fraction q of the
+// scores lie, by the nearest-rank method. q is clamped to [0, 1].
+func (c *Calibration) Quantile(q float64) float64 {
+	q = min(max(q, 0), 1)
+	i := int(math.Ceil(q*float64(len(c.scores)))) - 1
+	return c.scores[min(max(i, 0), len(c.scores)-1)]
+}
+
+// Threshold returns the raw score that only the top fraction of sampled
+// scores reach, e.g. Threshold(0.05) for "top 5% relevance", for use as
+// RetrieverConfig.MinScore or Query.MinScore.
+func (c *Calibration) Threshold(top float64) float64 {
+	return c.Quantile(1 - top)
+}
@@ -0,0 +1,136 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CollectionConfig describes a logical collection of vectors.
+type CollectionConfig struct {
+	// Name is the logical collection name used by the application.
+	Name string
+	// Index configures the backing index. Index.Name is the provider-specific
+	// table, namespace, or class; it defaults to the registry prefix plus Name.
+	Index IndexConfig
+	// Metadata holds application-defined settings (e.g., embedding model).
+	Metadata map[string]string
+}
+
+// IndexOpener opens the provider index backing a collection.
+type IndexOpener func(ctx context.Context, cfg IndexConfig) (Index, error)
+
+// CollectionRegistryConfig configures a CollectionRegistry.
+type CollectionRegistryConfig struct {
+	// Open opens provider indexes for collections.
+	Open IndexOpener
+	// Manager, if set, creates the backing index on first use when it does
+	// not exist yet.
+	Manager IndexManager
+	// Prefix is prepended to logical names to derive index names.
+	Prefix string
+}
+
+// CollectionRegistry maps logical collection names to provider indexes, so
+// applications managing many corpora don't juggle raw table names. Indexes
+// are opened lazily on first use. It is safe for concurrent use.
+type CollectionRegistry struct {
+	mu      sync.Mutex
+	config  CollectionRegistryConfig
+	configs map[string]CollectionConfig
+	indexes map[string]Index
+}
+
+// NewCollectionRegistry creates a new collection registry.
+func NewCollectionRegistry(cfg CollectionRegistryConfig) *CollectionRegistry {
+	return &CollectionRegistry{
+		config:  cfg,
+		configs: make(map[string]CollectionConfig),
+		indexes: make(map[string]Index),
+	}
+}
+
+// Register adds a collection. It fails if the name is empty or already registered.
+func (r *CollectionRegistry) Register(cfg CollectionConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("collection name is required")
+	}
+	if cfg.Index.Name == "" {
+		cfg.Index.Name = r.config.Prefix + cfg.Name
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.configs[cfg.Name]; ok {
+		return fmt.Errorf("collection %s is already registered", cfg.Name)
+	}
+	r.configs[cfg.Name] = cfg
+	return nil
+}
+
+// Unregister removes a collection from the registry. The backing index is
+// left untouched.
+func (r *CollectionRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.configs, name)
+	delete(r.indexes, name)
+}
+
+// Config returns the configuration of a registered collection.
+func (r *CollectionRegistry) Config(name string) (CollectionConfig, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg, ok := r.configs[name]
+	return cfg, ok
+}
+
+// Names returns the registered collection names in sorted order.
+func (r *CollectionRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.configs))
+	for name := range r.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Index returns the index backing a collection, opening (and, with a
+// Manager, creating) it on first use.
+func (r *CollectionRegistry) Index(ctx context.Context, name string) (Index, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if idx, ok := r.indexes[name]; ok {
+		return idx, nil
+	}
+	cfg, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("collection %s is not registered", name)
+	}
+	if r.config.Open == nil {
+		return nil, fmt.Errorf("collection registry has no index opener")
+	}
+
+	if r.config.Manager != nil {
+		exists, err := r.config.Manager.IndexExists(ctx, cfg.Index.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check index for collection %s: %w", name, err)
+		}
+		if !exists {
+			if err := r.config.Manager.CreateIndex(ctx, cfg.Index); err != nil {
+				return nil, fmt.Errorf("failed to create index for collection %s: %w", name, err)
+			}
+		}
+	}
+
+	idx, err := r.config.Open(ctx, cfg.Index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open collection %s: %w", name, err)
+	}
+	r.indexes[name] = idx
+	return idx, nil
+}
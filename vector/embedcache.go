@@ -0,0 +1,52 @@
+package vector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// EmbeddingCache caches query embeddings, so repeated queries skip the
+// embedding model. Implementations must be safe for concurrent use.
+type EmbeddingCache interface {
+	// Get returns the cached embedding for key. Callers must not modify it.
+	Get(ctx context.Context, key string) ([]float32, bool)
+	// Set caches embedding under key.
+	Set(ctx context.Context, key string, embedding []float32)
+}
+
+// EmbeddingCacheKey returns the cache key of text embedded by model: the
+// model name and a SHA-256 hash of the text.
+func EmbeddingCacheKey(model, text string) string {
+	h := sha256.Sum256([]byte(text))
+	return model + ":" + hex.EncodeToString(h[:])
+}
+
+// embed returns the query embedding of text, from the EmbeddingCache if
+// configured. Hits and misses are reported to observers implementing
+// retrieve.EmbeddingCacheObserver.
+func (r *Retriever) embed(ctx context.Context, text string) ([]float32, error) {
+	cache := r.config.EmbeddingCache
+	if cache == nil {
+		return r.config.Embedder.Embed(ctx, text)
+	}
+
+	model := r.config.Embedder.Model()
+	key := EmbeddingCacheKey(model, text)
+	embedding, hit := cache.Get(ctx, key)
+	if obs, ok := r.config.Observer.(retrieve.EmbeddingCacheObserver); ok {
+		obs.OnEmbeddingCache(ctx, model, hit)
+	}
+	if hit {
+		return embedding, nil
+	}
+
+	embedding, err := r.config.Embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(ctx, key, embedding)
+	return embedding, nil
+}
@@ -0,0 +1,219 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EmbeddingIndexConfig configures an EmbeddingIndex.
+type EmbeddingIndexConfig struct {
+	// Index is the wrapped index. BatchIndex is used when implemented.
+	Index Index
+	// Embedder embeds the content of nodes written without an Embedding.
+	Embedder Embedder
+	// BatchSize is the number of texts per EmbedBatch call (default 64).
+	BatchSize int
+	// Concurrency is the number of EmbedBatch calls made in parallel for a
+	// single write (default 1).
+	Concurrency int
+	// RequestsPerSecond limits EmbedBatch calls across all writes, e.g. to
+	// stay within an embedding API's rate limit (default: unlimited).
+	RequestsPerSecond float64
+}
+
+// EmbeddingIndex wraps an Index and embeds the content of nodes written
+// without an Embedding, so callers don't have to pre-compute embeddings.
+// Nodes that already have one are written as is.
+type EmbeddingIndex struct {
+	Index
+	config  EmbeddingIndexConfig
+	limiter *rateLimiter
+}
+
+// NewEmbeddingIndex creates a new embedding index wrapper.
+func NewEmbeddingIndex(cfg EmbeddingIndexConfig) *EmbeddingIndex {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 64
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	idx := &EmbeddingIndex{Index: cfg.Index, config: cfg}
+	if cfg.RequestsPerSecond > 0 {
+		idx.limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / cfg.RequestsPerSecond)}
+	}
+	return idx
+}
+
+// Insert implements Index.
+func (idx *EmbeddingIndex) Insert(ctx context.Context, node Node) error {
+	nodes, err := idx.embed(ctx, []Node{node})
+	if err != nil {
+		return err
+	}
+	return idx.Index.Insert(ctx, nodes[0])
+}
+
+// Upsert implements Index.
+func (idx *EmbeddingIndex) Upsert(ctx context.Context, node Node) error {
+	nodes, err := idx.embed(ctx, []Node{node})
+	if err != nil {
+		return err
+	}
+	return idx.Index.Upsert(ctx, nodes[0])
+}
+
+// InsertBatch implements BatchIndex. If the wrapped index does not support
+// batches, nodes are inserted one at a time.
+func (idx *EmbeddingIndex) InsertBatch(ctx context.Context, nodes []Node) error {
+	nodes, err := idx.embed(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	if batch, ok := idx.Index.(BatchIndex); ok {
+		return batch.InsertBatch(ctx, nodes)
+	}
+	for _, node := range nodes {
+		if err := idx.Index.Insert(ctx, node); err != nil {
+			return fmt.Errorf("failed to insert node %s: %w", node.ID, err)
+		}
+	}
+	return nil
+}
+
+// UpsertBatch implements BatchIndex. If the wrapped index does not support
+// batches, nodes are upserted one at a time.
+func (idx *EmbeddingIndex) UpsertBatch(ctx context.Context, nodes []Node) error {
+	nodes, err := idx.embed(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	if batch, ok := idx.Index.(BatchIndex); ok {
+		return batch.UpsertBatch(ctx, nodes)
+	}
+	for _, node := range nodes {
+		if err := idx.Index.Upsert(ctx, node); err != nil {
+			return fmt.Errorf("failed to upsert node %s: %w", node.ID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteBatch implements BatchIndex. If the wrapped index does not support
+// batches, nodes are deleted one at a time.
+func (idx *EmbeddingIndex) DeleteBatch(ctx context.Context, ids []string) error {
+	if batch, ok := idx.Index.(BatchIndex); ok {
+		return batch.DeleteBatch(ctx, ids)
+	}
+	for _, id := range ids {
+		if err := idx.Index.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete node %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// embed returns nodes with the content of those without an Embedding
+// embedded, in batches of BatchSize run Concurrency at a time. The
+// caller's nodes are not modified.
+func (idx *EmbeddingIndex) embed(ctx context.Context, nodes []Node) ([]Node, error) {
+	var missing []int
+	for i, node := range nodes {
+		if len(node.Embedding) == 0 {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return nodes, nil
+	}
+	if idx.config.Embedder == nil {
+		return nil, errors.New("embedding index: embedder is required for nodes without embeddings")
+	}
+
+	embedded := slices.Clone(nodes)
+	size := idx.config.BatchSize
+	errs := make([]error, (len(missing)+size-1)/size)
+	sem := make(chan struct{}, idx.config.Concurrency)
+	var wg sync.WaitGroup
+	for b := range errs {
+		batch := missing[b*size : min((b+1)*size, len(missing))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[b] = idx.embedBatch(ctx, embedded, batch)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("failed to embed nodes: %w", err)
+	}
+	return embedded, nil
+}
+
+// embedBatch embeds the content of nodes[i] for each i in batch with a
+// single EmbedBatch call.
+func (idx *EmbeddingIndex) embedBatch(ctx context.Context, nodes []Node, batch []int) error {
+	if err := idx.limiter.wait(ctx); err != nil {
+		return err
+	}
+	texts := make([]string, len(batch))
+	for j, i := range batch {
+		texts[j] = nodes[i].Content
+	}
+	embeddings, err := idx.config.Embedder.EmbedBatch(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(embeddings) != len(texts) {
+		return fmt.Errorf("embedder returned %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+	for j, i := range batch {
+		nodes[i].Embedding = embeddings[j]
+	}
+	return nil
+}
+
+// rateLimiter spaces calls at least interval apart. A nil rateLimiter
+// doesn't limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next call may start, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	at := time.Now()
+	if l.next.After(at) {
+		at = l.next
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Verify interface compliance
+var _ BatchIndex = (*EmbeddingIndex)(nil)
@@ -0,0 +1,36 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// Sentinel errors returned by Index implementations, wrapped with details,
+// so callers can handle failures with errors.Is regardless of the backend.
+// Errors retrying can't fix are retrieve.PermanentErrors.
+var (
+	// ErrNotFound reports a node or other resource that doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDimensionMismatch reports an embedding whose length doesn't match
+	// the index's dimensions.
+	ErrDimensionMismatch = retrieve.NewPermanentError("embedding dimension mismatch")
+	// ErrIndexNotReady reports an index that can't serve requests yet, e.g.
+	// because its table hasn't been created or its backend is starting up.
+	ErrIndexNotReady = errors.New("index not ready")
+	// ErrRateLimited reports a request rejected by a backend limit, such as
+	// an API rate limit or a connection limit. It is worth retrying later.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrMetricUnsupported reports a SearchOptions.Metric override the index
+	// can't honor.
+	ErrMetricUnsupported = retrieve.NewPermanentError("distance metric not supported by index")
+	// ErrConflict reports a write rejected because it conflicts with
+	// existing data, such as a node ID taken in another namespace or by
+	// another tenant.
+	ErrConflict = retrieve.NewPermanentError("conflict")
+)
+
+// ErrNodeNotFound is returned by GetIndex.Get when no node has the given ID.
+// It matches ErrNotFound.
+var ErrNodeNotFound = fmt.Errorf("node %w", ErrNotFound)
@@ -0,0 +1,76 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrExpirationUnsupported is returned when a node with ExpiresAt is written
+// to, or expired nodes are purged from, an index without expiration support.
+var ErrExpirationUnsupported = errors.New("index does not support expiration")
+
+// Expired reports whether the node has expired at t.
+func (n Node) Expired(t time.Time) bool {
+	return !n.ExpiresAt.IsZero() && !t.Before(n.ExpiresAt)
+}
+
+// ExpiringIndex is implemented by indexes that honor Node.ExpiresAt but
+// don't remove expired nodes on their own. Searches and lookups skip expired
+// nodes; PurgeExpired removes them.
+type ExpiringIndex interface {
+	Index
+	// PurgeExpired removes the nodes that expired at or before now,
+	// regardless of the context's namespace, and returns how many were
+	// removed.
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// ReaperConfig configures a Reaper.
+type ReaperConfig struct {
+	// Index is the index to purge.
+	Index ExpiringIndex
+	// Interval is the time between purges (default 1m).
+	Interval time.Duration
+	// OnReap, if set, is called after each purge with the number of nodes
+	// removed and the error, if any.
+	OnReap func(purged int64, err error)
+}
+
+// Reaper periodically purges expired nodes from an ExpiringIndex, for
+// backends without native TTL.
+type Reaper struct {
+	config ReaperConfig
+}
+
+// NewReaper creates a new reaper.
+func NewReaper(cfg ReaperConfig) *Reaper {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	return &Reaper{config: cfg}
+}
+
+// Reap purges the nodes that have expired by now.
+func (r *Reaper) Reap(ctx context.Context) (int64, error) {
+	return r.config.Index.PurgeExpired(ctx, time.Now())
+}
+
+// Run purges expired nodes every Interval until ctx is done, and returns the
+// context error. A failed purge doesn't stop the reaper; use OnReap to
+// observe failures.
+func (r *Reaper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			purged, err := r.Reap(ctx)
+			if r.config.OnReap != nil {
+				r.config.OnReap(purged, err)
+			}
+		}
+	}
+}
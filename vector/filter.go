@@ -0,0 +1,318 @@
+package vector
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FilterOp is a filter expression operator.
+type FilterOp string
+
+const (
+	// FilterEq matches metadata values equal to the value.
+	FilterEq FilterOp = "eq"
+	// FilterNeq matches metadata values not equal to the value, including
+	// nodes without the key.
+	FilterNeq FilterOp = "neq"
+	// FilterIn matches metadata values equal to any of the values.
+	FilterIn FilterOp = "in"
+	// FilterGt matches metadata values greater than the value.
+	FilterGt FilterOp = "gt"
+	// FilterGte matches metadata values greater than or equal to the value.
+	FilterGte FilterOp = "gte"
+	// FilterLt matches metadata values less than the value.
+	FilterLt FilterOp = "lt"
+	// FilterLte matches metadata values less than or equal to the value.
+	FilterLte FilterOp = "lte"
+	// FilterContains matches metadata values containing the value as a substring.
+	FilterContains FilterOp = "contains"
+	// FilterExists matches nodes that have the key.
+	FilterExists FilterOp = "exists"
+	// FilterAnd matches when all sub-filters match (or there are none).
+	FilterAnd FilterOp = "and"
+	// FilterOr matches when any sub-filter matches.
+	FilterOr FilterOp = "or"
+)
+
+// Filter is a metadata filter expression. Since metadata values are
+// strings, Eq, Neq, and In compare string forms. Comparisons (Gt, Gte, Lt,
+// Lte) are numeric when the value is a number, skipping nodes whose metadata
+// value doesn't parse as one, and byte-wise otherwise (e.g., for RFC 3339
+// timestamps). The zero Filter matches everything.
+type Filter struct {
+	// Op is the operator.
+	Op FilterOp
+	// Key is the metadata key for comparison operators.
+	Key string
+	// Value is the operand: a string, number, or bool, or a slice of them for In.
+	Value any
+	// Filters are the operands of And and Or.
+	Filters []Filter
+}
+
+// FilterIndex extends Index with search by filter expression.
+type FilterIndex interface {
+	Index
+	// SearchFilter finds the k most similar nodes matching the filter.
+	SearchFilter(ctx context.Context, embedding []float32, k int, filter Filter) ([]SearchResult, error)
+}
+
+// SearchOptions tunes a single search.
+type SearchOptions struct {
+	// Filter restricts results by metadata.
+	Filter Filter
+	// EfSearch is the HNSW candidate list size; larger values trade latency
+	// for recall (0 uses the backend default).
+	EfSearch int
+	// Probes is the number of IVFFlat lists searched; larger values trade
+	// latency for recall (0 uses the backend default).
+	Probes int
+	// IncludeDeleted includes soft-deleted nodes, e.g. to audit past
+	// retrievals. Indexes that delete immediately ignore it.
+	IncludeDeleted bool
+	// Metric overrides the index's distance metric for this search, e.g. dot
+	// product for recommendations over an index searched by cosine.
+	// Indexes that can't honor it fail with ErrMetricUnsupported.
+	Metric DistanceMetric
+}
+
+// TunableIndex extends Index with per-search recall/latency tuning.
+// Backends without approximate indexes may ignore EfSearch and Probes.
+type TunableIndex interface {
+	Index
+	// SearchWithOptions finds the k most similar nodes using opts.
+	SearchWithOptions(ctx context.Context, embedding []float32, k int, opts SearchOptions) ([]SearchResult, error)
+}
+
+// Eq returns a filter matching metadata values equal to value.
+func Eq(key string, value any) Filter { return Filter{Op: FilterEq, Key: key, Value: value} }
+
+// Neq returns a filter matching metadata values not equal to value.
+func Neq(key string, value any) Filter { return Filter{Op: FilterNeq, Key: key, Value: value} }
+
+// In returns a filter matching metadata values equal to any of values.
+func In(key string, values ...any) Filter { return Filter{Op: FilterIn, Key: key, Value: values} }
+
+// Gt returns a filter matching metadata values greater than value.
+func Gt(key string, value any) Filter { return Filter{Op: FilterGt, Key: key, Value: value} }
+
+// Gte returns a filter matching metadata values greater than or equal to value.
+func Gte(key string, value any) Filter { return Filter{Op: FilterGte, Key: key, Value: value} }
+
+// Lt returns a filter matching metadata values less than value.
+func Lt(key string, value any) Filter { return Filter{Op: FilterLt, Key: key, Value: value} }
+
+// Lte returns a filter matching metadata values less than or equal to value.
+func Lte(key string, value any) Filter { return Filter{Op: FilterLte, Key: key, Value: value} }
+
+// Contains returns a filter matching metadata values containing substr.
+func Contains(key, substr string) Filter {
+	return Filter{Op: FilterContains, Key: key, Value: substr}
+}
+
+// Exists returns a filter matching nodes that have the key.
+func Exists(key string) Filter { return Filter{Op: FilterExists, Key: key} }
+
+// And returns a filter matching when all filters match.
+func And(filters ...Filter) Filter { return Filter{Op: FilterAnd, Filters: filters} }
+
+// Or returns a filter matching when any filter matches.
+func Or(filters ...Filter) Filter { return Filter{Op: FilterOr, Filters: filters} }
+
+// FilterFromMap converts exact-match filters into an equivalent expression.
+func FilterFromMap(filters map[string]string) Filter {
+	if len(filters) == 0 {
+		return Filter{}
+	}
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	expr := make([]Filter, len(keys))
+	for i, k := range keys {
+		expr[i] = Eq(k, filters[k])
+	}
+	return And(expr...)
+}
+
+// Validate reports malformed expressions, such as unknown operators,
+// missing keys, or operands of the wrong type.
+func (f Filter) Validate() error {
+	switch f.Op {
+	case "":
+		return nil
+	case FilterAnd, FilterOr:
+		for _, sub := range f.Filters {
+			if err := sub.Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FilterEq, FilterNeq, FilterIn, FilterGt, FilterGte, FilterLt, FilterLte, FilterContains, FilterExists:
+	default:
+		return fmt.Errorf("unknown filter operator %q", f.Op)
+	}
+
+	if f.Key == "" {
+		return fmt.Errorf("filter %s requires a key", f.Op)
+	}
+	switch f.Op {
+	case FilterExists:
+		return nil
+	case FilterIn:
+		if _, err := FilterValues(f.Value); err != nil {
+			return fmt.Errorf("filter in on %s: %w", f.Key, err)
+		}
+		return nil
+	case FilterContains:
+		if _, ok := f.Value.(string); !ok {
+			return fmt.Errorf("filter contains on %s requires a string", f.Key)
+		}
+		return nil
+	}
+	if _, err := FormatFilterValue(f.Value); err != nil {
+		return fmt.Errorf("filter %s on %s: %w", f.Op, f.Key, err)
+	}
+	return nil
+}
+
+// Match reports whether metadata satisfies the filter. Filters are assumed
+// to be valid; invalid operands never match.
+func (f Filter) Match(metadata map[string]string) bool {
+	switch f.Op {
+	case "":
+		return true
+	case FilterAnd:
+		for _, sub := range f.Filters {
+			if !sub.Match(metadata) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, sub := range f.Filters {
+			if sub.Match(metadata) {
+				return true
+			}
+		}
+		return false
+	}
+
+	actual, ok := metadata[f.Key]
+	switch f.Op {
+	case FilterExists:
+		return ok
+	case FilterNeq:
+		want, err := FormatFilterValue(f.Value)
+		return err == nil && (!ok || actual != want)
+	}
+	if !ok {
+		return false
+	}
+
+	switch f.Op {
+	case FilterEq:
+		want, err := FormatFilterValue(f.Value)
+		return err == nil && actual == want
+	case FilterIn:
+		values, err := FilterValues(f.Value)
+		if err != nil {
+			return false
+		}
+		for _, v := range values {
+			if actual == v {
+				return true
+			}
+		}
+		return false
+	case FilterContains:
+		substr, ok := f.Value.(string)
+		return ok && strings.Contains(actual, substr)
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		var c int
+		if want, ok := FilterNumber(f.Value); ok {
+			got, err := strconv.ParseFloat(actual, 64)
+			if err != nil {
+				return false
+			}
+			c = cmp.Compare(got, want)
+		} else if want, ok := f.Value.(string); ok {
+			c = strings.Compare(actual, want)
+		} else {
+			return false
+		}
+		switch f.Op {
+		case FilterGt:
+			return c > 0
+		case FilterGte:
+			return c >= 0
+		case FilterLt:
+			return c < 0
+		default:
+			return c <= 0
+		}
+	}
+	return false
+}
+
+// FilterNumber returns a filter operand as a float64 if it is numeric.
+func FilterNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// FormatFilterValue returns the string form a scalar operand is compared as.
+func FormatFilterValue(v any) (string, error) {
+	if n, ok := FilterNumber(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	}
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case bool:
+		return strconv.FormatBool(s), nil
+	}
+	return "", fmt.Errorf("unsupported filter value type %T", v)
+}
+
+// FilterValues returns the string forms of an In operand.
+func FilterValues(v any) ([]string, error) {
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []any:
+		values := make([]string, len(list))
+		for i, item := range list {
+			s, err := FormatFilterValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = s
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported filter list type %T", v)
+}
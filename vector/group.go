@@ -0,0 +1,35 @@
+package vector
+
+import "context"
+
+// GroupIndex extends Index with grouped search, returning the best result
+// per value of a metadata key, e.g. one chunk per document.
+type GroupIndex interface {
+	Index
+	// SearchGroups returns the best result for each of up to k distinct
+	// values of the groupBy metadata key, in descending score order. Nodes
+	// without the key are skipped.
+	SearchGroups(ctx context.Context, embedding []float32, k int, groupBy string, opts SearchOptions) ([]SearchResult, error)
+}
+
+// GroupResults returns the first result for each of up to k distinct values
+// of the groupBy metadata key in results, which must be sorted by descending
+// score. Results without the key are skipped. It groups the results of
+// indexes that don't implement GroupIndex, searched with a k large enough
+// to cover k groups.
+func GroupResults(results []SearchResult, groupBy string, k int) []SearchResult {
+	seen := make(map[string]bool)
+	var grouped []SearchResult
+	for _, r := range results {
+		if len(grouped) >= k {
+			break
+		}
+		value, ok := r.Node.Metadata[groupBy]
+		if !ok || seen[value] {
+			continue
+		}
+		seen[value] = true
+		grouped = append(grouped, r)
+	}
+	return grouped
+}
@@ -0,0 +1,41 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+)
+
+// IndexDescription describes an index's configuration and contents.
+type IndexDescription struct {
+	// Name is the index name.
+	Name string
+	// Dimensions is the embedding length the index accepts, or 0 if it
+	// doesn't know it yet (e.g., an empty index sized by its first write).
+	Dimensions int
+	// DistanceMetric is the metric search scores are derived from.
+	DistanceMetric DistanceMetric
+	// Count is the number of nodes visible to the context, which may be an
+	// estimate for large indexes.
+	Count int64
+}
+
+// Check returns an error wrapping ErrDimensionMismatch if the index doesn't
+// accept embeddings of the given length.
+func (d IndexDescription) Check(dimensions int) error {
+	if d.Dimensions != 0 && d.Dimensions != dimensions {
+		return fmt.Errorf("%w: index %s has %d dimensions, expected %d",
+			ErrDimensionMismatch, d.Name, d.Dimensions, dimensions)
+	}
+	return nil
+}
+
+// IntrospectableIndex extends Index with introspection, so orchestration
+// code can validate its configuration, e.g. the embedder's dimensions, at
+// startup instead of failing on the first search.
+type IntrospectableIndex interface {
+	Index
+	// Exists reports whether a node with the given ID exists.
+	Exists(ctx context.Context, id string) (bool, error)
+	// Describe returns the index's configuration and node count.
+	Describe(ctx context.Context) (IndexDescription, error)
+}
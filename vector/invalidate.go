@@ -0,0 +1,135 @@
+package vector
+
+import (
+	"context"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// InvalidatingIndexConfig configures an InvalidatingIndex.
+type InvalidatingIndexConfig struct {
+	// Index is the wrapped index.
+	Index Index
+	// Bus receives a mutation event after every successful write.
+	Bus *retrieve.InvalidationBus
+	// FlushOnWrite flushes the whole namespace on inserts and upserts, since
+	// new content can change the results of any cached query, not only those
+	// that already contain the written IDs. Deletes always evict by ID.
+	FlushOnWrite bool
+}
+
+// InvalidatingIndex wraps an Index and publishes mutation events so that
+// subscribed caches evict stale retrieval results after re-ingestion.
+type InvalidatingIndex struct {
+	Index
+	config InvalidatingIndexConfig
+}
+
+// NewInvalidatingIndex creates a new invalidating index wrapper.
+func NewInvalidatingIndex(cfg InvalidatingIndexConfig) *InvalidatingIndex {
+	return &InvalidatingIndex{Index: cfg.Index, config: cfg}
+}
+
+// Insert implements Index.
+func (idx *InvalidatingIndex) Insert(ctx context.Context, node Node) error {
+	if err := idx.Index.Insert(ctx, node); err != nil {
+		return err
+	}
+	idx.publish(ctx, retrieve.MutationUpsert, []string{node.ID})
+	return nil
+}
+
+// Upsert implements Index.
+func (idx *InvalidatingIndex) Upsert(ctx context.Context, node Node) error {
+	if err := idx.Index.Upsert(ctx, node); err != nil {
+		return err
+	}
+	idx.publish(ctx, retrieve.MutationUpsert, []string{node.ID})
+	return nil
+}
+
+// Delete implements Index.
+func (idx *InvalidatingIndex) Delete(ctx context.Context, id string) error {
+	if err := idx.Index.Delete(ctx, id); err != nil {
+		return err
+	}
+	idx.publish(ctx, retrieve.MutationDelete, []string{id})
+	return nil
+}
+
+// InsertBatch implements BatchIndex. If the wrapped index does not support
+// batches, nodes are inserted one at a time.
+func (idx *InvalidatingIndex) InsertBatch(ctx context.Context, nodes []Node) error {
+	var err error
+	if batch, ok := idx.Index.(BatchIndex); ok {
+		err = batch.InsertBatch(ctx, nodes)
+	} else {
+		for _, node := range nodes {
+			if err = idx.Index.Insert(ctx, node); err != nil {
+				break
+			}
+		}
+	}
+	// Publish even on partial failure, since some nodes may have been written.
+	idx.publish(ctx, retrieve.MutationUpsert, nodeIDs(nodes))
+	return err
+}
+
+// UpsertBatch implements BatchIndex. If the wrapped index does not support
+// batches, nodes are upserted one at a time.
+func (idx *InvalidatingIndex) UpsertBatch(ctx context.Context, nodes []Node) error {
+	var err error
+	if batch, ok := idx.Index.(BatchIndex); ok {
+		err = batch.UpsertBatch(ctx, nodes)
+	} else {
+		for _, node := range nodes {
+			if err = idx.Index.Upsert(ctx, node); err != nil {
+				break
+			}
+		}
+	}
+	idx.publish(ctx, retrieve.MutationUpsert, nodeIDs(nodes))
+	return err
+}
+
+// DeleteBatch implements BatchIndex. If the wrapped index does not support
+// batches, IDs are deleted one at a time.
+func (idx *InvalidatingIndex) DeleteBatch(ctx context.Context, ids []string) error {
+	var err error
+	if batch, ok := idx.Index.(BatchIndex); ok {
+		err = batch.DeleteBatch(ctx, ids)
+	} else {
+		for _, id := range ids {
+			if err = idx.Index.Delete(ctx, id); err != nil {
+				break
+			}
+		}
+	}
+	idx.publish(ctx, retrieve.MutationDelete, ids)
+	return err
+}
+
+// publish sends a mutation event for the given IDs.
+func (idx *InvalidatingIndex) publish(ctx context.Context, op retrieve.MutationOp, ids []string) {
+	if idx.config.Bus == nil || len(ids) == 0 {
+		return
+	}
+	idx.config.Bus.Publish(ctx, retrieve.Mutation{
+		Namespace: idx.Index.Name(),
+		Op:        op,
+		IDs:       ids,
+		Flush:     idx.config.FlushOnWrite && op == retrieve.MutationUpsert,
+	})
+}
+
+// nodeIDs returns the IDs of the given nodes.
+func nodeIDs(nodes []Node) []string {
+	ids := make([]string, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// Verify interface compliance
+var _ BatchIndex = (*InvalidatingIndex)(nil)
@@ -0,0 +1,99 @@
+package vector
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// MultiVectorIndex is implemented by indexes that score nodes with multiple
+// embeddings (Node.Embeddings) natively by late interaction, ColBERT-style.
+type MultiVectorIndex interface {
+	Index
+	// SearchMulti finds the k nodes with the highest MaxSim score for the
+	// query embeddings.
+	SearchMulti(ctx context.Context, embeddings [][]float32, k int, filters map[string]string) ([]SearchResult, error)
+}
+
+// MultiEmbedder creates multiple embeddings per text, e.g. one per token.
+type MultiEmbedder interface {
+	// EmbedMulti creates the embeddings for the given text.
+	EmbedMulti(ctx context.Context, text string) ([][]float32, error)
+}
+
+// MaxSim returns the late-interaction score of a document for a query: the
+// mean, over the query embeddings, of the highest cosine similarity to any
+// document embedding. Like cosine similarity it ranges from -1 to 1.
+func MaxSim(query, doc [][]float32) float64 {
+	if len(query) == 0 || len(doc) == 0 {
+		return 0
+	}
+	var total float64
+	for _, q := range query {
+		best := math.Inf(-1)
+		for _, d := range doc {
+			best = max(best, Similarity(DistanceCosine, q, d))
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+// MeanPool returns the element-wise mean of embeddings, e.g. to derive
+// Node.Embedding from Node.Embeddings for single-vector search.
+func MeanPool(embeddings [][]float32) []float32 {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	pooled := make([]float32, len(embeddings[0]))
+	for _, e := range embeddings {
+		for i := range min(len(e), len(pooled)) {
+			pooled[i] += e[i]
+		}
+	}
+	for i := range pooled {
+		pooled[i] /= float32(len(embeddings))
+	}
+	return pooled
+}
+
+// NodeEmbeddings returns the embeddings MaxSim scores a node by: its
+// Embeddings, or its single Embedding if it has none.
+func NodeEmbeddings(node Node) [][]float32 {
+	if len(node.Embeddings) > 0 {
+		return node.Embeddings
+	}
+	if len(node.Embedding) > 0 {
+		return [][]float32{node.Embedding}
+	}
+	return nil
+}
+
+// searchMulti finds the k nodes with the highest MaxSim score. Indexes
+// implementing MultiVectorIndex are searched natively; others are searched
+// with the mean-pooled query for candidates that are re-scored by MaxSim.
+func (r *Retriever) searchMulti(ctx context.Context, embeddings [][]float32, k int, filters map[string]string) ([]SearchResult, error) {
+	if idx, ok := r.config.Index.(MultiVectorIndex); ok {
+		return idx.SearchMulti(ctx, embeddings, k, filters)
+	}
+
+	candidates := r.config.MultiVectorCandidates
+	if candidates == 0 {
+		candidates = 4 * k
+	}
+	candidates = max(candidates, k)
+	results, err := r.config.Index.Search(ctx, MeanPool(embeddings), candidates, filters)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Score = MaxSim(embeddings, NodeEmbeddings(results[i].Node))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
@@ -0,0 +1,51 @@
+package vector
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNamespacesUnsupported is returned when a namespace is requested from an
+// index that doesn't implement NamespaceIndex.
+var ErrNamespacesUnsupported = errors.New("index does not support namespaces")
+
+// namespaceKey is the context key of WithNamespace.
+type namespaceKey struct{}
+
+// WithNamespace returns a context that routes the index operations it is
+// passed to a namespace. Indexes implementing NamespaceIndex only search,
+// get, and delete nodes in that namespace, and write nodes without a
+// Namespace to it. Without a namespace, operations use the default
+// namespace "".
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// ContextNamespace returns the namespace set with WithNamespace, or "".
+func ContextNamespace(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceKey{}).(string)
+	return namespace
+}
+
+// NodeNamespace returns the namespace a node is written to: its Namespace,
+// or the context's if it has none.
+func NodeNamespace(ctx context.Context, node Node) string {
+	if node.Namespace != "" {
+		return node.Namespace
+	}
+	return ContextNamespace(ctx)
+}
+
+// NamespaceIndex is implemented by indexes that partition nodes into
+// namespaces (e.g., Pinecone namespaces or Qdrant collections) under one
+// connection. Namespaces are selected with WithNamespace; search results
+// carry their node's Namespace. Whether node IDs are unique per namespace
+// or across namespaces depends on the index; indexes with IDs unique across
+// namespaces fail writes of an ID taken in another namespace with
+// ErrConflict.
+type NamespaceIndex interface {
+	Index
+	// Namespaces returns the namespaces holding nodes in sorted order,
+	// including "" if the default namespace does.
+	Namespaces(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,239 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// IndexOptimizer is implemented by index managers that support maintenance
+// operations beyond the IndexManager lifecycle.
+type IndexOptimizer interface {
+	// RebuildIndex rebuilds the vector index of the named index. For IVFFlat
+	// this retrains the list centroids on the current data; for HNSW and
+	// DiskANN it reclaims space left by deleted nodes.
+	RebuildIndex(ctx context.Context, name string) error
+	// RefreshStats refreshes the backend's cached statistics (e.g., query
+	// planner statistics) for the named index.
+	RefreshStats(ctx context.Context, name string) error
+}
+
+// OptimizerAction identifies a maintenance action.
+type OptimizerAction string
+
+const (
+	// ActionRebuild rebuilds an index.
+	ActionRebuild OptimizerAction = "rebuild"
+	// ActionRefreshStats refreshes an index's cached statistics.
+	ActionRefreshStats OptimizerAction = "refresh_stats"
+	// ActionCompact compacts an in-memory component.
+	ActionCompact OptimizerAction = "compact"
+)
+
+// OptimizerConfig configures an Optimizer.
+type OptimizerConfig struct {
+	// Manager provides index statistics. Rebuilds and statistics refreshes
+	// are only performed if it also implements IndexOptimizer.
+	Manager IndexManager
+	// Indexes are the index names to watch (default: all from ListIndexes).
+	Indexes []string
+	// Compactors are compacted on every pass, e.g. the in-memory caches and
+	// vector indexes of package memory.
+	Compactors []retrieve.Compactor
+	// Interval is the time between passes when running (default 1h).
+	Interval time.Duration
+	// GrowthThreshold triggers a rebuild when the node count has grown by
+	// this fraction since the last rebuild (default 0.5).
+	GrowthThreshold float64
+	// DeadTupleRatio triggers a rebuild when the dead tuples accumulated
+	// since the last rebuild exceed this fraction of the node count (default
+	// 0.2). Rebuilding doesn't reclaim dead tuples, so they are counted from
+	// the last rebuild until a vacuum lowers the count.
+	DeadTupleRatio float64
+	// MinNodes is the node count below which indexes are never rebuilt
+	// (default 1000).
+	MinNodes int64
+	// Observer receives maintenance events if it implements
+	// retrieve.MaintenanceObserver.
+	Observer retrieve.Observer
+}
+
+// Action describes a maintenance action taken by the Optimizer.
+type Action struct {
+	// Target is the index name, or the compactor type for compactions.
+	Target string
+	// Kind is the action performed.
+	Kind OptimizerAction
+	// Reason explains why the action was taken.
+	Reason string
+	// Err is the error returned by the action, if any.
+	Err error
+}
+
+// Optimizer watches index statistics and performs background maintenance:
+// it rebuilds indexes that have grown or accumulated dead tuples, refreshes
+// statistics for indexes that changed, and compacts in-memory components.
+type Optimizer struct {
+	config OptimizerConfig
+	mu     sync.Mutex
+	state  map[string]*indexState
+}
+
+// indexState tracks node counts observed for an index.
+type indexState struct {
+	built int64 // Node count at the last rebuild, or when first seen
+	seen  int64 // Node count at the previous pass
+	dead  int64 // Dead tuples at the last rebuild
+}
+
+// NewOptimizer creates a new index optimizer.
+func NewOptimizer(cfg OptimizerConfig) *Optimizer {
+	if cfg.Interval == 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.GrowthThreshold == 0 {
+		cfg.GrowthThreshold = 0.5
+	}
+	if cfg.DeadTupleRatio == 0 {
+		cfg.DeadTupleRatio = 0.2
+	}
+	if cfg.MinNodes == 0 {
+		cfg.MinNodes = 1000
+	}
+	return &Optimizer{config: cfg, state: make(map[string]*indexState)}
+}
+
+// Run performs a pass every Interval until ctx is canceled, then returns
+// the context error. Intended to be started in its own goroutine; failures
+// are reported through the Observer.
+func (o *Optimizer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = o.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single maintenance pass and returns the actions taken.
+// The returned error joins every failure; other indexes are still processed.
+func (o *Optimizer) RunOnce(ctx context.Context) ([]Action, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var actions []Action
+	var errs []error
+
+	if o.config.Manager != nil {
+		names := o.config.Indexes
+		if len(names) == 0 {
+			var err error
+			if names, err = o.config.Manager.ListIndexes(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to list indexes: %w", err))
+			}
+		}
+		for _, name := range names {
+			acted, err := o.optimizeIndex(ctx, name)
+			actions = append(actions, acted...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, c := range o.config.Compactors {
+		action := o.act(ctx, fmt.Sprintf("%T", c), ActionCompact, "scheduled", c.Compact)
+		actions = append(actions, action)
+		if action.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to compact %s: %w", action.Target, action.Err))
+		}
+	}
+
+	return actions, errors.Join(errs...)
+}
+
+// optimizeIndex checks one index and rebuilds or refreshes it as needed.
+func (o *Optimizer) optimizeIndex(ctx context.Context, name string) ([]Action, error) {
+	stats, err := o.config.Manager.IndexStats(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats for index %s: %w", name, err)
+	}
+
+	state, ok := o.state[name]
+	if !ok {
+		state = &indexState{built: stats.NodeCount, seen: stats.NodeCount}
+		o.state[name] = state
+	}
+	changed := stats.NodeCount != state.seen
+	state.seen = stats.NodeCount
+	if stats.DeadTuples < state.dead {
+		// A vacuum reclaimed dead tuples since the last rebuild
+		state.dead = stats.DeadTuples
+	}
+
+	optimizer, ok := o.config.Manager.(IndexOptimizer)
+	if !ok {
+		return nil, nil
+	}
+
+	if reason := o.rebuildReason(stats, state); reason != "" {
+		action := o.act(ctx, name, ActionRebuild, reason, func(ctx context.Context) error {
+			return optimizer.RebuildIndex(ctx, name)
+		})
+		if action.Err != nil {
+			return []Action{action}, fmt.Errorf("failed to rebuild index %s: %w", name, action.Err)
+		}
+		state.built = stats.NodeCount
+		state.dead = stats.DeadTuples
+		return []Action{action}, nil
+	}
+
+	if changed {
+		action := o.act(ctx, name, ActionRefreshStats, "node count changed", func(ctx context.Context) error {
+			return optimizer.RefreshStats(ctx, name)
+		})
+		if action.Err != nil {
+			return []Action{action}, fmt.Errorf("failed to refresh stats for index %s: %w", name, action.Err)
+		}
+		return []Action{action}, nil
+	}
+	return nil, nil
+}
+
+// rebuildReason returns why an index needs rebuilding, or "" if it doesn't.
+func (o *Optimizer) rebuildReason(stats *IndexStats, state *indexState) string {
+	switch stats.IndexType {
+	case IndexTypeHNSW, IndexTypeIVFFlat, IndexTypeDiskANN:
+	default:
+		return ""
+	}
+	if stats.NodeCount < o.config.MinNodes {
+		return ""
+	}
+	if dead := stats.DeadTuples - state.dead; dead > 0 && float64(dead) > o.config.DeadTupleRatio*float64(stats.NodeCount) {
+		return fmt.Sprintf("dead tuples %d exceed %.0f%% of %d nodes", dead, o.config.DeadTupleRatio*100, stats.NodeCount)
+	}
+	if growth := stats.NodeCount - state.built; growth > 0 && float64(growth) >= o.config.GrowthThreshold*float64(state.built) {
+		return fmt.Sprintf("grew from %d to %d nodes since last rebuild", state.built, stats.NodeCount)
+	}
+	return ""
+}
+
+// act runs a maintenance action and reports it to the observer.
+func (o *Optimizer) act(ctx context.Context, target string, kind OptimizerAction, reason string, fn func(ctx context.Context) error) Action {
+	start := time.Now()
+	err := fn(ctx)
+	if obs, ok := o.config.Observer.(retrieve.MaintenanceObserver); ok {
+		obs.OnMaintenance(ctx, target, string(kind), reason, err, time.Since(start).Milliseconds())
+	}
+	return Action{Target: target, Kind: kind, Reason: reason, Err: err}
+}
@@ -0,0 +1,78 @@
+package vector
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// PageIndex extends Index with cursor-paginated search, so UIs can page
+// through results without re-running searches with a larger k.
+type PageIndex interface {
+	Index
+	// SearchPage returns up to limit results ranked after cursor, in
+	// descending score order with ties broken by ID, and the cursor of the
+	// next page. Pass "" for the first page. The returned cursor is "" after
+	// a short page; a full last page is followed by an empty one.
+	SearchPage(ctx context.Context, embedding []float32, cursor string, limit int, opts SearchOptions) ([]SearchResult, string, error)
+}
+
+// PageCursor is the position of the last result of a search page. Its
+// encoded form is the opaque cursor token passed to SearchPage.
+type PageCursor struct {
+	// Score is the score of the last result.
+	Score float64 `json:"s"`
+	// ID is the ID of the last result.
+	ID string `json:"id"`
+}
+
+// ParsePageCursor parses a cursor token. The empty token parses to the
+// zero PageCursor, which precedes every result.
+func ParsePageCursor(token string) (PageCursor, error) {
+	var c PageCursor
+	if token == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.ID == "" {
+		return PageCursor{}, fmt.Errorf("invalid page cursor %q", token)
+	}
+	return c, nil
+}
+
+// IsZero reports whether c is the zero PageCursor.
+func (c PageCursor) IsZero() bool {
+	return c.ID == ""
+}
+
+// String returns the cursor token, or "" for the zero PageCursor.
+func (c PageCursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Precedes reports whether r ranks after c: with a lower score, or an equal
+// score and a greater ID.
+func (c PageCursor) Precedes(r SearchResult) bool {
+	if c.IsZero() {
+		return true
+	}
+	return r.Score < c.Score || (r.Score == c.Score && r.Node.ID > c.ID)
+}
+
+// NextPageCursor returns the cursor token following a page of results, or
+// "" if the page holds fewer than limit results and so is the last.
+func NextPageCursor(results []SearchResult, limit int) string {
+	if len(results) == 0 || len(results) < limit {
+		return ""
+	}
+	last := results[len(results)-1]
+	return PageCursor{Score: last.Score, ID: last.Node.ID}.String()
+}
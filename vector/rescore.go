@@ -0,0 +1,69 @@
+package vector
+
+import (
+	"math"
+	"sort"
+)
+
+// Similarity returns the similarity of a and b under metric, higher being
+// more similar: the cosine similarity (the default), the dot product, or
+// 1 / (1 + Euclidean distance). It returns 0 if their lengths differ.
+func Similarity(metric DistanceMetric, a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	switch metric {
+	case DistanceDot:
+		var dot float64
+		for i := range a {
+			dot += float64(a[i]) * float64(b[i])
+		}
+		return dot
+	case DistanceEuclidean:
+		var sum float64
+		for i := range a {
+			d := float64(a[i]) - float64(b[i])
+			sum += d * d
+		}
+		return 1 / (1 + math.Sqrt(sum))
+	default:
+		return cosineSimilarity(a, b)
+	}
+}
+
+// Rescore replaces the scores of results with their exact Similarity to
+// embedding under metric, and returns the k best in descending score order.
+// Results without an embedding keep their index score. results is sorted in
+// place.
+func Rescore(results []SearchResult, embedding []float32, metric DistanceMetric, k int) []SearchResult {
+	for i := range results {
+		if len(results[i].Node.Embedding) > 0 {
+			results[i].Score = Similarity(metric, embedding, results[i].Node.Embedding)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if their
+// lengths differ or either is zero.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
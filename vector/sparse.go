@@ -0,0 +1,83 @@
+package vector
+
+import (
+	"context"
+	"sort"
+
+	"github.com/agentplexus/omniretrieve/retrieve"
+)
+
+// ErrSparseUnsupported is returned when sparse retrieval is configured for an
+// index that doesn't implement SparseIndex.
+var ErrSparseUnsupported = retrieve.NewPermanentError("index does not support sparse vectors")
+
+// SparseVector is a sparse embedding mapping term indices (e.g., SPLADE
+// vocabulary IDs or hashed BM25 terms) to weights. Absent terms weigh 0.
+type SparseVector map[uint32]float32
+
+// Dot returns the dot product of v and w, the similarity of sparse
+// embeddings.
+func (v SparseVector) Dot(w SparseVector) float64 {
+	if len(w) < len(v) {
+		v, w = w, v
+	}
+	var dot float64
+	for term, weight := range v {
+		dot += float64(weight) * float64(w[term])
+	}
+	return dot
+}
+
+// SparseEmbedder creates sparse embeddings for text, e.g. with SPLADE or
+// BM25 term weighting.
+type SparseEmbedder interface {
+	// EmbedSparse creates a sparse embedding for the given text.
+	EmbedSparse(ctx context.Context, text string) (SparseVector, error)
+	// EmbedSparseBatch creates sparse embeddings for multiple texts.
+	EmbedSparseBatch(ctx context.Context, texts []string) ([]SparseVector, error)
+	// Model returns the name of the embedding model.
+	Model() string
+}
+
+// SparseIndex extends Index with search over the nodes' SparseEmbedding,
+// for hybrid dense/sparse retrieval (e.g., Qdrant sparse vectors).
+type SparseIndex interface {
+	Index
+	// SearchSparse finds the k nodes whose sparse embeddings have the
+	// highest dot product with sparse.
+	SearchSparse(ctx context.Context, sparse SparseVector, k int, opts SearchOptions) ([]SearchResult, error)
+}
+
+// rrfK is the reciprocal rank fusion constant.
+const rrfK = 60
+
+// fuseRRF fuses the dense and sparse rankings by weighted reciprocal rank
+// fusion and returns the k best results. Scores are the fused scores scaled
+// to [0, 1], 1 being first in both rankings, so MinScore applies to them
+// like to similarities.
+func fuseRRF(dense, sparse []SearchResult, sparseWeight float64, k int) []SearchResult {
+	scores := make(map[string]float64)
+	nodes := make(map[string]Node)
+	add := func(results []SearchResult, weight float64) {
+		for rank, r := range results {
+			scores[r.Node.ID] += weight / float64(rrfK+rank+1)
+			if _, ok := nodes[r.Node.ID]; !ok {
+				nodes[r.Node.ID] = r.Node
+			}
+		}
+	}
+	add(dense, 1-sparseWeight)
+	add(sparse, sparseWeight)
+
+	fused := make([]SearchResult, 0, len(nodes))
+	for id, node := range nodes {
+		fused = append(fused, SearchResult{Node: node, Score: scores[id] * (rrfK + 1)})
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].Node.ID < fused[j].Node.ID
+	})
+	return fused[:min(k, len(fused))]
+}
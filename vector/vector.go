@@ -3,6 +3,9 @@ package vector
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/agentplexus/omniretrieve/retrieve"
@@ -16,10 +19,26 @@ type Node struct {
 	Content string
 	// Embedding is the vector embedding for this node.
 	Embedding []float32
+	// Embeddings are optional multiple embeddings for this node (e.g., one
+	// per token or chunk), scored by late interaction (MaxSim). Indexes
+	// without MultiVectorIndex support ignore them; set Embedding, e.g. with
+	// MeanPool, for single-vector search.
+	Embeddings [][]float32
+	// SparseEmbedding is an optional sparse embedding (e.g., SPLADE term
+	// weights) searched by SparseIndex. Other indexes ignore it.
+	SparseEmbedding SparseVector
 	// Source identifies where this node came from.
 	Source string
 	// Metadata contains additional node metadata.
 	Metadata map[string]string
+	// Namespace is the namespace the node belongs to in a NamespaceIndex
+	// ("" is the default namespace). Writes without one use the context's
+	// namespace (see WithNamespace).
+	Namespace string
+	// ExpiresAt is when the node expires (zero: never). Searches skip
+	// expired nodes; indexes without native TTL keep them stored until a
+	// Reaper purges them.
+	ExpiresAt time.Time
 }
 
 // SearchResult represents a single search result from vector search.
@@ -55,6 +74,17 @@ type BatchIndex interface {
 	DeleteBatch(ctx context.Context, ids []string) error
 }
 
+// GetIndex extends Index with lookup of nodes by ID, e.g. to ground graph
+// results in their stored content without a similarity search.
+type GetIndex interface {
+	Index
+	// Get returns the node with the given ID, or ErrNodeNotFound.
+	Get(ctx context.Context, id string) (Node, error)
+	// GetBatch returns the nodes with the given IDs in the order of ids.
+	// IDs without a node are skipped.
+	GetBatch(ctx context.Context, ids []string) ([]Node, error)
+}
+
 // IndexConfig configures a vector index.
 type IndexConfig struct {
 	// Name is the index name.
@@ -63,10 +93,12 @@ type IndexConfig struct {
 	Dimensions int
 	// DistanceMetric is the distance function (cosine, euclidean, dot).
 	DistanceMetric DistanceMetric
-	// IndexType is the index algorithm (hnsw, ivfflat, flat).
+	// IndexType is the index algorithm (hnsw, ivfflat, diskann, flat).
 	IndexType IndexType
 	// HNSWConfig contains HNSW-specific settings.
 	HNSWConfig *HNSWConfig
+	// DiskANNConfig contains DiskANN-specific settings.
+	DiskANNConfig *DiskANNConfig
 }
 
 // DistanceMetric defines the distance function for similarity.
@@ -84,6 +116,7 @@ type IndexType string
 const (
 	IndexTypeHNSW    IndexType = "hnsw"
 	IndexTypeIVFFlat IndexType = "ivfflat"
+	IndexTypeDiskANN IndexType = "diskann"
 	IndexTypeFlat    IndexType = "flat"
 )
 
@@ -97,6 +130,16 @@ type HNSWConfig struct {
 	EfSearch int
 }
 
+// DiskANNConfig contains DiskANN index parameters.
+type DiskANNConfig struct {
+	// NumNeighbors is the maximum number of neighbors per node.
+	NumNeighbors int
+	// SearchListSize is the size of the candidate list during construction.
+	SearchListSize int
+	// MaxAlpha is the pruning parameter; larger values keep longer edges.
+	MaxAlpha float64
+}
+
 // IndexStats contains index statistics.
 type IndexStats struct {
 	// Name is the index name.
@@ -105,8 +148,18 @@ type IndexStats struct {
 	NodeCount int64
 	// Dimensions is the vector dimension size.
 	Dimensions int
-	// IndexSizeBytes is the approximate index size in bytes.
+	// IndexSizeBytes is the approximate size of the vector index in bytes.
 	IndexSizeBytes int64
+	// TableSizeBytes is the approximate size of the underlying storage in bytes,
+	// excluding the vector index.
+	TableSizeBytes int64
+	// IndexType is the index algorithm backing the vector column, if any.
+	IndexType IndexType
+	// IndexParams contains the build parameters of the vector index
+	// (e.g., "m", "ef_construction", "lists").
+	IndexParams map[string]string
+	// DeadTuples is the number of deleted or obsolete rows not yet reclaimed.
+	DeadTuples int64
 }
 
 // IndexManager provides index lifecycle operations.
@@ -119,7 +172,7 @@ type IndexManager interface {
 	IndexExists(ctx context.Context, name string) (bool, error)
 	// IndexStats returns statistics for an index.
 	IndexStats(ctx context.Context, name string) (*IndexStats, error)
-	// ListIndexes returns all index names.
+	// ListIndexes returns all index names. Aliases are not included.
 	ListIndexes(ctx context.Context) ([]string, error)
 }
 
@@ -139,10 +192,38 @@ type RetrieverConfig struct {
 	Index Index
 	// Embedder creates embeddings for queries.
 	Embedder Embedder
+	// EmbeddingCache caches the Embedder's query embeddings by model and
+	// query text (optional), e.g. a memory.EmbeddingCache.
+	EmbeddingCache EmbeddingCache
+	// MultiEmbedder, if set, creates multiple embeddings for query text, and
+	// results are scored by late interaction (MaxSim) instead. It is used
+	// unless the query has a precomputed Embedding.
+	MultiEmbedder MultiEmbedder
+	// SparseEmbedder, if set, also embeds query text sparsely, and the
+	// results of the index's sparse search are fused with the dense results
+	// by weighted reciprocal rank fusion. The index must implement
+	// SparseIndex.
+	SparseEmbedder SparseEmbedder
+	// SparseWeight is the weight of the sparse ranking in fusion; the dense
+	// ranking gets 1 - SparseWeight (default 0.5).
+	SparseWeight float64
+	// MultiVectorCandidates is the number of candidates re-scored by MaxSim
+	// for indexes that don't implement MultiVectorIndex (default 4 * TopK).
+	MultiVectorCandidates int
+	// Oversample fetches TopK * Oversample candidates from the index and
+	// re-scores them exactly with Metric before truncating to TopK,
+	// recovering recall lost by approximate indexes. Factors of 1 or less
+	// disable it (default). Query.Oversample overrides it per query.
+	Oversample float64
+	// Metric is the distance metric oversampled candidates are re-scored
+	// with (default cosine). It should match the index's metric.
+	Metric DistanceMetric
 	// DefaultTopK is the default number of results to return.
 	DefaultTopK int
 	// MinScore is the minimum similarity score threshold.
 	MinScore float64
+	// Autocut truncates results at a score gap instead of returning all TopK.
+	Autocut retrieve.Autocut
 	// Observer for tracing and metrics.
 	Observer retrieve.Observer
 }
@@ -157,23 +238,25 @@ func NewRetriever(cfg RetrieverConfig) *Retriever {
 	if cfg.DefaultTopK == 0 {
 		cfg.DefaultTopK = 10
 	}
+	if cfg.SparseWeight == 0 {
+		cfg.SparseWeight = 0.5
+	}
 	return &Retriever{config: cfg}
 }
 
+// Warmup implements retrieve.Warmer by validating the configuration and
+// warming the index and embedder when they support it.
+func (r *Retriever) Warmup(ctx context.Context) error {
+	if r.config.Index == nil {
+		return errors.New("vector retriever: index is required")
+	}
+	return retrieve.Warmup(ctx, r.config.Index, r.config.Embedder, r.config.MultiEmbedder, r.config.SparseEmbedder)
+}
+
 // Retrieve performs vector similarity search.
 func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.Result, error) {
 	start := time.Now()
 
-	// Get or compute embedding
-	embedding := q.Embedding
-	if len(embedding) == 0 && r.config.Embedder != nil {
-		var err error
-		embedding, err = r.config.Embedder.Embed(ctx, q.Text)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	// Determine top-k
 	topK := q.TopK
 	if topK == 0 {
@@ -181,7 +264,7 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 	}
 
 	// Perform search
-	results, err := r.config.Index.Search(ctx, embedding, topK, q.Filters)
+	results, err := r.search(ctx, q, topK)
 	if err != nil {
 		return nil, err
 	}
@@ -192,9 +275,13 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 		minScore = r.config.MinScore
 	}
 
+	searchLatency := time.Since(start).Milliseconds()
+
 	items := make([]retrieve.ContextItem, 0, len(results))
+	dropped := 0
 	for _, res := range results {
 		if res.Score < minScore {
+			dropped++
 			continue
 		}
 		items = append(items, retrieve.ContextItem{
@@ -211,6 +298,10 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 		})
 	}
 
+	// Truncate the irrelevant tail if autocut is enabled
+	filteredCount := len(items)
+	items = r.config.Autocut.Apply(items)
+
 	latency := time.Since(start).Milliseconds()
 
 	// Report to observer
@@ -218,7 +309,7 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 		r.config.Observer.OnVectorSearch(ctx, r.config.Index.Name(), topK, len(items), latency)
 	}
 
-	return &retrieve.Result{
+	result := &retrieve.Result{
 		Items: items,
 		Query: q,
 		Metadata: retrieve.ResultMetadata{
@@ -226,5 +317,124 @@ func (r *Retriever) Retrieve(ctx context.Context, q retrieve.Query) (*retrieve.R
 			LatencyMS:       latency,
 			ModesUsed:       []retrieve.Mode{retrieve.ModeVector},
 		},
-	}, nil
+	}
+
+	if q.Explain {
+		result.Debug = &retrieve.Debug{
+			Filters:  q.Filters,
+			MinScore: minScore,
+			Stages: []retrieve.StageDebug{{
+				Name:       "vector.search",
+				Backend:    r.config.Index.Name(),
+				Candidates: len(results),
+				Returned:   filteredCount,
+				LatencyMS:  searchLatency,
+			}},
+			DroppedByMinScore: dropped,
+		}
+		if r.config.Autocut.Mode != retrieve.AutocutOff {
+			result.Debug.Stages = append(result.Debug.Stages, retrieve.StageDebug{
+				Name:       "vector.autocut",
+				Candidates: filteredCount,
+				Returned:   len(items),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// search embeds the query and searches the index, in the query's namespace
+// if set, by late interaction if a MultiEmbedder is configured, and fused
+// with sparse search if a SparseEmbedder is.
+func (r *Retriever) search(ctx context.Context, q retrieve.Query, k int) ([]SearchResult, error) {
+	if q.Namespace != "" {
+		if _, ok := r.config.Index.(NamespaceIndex); !ok {
+			return nil, ErrNamespacesUnsupported
+		}
+		ctx = WithNamespace(ctx, q.Namespace)
+	}
+
+	if len(q.Embedding) == 0 && r.config.MultiEmbedder != nil {
+		embeddings, err := r.config.MultiEmbedder.EmbedMulti(ctx, q.Text)
+		if err != nil {
+			return nil, err
+		}
+		return r.searchMulti(ctx, embeddings, k, q.Filters)
+	}
+
+	// Get or compute embedding
+	embedding := q.Embedding
+	if len(embedding) == 0 && r.config.Embedder != nil {
+		var err error
+		embedding, err = r.embed(ctx, q.Text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.config.SparseEmbedder != nil && q.Text != "" {
+		return r.searchHybrid(ctx, q, embedding, k)
+	}
+	return r.searchDense(ctx, q, embedding, k)
+}
+
+// searchDense searches the index for embedding, oversampling candidates if
+// configured.
+func (r *Retriever) searchDense(ctx context.Context, q retrieve.Query, embedding []float32, k int) ([]SearchResult, error) {
+	oversample := q.Oversample
+	if oversample == 0 {
+		oversample = r.config.Oversample
+	}
+	if oversample <= 1 || len(embedding) == 0 {
+		return r.searchIndex(ctx, q, embedding, k)
+	}
+	results, err := r.searchIndex(ctx, q, embedding, int(math.Ceil(float64(k)*oversample)))
+	if err != nil {
+		return nil, err
+	}
+	metric := r.config.Metric
+	if q.Metric != "" {
+		metric = DistanceMetric(q.Metric)
+	}
+	return Rescore(results, embedding, metric, k), nil
+}
+
+// searchIndex searches the index, with the query's metric if set, which
+// requires a TunableIndex.
+func (r *Retriever) searchIndex(ctx context.Context, q retrieve.Query, embedding []float32, k int) ([]SearchResult, error) {
+	if q.Metric == "" {
+		return r.config.Index.Search(ctx, embedding, k, q.Filters)
+	}
+	idx, ok := r.config.Index.(TunableIndex)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMetricUnsupported, q.Metric)
+	}
+	return idx.SearchWithOptions(ctx, embedding, k, SearchOptions{
+		Filter: FilterFromMap(q.Filters),
+		Metric: DistanceMetric(q.Metric),
+	})
+}
+
+// searchHybrid fuses the dense and sparse rankings of 4 * k candidates each.
+func (r *Retriever) searchHybrid(ctx context.Context, q retrieve.Query, embedding []float32, k int) ([]SearchResult, error) {
+	idx, ok := r.config.Index.(SparseIndex)
+	if !ok {
+		return nil, ErrSparseUnsupported
+	}
+	sparse, err := r.config.SparseEmbedder.EmbedSparse(ctx, q.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := 4 * k
+	dense, err := r.searchDense(ctx, q, embedding, candidates)
+	if err != nil {
+		return nil, err
+	}
+	sparseResults, err := idx.SearchSparse(ctx, sparse, candidates, SearchOptions{Filter: FilterFromMap(q.Filters)})
+	if err != nil {
+		return nil, err
+	}
+	return fuseRRF(dense, sparseResults, r.config.SparseWeight, k), nil
 }
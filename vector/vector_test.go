@@ -2,10 +2,20 @@ package vector_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/observe"
 	"github.com/agentplexus/omniretrieve/retrieve"
+	"github.com/agentplexus/omniretrieve/retrievetest"
 	"github.com/agentplexus/omniretrieve/vector"
 )
 
@@ -162,3 +172,1269 @@ func TestVectorRetrieverMinScore(t *testing.T) {
 	// Note: with hash embedder, similarity might still be high
 	t.Logf("got %d results with min score filter", len(result.Items))
 }
+
+func TestVectorRetrieverExplain(t *testing.T) {
+	ctx := context.Background()
+
+	idx := memory.NewVectorIndex("test-index")
+	embedder := memory.NewHashEmbedder(128)
+
+	for i, text := range []string{"alpha document", "beta document", "gamma document"} {
+		embedding, _ := embedder.Embed(ctx, text)
+		if err := idx.Insert(ctx, vector.Node{
+			ID:        string(rune('A' + i)),
+			Content:   text,
+			Embedding: embedding,
+		}); err != nil {
+			t.Fatalf("failed to insert node: %v", err)
+		}
+	}
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{
+		Index:    idx,
+		Embedder: embedder,
+		MinScore: 0.99,
+	})
+
+	result, err := retriever.Retrieve(ctx, retrieve.Query{
+		Text:    "alpha document",
+		Explain: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+
+	if result.Debug == nil {
+		t.Fatal("expected debug section when Explain is set")
+	}
+	if len(result.Debug.Stages) != 1 || result.Debug.Stages[0].Name != "vector.search" {
+		t.Fatalf("unexpected stages: %+v", result.Debug.Stages)
+	}
+
+	stage := result.Debug.Stages[0]
+	if stage.Candidates != 3 {
+		t.Errorf("expected 3 candidates, got %d", stage.Candidates)
+	}
+	if stage.Returned != len(result.Items) {
+		t.Errorf("expected %d returned, got %d", len(result.Items), stage.Returned)
+	}
+	if result.Debug.DroppedByMinScore != stage.Candidates-stage.Returned {
+		t.Errorf("expected %d dropped, got %d", stage.Candidates-stage.Returned, result.Debug.DroppedByMinScore)
+	}
+
+	// Without Explain no debug section is produced
+	result, err = retriever.Retrieve(ctx, retrieve.Query{Text: "alpha document"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if result.Debug != nil {
+		t.Error("expected no debug section without Explain")
+	}
+}
+
+func TestVectorRetrieverWarmup(t *testing.T) {
+	ctx := context.Background()
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{
+		Index:    memory.NewVectorIndex("test-index"),
+		Embedder: memory.NewHashEmbedder(128),
+	})
+	if err := retrieve.Warmup(ctx, retriever); err != nil {
+		t.Errorf("unexpected warmup error: %v", err)
+	}
+
+	// A retriever without an index fails warmup
+	if err := retrieve.Warmup(ctx, vector.NewRetriever(vector.RetrieverConfig{})); err == nil {
+		t.Error("expected warmup error for retriever without index")
+	}
+}
+
+func TestVectorRetrieverAutocut(t *testing.T) {
+	ctx := context.Background()
+
+	scores := []float64{0.92, 0.90, 0.88, 0.41, 0.40, 0.38}
+	results := make([]vector.SearchResult, len(scores))
+	for i, score := range scores {
+		results[i] = vector.SearchResult{Node: vector.Node{ID: string(rune('A' + i))}, Score: score}
+	}
+
+	for _, mode := range []retrieve.AutocutMode{retrieve.AutocutLargestGap, retrieve.AutocutKnee} {
+		t.Run(string(mode), func(t *testing.T) {
+			retriever := vector.NewRetriever(vector.RetrieverConfig{
+				Index:   &retrievetest.Index{SearchResults: [][]vector.SearchResult{results}},
+				Autocut: retrieve.Autocut{Mode: mode},
+			})
+
+			result, err := retriever.Retrieve(ctx, retrieve.Query{Embedding: []float32{1}, Explain: true})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+
+			if len(result.Items) != 3 {
+				t.Fatalf("expected autocut to keep 3 items, got %d", len(result.Items))
+			}
+
+			stages := result.Debug.Stages
+			if len(stages) != 2 || stages[1].Name != "vector.autocut" || stages[1].Candidates != 6 {
+				t.Errorf("unexpected debug stages: %+v", stages)
+			}
+		})
+	}
+}
+
+func TestAutocutMinItems(t *testing.T) {
+	autocut := retrieve.Autocut{Mode: retrieve.AutocutLargestGap, MinItems: 2}
+
+	if keep := autocut.Cut([]float64{0.9, 0.2, 0.19}); keep != 2 {
+		t.Errorf("expected MinItems to keep 2, got %d", keep)
+	}
+
+	// Flat scores have no gap and are kept in full
+	if keep := autocut.Cut([]float64{0.5, 0.5, 0.5}); keep != 3 {
+		t.Errorf("expected flat scores to be kept, got %d", keep)
+	}
+
+	// Disabled autocut keeps everything
+	if keep := (retrieve.Autocut{}).Cut([]float64{0.9, 0.1}); keep != 2 {
+		t.Errorf("expected disabled autocut to keep 2, got %d", keep)
+	}
+}
+
+func TestInvalidatingIndex(t *testing.T) {
+	ctx := context.Background()
+
+	embedder := memory.NewHashEmbedder(128)
+	bus := retrieve.NewInvalidationBus()
+	cache := memory.NewResultCache(memory.ResultCacheConfig{})
+	unsubscribe := bus.Subscribe(cache)
+	defer unsubscribe()
+
+	idx := vector.NewInvalidatingIndex(vector.InvalidatingIndexConfig{
+		Index: memory.NewVectorIndex("test-index"),
+		Bus:   bus,
+	})
+
+	embedding, err := embedder.Embed(ctx, "Go is a statically typed programming language")
+	if err != nil {
+		t.Fatalf("failed to embed text: %v", err)
+	}
+	if err := idx.Upsert(ctx, vector.Node{ID: "A", Content: "Go", Embedding: embedding}); err != nil {
+		t.Fatalf("failed to upsert node: %v", err)
+	}
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{Index: idx, Embedder: embedder})
+	query := retrieve.Query{Text: "Go programming", TopK: 5}
+
+	result, err := retriever.Retrieve(ctx, query)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if err := cache.Set(ctx, query, result); err != nil {
+		t.Fatalf("failed to cache result: %v", err)
+	}
+
+	cached, ok := cache.Get(ctx, query)
+	if !ok || !cached.Metadata.CacheHit {
+		t.Fatal("expected cache hit before write")
+	}
+
+	// Writing an unrelated ID leaves the entry in place
+	if err := idx.UpsertBatch(ctx, []vector.Node{{ID: "B", Content: "other", Embedding: embedding}}); err != nil {
+		t.Fatalf("failed to upsert batch: %v", err)
+	}
+	if _, ok := cache.Get(ctx, query); !ok {
+		t.Error("expected cache hit after unrelated write")
+	}
+
+	// Re-ingesting a cached ID evicts the entry
+	if err := idx.Upsert(ctx, vector.Node{ID: "A", Content: "Go v2", Embedding: embedding}); err != nil {
+		t.Fatalf("failed to upsert node: %v", err)
+	}
+	if _, ok := cache.Get(ctx, query); ok {
+		t.Error("expected cache miss after re-ingesting cached ID")
+	}
+
+	// FlushOnWrite evicts every entry in the namespace on any upsert
+	if err := cache.Set(ctx, query, result); err != nil {
+		t.Fatalf("failed to cache result: %v", err)
+	}
+	flushing := vector.NewInvalidatingIndex(vector.InvalidatingIndexConfig{
+		Index:        memory.NewVectorIndex("test-index"),
+		Bus:          bus,
+		FlushOnWrite: true,
+	})
+	if err := flushing.Insert(ctx, vector.Node{ID: "C", Content: "new", Embedding: embedding}); err != nil {
+		t.Fatalf("failed to insert node: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected namespace flush to empty the cache, got %d entries", cache.Len())
+	}
+}
+
+// flakyIndex fails UpsertBatch for chunks containing failID until failures
+// reaches zero.
+type flakyIndex struct {
+	*memory.VectorIndex
+	mu       sync.Mutex
+	failID   string
+	failures int
+}
+
+func (idx *flakyIndex) UpsertBatch(ctx context.Context, nodes []vector.Node) error {
+	idx.mu.Lock()
+	for _, n := range nodes {
+		if n.ID == idx.failID && idx.failures != 0 {
+			idx.failures--
+			idx.mu.Unlock()
+			return errors.New("transient failure")
+		}
+	}
+	idx.mu.Unlock()
+	return idx.VectorIndex.UpsertBatch(ctx, nodes)
+}
+
+func TestChunkedUpserter(t *testing.T) {
+	ctx := context.Background()
+
+	nodes := make([]vector.Node, 25)
+	for i := range nodes {
+		nodes[i] = vector.Node{ID: fmt.Sprintf("n%02d", i), Embedding: []float32{1, 0}}
+	}
+
+	t.Run("all chunks succeed", func(t *testing.T) {
+		idx := &retrievetest.Index{}
+		upserter := vector.NewChunkedUpserter(vector.ChunkedUpserterConfig{Index: idx, ChunkSize: 10})
+		if err := upserter.Upsert(ctx, nodes); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := idx.CallCount(retrievetest.MethodUpsertBatch); n != 3 {
+			t.Errorf("expected 3 chunks, got %d", n)
+		}
+	})
+
+	t.Run("transient failure is retried", func(t *testing.T) {
+		idx := &flakyIndex{VectorIndex: memory.NewVectorIndex("test"), failID: "n12", failures: 1}
+		upserter := vector.NewChunkedUpserter(vector.ChunkedUpserterConfig{
+			Index:        idx,
+			ChunkSize:    10,
+			MaxRetries:   1,
+			RetryBackoff: time.Millisecond,
+		})
+		if err := upserter.Upsert(ctx, nodes); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if idx.Count() != len(nodes) {
+			t.Errorf("expected %d nodes, got %d", len(nodes), idx.Count())
+		}
+	})
+
+	t.Run("persistent failure is reported", func(t *testing.T) {
+		idx := &flakyIndex{VectorIndex: memory.NewVectorIndex("test"), failID: "n12", failures: -1}
+		upserter := vector.NewChunkedUpserter(vector.ChunkedUpserterConfig{
+			Index:        idx,
+			ChunkSize:    10,
+			MaxRetries:   2,
+			RetryBackoff: time.Millisecond,
+		})
+
+		err := upserter.Upsert(ctx, nodes)
+		var batchErr *vector.BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("expected *vector.BatchError, got %v", err)
+		}
+		if len(batchErr.Chunks) != 1 {
+			t.Fatalf("expected 1 failed chunk, got %d", len(batchErr.Chunks))
+		}
+		chunk := batchErr.Chunks[0]
+		if chunk.Offset != 10 || chunk.Size != 10 || chunk.Attempts != 3 {
+			t.Errorf("unexpected chunk error: %+v", chunk)
+		}
+		if ids := batchErr.FailedIDs(nodes); len(ids) != 10 || ids[0] != "n10" {
+			t.Errorf("unexpected failed IDs: %v", ids)
+		}
+		if idx.Count() != 15 {
+			t.Errorf("expected successful chunks to be written, got %d nodes", idx.Count())
+		}
+	})
+}
+
+func TestBulkImporter(t *testing.T) {
+	ctx := context.Background()
+
+	nodes := make(chan vector.Node)
+	go func() {
+		defer close(nodes)
+		for i := range 25 {
+			nodes <- vector.Node{ID: fmt.Sprintf("n%02d", i), Embedding: []float32{1, 0}}
+		}
+	}()
+
+	idx := &flakyIndex{VectorIndex: memory.NewVectorIndex("test"), failID: "n12", failures: -1}
+	var updates []vector.BulkProgress
+	importer := vector.NewBulkImporter(vector.BulkImporterConfig{
+		Index:        idx,
+		BatchSize:    10,
+		Concurrency:  2,
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+		OnProgress:   func(p vector.BulkProgress) { updates = append(updates, p) },
+	})
+
+	progress, err := importer.ImportChan(ctx, nodes)
+	var batchErr *vector.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected *vector.BatchError, got %v", err)
+	}
+	if len(batchErr.Chunks) != 1 || batchErr.Chunks[0].Offset != 10 || batchErr.Chunks[0].Attempts != 2 {
+		t.Errorf("unexpected failed batches: %v", batchErr)
+	}
+	if progress.Imported != 15 || progress.Failed != 10 || progress.Batches != 3 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+	if progress.LastError == nil || progress.Throughput() <= 0 {
+		t.Errorf("expected last error and throughput, got %+v", progress)
+	}
+	if len(updates) != 3 || updates[2].Imported+updates[2].Failed != 25 {
+		t.Errorf("expected a progress update per batch, got %+v", updates)
+	}
+	if idx.Count() != 15 {
+		t.Errorf("expected successful batches to be written, got %d nodes", idx.Count())
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	seq := func(yield func(vector.Node) bool) {
+		yield(vector.Node{ID: "x", Embedding: []float32{1, 0}})
+	}
+	if _, err := importer.Import(canceled, seq); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestReaper(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	idx := memory.NewVectorIndex("test")
+	if err := idx.UpsertBatch(ctx, []vector.Node{
+		{ID: "expired", Embedding: []float32{1, 0}, ExpiresAt: time.Now().Add(-time.Second)},
+		{ID: "live", Embedding: []float32{1, 0}},
+	}); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+
+	reaped := make(chan int64, 1)
+	reaper := vector.NewReaper(vector.ReaperConfig{
+		Index:    idx,
+		Interval: time.Millisecond,
+		OnReap: func(purged int64, err error) {
+			if err == nil && purged > 0 {
+				reaped <- purged
+			}
+		},
+	})
+	done := make(chan error)
+	go func() { done <- reaper.Run(ctx) }()
+
+	if purged := <-reaped; purged != 1 {
+		t.Errorf("expected 1 purged node, got %d", purged)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if idx.Count() != 1 {
+		t.Errorf("expected the live node to remain, got %d nodes", idx.Count())
+	}
+}
+
+func TestCollectionRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	registry := vector.NewCollectionRegistry(vector.CollectionRegistryConfig{
+		Open:   memory.OpenVectorIndex,
+		Prefix: "app_",
+	})
+
+	if err := registry.Register(vector.CollectionConfig{Name: "docs", Index: vector.IndexConfig{Dimensions: 128}}); err != nil {
+		t.Fatalf("failed to register collection: %v", err)
+	}
+	if err := registry.Register(vector.CollectionConfig{Name: "tickets", Index: vector.IndexConfig{Name: "support_tickets"}}); err != nil {
+		t.Fatalf("failed to register collection: %v", err)
+	}
+	if err := registry.Register(vector.CollectionConfig{Name: "docs"}); err == nil {
+		t.Error("expected error for duplicate collection")
+	}
+
+	if names := registry.Names(); len(names) != 2 || names[0] != "docs" || names[1] != "tickets" {
+		t.Errorf("unexpected collection names: %v", names)
+	}
+
+	docs, err := registry.Index(ctx, "docs")
+	if err != nil {
+		t.Fatalf("failed to open collection: %v", err)
+	}
+	if docs.Name() != "app_docs" {
+		t.Errorf("expected prefixed index name, got %s", docs.Name())
+	}
+
+	again, err := registry.Index(ctx, "docs")
+	if err != nil || again != docs {
+		t.Error("expected the opened index to be reused")
+	}
+
+	tickets, err := registry.Index(ctx, "tickets")
+	if err != nil || tickets.Name() != "support_tickets" {
+		t.Errorf("expected explicit index name, got %v", err)
+	}
+
+	if _, err := registry.Index(ctx, "missing"); err == nil {
+		t.Error("expected error for unregistered collection")
+	}
+
+	registry.Unregister("docs")
+	if _, ok := registry.Config("docs"); ok {
+		t.Error("expected collection to be unregistered")
+	}
+}
+
+// statsManager is an IndexManager and IndexOptimizer backed by fixed stats.
+type statsManager struct {
+	stats    map[string]*vector.IndexStats
+	rebuilt  []string
+	analyzed []string
+}
+
+func (m *statsManager) CreateIndex(context.Context, vector.IndexConfig) error { return nil }
+func (m *statsManager) DropIndex(context.Context, string) error               { return nil }
+func (m *statsManager) IndexExists(context.Context, string) (bool, error)     { return true, nil }
+
+func (m *statsManager) IndexStats(_ context.Context, name string) (*vector.IndexStats, error) {
+	stats, ok := m.stats[name]
+	if !ok {
+		return nil, fmt.Errorf("index %s not found", name)
+	}
+	copied := *stats
+	return &copied, nil
+}
+
+func (m *statsManager) ListIndexes(context.Context) ([]string, error) {
+	return []string{"hnsw", "flat"}, nil
+}
+
+func (m *statsManager) RebuildIndex(_ context.Context, name string) error {
+	m.rebuilt = append(m.rebuilt, name)
+	return nil
+}
+
+func (m *statsManager) RefreshStats(_ context.Context, name string) error {
+	m.analyzed = append(m.analyzed, name)
+	return nil
+}
+
+// maintenanceRecorder records maintenance events.
+type maintenanceRecorder struct {
+	retrieve.Observer
+	events []string
+}
+
+func (r *maintenanceRecorder) OnMaintenance(_ context.Context, target, action, _ string, _ error, _ int64) {
+	r.events = append(r.events, action+":"+target)
+}
+
+func TestResultCacheCopies(t *testing.T) {
+	ctx := context.Background()
+	cache := memory.NewResultCache(memory.ResultCacheConfig{})
+	query := retrieve.Query{Text: "q"}
+
+	result := &retrieve.Result{Items: []retrieve.ContextItem{{ID: "a", Score: 0.9, Metadata: map[string]string{"k": "v"}}}}
+	if err := cache.Set(ctx, query, result); err != nil {
+		t.Fatalf("failed to cache result: %v", err)
+	}
+	result.Items[0].Score = 0
+
+	// Editing a cached result in place leaves the cache intact
+	cached, _ := cache.Get(ctx, query)
+	cached.Items[0].Score = 0.1
+	cached.Items[0].Metadata["k"] = "edited"
+
+	cached, _ = cache.Get(ctx, query)
+	if cached.Items[0].Score != 0.9 || cached.Items[0].Metadata["k"] != "v" {
+		t.Errorf("expected the cached item to be unchanged, got %+v", cached.Items[0])
+	}
+}
+
+func TestMemoryCompaction(t *testing.T) {
+	ctx := context.Background()
+	idx := memory.NewVectorIndex("test")
+	nodes := []vector.Node{
+		{ID: "live", Embedding: []float32{1, 0}},
+		{ID: "expired", Embedding: []float32{0, 1}, ExpiresAt: time.Now().Add(-time.Minute)},
+	}
+	if err := idx.InsertBatch(ctx, nodes); err != nil {
+		t.Fatalf("failed to insert nodes: %v", err)
+	}
+	embeddings := memory.NewEmbeddingCache(memory.EmbeddingCacheConfig{TTL: time.Nanosecond})
+	embeddings.Set(ctx, "q", []float32{1, 0})
+	time.Sleep(time.Millisecond)
+
+	optimizer := vector.NewOptimizer(vector.OptimizerConfig{
+		Manager: &statsManager{stats: map[string]*vector.IndexStats{
+			"hnsw": {Name: "hnsw"},
+			"flat": {Name: "flat"},
+		}},
+		Compactors: []retrieve.Compactor{idx, embeddings},
+	})
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if idx.Count() != 1 || embeddings.Len() != 0 {
+		t.Errorf("expected expired nodes and embeddings to be purged, got %d nodes and %d embeddings", idx.Count(), embeddings.Len())
+	}
+}
+
+func TestOptimizer(t *testing.T) {
+	ctx := context.Background()
+	manager := &statsManager{stats: map[string]*vector.IndexStats{
+		"hnsw": {Name: "hnsw", NodeCount: 2000, IndexType: vector.IndexTypeHNSW},
+		"flat": {Name: "flat", NodeCount: 2000, IndexType: vector.IndexTypeFlat},
+	}}
+	recorder := &maintenanceRecorder{}
+	cache := memory.NewResultCache(memory.ResultCacheConfig{TTL: time.Nanosecond})
+	_ = cache.Set(ctx, retrieve.Query{Text: "q"}, &retrieve.Result{})
+
+	optimizer := vector.NewOptimizer(vector.OptimizerConfig{
+		Manager:    manager,
+		Compactors: []retrieve.Compactor{cache},
+		Observer:   recorder,
+	})
+
+	// First pass records baselines and compacts expired cache entries
+	actions, err := optimizer.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Kind != vector.ActionCompact || cache.Len() != 0 {
+		t.Errorf("expected only a compaction, got %+v", actions)
+	}
+
+	// Growth triggers a rebuild of the HNSW index; the flat index only gets
+	// its statistics refreshed
+	manager.stats["hnsw"].NodeCount = 3000
+	manager.stats["flat"].NodeCount = 3000
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if len(manager.rebuilt) != 1 || manager.rebuilt[0] != "hnsw" {
+		t.Errorf("expected hnsw to be rebuilt, got %v", manager.rebuilt)
+	}
+	if len(manager.analyzed) != 1 || manager.analyzed[0] != "flat" {
+		t.Errorf("expected flat stats to be refreshed, got %v", manager.analyzed)
+	}
+
+	// Dead tuples trigger a rebuild without growth
+	manager.stats["hnsw"].DeadTuples = 1000
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if len(manager.rebuilt) != 2 {
+		t.Errorf("expected a second rebuild, got %v", manager.rebuilt)
+	}
+
+	// Rebuilding doesn't reclaim dead tuples, so an unchanged count doesn't
+	// trigger another rebuild
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if len(manager.rebuilt) != 2 {
+		t.Errorf("expected no rebuild for unchanged dead tuples, got %v", manager.rebuilt)
+	}
+
+	// Once a vacuum clears them, new dead tuples count again
+	manager.stats["hnsw"].DeadTuples = 0
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	manager.stats["hnsw"].DeadTuples = 1000
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if len(manager.rebuilt) != 3 {
+		t.Errorf("expected a rebuild after new dead tuples, got %v", manager.rebuilt)
+	}
+
+	if len(recorder.events) == 0 || recorder.events[0] != "compact:*memory.ResultCache" {
+		t.Errorf("unexpected maintenance events: %v", recorder.events)
+	}
+}
+
+func TestOptimizerDiskANN(t *testing.T) {
+	ctx := context.Background()
+	manager := &statsManager{stats: map[string]*vector.IndexStats{
+		"diskann": {Name: "diskann", NodeCount: 2000, IndexType: vector.IndexTypeDiskANN},
+	}}
+	optimizer := vector.NewOptimizer(vector.OptimizerConfig{Manager: manager, Indexes: []string{"diskann"}})
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+
+	manager.stats["diskann"].DeadTuples = 1000
+	if _, err := optimizer.RunOnce(ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+	if len(manager.rebuilt) != 1 {
+		t.Errorf("expected the DiskANN index to be rebuilt, got %v", manager.rebuilt)
+	}
+}
+
+func TestOptimizerRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &statsManager{}
+	optimizer := vector.NewOptimizer(vector.OptimizerConfig{Manager: manager, Indexes: []string{"missing"}, Interval: time.Millisecond})
+
+	done := make(chan error, 1)
+	go func() { done <- optimizer.Run(ctx) }()
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// tokenEmbedder embeds each word separately for late interaction.
+type tokenEmbedder struct {
+	embedder *memory.HashEmbedder
+}
+
+func (e tokenEmbedder) EmbedMulti(ctx context.Context, text string) ([][]float32, error) {
+	var embeddings [][]float32
+	for _, word := range strings.Fields(text) {
+		embedding, err := e.embedder.Embed(ctx, word)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, nil
+}
+
+// singleVectorIndex hides the optional interfaces of the wrapped index.
+type singleVectorIndex struct {
+	vector.Index
+}
+
+func TestMaxSim(t *testing.T) {
+	query := [][]float32{{1, 0}, {0, 1}}
+
+	if got := vector.MaxSim(query, [][]float32{{1, 0}, {0, 1}}); got != 1 {
+		t.Errorf("MaxSim of identical embeddings = %v, want 1", got)
+	}
+	if got := vector.MaxSim(query, [][]float32{{1, 0}}); got != 0.5 {
+		t.Errorf("MaxSim of half matching embeddings = %v, want 0.5", got)
+	}
+	if got := vector.MaxSim(query, nil); got != 0 {
+		t.Errorf("MaxSim without document embeddings = %v, want 0", got)
+	}
+
+	pooled := vector.MeanPool([][]float32{{1, 0}, {0, 1}})
+	if len(pooled) != 2 || pooled[0] != 0.5 || pooled[1] != 0.5 {
+		t.Errorf("MeanPool = %v, want [0.5 0.5]", pooled)
+	}
+}
+
+func TestVectorRetrieverMultiVector(t *testing.T) {
+	ctx := context.Background()
+
+	embedder := tokenEmbedder{embedder: memory.NewHashEmbedder(128)}
+	idx := memory.NewVectorIndex("test-index")
+
+	texts := []string{
+		"quick brown fox",
+		"lazy sleeping dog",
+		"statically typed language",
+		"brown dog barks",
+	}
+	for i, text := range texts {
+		embeddings, err := embedder.EmbedMulti(ctx, text)
+		if err != nil {
+			t.Fatalf("failed to embed text: %v", err)
+		}
+		node := vector.Node{
+			ID:         string(rune('A' + i)),
+			Content:    text,
+			Embedding:  vector.MeanPool(embeddings),
+			Embeddings: embeddings,
+		}
+		if err := idx.Insert(ctx, node); err != nil {
+			t.Fatalf("failed to insert node: %v", err)
+		}
+	}
+
+	for name, index := range map[string]vector.Index{
+		"native":   idx,
+		"fallback": singleVectorIndex{idx},
+	} {
+		t.Run(name, func(t *testing.T) {
+			retriever := vector.NewRetriever(vector.RetrieverConfig{
+				Index:         index,
+				MultiEmbedder: embedder,
+				DefaultTopK:   2,
+			})
+
+			result, err := retriever.Retrieve(ctx, retrieve.Query{Text: "brown fox"})
+			if err != nil {
+				t.Fatalf("failed to retrieve: %v", err)
+			}
+			if len(result.Items) != 2 {
+				t.Fatalf("expected 2 results, got %d", len(result.Items))
+			}
+			if result.Items[0].ID != "A" {
+				t.Errorf("expected A first, got %s", result.Items[0].ID)
+			}
+			if result.Items[0].Score < 0.99 {
+				t.Errorf("expected MaxSim score of 1 for exact match, got %v", result.Items[0].Score)
+			}
+			if result.Items[1].ID != "D" {
+				t.Errorf("expected D second, got %s", result.Items[1].ID)
+			}
+		})
+	}
+}
+
+func TestVectorRetrieverNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	idx := memory.NewVectorIndex("test-index")
+	embedder := memory.NewHashEmbedder(128)
+	for _, node := range []vector.Node{
+		{ID: "A", Content: "shared text", Namespace: "team-a"},
+		{ID: "B", Content: "shared text", Namespace: "team-b"},
+	} {
+		embedding, err := embedder.Embed(ctx, node.Content)
+		if err != nil {
+			t.Fatalf("failed to embed text: %v", err)
+		}
+		node.Embedding = embedding
+		if err := idx.Insert(ctx, node); err != nil {
+			t.Fatalf("failed to insert node: %v", err)
+		}
+	}
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{Index: idx, Embedder: embedder, DefaultTopK: 5})
+	result, err := retriever.Retrieve(ctx, retrieve.Query{Text: "shared text", Namespace: "team-b"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "B" {
+		t.Errorf("expected [B] in team-b, got %+v", result.Items)
+	}
+
+	// The default namespace is empty
+	result, err = retriever.Retrieve(ctx, retrieve.Query{Text: "shared text"})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 0 {
+		t.Errorf("expected no results in the default namespace, got %+v", result.Items)
+	}
+
+	unsupported := vector.NewRetriever(vector.RetrieverConfig{Index: singleVectorIndex{idx}, Embedder: embedder})
+	if _, err := unsupported.Retrieve(ctx, retrieve.Query{Text: "shared text", Namespace: "team-a"}); !errors.Is(err, vector.ErrNamespacesUnsupported) {
+		t.Errorf("expected ErrNamespacesUnsupported, got %v", err)
+	}
+}
+
+// countingEmbedder counts Embed calls.
+type countingEmbedder struct {
+	*memory.HashEmbedder
+	calls int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	return e.HashEmbedder.Embed(ctx, text)
+}
+
+// cacheRecorder records query embedding cache lookups.
+type cacheRecorder struct {
+	observe.NoOpObserver
+	hits, misses int
+}
+
+func (r *cacheRecorder) OnEmbeddingCache(_ context.Context, _ string, hit bool) {
+	if hit {
+		r.hits++
+	} else {
+		r.misses++
+	}
+}
+
+func TestVectorRetrieverEmbeddingCache(t *testing.T) {
+	ctx := context.Background()
+
+	embedder := &countingEmbedder{HashEmbedder: memory.NewHashEmbedder(128)}
+	recorder := &cacheRecorder{}
+	cache := memory.NewEmbeddingCache(memory.EmbeddingCacheConfig{MaxEntries: 1})
+	retriever := vector.NewRetriever(vector.RetrieverConfig{
+		Index:          memory.NewVectorIndex("test-index"),
+		Embedder:       embedder,
+		EmbeddingCache: cache,
+		Observer:       recorder,
+	})
+
+	for _, text := range []string{"first", "first", "second", "first"} {
+		if _, err := retriever.Retrieve(ctx, retrieve.Query{Text: text}); err != nil {
+			t.Fatalf("failed to retrieve: %v", err)
+		}
+	}
+
+	// "second" evicts "first" from the single-entry cache
+	if embedder.calls != 3 {
+		t.Errorf("expected 3 embedding calls, got %d", embedder.calls)
+	}
+	if recorder.hits != 1 || recorder.misses != 3 {
+		t.Errorf("expected 1 hit and 3 misses, got %d and %d", recorder.hits, recorder.misses)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 cached embedding, got %d", cache.Len())
+	}
+
+	if _, ok := cache.Get(ctx, vector.EmbeddingCacheKey(embedder.Model(), "first")); !ok {
+		t.Error("expected the embedding to be cached by model and text")
+	}
+	if _, ok := cache.Get(ctx, vector.EmbeddingCacheKey("other-model", "first")); ok {
+		t.Error("expected embeddings of other models to miss")
+	}
+}
+
+func TestEmbeddingCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := memory.NewEmbeddingCache(memory.EmbeddingCacheConfig{TTL: time.Nanosecond})
+	cache.Set(ctx, "key", []float32{1})
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Get(ctx, "key"); ok {
+		t.Error("expected expired embedding to miss")
+	}
+}
+
+// batchRecorder records EmbedBatch calls and their peak concurrency.
+type batchRecorder struct {
+	*memory.HashEmbedder
+	mu      sync.Mutex
+	batches []int
+	active  int
+	peak    int
+}
+
+func (e *batchRecorder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.batches = append(e.batches, len(texts))
+	e.active++
+	e.peak = max(e.peak, e.active)
+	e.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	embeddings, err := e.HashEmbedder.EmbedBatch(ctx, texts)
+
+	e.mu.Lock()
+	e.active--
+	e.mu.Unlock()
+	return embeddings, err
+}
+
+func TestEmbeddingIndex(t *testing.T) {
+	ctx := context.Background()
+
+	inner := memory.NewVectorIndex("test-index")
+	embedder := &batchRecorder{HashEmbedder: memory.NewHashEmbedder(16)}
+	idx := vector.NewEmbeddingIndex(vector.EmbeddingIndexConfig{
+		Index:       inner,
+		Embedder:    embedder,
+		BatchSize:   2,
+		Concurrency: 2,
+	})
+
+	preset := []float32{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	nodes := []vector.Node{
+		{ID: "a", Content: "alpha"},
+		{ID: "b", Content: "beta"},
+		{ID: "c", Content: "gamma", Embedding: preset},
+		{ID: "d", Content: "delta"},
+		{ID: "e", Content: "epsilon"},
+		{ID: "f", Content: "zeta"},
+	}
+	if err := idx.UpsertBatch(ctx, nodes); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+
+	if len(embedder.batches) != 3 {
+		t.Errorf("expected 3 embedding batches, got %v", embedder.batches)
+	}
+	if embedder.peak > 2 {
+		t.Errorf("expected at most 2 concurrent batches, got %d", embedder.peak)
+	}
+	if nodes[0].Embedding != nil {
+		t.Error("expected the caller's nodes to be left unmodified")
+	}
+
+	stored, err := inner.GetBatch(ctx, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("GetBatch failed: %v", err)
+	}
+	want, _ := embedder.Embed(ctx, "alpha")
+	if len(stored) != 2 || !slices.Equal(stored[0].Embedding, want) || !slices.Equal(stored[1].Embedding, preset) {
+		t.Errorf("expected embedded and preset embeddings, got %+v", stored)
+	}
+
+	if err := idx.Insert(ctx, vector.Node{ID: "g", Content: "eta"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if node, err := inner.Get(ctx, "g"); err != nil || len(node.Embedding) != 16 {
+		t.Errorf("expected inserted node to be embedded, got %+v (%v)", node, err)
+	}
+
+	if err := vector.NewEmbeddingIndex(vector.EmbeddingIndexConfig{Index: inner}).Insert(ctx, vector.Node{ID: "h"}); err == nil {
+		t.Error("expected an error without an embedder")
+	}
+}
+
+func TestEmbeddingIndexRateLimit(t *testing.T) {
+	ctx := context.Background()
+
+	embedder := &batchRecorder{HashEmbedder: memory.NewHashEmbedder(16)}
+	idx := vector.NewEmbeddingIndex(vector.EmbeddingIndexConfig{
+		Index:             memory.NewVectorIndex("test-index"),
+		Embedder:          embedder,
+		BatchSize:         1,
+		Concurrency:       3,
+		RequestsPerSecond: 50,
+	})
+
+	start := time.Now()
+	if err := idx.InsertBatch(ctx, []vector.Node{{ID: "a"}, {ID: "b"}, {ID: "c"}}); err != nil {
+		t.Fatalf("InsertBatch failed: %v", err)
+	}
+	// Three calls at 50 per second are spaced at least 20ms apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected rate-limited calls to take at least 40ms, took %v", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := idx.Insert(canceled, vector.Node{ID: "d"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// lossyIndex simulates an approximate index with poor recall: it returns
+// the first k nodes by ID with a zero score.
+type lossyIndex struct {
+	*memory.VectorIndex
+	ids []string
+}
+
+func (idx lossyIndex) Search(ctx context.Context, _ []float32, k int, _ map[string]string) ([]vector.SearchResult, error) {
+	nodes, err := idx.GetBatch(ctx, idx.ids[:min(k, len(idx.ids))])
+	if err != nil {
+		return nil, err
+	}
+	results := make([]vector.SearchResult, len(nodes))
+	for i, node := range nodes {
+		results[i] = vector.SearchResult{Node: node}
+	}
+	return results, nil
+}
+
+func TestVectorRetrieverOversample(t *testing.T) {
+	ctx := context.Background()
+
+	inner := memory.NewVectorIndex("test-index")
+	for _, node := range []vector.Node{
+		{ID: "a", Embedding: []float32{0, 1}},
+		{ID: "b", Embedding: []float32{0.6, 0.8}},
+		{ID: "c", Embedding: []float32{1, 0}},
+	} {
+		if err := inner.Insert(ctx, node); err != nil {
+			t.Fatalf("failed to insert node: %v", err)
+		}
+	}
+	idx := lossyIndex{VectorIndex: inner, ids: []string{"a", "b", "c"}}
+	query := retrieve.Query{Embedding: []float32{1, 0}, TopK: 1}
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{Index: idx})
+	result, err := retriever.Retrieve(ctx, query)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "a" {
+		t.Fatalf("expected the lossy index to miss the best match, got %+v", result.Items)
+	}
+
+	retriever = vector.NewRetriever(vector.RetrieverConfig{Index: idx, Oversample: 3})
+	result, err = retriever.Retrieve(ctx, query)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "c" || result.Items[0].Score != 1 {
+		t.Errorf("expected c re-scored to 1, got %+v", result.Items)
+	}
+
+	// Queries override the factor
+	query.Oversample = 1
+	result, err = retriever.Retrieve(ctx, query)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "a" {
+		t.Errorf("expected oversampling to be disabled by the query, got %+v", result.Items)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a, b := []float32{3, 0}, []float32{0, 4}
+	if got := vector.Similarity(vector.DistanceCosine, a, b); got != 0 {
+		t.Errorf("cosine similarity = %v, want 0", got)
+	}
+	if got := vector.Similarity(vector.DistanceDot, a, []float32{2, 1}); got != 6 {
+		t.Errorf("dot product = %v, want 6", got)
+	}
+	if got := vector.Similarity(vector.DistanceEuclidean, a, b); got != 1.0/6 {
+		t.Errorf("euclidean similarity = %v, want 1/6", got)
+	}
+	if got := vector.Similarity(vector.DistanceDot, a, []float32{1}); got != 0 {
+		t.Errorf("similarity of mismatched lengths = %v, want 0", got)
+	}
+}
+
+func TestCalibration(t *testing.T) {
+	cal := vector.NewCalibration([]float64{0.5, 0.1, 0.9, 0.3, 0.7})
+
+	if got := cal.Percentile(0.5); got != 0.6 {
+		t.Errorf("Percentile(0.5) = %v, want 0.6", got)
+	}
+	if got := cal.Percentile(0); got != 0 {
+		t.Errorf("Percentile(0) = %v, want 0", got)
+	}
+	if got := cal.Percentile(1); got != 1 {
+		t.Errorf("Percentile(1) = %v, want 1", got)
+	}
+	if got := cal.Threshold(0.2); got != 0.7 {
+		t.Errorf("Threshold(0.2) = %v, want 0.7", got)
+	}
+	if got := cal.Quantile(0); got != 0.1 {
+		t.Errorf("Quantile(0) = %v, want 0.1", got)
+	}
+	if got := cal.Quantile(1); got != 0.9 {
+		t.Errorf("Quantile(1) = %v, want 0.9", got)
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	ctx := context.Background()
+
+	idx := memory.NewVectorIndex("test-index")
+	nodes := []vector.Node{
+		{ID: "a", Embedding: []float32{1, 0}},
+		{ID: "b", Embedding: []float32{0.6, 0.8}},
+		{ID: "c", Embedding: []float32{0, 1}},
+	}
+	for _, node := range nodes {
+		if err := idx.Insert(ctx, node); err != nil {
+			t.Fatalf("failed to insert node: %v", err)
+		}
+	}
+
+	cal, err := vector.Calibrate(ctx, idx, vector.CalibrationConfig{Samples: nodes, K: 1})
+	if err != nil {
+		t.Fatalf("Calibrate failed: %v", err)
+	}
+	// Each sample's nearest other node, excluding itself
+	scores := cal.Scores()
+	if len(scores) != 3 || math.Abs(scores[0]-0.6) > 1e-6 || math.Abs(scores[2]-0.8) > 1e-6 {
+		t.Errorf("expected nearest-neighbor scores [0.6 0.8 0.8], got %v", scores)
+	}
+
+	if _, err := vector.Calibrate(ctx, idx, vector.CalibrationConfig{}); err == nil {
+		t.Error("expected an error without samples")
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	ctx := context.Background()
+
+	idx := memory.NewVectorIndex("test-index")
+	for i := range 7 {
+		// Pairs of nodes tie on score
+		node := vector.Node{ID: fmt.Sprint(i), Embedding: []float32{1, float32(i / 2)}}
+		if err := idx.Insert(ctx, node); err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+	}
+
+	var ids []string
+	cursor := ""
+	for range 10 {
+		page, next, err := idx.SearchPage(ctx, []float32{1, 0}, cursor, 2, vector.SearchOptions{})
+		if err != nil {
+			t.Fatalf("failed to search page: %v", err)
+		}
+		for _, r := range page {
+			ids = append(ids, r.Node.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if want := "0 1 2 3 4 5 6"; strings.Join(ids, " ") != want {
+		t.Errorf("expected %s, got %v", want, ids)
+	}
+
+	if _, _, err := idx.SearchPage(ctx, []float32{1, 0}, "bogus", 2, vector.SearchOptions{}); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+	c, err := vector.ParsePageCursor(vector.PageCursor{Score: 0.25, ID: "x"}.String())
+	if err != nil || c.Score != 0.25 || c.ID != "x" {
+		t.Errorf("cursor round trip = %+v, %v", c, err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	ctx := context.Background()
+
+	idx := memory.NewVectorIndex("test-index")
+	desc, err := idx.Describe(ctx)
+	if err != nil || desc.Dimensions != 0 || desc.Count != 0 {
+		t.Fatalf("empty index: %+v, %v", desc, err)
+	}
+	if err := desc.Check(3); err != nil {
+		t.Errorf("expected empty index to accept any dimensions, got %v", err)
+	}
+
+	_ = idx.Insert(ctx, vector.Node{ID: "a", Embedding: []float32{1, 0, 0}})
+	_ = idx.Insert(vector.WithNamespace(ctx, "other"), vector.Node{ID: "b", Embedding: []float32{0, 1, 0}})
+	desc, _ = idx.Describe(ctx)
+	if desc.Name != "test-index" || desc.Dimensions != 3 || desc.DistanceMetric != vector.DistanceCosine || desc.Count != 1 {
+		t.Errorf("unexpected description %+v", desc)
+	}
+	if exists, _ := idx.Exists(ctx, "b"); exists {
+		t.Error("expected node of another namespace not to exist")
+	}
+}
+
+// termEmbedder embeds each distinct word as a term of weight 1.
+type termEmbedder struct{}
+
+func (termEmbedder) EmbedSparse(ctx context.Context, text string) (vector.SparseVector, error) {
+	sparse := make(vector.SparseVector)
+	for _, word := range strings.Fields(text) {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		sparse[h.Sum32()] = 1
+	}
+	return sparse, nil
+}
+
+func (e termEmbedder) EmbedSparseBatch(ctx context.Context, texts []string) ([]vector.SparseVector, error) {
+	out := make([]vector.SparseVector, len(texts))
+	for i, text := range texts {
+		out[i], _ = e.EmbedSparse(ctx, text)
+	}
+	return out, nil
+}
+
+func (termEmbedder) Model() string { return "terms" }
+
+func TestVectorRetrieverSparse(t *testing.T) {
+	ctx := context.Background()
+
+	idx := memory.NewVectorIndex("test-index")
+	for _, node := range []vector.Node{
+		{ID: "a", Content: "alpha", Embedding: []float32{1, 0}},
+		{ID: "b", Content: "golang", Embedding: []float32{0.9, 0.44}},
+		{ID: "c", Content: "golang rust", Embedding: []float32{0, 1}},
+	} {
+		node.SparseEmbedding, _ = termEmbedder{}.EmbedSparse(ctx, node.Content)
+		if err := idx.Insert(ctx, node); err != nil {
+			t.Fatalf("failed to insert node: %v", err)
+		}
+	}
+
+	sparse, _ := termEmbedder{}.EmbedSparse(ctx, "golang rust")
+	results, err := idx.SearchSparse(ctx, sparse, 10, vector.SearchOptions{})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 2 || results[0].Node.ID != "c" || results[0].Score != 2 || results[1].Node.ID != "b" {
+		t.Errorf("expected nodes sharing terms ranked by dot product, got %+v", results)
+	}
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{
+		Index:          idx,
+		SparseEmbedder: termEmbedder{},
+		DefaultTopK:    2,
+	})
+	result, err := retriever.Retrieve(ctx, retrieve.Query{Text: "golang rust", Embedding: []float32{1, 0}})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	// a leads the dense ranking but has no terms, so fusion favors c and b
+	if len(result.Items) != 2 || result.Items[0].ID != "c" || result.Items[1].ID != "b" {
+		t.Errorf("expected fused results [c b], got %+v", result.Items)
+	}
+
+	// Fused scores are scaled like similarities, so MinScore drops only a
+	retriever = vector.NewRetriever(vector.RetrieverConfig{
+		Index:          idx,
+		SparseEmbedder: termEmbedder{},
+		DefaultTopK:    3,
+		MinScore:       0.9,
+	})
+	result, err = retriever.Retrieve(ctx, retrieve.Query{Text: "golang rust", Embedding: []float32{1, 0}})
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].ID != "c" || result.Items[0].Score > 1 || result.Items[1].ID != "b" {
+		t.Errorf("expected fused results [c b] above MinScore, got %+v", result.Items)
+	}
+
+	retriever = vector.NewRetriever(vector.RetrieverConfig{Index: singleVectorIndex{idx}, SparseEmbedder: termEmbedder{}})
+	if _, err := retriever.Retrieve(ctx, retrieve.Query{Text: "golang", Embedding: []float32{1, 0}}); !errors.Is(err, vector.ErrSparseUnsupported) {
+		t.Errorf("expected ErrSparseUnsupported, got %v", err)
+	}
+}
+
+func TestVectorRetrieverMetric(t *testing.T) {
+	ctx := context.Background()
+
+	// b points the same way as the query, a is longer but at an angle
+	idx := memory.NewVectorIndex("test-index")
+	_ = idx.Insert(ctx, vector.Node{ID: "a", Embedding: []float32{3, 3}})
+	_ = idx.Insert(ctx, vector.Node{ID: "b", Embedding: []float32{1, 0}})
+
+	retriever := vector.NewRetriever(vector.RetrieverConfig{Index: idx})
+	for metric, want := range map[string]string{"": "b", "cosine": "b", "dot": "a"} {
+		result, err := retriever.Retrieve(ctx, retrieve.Query{Embedding: []float32{1, 0}, Metric: metric})
+		if err != nil {
+			t.Fatalf("failed to retrieve with metric %q: %v", metric, err)
+		}
+		if result.Items[0].ID != want {
+			t.Errorf("metric %q: expected %s first, got %s", metric, want, result.Items[0].ID)
+		}
+	}
+
+	retriever = vector.NewRetriever(vector.RetrieverConfig{Index: singleVectorIndex{idx}})
+	if _, err := retriever.Retrieve(ctx, retrieve.Query{Embedding: []float32{1, 0}, Metric: "dot"}); !errors.Is(err, vector.ErrMetricUnsupported) {
+		t.Errorf("expected ErrMetricUnsupported, got %v", err)
+	}
+}
+
+func TestAliasesUnsupported(t *testing.T) {
+	manager := &statsManager{}
+	if err := vector.SwapAlias(context.Background(), manager, "docs", "docs_v2"); !errors.Is(err, vector.ErrAliasesUnsupported) {
+		t.Errorf("expected ErrAliasesUnsupported, got %v", err)
+	}
+	if _, err := vector.ResolveAlias(context.Background(), manager, "docs"); !errors.Is(err, vector.ErrAliasesUnsupported) {
+		t.Errorf("expected ErrAliasesUnsupported, got %v", err)
+	}
+}
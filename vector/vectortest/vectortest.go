@@ -0,0 +1,559 @@
+// Package vectortest provides a conformance suite for vector.Index
+// implementations. Provider authors can run it from their own tests to
+// verify that an index honors the contracts the retrievers rely on.
+//
+//	func TestConformance(t *testing.T) {
+//		vectortest.RunIndexTests(t, func(t *testing.T, dimensions int) vector.Index {
+//			return myprovider.New(t.Name(), dimensions)
+//		})
+//	}
+package vectortest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omniretrieve/vector"
+)
+
+// Dimensions is the embedding size used by the conformance suite.
+const Dimensions = 4
+
+// Query is the embedding the suite searches with.
+var Query = []float32{1, 0, 0, 0}
+
+// IndexFactory creates a new, empty index with the given dimensions.
+// It is called once per subtest; use t.Cleanup to release resources.
+type IndexFactory func(t *testing.T, dimensions int) vector.Index
+
+// RunIndexTests runs the vector.Index conformance suite. If the index also
+// implements vector.BatchIndex, vector.FilterIndex, vector.TunableIndex,
+// vector.GetIndex, vector.NamespaceIndex, or vector.ExpiringIndex, those
+// contracts are verified as well.
+func RunIndexTests(t *testing.T, factory IndexFactory) {
+	t.Helper()
+
+	t.Run("EmptySearch", func(t *testing.T) { testEmptySearch(t, factory) })
+	t.Run("InsertAndSearch", func(t *testing.T) { testInsertAndSearch(t, factory) })
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, factory) })
+	t.Run("TopK", func(t *testing.T) { testTopK(t, factory) })
+	t.Run("Filters", func(t *testing.T) { testFilters(t, factory) })
+	t.Run("FilterExpressions", func(t *testing.T) { testFilterExpressions(t, factory) })
+	t.Run("SearchOptions", func(t *testing.T) { testSearchOptions(t, factory) })
+	t.Run("MetricOverride", func(t *testing.T) { testMetricOverride(t, factory) })
+	t.Run("UpsertReplaces", func(t *testing.T) { testUpsertReplaces(t, factory) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, factory) })
+	t.Run("Batch", func(t *testing.T) { testBatch(t, factory) })
+	t.Run("Get", func(t *testing.T) { testGet(t, factory) })
+	t.Run("DimensionMismatch", func(t *testing.T) { testDimensionMismatch(t, factory) })
+	t.Run("Introspection", func(t *testing.T) { testIntrospection(t, factory) })
+	t.Run("GroupedSearch", func(t *testing.T) { testGroupedSearch(t, factory) })
+	t.Run("Namespaces", func(t *testing.T) { testNamespaces(t, factory) })
+	t.Run("Expiration", func(t *testing.T) { testExpiration(t, factory) })
+}
+
+// fixtures returns nodes at decreasing similarity to Query.
+func fixtures() []vector.Node {
+	return []vector.Node{
+		{ID: "a", Content: "alpha", Embedding: []float32{1, 0, 0, 0}, Source: "test", Metadata: map[string]string{"group": "x", "kind": "doc", "rank": "2"}},
+		{ID: "b", Content: "beta", Embedding: []float32{0.8, 0.6, 0, 0}, Source: "test", Metadata: map[string]string{"group": "x", "kind": "note", "rank": "10"}},
+		{ID: "c", Content: "gamma", Embedding: []float32{0, 1, 0, 0}, Source: "test", Metadata: map[string]string{"group": "y", "kind": "doc", "rank": "n/a", "tag": "draft"}},
+	}
+}
+
+func newIndex(t *testing.T, factory IndexFactory, nodes ...vector.Node) vector.Index {
+	t.Helper()
+	idx := factory(t, Dimensions)
+	for _, n := range nodes {
+		if err := idx.Insert(context.Background(), n); err != nil {
+			t.Fatalf("Insert(%s) failed: %v", n.ID, err)
+		}
+	}
+	return idx
+}
+
+func search(t *testing.T, idx vector.Index, k int, filters map[string]string) []vector.SearchResult {
+	t.Helper()
+	results, err := idx.Search(context.Background(), Query, k, filters)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return results
+}
+
+func ids(results []vector.SearchResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Node.ID
+	}
+	return out
+}
+
+func testEmptySearch(t *testing.T, factory IndexFactory) {
+	idx := newIndex(t, factory)
+	if results := search(t, idx, 10, nil); len(results) != 0 {
+		t.Errorf("expected no results from empty index, got %v", ids(results))
+	}
+}
+
+func testInsertAndSearch(t *testing.T, factory IndexFactory) {
+	node := fixtures()[0]
+	idx := newIndex(t, factory, node)
+
+	results := search(t, idx, 10, nil)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	got := results[0].Node
+	if got.ID != node.ID || got.Content != node.Content || got.Source != node.Source {
+		t.Errorf("expected node %+v, got %+v", node, got)
+	}
+	if got.Metadata["group"] != "x" {
+		t.Errorf("expected metadata to round-trip, got %v", got.Metadata)
+	}
+}
+
+func testOrdering(t *testing.T, factory IndexFactory) {
+	idx := newIndex(t, factory, fixtures()...)
+
+	results := search(t, idx, 10, nil)
+	want := []string{"a", "b", "c"}
+	got := ids(results)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("scores not descending: %v", results)
+		}
+	}
+}
+
+func testTopK(t *testing.T, factory IndexFactory) {
+	idx := newIndex(t, factory, fixtures()...)
+
+	results := search(t, idx, 2, nil)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results for k=2, got %d", len(results))
+	}
+	if results[0].Node.ID != "a" || results[1].Node.ID != "b" {
+		t.Errorf("expected [a b], got %v", ids(results))
+	}
+}
+
+func testFilters(t *testing.T, factory IndexFactory) {
+	idx := newIndex(t, factory, fixtures()...)
+
+	results := search(t, idx, 10, map[string]string{"group": "x"})
+	if got := ids(results); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("filter group=x: expected [a b], got %v", got)
+	}
+
+	results = search(t, idx, 10, map[string]string{"group": "x", "kind": "doc"})
+	if got := ids(results); len(got) != 1 || got[0] != "a" {
+		t.Errorf("filter group=x,kind=doc: expected [a], got %v", got)
+	}
+
+	results = search(t, idx, 10, map[string]string{"group": "missing"})
+	if len(results) != 0 {
+		t.Errorf("filter group=missing: expected no results, got %v", ids(results))
+	}
+}
+
+func testFilterExpressions(t *testing.T, factory IndexFactory) {
+	idx, ok := newIndex(t, factory, fixtures()...).(vector.FilterIndex)
+	if !ok {
+		t.Skip("index does not implement vector.FilterIndex")
+	}
+
+	tests := []struct {
+		name   string
+		filter vector.Filter
+		want   []string
+	}{
+		{name: "none", filter: vector.Filter{}, want: []string{"a", "b", "c"}},
+		{name: "eq", filter: vector.Eq("kind", "doc"), want: []string{"a", "c"}},
+		{name: "neq includes missing keys", filter: vector.Neq("tag", "draft"), want: []string{"a", "b"}},
+		{name: "in", filter: vector.In("kind", "note", "memo"), want: []string{"b"}},
+		{name: "numeric gt skips non-numbers", filter: vector.Gt("rank", 5), want: []string{"b"}},
+		{name: "numeric lte", filter: vector.Lte("rank", 2), want: []string{"a"}},
+		{name: "string lt", filter: vector.Lt("rank", "2"), want: []string{"b"}},
+		{name: "contains", filter: vector.Contains("kind", "ot"), want: []string{"b"}},
+		{name: "exists", filter: vector.Exists("tag"), want: []string{"c"}},
+		{name: "and", filter: vector.And(vector.Eq("group", "x"), vector.Gte("rank", 2)), want: []string{"a", "b"}},
+		{name: "or", filter: vector.Or(vector.Eq("kind", "note"), vector.Exists("tag")), want: []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.SearchFilter(context.Background(), Query, 10, tt.filter)
+			if err != nil {
+				t.Fatalf("SearchFilter failed: %v", err)
+			}
+			if got := ids(results); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := idx.SearchFilter(context.Background(), Query, 10, vector.Filter{Op: "near"}); err == nil {
+		t.Error("expected invalid filter to fail")
+	}
+}
+
+func testSearchOptions(t *testing.T, factory IndexFactory) {
+	idx, ok := newIndex(t, factory, fixtures()...).(vector.TunableIndex)
+	if !ok {
+		t.Skip("index does not implement vector.TunableIndex")
+	}
+
+	opts := vector.SearchOptions{Filter: vector.Eq("group", "x"), EfSearch: 100, Probes: 10}
+	results, err := idx.SearchWithOptions(context.Background(), Query, 10, opts)
+	if err != nil {
+		t.Fatalf("SearchWithOptions failed: %v", err)
+	}
+	if got := ids(results); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected [a b], got %v", got)
+	}
+}
+
+func testMetricOverride(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := newIndex(t, factory, fixtures()...).(vector.TunableIndex)
+	if !ok {
+		t.Skip("index does not implement vector.TunableIndex")
+	}
+
+	// The fixtures are unit-length, so every metric ranks them alike
+	for _, metric := range []vector.DistanceMetric{vector.DistanceCosine, vector.DistanceEuclidean, vector.DistanceDot} {
+		results, err := idx.SearchWithOptions(ctx, Query, 10, vector.SearchOptions{Metric: metric})
+		if errors.Is(err, vector.ErrMetricUnsupported) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("SearchWithOptions(%s) failed: %v", metric, err)
+		}
+		if got := strings.Join(ids(results), ","); got != "a,b,c" {
+			t.Errorf("%s: expected [a b c], got %v", metric, got)
+		}
+	}
+
+	if _, err := idx.SearchWithOptions(ctx, Query, 10, vector.SearchOptions{Metric: "bogus"}); !errors.Is(err, vector.ErrMetricUnsupported) {
+		t.Errorf("expected ErrMetricUnsupported for unknown metric, got %v", err)
+	}
+}
+
+func testUpsertReplaces(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx := factory(t, Dimensions)
+
+	node := fixtures()[0]
+	if err := idx.Upsert(ctx, node); err != nil {
+		t.Fatalf("Upsert (insert) failed: %v", err)
+	}
+
+	node.Content = "updated"
+	node.Metadata = map[string]string{"group": "z"}
+	if err := idx.Upsert(ctx, node); err != nil {
+		t.Fatalf("Upsert (update) failed: %v", err)
+	}
+
+	results := search(t, idx, 10, nil)
+	if len(results) != 1 {
+		t.Fatalf("expected upsert to replace, got %d results", len(results))
+	}
+	if results[0].Node.Content != "updated" || results[0].Node.Metadata["group"] != "z" {
+		t.Errorf("expected updated node, got %+v", results[0].Node)
+	}
+}
+
+func testDelete(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx := newIndex(t, factory, fixtures()...)
+
+	if err := idx.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got := ids(search(t, idx, 10, nil)); len(got) != 2 || got[0] != "b" {
+		t.Errorf("expected [b c] after delete, got %v", got)
+	}
+
+	// Deleting a missing ID is not an error
+	if err := idx.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of missing ID failed: %v", err)
+	}
+}
+
+func testBatch(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := factory(t, Dimensions).(vector.BatchIndex)
+	if !ok {
+		t.Skip("index does not implement vector.BatchIndex")
+	}
+
+	// Empty batches are no-ops
+	if err := idx.InsertBatch(ctx, nil); err != nil {
+		t.Errorf("InsertBatch(nil) failed: %v", err)
+	}
+	if err := idx.UpsertBatch(ctx, nil); err != nil {
+		t.Errorf("UpsertBatch(nil) failed: %v", err)
+	}
+	if err := idx.DeleteBatch(ctx, nil); err != nil {
+		t.Errorf("DeleteBatch(nil) failed: %v", err)
+	}
+
+	nodes := fixtures()
+	if err := idx.InsertBatch(ctx, nodes[:2]); err != nil {
+		t.Fatalf("InsertBatch failed: %v", err)
+	}
+
+	nodes[0].Content = "updated"
+	if err := idx.UpsertBatch(ctx, nodes); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+
+	results := search(t, idx, 10, nil)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results after UpsertBatch, got %v", ids(results))
+	}
+	if results[0].Node.Content != "updated" {
+		t.Errorf("expected UpsertBatch to update a, got %+v", results[0].Node)
+	}
+
+	if err := idx.DeleteBatch(ctx, []string{"a", "c"}); err != nil {
+		t.Fatalf("DeleteBatch failed: %v", err)
+	}
+	if got := ids(search(t, idx, 10, nil)); len(got) != 1 || got[0] != "b" {
+		t.Errorf("expected [b] after DeleteBatch, got %v", got)
+	}
+}
+
+func testDimensionMismatch(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx := newIndex(t, factory, fixtures()...)
+
+	node := vector.Node{ID: "wide", Content: "too wide", Embedding: make([]float32, Dimensions+1)}
+	if err := idx.Insert(ctx, node); !errors.Is(err, vector.ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+	if _, err := idx.Search(ctx, node.Embedding, 3, nil); !errors.Is(err, vector.ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch for query, got %v", err)
+	}
+}
+
+func testIntrospection(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := newIndex(t, factory, fixtures()...).(vector.IntrospectableIndex)
+	if !ok {
+		t.Skip("index does not implement vector.IntrospectableIndex")
+	}
+
+	for id, want := range map[string]bool{"a": true, "missing": false} {
+		exists, err := idx.Exists(ctx, id)
+		if err != nil {
+			t.Fatalf("Exists failed: %v", err)
+		}
+		if exists != want {
+			t.Errorf("Exists(%q) = %v, want %v", id, exists, want)
+		}
+	}
+
+	desc, err := idx.Describe(ctx)
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if desc.Dimensions != Dimensions {
+		t.Errorf("expected %d dimensions, got %d", Dimensions, desc.Dimensions)
+	}
+	if err := desc.Check(Dimensions + 1); !errors.Is(err, vector.ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+}
+
+func testGroupedSearch(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := newIndex(t, factory, fixtures()...).(vector.GroupIndex)
+	if !ok {
+		t.Skip("index does not implement vector.GroupIndex")
+	}
+
+	results, err := idx.SearchGroups(ctx, Query, 10, "group", vector.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchGroups failed: %v", err)
+	}
+	if got := strings.Join(ids(results), ","); got != "a,c" {
+		t.Errorf("expected the best node per group [a c], got %v", got)
+	}
+
+	results, err = idx.SearchGroups(ctx, Query, 10, "group", vector.SearchOptions{Filter: vector.Eq("kind", "note")})
+	if err != nil {
+		t.Fatalf("SearchGroups failed: %v", err)
+	}
+	if got := strings.Join(ids(results), ","); got != "b" {
+		t.Errorf("expected filter to apply before grouping, got %v", got)
+	}
+
+	results, err = idx.SearchGroups(ctx, Query, 1, "tag", vector.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchGroups failed: %v", err)
+	}
+	if got := strings.Join(ids(results), ","); got != "c" {
+		t.Errorf("expected nodes without the key to be skipped, got %v", got)
+	}
+}
+
+func testGet(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := newIndex(t, factory, fixtures()...).(vector.GetIndex)
+	if !ok {
+		t.Skip("index does not implement vector.GetIndex")
+	}
+
+	node, err := idx.Get(ctx, "b")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	want := fixtures()[1]
+	if node.ID != want.ID || node.Content != want.Content || node.Source != want.Source ||
+		node.Metadata["kind"] != want.Metadata["kind"] || len(node.Embedding) != Dimensions {
+		t.Errorf("expected %+v, got %+v", want, node)
+	}
+
+	if _, err := idx.Get(ctx, "missing"); !errors.Is(err, vector.ErrNodeNotFound) || !errors.Is(err, vector.ErrNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+
+	nodes, err := idx.GetBatch(ctx, []string{"c", "missing", "a"})
+	if err != nil {
+		t.Fatalf("GetBatch failed: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].ID != "c" || nodes[1].ID != "a" {
+		t.Errorf("expected [c a], got %+v", nodes)
+	}
+}
+
+func testNamespaces(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := newIndex(t, factory, fixtures()[0]).(vector.NamespaceIndex)
+	if !ok {
+		t.Skip("index does not implement vector.NamespaceIndex")
+	}
+	if _, err := idx.Namespaces(ctx); errors.Is(err, vector.ErrNamespacesUnsupported) {
+		t.Skip("index is not configured for namespaces")
+	}
+
+	nsCtx := vector.WithNamespace(ctx, "ns1")
+	b := fixtures()[1]
+	b.Namespace = "ns1"
+	if err := idx.Insert(ctx, b); err != nil {
+		t.Fatalf("Insert(b) failed: %v", err)
+	}
+	if err := idx.Insert(nsCtx, fixtures()[2]); err != nil {
+		t.Fatalf("Insert(c) failed: %v", err)
+	}
+
+	if got := ids(search(t, idx, 10, nil)); len(got) != 1 || got[0] != "a" {
+		t.Errorf("expected [a] in the default namespace, got %v", got)
+	}
+	results, err := idx.Search(nsCtx, Query, 10, nil)
+	if err != nil {
+		t.Fatalf("Search in namespace failed: %v", err)
+	}
+	if got := ids(results); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("expected [b c] in ns1, got %v", got)
+	}
+	for _, r := range results {
+		if r.Node.Namespace != "ns1" {
+			t.Errorf("expected result %s in ns1, got %q", r.Node.ID, r.Node.Namespace)
+		}
+	}
+
+	// Deletes only apply to the context's namespace
+	if err := idx.Delete(nsCtx, "a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got := ids(search(t, idx, 10, nil)); len(got) != 1 || got[0] != "a" {
+		t.Errorf("expected a to survive a delete in ns1, got %v", got)
+	}
+
+	// Upserting an ID taken in another namespace either writes a separate
+	// node or fails with ErrConflict, but never changes the other node
+	moved := fixtures()[0]
+	moved.Content = "moved"
+	err = idx.Upsert(nsCtx, moved)
+	if err != nil && !errors.Is(err, vector.ErrConflict) {
+		t.Fatalf("Upsert in another namespace failed: %v", err)
+	}
+	if err == nil {
+		results, err := idx.Search(nsCtx, Query, 10, nil)
+		if err != nil {
+			t.Fatalf("Search in namespace failed: %v", err)
+		}
+		if len(results) == 0 || results[0].Node.ID != "a" || results[0].Node.Content != "moved" {
+			t.Errorf("expected the upserted node in ns1, got %+v", results)
+		}
+	}
+	if got := search(t, idx, 10, nil); len(got) != 1 || got[0].Node.Content != "alpha" {
+		t.Errorf("expected a to be unchanged in the default namespace, got %+v", got)
+	}
+
+	namespaces, err := idx.Namespaces(ctx)
+	if err != nil {
+		t.Fatalf("Namespaces failed: %v", err)
+	}
+	if len(namespaces) != 2 || namespaces[0] != "" || namespaces[1] != "ns1" {
+		t.Errorf(`expected namespaces ["" ns1], got %q`, namespaces)
+	}
+}
+
+func testExpiration(t *testing.T, factory IndexFactory) {
+	ctx := context.Background()
+	idx, ok := factory(t, Dimensions).(vector.ExpiringIndex)
+	if !ok {
+		t.Skip("index does not implement vector.ExpiringIndex")
+	}
+	if _, err := idx.PurgeExpired(ctx, time.Now()); errors.Is(err, vector.ErrExpirationUnsupported) {
+		t.Skip("index is not configured for expiration")
+	}
+
+	nodes := fixtures()
+	nodes[0].ExpiresAt = time.Now().Add(-time.Minute)
+	nodes[1].ExpiresAt = time.Now().Add(time.Hour)
+	for _, n := range nodes {
+		if err := idx.Insert(ctx, n); err != nil {
+			t.Fatalf("Insert(%s) failed: %v", n.ID, err)
+		}
+	}
+
+	if got := strings.Join(ids(search(t, idx, 10, nil)), ","); got != "b,c" {
+		t.Errorf("expected expired node to be skipped, got %v", got)
+	}
+	if getter, ok := idx.(vector.GetIndex); ok {
+		if _, err := getter.Get(ctx, "a"); !errors.Is(err, vector.ErrNotFound) {
+			t.Errorf("expected expired node to be missing, got %v", err)
+		}
+	}
+
+	purged, err := idx.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("PurgeExpired failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged node, got %d", purged)
+	}
+	if purged, err = idx.PurgeExpired(ctx, time.Now().Add(2*time.Hour)); err != nil || purged != 1 {
+		t.Errorf("expected b to be purged once expired, got %d, %v", purged, err)
+	}
+	if got := strings.Join(ids(search(t, idx, 10, nil)), ","); got != "c" {
+		t.Errorf("expected [c] after purge, got %v", got)
+	}
+}
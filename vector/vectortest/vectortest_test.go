@@ -0,0 +1,15 @@
+package vectortest_test
+
+import (
+	"testing"
+
+	"github.com/agentplexus/omniretrieve/memory"
+	"github.com/agentplexus/omniretrieve/vector"
+	"github.com/agentplexus/omniretrieve/vector/vectortest"
+)
+
+func TestMemoryVectorIndex(t *testing.T) {
+	vectortest.RunIndexTests(t, func(t *testing.T, _ int) vector.Index {
+		return memory.NewVectorIndex(t.Name())
+	})
+}